@@ -9,12 +9,18 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/fintech-multipass/backend/internal/infrastructure/banking"
 	"github.com/fintech-multipass/backend/internal/infrastructure/config"
 	"github.com/fintech-multipass/backend/internal/infrastructure/database"
+	"github.com/fintech-multipass/backend/internal/infrastructure/encryption"
 	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
 	"github.com/fintech-multipass/backend/internal/interfaces/http/router"
 	"github.com/fintech-multipass/backend/internal/infrastructure/cache"
+	"github.com/fintech-multipass/backend/internal/infrastructure/persistence"
 	"github.com/fintech-multipass/backend/internal/infrastructure/queue"
+	"github.com/fintech-multipass/backend/internal/infrastructure/scoring"
+	"github.com/fintech-multipass/backend/internal/infrastructure/validation"
+	"github.com/fintech-multipass/backend/internal/infrastructure/watchlist"
 	"github.com/joho/godotenv"
 )
 
@@ -64,15 +70,46 @@ func main() {
 	}
 	defer cacheClient.Close()
 
+	// Initialize envelope encryption for sensitive banking data
+	cipher, err := encryption.NewEnvelope(cfg.Encryption.Keys, cfg.Encryption.ActiveKeyID)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize encryption")
+	}
+
+	// Initialize banking provider integration
+	providerRepo := persistence.NewBankingProviderRepository(db)
+	circuitBreaker := banking.NewCircuitBreaker(providerRepo, banking.CircuitBreakerConfig{
+		FailureThreshold: cfg.Banking.CircuitFailureThreshold,
+		OpenDuration:     cfg.Banking.CircuitOpenDuration,
+		Window:           cfg.Banking.CircuitWindow,
+	})
+	outboundClient := banking.NewOutboundClient(banking.NewPostgresRateLimiter(db), log)
+	bankingService := banking.NewProviderService(db, providerRepo, banking.NewDefaultRegistry(cfg.Banking.UseStub), circuitBreaker, outboundClient, cipher, cfg.Banking.CallbackURL, log)
+
+	// Initialize risk scoring (versioned models per country)
+	scoringEngine := scoring.NewEngine(persistence.NewScoringModelRepository(db), log)
+
+	// Initialize country rules validation (evaluated during RISK_EVALUATION)
+	ruleValidator := validation.NewRuleValidator(db, log)
+
+	// Initialize sanctions/PEP screening (evaluated during RISK_EVALUATION)
+	screener := watchlist.NewScreener(persistence.NewWatchlistRepository(db), watchlist.DefaultMatchThreshold, log)
+
 	// Initialize job queue
-	jobQueue := queue.NewPostgresQueue(db, log)
+	jobQueue := queue.NewPostgresQueue(db, bankingService, scoringEngine, ruleValidator, persistence.NewCountryRepository(db), screener, log)
 	
+	queueOptions, err := queue.OptionsFromConfig(cfg.Queue)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid queue configuration")
+	}
+	jobQueue.SetOptions(queueOptions)
+
 	// Start queue workers
 	workerCtx, workerCancel := context.WithCancel(context.Background())
 	jobQueue.StartWorkers(workerCtx, cfg.Queue.WorkerCount)
 
 	// Setup router with all dependencies
-	r := router.NewRouter(db, cacheClient, jobQueue, cfg, log)
+	r := router.NewRouter(db, cacheClient, jobQueue, bankingService, cipher, cfg, log)
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -2,19 +2,31 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/fintech-multipass/backend/internal/infrastructure/banking"
 	"github.com/fintech-multipass/backend/internal/infrastructure/config"
 	"github.com/fintech-multipass/backend/internal/infrastructure/database"
+	"github.com/fintech-multipass/backend/internal/infrastructure/encryption"
 	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+	"github.com/fintech-multipass/backend/internal/infrastructure/persistence"
 	"github.com/fintech-multipass/backend/internal/infrastructure/queue"
+	"github.com/fintech-multipass/backend/internal/infrastructure/scoring"
+	"github.com/fintech-multipass/backend/internal/infrastructure/validation"
+	"github.com/fintech-multipass/backend/internal/infrastructure/watchlist"
 	"github.com/joho/godotenv"
 )
 
 // Worker principal para procesamiento asíncrono de trabajos
 func main() {
+	types := flag.String("types", "", "Worker pools per job type, e.g. BANKING_INFO_FETCH:4,NOTIFICATION:2 (\"*:N\" adds a pool for the remaining types)")
+	maxInFlight := flag.String("max-in-flight", "", "Max PROCESSING jobs per type across workers, e.g. BANKING_INFO_FETCH:8")
+	flag.Parse()
+
 	// Load .env file if it exists (for local development)
 	if err := godotenv.Load("../.env"); err != nil {
 		// .env file not found or error loading it, continue with system environment variables
@@ -39,14 +51,65 @@ func main() {
 	}
 	defer db.Close()
 
+	// Initialize envelope encryption for sensitive banking data
+	cipher, err := encryption.NewEnvelope(cfg.Encryption.Keys, cfg.Encryption.ActiveKeyID)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize encryption")
+	}
+
+	// Initialize banking provider integration
+	providerRepo := persistence.NewBankingProviderRepository(db)
+	circuitBreaker := banking.NewCircuitBreaker(providerRepo, banking.CircuitBreakerConfig{
+		FailureThreshold: cfg.Banking.CircuitFailureThreshold,
+		OpenDuration:     cfg.Banking.CircuitOpenDuration,
+		Window:           cfg.Banking.CircuitWindow,
+	})
+	outboundClient := banking.NewOutboundClient(banking.NewPostgresRateLimiter(db), log)
+	bankingService := banking.NewProviderService(db, providerRepo, banking.NewDefaultRegistry(cfg.Banking.UseStub), circuitBreaker, outboundClient, cipher, cfg.Banking.CallbackURL, log)
+
+	// Initialize risk scoring (versioned models per country)
+	scoringEngine := scoring.NewEngine(persistence.NewScoringModelRepository(db), log)
+
+	// Initialize country rules validation (evaluated during RISK_EVALUATION)
+	ruleValidator := validation.NewRuleValidator(db, log)
+
+	// Initialize sanctions/PEP screening (evaluated during RISK_EVALUATION)
+	screener := watchlist.NewScreener(persistence.NewWatchlistRepository(db), watchlist.DefaultMatchThreshold, log)
+
 	// Initialize job queue
-	jobQueue := queue.NewPostgresQueue(db, log)
+	jobQueue := queue.NewPostgresQueue(db, bankingService, scoringEngine, ruleValidator, persistence.NewCountryRepository(db), screener, log)
+
+	queueOptions, err := queue.OptionsFromConfig(cfg.Queue)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid queue configuration")
+	}
+	if *maxInFlight != "" {
+		if queueOptions.MaxInFlight, err = queue.ParseMaxInFlight(*maxInFlight); err != nil {
+			log.Fatal().Err(err).Msg("Invalid --max-in-flight")
+		}
+	}
+	jobQueue.SetOptions(queueOptions)
+
+	// Pools: --types, luego queue.pools y por defecto un pool general de worker_count
+	poolSpec := *types
+	if poolSpec == "" {
+		poolSpec = cfg.Queue.Pools
+	}
+	if poolSpec == "" {
+		poolSpec = fmt.Sprintf("*:%d", cfg.Queue.WorkerCount)
+	}
+	pools, err := queue.ParsePools(poolSpec)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid worker pools")
+	}
 
 	// Start workers
 	ctx, cancel := context.WithCancel(context.Background())
-	jobQueue.StartWorkers(ctx, cfg.Queue.WorkerCount)
+	if err := jobQueue.StartPools(ctx, pools); err != nil {
+		log.Fatal().Err(err).Msg("Failed to start worker pools")
+	}
 
-	log.Info().Int("workers", cfg.Queue.WorkerCount).Msg("Workers started")
+	log.Info().Str("pools", poolSpec).Msg("Workers started")
 
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
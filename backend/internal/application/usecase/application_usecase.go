@@ -2,7 +2,10 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/fintech-multipass/backend/internal/domain/entity"
@@ -13,6 +16,18 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrBankingRefreshNotAllowed la solicitud ya tiene una decisión y no admite nueva consulta bancaria
+var ErrBankingRefreshNotAllowed = errors.New("banking info refresh not allowed in current status")
+
+// ErrDecisionNotFound la solicitud todavía no tiene evaluación de riesgo
+var ErrDecisionNotFound = errors.New("risk decision not found")
+
+// ErrVelocityLimitExceeded el solicitante ya tiene demasiadas solicitudes abiertas en el país
+var ErrVelocityLimitExceeded = errors.New("velocity limit exceeded")
+
+// ErrWatchlistHitPending la solicitud tiene hits de listas sin descartar y no puede aprobarse
+var ErrWatchlistHitPending = errors.New("watchlist hit pending")
+
 // ApplicationUseCase casos de uso para solicitudes de crédito
 type ApplicationUseCase struct {
 	appRepo      repository.CreditApplicationRepository
@@ -76,7 +91,17 @@ func (uc *ApplicationUseCase) CreateApplication(ctx context.Context, input Creat
 		return nil, fmt.Errorf("invalid country: %w", err)
 	}
 
-	// 2. Validar montos contra configuración del país
+	// 2. Validar montos contra la configuración del país vigente en la fecha de la solicitud
+	// (puede haber un cambio programado que ya entró en vigor y aún no está en caché)
+	applicationDate := time.Now()
+	config, err := uc.countryRepo.GetConfigAt(ctx, country.ID, applicationDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get country config: %w", err)
+	}
+	effectiveCountry := *country
+	effectiveCountry.Config = *config
+	country = &effectiveCountry
+
 	if err := uc.validateAmountLimits(input.RequestedAmount, country.Config); err != nil {
 		return nil, err
 	}
@@ -104,11 +129,33 @@ func (uc *ApplicationUseCase) CreateApplication(ctx context.Context, input Creat
 		MonthlyIncome:   input.MonthlyIncome,
 		Status:          entity.StatusPending,
 		RequiresReview:  input.RequestedAmount >= country.Config.ReviewThreshold,
-		ApplicationDate: time.Now(),
+		ApplicationDate: applicationDate,
 		CreatedByIP:     input.IPAddress,
 		UserAgent:       input.UserAgent,
 	}
 
+	// 4b. Controles de velocidad: solicitudes abiertas con el mismo documento, email, teléfono o IP
+	if country.Config.Velocity != nil {
+		check, err := uc.checkVelocity(ctx, app, *country.Config.Velocity)
+		if err != nil {
+			return nil, err
+		}
+		app.VelocityCheck = check
+		if check.IsExceeded() {
+			if check.Action == entity.RuleActionReject {
+				if err := uc.appRepo.RecordVelocityRejection(ctx, country.ID, check, input.DocumentType, input.IPAddress, input.UserAgent); err != nil {
+					uc.log.Error().Err(err).Msg("Failed to record velocity rejection")
+				}
+				uc.log.Warn().
+					Str("country", country.Code).
+					Strs("exceeded", check.Exceeded).
+					Msg("Credit application rejected by velocity limits")
+				return nil, fmt.Errorf("%w: too many open applications for %s", ErrVelocityLimitExceeded, strings.Join(check.Exceeded, ", "))
+			}
+			app.RequiresReview = true
+		}
+	}
+
 	// 5. Guardar en base de datos
 	// El trigger de PostgreSQL creará automáticamente los jobs de validación y obtención de info bancaria
 	if err := uc.appRepo.Create(ctx, app); err != nil {
@@ -185,6 +232,12 @@ func (uc *ApplicationUseCase) UpdateStatus(ctx context.Context, input UpdateStat
 		return nil, fmt.Errorf("invalid status transition from %s to %s", app.Status, input.NewStatus)
 	}
 
+	// 2b. No aprobar con hits de listas pendientes o confirmados
+	if input.NewStatus == entity.StatusApproved &&
+		(app.ScreeningStatus == entity.ScreeningPotentialHit || app.ScreeningStatus == entity.ScreeningConfirmedHit) {
+		return nil, fmt.Errorf("%w: screening status is %s", ErrWatchlistHitPending, app.ScreeningStatus)
+	}
+
 	oldStatus := app.Status
 
 	// 3. Actualizar estado
@@ -230,6 +283,79 @@ func (uc *ApplicationUseCase) GetApplicationHistory(ctx context.Context, id uuid
 	return uc.appRepo.GetStateTransitions(ctx, id)
 }
 
+// GetDecision obtiene la última evaluación de riesgo de una solicitud con su
+// desglose por factor y los códigos de motivo
+func (uc *ApplicationUseCase) GetDecision(ctx context.Context, id uuid.UUID) (*entity.RiskDecision, error) {
+	if _, err := uc.appRepo.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("application not found: %w", err)
+	}
+
+	decision, err := uc.appRepo.GetLatestRiskDecision(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if decision == nil {
+		return nil, ErrDecisionNotFound
+	}
+	return decision, nil
+}
+
+// GetDecisionHistory obtiene todas las evaluaciones de riesgo de una solicitud
+func (uc *ApplicationUseCase) GetDecisionHistory(ctx context.Context, id uuid.UUID) ([]entity.RiskDecision, error) {
+	return uc.appRepo.ListRiskDecisions(ctx, id)
+}
+
+// RefreshBankingInfo fuerza una nueva consulta al proveedor bancario, ignorando
+// los reportes reutilizables de otras solicitudes del mismo documento
+func (uc *ApplicationUseCase) RefreshBankingInfo(ctx context.Context, id uuid.UUID) (*entity.Job, error) {
+	if uc.jobQueue == nil {
+		return nil, fmt.Errorf("job queue not configured")
+	}
+
+	app, err := uc.appRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("application not found: %w", err)
+	}
+
+	switch app.Status {
+	case entity.StatusPending, entity.StatusValidating, entity.StatusPendingBankInfo:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrBankingRefreshNotAllowed, app.Status)
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"application_id":  app.ID.String(),
+		"country_id":      app.CountryID.String(),
+		"document_type":   app.DocumentType,
+		"document_number": app.DocumentNumber,
+		"force_refresh":   true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build job payload: %w", err)
+	}
+
+	job := &entity.Job{
+		ID:       uuid.New(),
+		Type:     entity.JobTypeBankingInfoFetch,
+		Priority: 8,
+		Payload:  payload,
+	}
+	if err := uc.jobQueue.Enqueue(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to enqueue banking refresh: %w", err)
+	}
+
+	if uc.cache != nil {
+		_ = uc.cache.InvalidateApplication(ctx, app.ID)
+	}
+
+	uc.log.Info().
+		Str("application_id", app.ID.String()).
+		Str("job_id", job.ID.String()).
+		Msg("Forced banking info refresh enqueued")
+
+	return job, nil
+}
+
 // Helper methods
 
 func (uc *ApplicationUseCase) getCountryByCode(ctx context.Context, code string) (*entity.Country, error) {
@@ -265,6 +391,69 @@ func (uc *ApplicationUseCase) validateAmountLimits(amount float64, config entity
 	return nil
 }
 
+// checkVelocity cuenta las solicitudes abiertas previas del país dentro de la ventana y
+// marca los límites superados (el límite cuenta también la nueva solicitud)
+func (uc *ApplicationUseCase) checkVelocity(ctx context.Context, app *entity.CreditApplication, config entity.VelocityConfig) (*entity.VelocityCheck, error) {
+	now := time.Now()
+	var since *time.Time
+	if config.WindowHours > 0 {
+		from := now.Add(-time.Duration(config.WindowHours) * time.Hour)
+		since = &from
+	}
+
+	keys := entity.VelocityKeys{
+		DocumentNumber: app.DocumentNumber,
+		Email:          strings.ToLower(strings.TrimSpace(app.Email)),
+		Phone:          digitsOnly(app.Phone),
+		IP:             app.CreatedByIP,
+	}
+	counts, err := uc.appRepo.CountOpenApplications(ctx, app.CountryID, since, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check velocity limits: %w", err)
+	}
+
+	check := &entity.VelocityCheck{
+		WindowHours: config.WindowHours,
+		Counts:      *counts,
+		Limits:      config,
+		CheckedAt:   now,
+	}
+	limits := []struct {
+		name  string
+		count int
+		max   int
+		key   string
+	}{
+		{entity.VelocityDocument, counts.Document, config.MaxPerDocument, keys.DocumentNumber},
+		{entity.VelocityEmail, counts.Email, config.MaxPerEmail, keys.Email},
+		{entity.VelocityPhone, counts.Phone, config.MaxPerPhone, keys.Phone},
+		{entity.VelocityIP, counts.IP, config.MaxPerIP, keys.IP},
+	}
+	for _, l := range limits {
+		if l.max > 0 && l.key != "" && l.count >= l.max {
+			check.Exceeded = append(check.Exceeded, l.name)
+		}
+	}
+	if check.IsExceeded() {
+		check.Action = config.Action
+		if check.Action == "" {
+			check.Action = entity.RuleActionReject
+		}
+	}
+	return check, nil
+}
+
+// digitsOnly deja solo los dígitos de un teléfono
+func digitsOnly(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
 func (uc *ApplicationUseCase) isValidDocumentType(docType string, validTypes []entity.DocumentType) bool {
 	for _, dt := range validTypes {
 		if dt.Code == docType {
@@ -0,0 +1,687 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/domain/repository"
+	"github.com/fintech-multipass/backend/internal/infrastructure/validation"
+	"github.com/google/uuid"
+)
+
+// Límites del historial de versiones
+const (
+	DefaultConfigVersionsLimit = 50
+	MaxConfigVersionsLimit     = 500
+)
+
+var (
+	// ErrInvalidDocumentType el tipo de documento no es válido (código repetido, regex que no compila)
+	ErrInvalidDocumentType = errors.New("invalid document type")
+	// ErrInvalidCountryConfig la configuración del país no es válida
+	ErrInvalidCountryConfig = errors.New("invalid country config")
+	// ErrConfigConflict otra modificación cambió la configuración del país al mismo tiempo
+	ErrConfigConflict = errors.New("country config was modified concurrently")
+	// ErrConfigVersionNotFound la versión de configuración no existe
+	ErrConfigVersionNotFound = errors.New("config version not found")
+	// ErrNoConfigChange el cambio (rollback o programado) no modifica la configuración vigente
+	ErrNoConfigChange = errors.New("no config change")
+	// ErrRuleNotFound la regla no existe en el país
+	ErrRuleNotFound = errors.New("rule not found")
+	// ErrDocumentTypeNotFound el tipo de documento no existe en el país
+	ErrDocumentTypeNotFound = errors.New("document type not found")
+)
+
+// snapshotIgnoredFields campos que no forman parte del diff entre versiones
+var snapshotIgnoredFields = map[string]bool{
+	"id":         true,
+	"country_id": true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// CreateRule valida y crea una regla de un país guardando una nueva versión
+func (uc *CountryUseCase) CreateRule(ctx context.Context, rule *entity.CountryRule, actor *uuid.UUID) error {
+	if err := validation.CompileRule(rule); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidRule, err.Error())
+	}
+	if rule.ID == uuid.Nil {
+		rule.ID = uuid.New()
+	}
+
+	change := configChange{changeType: entity.ConfigChangeRuleCreated, actor: actor}
+	if _, err := uc.applyChange(ctx, rule.CountryID, change, func(s *entity.CountrySnapshot) (string, error) {
+		s.Rules = append(s.Rules, *rule)
+		return fmt.Sprintf("Rule %q created", rule.Name), nil
+	}); err != nil {
+		return err
+	}
+
+	uc.log.Info().
+		Str("rule_id", rule.ID.String()).
+		Str("country_id", rule.CountryID.String()).
+		Str("rule_type", string(rule.RuleType)).
+		Msg("Country rule created")
+
+	return uc.reloadRule(ctx, rule)
+}
+
+// UpdateRule valida y actualiza una regla de un país guardando una nueva versión
+func (uc *CountryUseCase) UpdateRule(ctx context.Context, rule *entity.CountryRule, actor *uuid.UUID) error {
+	if err := validation.CompileRule(rule); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidRule, err.Error())
+	}
+
+	change := configChange{changeType: entity.ConfigChangeRuleUpdated, actor: actor}
+	if _, err := uc.applyChange(ctx, rule.CountryID, change, func(s *entity.CountrySnapshot) (string, error) {
+		for i := range s.Rules {
+			if s.Rules[i].ID == rule.ID {
+				s.Rules[i] = *rule
+				return fmt.Sprintf("Rule %q updated", rule.Name), nil
+			}
+		}
+		return "", ErrRuleNotFound
+	}); err != nil {
+		return err
+	}
+
+	uc.log.Info().
+		Str("rule_id", rule.ID.String()).
+		Str("country_id", rule.CountryID.String()).
+		Str("rule_type", string(rule.RuleType)).
+		Msg("Country rule updated")
+
+	return uc.reloadRule(ctx, rule)
+}
+
+// DeleteRule elimina una regla de un país; la regla sigue en las versiones anteriores
+func (uc *CountryUseCase) DeleteRule(ctx context.Context, countryID, ruleID uuid.UUID, actor *uuid.UUID) (*entity.CountryConfigVersion, error) {
+	change := configChange{changeType: entity.ConfigChangeRuleDeleted, actor: actor}
+	version, err := uc.applyChange(ctx, countryID, change, func(s *entity.CountrySnapshot) (string, error) {
+		for i := range s.Rules {
+			if s.Rules[i].ID == ruleID {
+				name := s.Rules[i].Name
+				s.Rules = append(s.Rules[:i], s.Rules[i+1:]...)
+				return fmt.Sprintf("Rule %q deleted", name), nil
+			}
+		}
+		return "", ErrRuleNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uc.log.Info().
+		Str("rule_id", ruleID.String()).
+		Str("country_id", countryID.String()).
+		Msg("Country rule deleted")
+
+	return version, nil
+}
+
+// GetAllRules obtiene todas las reglas de un país, incluidas las inactivas
+func (uc *CountryUseCase) GetAllRules(ctx context.Context, countryID uuid.UUID) ([]entity.CountryRule, error) {
+	snapshot, _, err := uc.countryRepo.GetSnapshot(ctx, countryID)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Rules, nil
+}
+
+// CreateDocumentType valida y crea un tipo de documento de un país guardando una nueva versión
+func (uc *CountryUseCase) CreateDocumentType(ctx context.Context, dt *entity.DocumentType, actor *uuid.UUID) error {
+	if dt.ID == uuid.Nil {
+		dt.ID = uuid.New()
+	}
+
+	change := configChange{changeType: entity.ConfigChangeDocumentTypeCreated, actor: actor}
+	if _, err := uc.applyChange(ctx, dt.CountryID, change, func(s *entity.CountrySnapshot) (string, error) {
+		s.DocumentTypes = append(s.DocumentTypes, *dt)
+		if err := validateDocumentTypes(s.DocumentTypes); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Document type %s created", strings.ToUpper(dt.Code)), nil
+	}); err != nil {
+		return err
+	}
+
+	uc.log.Info().
+		Str("document_type", dt.Code).
+		Str("country_id", dt.CountryID.String()).
+		Msg("Document type created")
+
+	return uc.reloadDocumentType(ctx, dt)
+}
+
+// UpdateDocumentType valida y actualiza un tipo de documento guardando una nueva versión
+func (uc *CountryUseCase) UpdateDocumentType(ctx context.Context, dt *entity.DocumentType, actor *uuid.UUID) error {
+	change := configChange{changeType: entity.ConfigChangeDocumentTypeUpdated, actor: actor}
+	if _, err := uc.applyChange(ctx, dt.CountryID, change, func(s *entity.CountrySnapshot) (string, error) {
+		for i := range s.DocumentTypes {
+			if s.DocumentTypes[i].ID == dt.ID {
+				s.DocumentTypes[i] = *dt
+				if err := validateDocumentTypes(s.DocumentTypes); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("Document type %s updated", s.DocumentTypes[i].Code), nil
+			}
+		}
+		return "", ErrDocumentTypeNotFound
+	}); err != nil {
+		return err
+	}
+
+	uc.log.Info().
+		Str("document_type", dt.Code).
+		Str("country_id", dt.CountryID.String()).
+		Msg("Document type updated")
+
+	return uc.reloadDocumentType(ctx, dt)
+}
+
+// DeleteDocumentType elimina un tipo de documento de un país
+// Las solicitudes existentes conservan su tipo; las nuevas ya no podrán usarlo
+func (uc *CountryUseCase) DeleteDocumentType(ctx context.Context, countryID, id uuid.UUID, actor *uuid.UUID) (*entity.CountryConfigVersion, error) {
+	change := configChange{changeType: entity.ConfigChangeDocumentTypeDeleted, actor: actor}
+	version, err := uc.applyChange(ctx, countryID, change, func(s *entity.CountrySnapshot) (string, error) {
+		for i := range s.DocumentTypes {
+			if s.DocumentTypes[i].ID == id {
+				code := s.DocumentTypes[i].Code
+				s.DocumentTypes = append(s.DocumentTypes[:i], s.DocumentTypes[i+1:]...)
+				return fmt.Sprintf("Document type %s deleted", code), nil
+			}
+		}
+		return "", ErrDocumentTypeNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uc.log.Info().
+		Str("document_type_id", id.String()).
+		Str("country_id", countryID.String()).
+		Msg("Document type deleted")
+
+	return version, nil
+}
+
+// UpdateConfig valida y reemplaza la configuración de un país guardando una nueva versión
+// Con effectiveFrom (fecha futura en la zona horaria del país) el cambio queda programado:
+// no altera la configuración actual y rige para las solicitudes desde esa fecha
+func (uc *CountryUseCase) UpdateConfig(ctx context.Context, country *entity.Country, config entity.CountryConfig, effectiveFrom string, actor *uuid.UUID) (*entity.CountryConfigVersion, error) {
+	if err := validateCountryConfig(config); err != nil {
+		return nil, err
+	}
+
+	change := configChange{changeType: entity.ConfigChangeConfigUpdated, actor: actor}
+	summary := "Country config updated"
+	if effectiveFrom != "" {
+		at, err := ParseEffectiveFrom(effectiveFrom, country.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		if !at.After(time.Now()) {
+			return nil, fmt.Errorf("%w: effective_from must be in the future", ErrInvalidCountryConfig)
+		}
+		change.changeType = entity.ConfigChangeConfigScheduled
+		change.effectiveFrom = &at
+		summary = fmt.Sprintf("Country config scheduled from %s", at.Format(time.RFC3339))
+	}
+
+	version, err := uc.applyChange(ctx, country.ID, change, func(s *entity.CountrySnapshot) (string, error) {
+		s.Config = config
+		return summary, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if version == nil && change.effectiveFrom != nil {
+		return nil, ErrNoConfigChange
+	}
+
+	event := uc.log.Info().Str("country", country.Code)
+	if change.effectiveFrom != nil {
+		event = event.Time("effective_from", *change.effectiveFrom)
+	}
+	event.Msg(summary)
+
+	return version, nil
+}
+
+// CancelScheduledConfig cancela un cambio de configuración programado que aún no entró en vigor
+func (uc *CountryUseCase) CancelScheduledConfig(ctx context.Context, countryID uuid.UUID, version int, actor *uuid.UUID) (*entity.CountryConfigVersion, error) {
+	if err := uc.countryRepo.CancelConfigVersion(ctx, countryID, version, actor); err != nil {
+		return nil, fmt.Errorf("%w: version %d is not a pending scheduled change", ErrConfigVersionNotFound, version)
+	}
+
+	uc.log.Info().
+		Str("country_id", countryID.String()).
+		Int("version", version).
+		Msg("Scheduled country config cancelled")
+
+	return uc.countryRepo.GetConfigVersion(ctx, countryID, version)
+}
+
+// ParseEffectiveFrom interpreta una fecha de entrada en vigor en la zona horaria del país
+// Acepta RFC3339 (con zona explícita) o fecha y hora local: 2006-01-02, 2006-01-02T15:04 o 2006-01-02T15:04:05
+func ParseEffectiveFrom(value, timezone string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to load country timezone %s: %w", timezone, err)
+	}
+	for _, layout := range []string{"2006-01-02", "2006-01-02T15:04", "2006-01-02T15:04:05"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: effective_from %q is not a valid date", ErrInvalidCountryConfig, value)
+}
+
+// ListConfigVersions lista el historial de versiones de un país
+func (uc *CountryUseCase) ListConfigVersions(ctx context.Context, countryID uuid.UUID, limit int) ([]entity.CountryConfigVersion, error) {
+	if limit <= 0 {
+		limit = DefaultConfigVersionsLimit
+	}
+	if limit > MaxConfigVersionsLimit {
+		limit = MaxConfigVersionsLimit
+	}
+	return uc.countryRepo.ListConfigVersions(ctx, countryID, limit)
+}
+
+// GetConfigVersion obtiene una versión con su foto completa
+func (uc *CountryUseCase) GetConfigVersion(ctx context.Context, countryID uuid.UUID, version int) (*entity.CountryConfigVersion, error) {
+	v, err := uc.countryRepo.GetConfigVersion(ctx, countryID, version)
+	if err != nil {
+		return nil, ErrConfigVersionNotFound
+	}
+	return v, nil
+}
+
+// RollbackConfig restaura la foto de una versión anterior como una versión nueva
+func (uc *CountryUseCase) RollbackConfig(ctx context.Context, countryID uuid.UUID, target int, actor *uuid.UUID) (*entity.CountryConfigVersion, error) {
+	previous, err := uc.countryRepo.GetConfigVersion(ctx, countryID, target)
+	if err != nil {
+		return nil, ErrConfigVersionNotFound
+	}
+
+	change := configChange{changeType: entity.ConfigChangeRollback, actor: actor, rolledBackTo: &target}
+	version, err := uc.applyChange(ctx, countryID, change, func(s *entity.CountrySnapshot) (string, error) {
+		*s = *previous.Snapshot
+		return fmt.Sprintf("Rolled back to version %d", target), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if version == nil {
+		return nil, ErrNoConfigChange
+	}
+
+	uc.log.Info().
+		Str("country_id", countryID.String()).
+		Int("version", version.Version).
+		Int("rolled_back_to", target).
+		Msg("Country config rolled back")
+
+	return version, nil
+}
+
+// configChange datos de la versión que genera un cambio
+type configChange struct {
+	changeType    entity.ConfigChangeType
+	actor         *uuid.UUID
+	rolledBackTo  *int
+	effectiveFrom *time.Time // Solo cambios de configuración programados
+}
+
+// applyChange aplica un cambio sobre la foto actual del país y la guarda como una versión nueva
+// mutate modifica la foto y devuelve el resumen del cambio. Si el país nunca se versionó,
+// antes guarda su estado actual como versión 1 (INITIAL, vigente desde siempre) para poder
+// volver a él. Un cambio programado parte de la configuración vigente en su fecha.
+// Un cambio sin diferencias no crea versión y devuelve nil
+func (uc *CountryUseCase) applyChange(ctx context.Context, countryID uuid.UUID, change configChange, mutate func(*entity.CountrySnapshot) (string, error)) (*entity.CountryConfigVersion, error) {
+	current, currentVersion, err := uc.countryRepo.GetSnapshot(ctx, countryID)
+	if err != nil {
+		return nil, ErrCountryNotFound
+	}
+
+	previous := current
+	if change.effectiveFrom != nil {
+		config, err := uc.countryRepo.GetConfigAt(ctx, countryID, *change.effectiveFrom)
+		if err != nil {
+			return nil, err
+		}
+		previous = &entity.CountrySnapshot{Config: *config, Rules: current.Rules, DocumentTypes: current.DocumentTypes}
+	}
+
+	next, err := cloneSnapshot(previous)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := mutate(next)
+	if err != nil {
+		return nil, err
+	}
+	for i := range next.Rules {
+		next.Rules[i].CountryID = countryID
+	}
+	for i := range next.DocumentTypes {
+		next.DocumentTypes[i].CountryID = countryID
+	}
+	sortSnapshot(next)
+
+	diff, err := diffSnapshots(previous, next)
+	if err != nil {
+		return nil, err
+	}
+	if len(diff) == 0 {
+		return nil, nil
+	}
+
+	var versions []entity.CountryConfigVersion
+	base := currentVersion
+	if base == 0 {
+		versions = append(versions, entity.CountryConfigVersion{
+			CountryID:     countryID,
+			Version:       1,
+			ChangeType:    entity.ConfigChangeInitial,
+			Summary:       "Configuration before the first versioned change",
+			Snapshot:      current,
+			Diff:          []entity.ConfigChange{},
+			EffectiveFrom: time.Unix(0, 0).UTC(),
+		})
+		base = 1
+	}
+	versions = append(versions, entity.CountryConfigVersion{
+		CountryID:    countryID,
+		Version:      base + 1,
+		ChangeType:   change.changeType,
+		Summary:      summary,
+		Snapshot:     next,
+		Diff:         diff,
+		RolledBackTo: change.rolledBackTo,
+		CreatedBy:    change.actor,
+	})
+	if change.effectiveFrom != nil {
+		versions[len(versions)-1].EffectiveFrom = *change.effectiveFrom
+	}
+
+	if err := uc.countryRepo.SaveConfigVersions(ctx, currentVersion, versions); err != nil {
+		if errors.Is(err, repository.ErrConfigVersionConflict) {
+			return nil, ErrConfigConflict
+		}
+		return nil, fmt.Errorf("failed to save config version: %w", err)
+	}
+
+	uc.invalidateCountryCache(ctx, countryID)
+
+	version := versions[len(versions)-1]
+	return &version, nil
+}
+
+// invalidateCountryCache refresca el país y el listado de países en caché
+func (uc *CountryUseCase) invalidateCountryCache(ctx context.Context, countryID uuid.UUID) {
+	if uc.cache == nil {
+		return
+	}
+
+	country, err := uc.countryRepo.GetByID(ctx, countryID)
+	if err != nil {
+		uc.log.Warn().Err(err).Str("country_id", countryID.String()).Msg("Failed to reload country for cache")
+		return
+	}
+	if err := uc.cache.SetCountry(ctx, country); err != nil {
+		uc.log.Warn().Err(err).Str("country", country.Code).Msg("Failed to refresh country cache")
+	}
+
+	countries, err := uc.countryRepo.GetAll(ctx, true)
+	if err != nil {
+		uc.log.Warn().Err(err).Msg("Failed to reload countries for cache")
+		return
+	}
+	if err := uc.cache.SetAllCountries(ctx, countries); err != nil {
+		uc.log.Warn().Err(err).Msg("Failed to refresh countries cache")
+	}
+}
+
+// reloadRule recarga la regla guardada (fechas asignadas por la base de datos)
+func (uc *CountryUseCase) reloadRule(ctx context.Context, rule *entity.CountryRule) error {
+	saved, err := uc.countryRepo.GetRuleByID(ctx, rule.ID)
+	if err != nil {
+		return fmt.Errorf("failed to reload rule: %w", err)
+	}
+	*rule = *saved
+	return nil
+}
+
+// reloadDocumentType recarga el tipo de documento guardado
+func (uc *CountryUseCase) reloadDocumentType(ctx context.Context, dt *entity.DocumentType) error {
+	snapshot, _, err := uc.countryRepo.GetSnapshot(ctx, dt.CountryID)
+	if err != nil {
+		return fmt.Errorf("failed to reload document type: %w", err)
+	}
+	for _, saved := range snapshot.DocumentTypes {
+		if saved.ID == dt.ID {
+			*dt = saved
+			return nil
+		}
+	}
+	return ErrDocumentTypeNotFound
+}
+
+// validateDocumentTypes códigos obligatorios y únicos por país, nombre obligatorio y regex válida
+func validateDocumentTypes(docTypes []entity.DocumentType) error {
+	seen := make(map[string]bool, len(docTypes))
+	for i := range docTypes {
+		dt := &docTypes[i]
+		dt.Code = strings.ToUpper(strings.TrimSpace(dt.Code))
+		if dt.Code == "" || dt.Name == "" {
+			return fmt.Errorf("%w: code and name are required", ErrInvalidDocumentType)
+		}
+		if len(dt.Code) > 20 {
+			return fmt.Errorf("%w: code %s is longer than 20 characters", ErrInvalidDocumentType, dt.Code)
+		}
+		if seen[dt.Code] {
+			return fmt.Errorf("%w: code %s already exists", ErrInvalidDocumentType, dt.Code)
+		}
+		seen[dt.Code] = true
+		if dt.ValidationRegex != "" {
+			if _, err := regexp.Compile(dt.ValidationRegex); err != nil {
+				return fmt.Errorf("%w: validation_regex: %s", ErrInvalidDocumentType, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// validateCountryConfig límites coherentes y acción de documento inválido conocida
+func validateCountryConfig(config entity.CountryConfig) error {
+	switch {
+	case config.MinLoanAmount < 0 || config.MaxLoanAmount <= 0:
+		return fmt.Errorf("%w: loan amounts must be positive", ErrInvalidCountryConfig)
+	case config.MinLoanAmount > config.MaxLoanAmount:
+		return fmt.Errorf("%w: min_loan_amount is greater than max_loan_amount", ErrInvalidCountryConfig)
+	case config.MinIncomeRequired < 0 || config.ReviewThreshold < 0:
+		return fmt.Errorf("%w: min_income_required and review_threshold cannot be negative", ErrInvalidCountryConfig)
+	case config.MaxDebtToIncomeRatio <= 0:
+		return fmt.Errorf("%w: max_debt_to_income_ratio must be positive", ErrInvalidCountryConfig)
+	case config.MinCreditScore < 0:
+		return fmt.Errorf("%w: min_credit_score cannot be negative", ErrInvalidCountryConfig)
+	}
+	switch config.InvalidDocumentAction {
+	case "", entity.RuleActionReject, entity.RuleActionRequireReview:
+	default:
+		return fmt.Errorf("%w: invalid_document_action must be REJECT or REQUIRE_REVIEW", ErrInvalidCountryConfig)
+	}
+	if v := config.Velocity; v != nil {
+		if v.WindowHours < 0 || v.MaxPerDocument < 0 || v.MaxPerEmail < 0 || v.MaxPerPhone < 0 || v.MaxPerIP < 0 {
+			return fmt.Errorf("%w: velocity window and limits cannot be negative", ErrInvalidCountryConfig)
+		}
+		switch v.Action {
+		case "", entity.RuleActionReject, entity.RuleActionRequireReview:
+		default:
+			return fmt.Errorf("%w: velocity action must be REJECT or REQUIRE_REVIEW", ErrInvalidCountryConfig)
+		}
+	}
+	if f := config.Fraud; f != nil {
+		switch {
+		case f.ReviewThreshold < 0 || f.ReviewThreshold > 100:
+			return fmt.Errorf("%w: fraud review_threshold must be between 0 and 100", ErrInvalidCountryConfig)
+		case f.LookbackDays < 0 || f.MaxDocumentsPerContact < 0 || f.MaxDebtToAnnualIncome < 0 ||
+			f.SubnetWindowHours < 0 || f.MaxApplicationsPerSubnet < 0:
+			return fmt.Errorf("%w: fraud settings cannot be negative", ErrInvalidCountryConfig)
+		}
+	}
+	return nil
+}
+
+// cloneSnapshot copia profunda de la foto (las configs de reglas son mapas)
+func cloneSnapshot(s *entity.CountrySnapshot) (*entity.CountrySnapshot, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy snapshot: %w", err)
+	}
+	var clone entity.CountrySnapshot
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, fmt.Errorf("failed to copy snapshot: %w", err)
+	}
+	return &clone, nil
+}
+
+// sortSnapshot mismo orden que GetSnapshot para que las fotos sean comparables
+func sortSnapshot(s *entity.CountrySnapshot) {
+	sort.SliceStable(s.Rules, func(i, j int) bool {
+		if s.Rules[i].Priority != s.Rules[j].Priority {
+			return s.Rules[i].Priority > s.Rules[j].Priority
+		}
+		return s.Rules[i].Name < s.Rules[j].Name
+	})
+	sort.SliceStable(s.DocumentTypes, func(i, j int) bool {
+		return s.DocumentTypes[i].Code < s.DocumentTypes[j].Code
+	})
+}
+
+// diffSnapshots cambios de configuración, reglas y tipos de documento entre dos fotos
+func diffSnapshots(before, after *entity.CountrySnapshot) ([]entity.ConfigChange, error) {
+	changes := []entity.ConfigChange{}
+
+	fields, err := diffFields(before.Config, after.Config)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 {
+		changes = append(changes, entity.ConfigChange{
+			Entity: "config",
+			Action: entity.ConfigActionUpdated,
+			Fields: fields,
+		})
+	}
+
+	beforeRules := make(map[uuid.UUID]entity.CountryRule, len(before.Rules))
+	for _, rule := range before.Rules {
+		beforeRules[rule.ID] = rule
+	}
+	afterRules := make(map[uuid.UUID]bool, len(after.Rules))
+	for _, rule := range after.Rules {
+		afterRules[rule.ID] = true
+		old, ok := beforeRules[rule.ID]
+		if !ok {
+			changes = append(changes, entity.ConfigChange{Entity: "rule", ID: rule.ID.String(), Name: rule.Name, Action: entity.ConfigActionCreated})
+			continue
+		}
+		fields, err := diffFields(old, rule)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) > 0 {
+			changes = append(changes, entity.ConfigChange{Entity: "rule", ID: rule.ID.String(), Name: rule.Name, Action: entity.ConfigActionUpdated, Fields: fields})
+		}
+	}
+	for _, rule := range before.Rules {
+		if !afterRules[rule.ID] {
+			changes = append(changes, entity.ConfigChange{Entity: "rule", ID: rule.ID.String(), Name: rule.Name, Action: entity.ConfigActionDeleted})
+		}
+	}
+
+	beforeDocs := make(map[uuid.UUID]entity.DocumentType, len(before.DocumentTypes))
+	for _, dt := range before.DocumentTypes {
+		beforeDocs[dt.ID] = dt
+	}
+	afterDocs := make(map[uuid.UUID]bool, len(after.DocumentTypes))
+	for _, dt := range after.DocumentTypes {
+		afterDocs[dt.ID] = true
+		old, ok := beforeDocs[dt.ID]
+		if !ok {
+			changes = append(changes, entity.ConfigChange{Entity: "document_type", ID: dt.ID.String(), Name: dt.Code, Action: entity.ConfigActionCreated})
+			continue
+		}
+		fields, err := diffFields(old, dt)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) > 0 {
+			changes = append(changes, entity.ConfigChange{Entity: "document_type", ID: dt.ID.String(), Name: dt.Code, Action: entity.ConfigActionUpdated, Fields: fields})
+		}
+	}
+	for _, dt := range before.DocumentTypes {
+		if !afterDocs[dt.ID] {
+			changes = append(changes, entity.ConfigChange{Entity: "document_type", ID: dt.ID.String(), Name: dt.Code, Action: entity.ConfigActionDeleted})
+		}
+	}
+
+	return changes, nil
+}
+
+// diffFields compara dos valores campo a campo según su representación JSON
+func diffFields(before, after interface{}) (map[string]entity.FieldChange, error) {
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]entity.FieldChange)
+	for key, value := range afterFields {
+		if !reflect.DeepEqual(beforeFields[key], value) {
+			fields[key] = entity.FieldChange{Before: beforeFields[key], After: value}
+		}
+	}
+	for key, value := range beforeFields {
+		if _, ok := afterFields[key]; !ok {
+			fields[key] = entity.FieldChange{Before: value, After: nil}
+		}
+	}
+	return fields, nil
+}
+
+func jsonFields(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot item: %w", err)
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot item: %w", err)
+	}
+	for key := range snapshotIgnoredFields {
+		delete(fields, key)
+	}
+	return fields, nil
+}
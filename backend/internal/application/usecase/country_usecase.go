@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 
 	"github.com/fintech-multipass/backend/internal/domain/entity"
 	"github.com/fintech-multipass/backend/internal/domain/repository"
@@ -10,6 +11,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrInvalidRule la configuración de la regla no es válida (ej. expresión CUSTOM que no compila)
+var ErrInvalidRule = errors.New("invalid rule")
+
 // CountryUseCase casos de uso para países
 type CountryUseCase struct {
 	countryRepo repository.CountryRepository
@@ -88,6 +92,11 @@ func (uc *CountryUseCase) GetCountryRules(ctx context.Context, countryID uuid.UU
 	return uc.countryRepo.GetRules(ctx, countryID)
 }
 
+// GetRule obtiene una regla por ID
+func (uc *CountryUseCase) GetRule(ctx context.Context, id uuid.UUID) (*entity.CountryRule, error) {
+	return uc.countryRepo.GetRuleByID(ctx, id)
+}
+
 // GetCountryDocumentTypes obtiene los tipos de documento de un país
 func (uc *CountryUseCase) GetCountryDocumentTypes(ctx context.Context, countryID uuid.UUID) ([]entity.DocumentType, error) {
 	return uc.countryRepo.GetDocumentTypes(ctx, countryID)
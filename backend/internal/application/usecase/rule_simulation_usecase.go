@@ -0,0 +1,325 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/domain/repository"
+	"github.com/fintech-multipass/backend/internal/domain/service"
+	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+	"github.com/fintech-multipass/backend/internal/infrastructure/scoring"
+	"github.com/fintech-multipass/backend/internal/infrastructure/validation"
+	"github.com/google/uuid"
+)
+
+// Límites de la simulación de reglas
+const (
+	DefaultSimulationLimit = 1000
+	MaxSimulationLimit     = 5000
+	maxSimulationChanges   = 100 // Solicitudes que cambian incluidas en la respuesta
+)
+
+// ErrInvalidSimulation los parámetros de la simulación no son válidos
+var ErrInvalidSimulation = errors.New("invalid simulation")
+
+// ErrCountryNotFound el país no existe
+var ErrCountryNotFound = errors.New("country not found")
+
+// RuleSimulationConfig cambios propuestos a la configuración del país; los campos nil conservan el valor actual
+type RuleSimulationConfig struct {
+	ReviewThreshold       *float64           `json:"review_threshold"`
+	MinCreditScore        *int               `json:"min_credit_score"`
+	MaxDebtToIncomeRatio  *float64           `json:"max_debt_to_income_ratio"`
+	InvalidDocumentAction *entity.RuleAction `json:"invalid_document_action"`
+}
+
+// RuleSimulationInput parámetros de una simulación
+type RuleSimulationInput struct {
+	CountryCode string
+	From        time.Time
+	To          time.Time
+	Limit       int
+
+	// Reglas propuestas; nil = reglas actuales del país
+	Rules []entity.CountryRule
+
+	// Modelo de scoring: una versión guardada o una definición sin guardar; sin ninguno, el activo
+	ScoringModelVersion *int
+	ScoringDefinition   *entity.ScoringDefinition
+
+	Config *RuleSimulationConfig
+}
+
+// RuleSimulationUseCase reproduce reglas y scoring propuestos sobre solicitudes históricas
+// No modifica nada: solo calcula qué decisiones cambiarían
+type RuleSimulationUseCase struct {
+	countryRepo repository.CountryRepository
+	appRepo     repository.CreditApplicationRepository
+	modelRepo   repository.ScoringModelRepository
+	validator   service.RuleValidator
+	log         *logger.Logger
+}
+
+// NewRuleSimulationUseCase crea una nueva instancia del caso de uso
+func NewRuleSimulationUseCase(
+	countryRepo repository.CountryRepository,
+	appRepo repository.CreditApplicationRepository,
+	modelRepo repository.ScoringModelRepository,
+	validator service.RuleValidator,
+	log *logger.Logger,
+) *RuleSimulationUseCase {
+	return &RuleSimulationUseCase{
+		countryRepo: countryRepo,
+		appRepo:     appRepo,
+		modelRepo:   modelRepo,
+		validator:   validator,
+		log:         log,
+	}
+}
+
+// Simulate evalúa las solicitudes decididas del rango con la configuración propuesta
+func (uc *RuleSimulationUseCase) Simulate(ctx context.Context, input RuleSimulationInput) (*entity.RuleSimulationResult, error) {
+	if input.From.IsZero() || input.To.IsZero() || !input.To.After(input.From) {
+		return nil, fmt.Errorf("%w: 'to' must be after 'from'", ErrInvalidSimulation)
+	}
+	if input.Limit <= 0 {
+		input.Limit = DefaultSimulationLimit
+	}
+	if input.Limit > MaxSimulationLimit {
+		input.Limit = MaxSimulationLimit
+	}
+
+	country, err := uc.countryRepo.GetByCode(ctx, input.CountryCode)
+	if err != nil {
+		return nil, ErrCountryNotFound
+	}
+
+	rules, err := uc.simulationRules(ctx, country.ID, input.Rules)
+	if err != nil {
+		return nil, err
+	}
+	model, err := uc.simulationModel(ctx, country.ID, input)
+	if err != nil {
+		return nil, err
+	}
+	config := country.Config
+	input.Config.applyTo(&config)
+
+	cases, err := uc.appRepo.ListForRuleSimulation(ctx, country.ID, input.From, input.To, input.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entity.RuleSimulationResult{
+		CountryCode:       country.Code,
+		From:              input.From,
+		To:                input.To,
+		PreviousOutcomes:  make(map[entity.ApplicationStatus]int),
+		SimulatedOutcomes: make(map[entity.ApplicationStatus]int),
+		Transitions:       make(map[entity.ApplicationStatus]map[entity.ApplicationStatus]int),
+		Changes:           []entity.RuleSimulationChange{},
+	}
+	for _, rule := range rules {
+		if rule.IsActive {
+			result.Rules++
+		}
+	}
+	if input.ScoringDefinition == nil {
+		version := model.Version
+		result.ScoringModelVersion = &version
+	}
+	if len(cases) > input.Limit {
+		cases = cases[:input.Limit]
+		result.Truncated = true
+	}
+
+	// La simulación usa una copia del país con la configuración propuesta
+	simCountry := *country
+	simCountry.Config = config
+
+	for i := range cases {
+		app := &cases[i].Application
+		app.Country = &simCountry
+
+		outcome, reason, err := uc.outcome(ctx, app, rules, model, config)
+		if err != nil {
+			return nil, err
+		}
+
+		previous := cases[i].PreviousOutcome
+		result.Evaluated++
+		result.PreviousOutcomes[previous]++
+		result.SimulatedOutcomes[outcome]++
+		if outcome == previous {
+			continue
+		}
+
+		result.Changed++
+		if result.Transitions[previous] == nil {
+			result.Transitions[previous] = make(map[entity.ApplicationStatus]int)
+		}
+		result.Transitions[previous][outcome]++
+		if len(result.Changes) < maxSimulationChanges {
+			result.Changes = append(result.Changes, entity.RuleSimulationChange{
+				ApplicationID:   app.ID,
+				PreviousOutcome: previous,
+				NewOutcome:      outcome,
+				Reason:          reason,
+			})
+		}
+	}
+	if result.Evaluated > 0 {
+		result.ChangeRate = float64(result.Changed) / float64(result.Evaluated)
+	}
+
+	uc.log.Info().
+		Str("country", country.Code).
+		Int("evaluated", result.Evaluated).
+		Int("changed", result.Changed).
+		Int("rules", result.Rules).
+		Msg("Rule simulation completed")
+
+	return result, nil
+}
+
+// outcome decide la solicitud como lo haría RISK_EVALUATION: documento inválido,
+// límites de velocidad, score de fraude, screening, reglas por prioridad y después el modelo
+// con las políticas de revisión del país
+// El screening no se repite (guarda hits): se usa el resultado guardado de la solicitud
+func (uc *RuleSimulationUseCase) outcome(ctx context.Context, app *entity.CreditApplication, rules []entity.CountryRule, model *scoring.Model, config entity.CountryConfig) (entity.ApplicationStatus, string, error) {
+	documentReview := false
+	if app.DocumentValid != nil && !*app.DocumentValid {
+		if config.InvalidDocumentAction != entity.RuleActionRequireReview {
+			return entity.StatusRejected, "Invalid identity document", nil
+		}
+		documentReview = true
+	}
+	velocityReview := app.VelocityCheck.IsExceeded()
+	fraudReview := config.Fraud != nil && config.Fraud.ReviewThreshold > 0 &&
+		app.FraudScore != nil && *app.FraudScore >= config.Fraud.ReviewThreshold
+	watchlistReview := screeningRequiresReview(app.ScreeningStatus)
+
+	results, err := uc.validator.ValidateApplication(ctx, app, rules)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to validate application %s: %w", app.ID, err)
+	}
+	rejectedBy, reviewRules := validation.ClassifyResults(results)
+	if rejectedBy != nil {
+		return entity.StatusRejected, fmt.Sprintf("Rejected by rule %s: %s", rejectedBy.RuleName, rejectedBy.Message), nil
+	}
+
+	evaluation := model.Evaluate(scoring.BuildInputs(app, scoring.CountryParams{
+		MaxDebtToIncomeRatio: config.MaxDebtToIncomeRatio,
+		MinCreditScore:       config.MinCreditScore,
+	}))
+	amountReview := app.RequestedAmount >= config.ReviewThreshold
+	outcome := scoring.Outcome(evaluation.Decision, amountReview || documentReview || velocityReview || fraudReview || watchlistReview || len(reviewRules) > 0)
+
+	reason := fmt.Sprintf("Risk score %.0f (%s)", evaluation.Score, evaluation.Decision)
+	switch {
+	case outcome != entity.StatusUnderReview || evaluation.Decision == entity.ScoringDecisionReview:
+	case amountReview:
+		reason += fmt.Sprintf(", amount exceeds review threshold %.2f", config.ReviewThreshold)
+	case documentReview:
+		reason += ", invalid identity document"
+	case velocityReview:
+		reason += ", velocity limits exceeded"
+	case fraudReview:
+		reason += fmt.Sprintf(", fraud score %.0f reaches threshold %.0f", *app.FraudScore, config.Fraud.ReviewThreshold)
+	case watchlistReview:
+		reason += fmt.Sprintf(", watchlist screening %s", app.ScreeningStatus)
+	default:
+		reason += ", review required by rules " + strings.Join(reviewRules, ", ")
+	}
+	return outcome, reason, nil
+}
+
+// screeningRequiresReview un posible hit (o uno confirmado) impide la aprobación automática
+// Las solicitudes anteriores al screening no tienen estado y no fuerzan revisión
+func screeningRequiresReview(status entity.ScreeningStatus) bool {
+	return status != "" && status != entity.ScreeningClear
+}
+
+// simulationRules reglas propuestas (validadas y por prioridad) o las actuales del país
+func (uc *RuleSimulationUseCase) simulationRules(ctx context.Context, countryID uuid.UUID, proposed []entity.CountryRule) ([]entity.CountryRule, error) {
+	if proposed == nil {
+		rules, err := uc.countryRepo.GetRules(ctx, countryID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load country rules: %w", err)
+		}
+		return rules, nil
+	}
+
+	rules := make([]entity.CountryRule, len(proposed))
+	for i, rule := range proposed {
+		if rule.ID == uuid.Nil {
+			rule.ID = uuid.New()
+		}
+		rule.CountryID = countryID
+		if err := validation.CompileRule(&rule); err != nil {
+			return nil, fmt.Errorf("%w: rule %q: %s", ErrInvalidRule, rule.Name, err.Error())
+		}
+		rules[i] = rule
+	}
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].Priority > rules[j].Priority })
+	return rules, nil
+}
+
+// simulationModel modelo de scoring de la simulación
+func (uc *RuleSimulationUseCase) simulationModel(ctx context.Context, countryID uuid.UUID, input RuleSimulationInput) (*scoring.Model, error) {
+	if input.ScoringDefinition != nil {
+		model, err := scoring.Compile("", 0, "simulation", *input.ScoringDefinition)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSimulation, err.Error())
+		}
+		return model, nil
+	}
+
+	var stored *entity.ScoringModel
+	var err error
+	if input.ScoringModelVersion != nil {
+		stored, err = uc.modelRepo.GetByVersion(ctx, countryID, *input.ScoringModelVersion)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scoring model version %d not found", ErrInvalidSimulation, *input.ScoringModelVersion)
+		}
+	} else {
+		stored, err = uc.modelRepo.GetActiveByCountry(ctx, countryID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load active scoring model: %w", err)
+		}
+	}
+
+	// Sin modelo activo RISK_EVALUATION usa el modelo integrado
+	if stored == nil {
+		return scoring.Compile("", scoring.BuiltinVersion, "builtin", scoring.DefaultDefinition())
+	}
+	model, err := scoring.CompileModel(stored)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSimulation, err.Error())
+	}
+	return model, nil
+}
+
+// applyTo aplica los cambios propuestos sobre la configuración del país
+func (c *RuleSimulationConfig) applyTo(config *entity.CountryConfig) {
+	if c == nil {
+		return
+	}
+	if c.ReviewThreshold != nil {
+		config.ReviewThreshold = *c.ReviewThreshold
+	}
+	if c.MinCreditScore != nil {
+		config.MinCreditScore = *c.MinCreditScore
+	}
+	if c.MaxDebtToIncomeRatio != nil {
+		config.MaxDebtToIncomeRatio = *c.MaxDebtToIncomeRatio
+	}
+	if c.InvalidDocumentAction != nil {
+		config.InvalidDocumentAction = *c.InvalidDocumentAction
+	}
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+	"github.com/fintech-multipass/backend/internal/infrastructure/scoring"
+	"github.com/google/uuid"
+)
+
+// passingValidator validador sin reglas que fallen
+type passingValidator struct{}
+
+func (passingValidator) ValidateApplication(ctx context.Context, app *entity.CreditApplication, rules []entity.CountryRule) ([]entity.ValidationResult, error) {
+	return nil, nil
+}
+
+func (passingValidator) ValidateDocument(ctx context.Context, docType, docNumber, countryCode string) (bool, string, error) {
+	return true, "", nil
+}
+
+func TestSimulationOutcomeScreening(t *testing.T) {
+	model, err := scoring.Compile("test", 1, "test", scoring.DefaultDefinition())
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	uc := &RuleSimulationUseCase{validator: passingValidator{}, log: logger.NewLogger()}
+	config := entity.CountryConfig{ReviewThreshold: 100000, MinCreditScore: 600, MaxDebtToIncomeRatio: 0.4}
+
+	score, accounts := 800, 3
+	history := "GOOD"
+	months := 60
+	debt, available := 1000.0, 20000.0
+	app := func(status entity.ScreeningStatus) *entity.CreditApplication {
+		return &entity.CreditApplication{
+			ID: uuid.New(), RequestedAmount: 5000, MonthlyIncome: 6000, ScreeningStatus: status,
+			BankingInfo: &entity.BankingInfo{
+				CreditScore: &score, TotalDebt: &debt, AvailableCredit: &available, PaymentHistory: &history,
+				BankAccounts: accounts, MonthsEmployed: &months,
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		status entity.ScreeningStatus
+		want   entity.ApplicationStatus
+		reason string
+	}{
+		{"sin hits", entity.ScreeningClear, entity.StatusApproved, ""},
+		{"anterior al screening", "", entity.StatusApproved, ""},
+		{"posible hit", entity.ScreeningPotentialHit, entity.StatusUnderReview, "watchlist screening POTENTIAL_HIT"},
+		{"hit confirmado", entity.ScreeningConfirmedHit, entity.StatusUnderReview, "watchlist screening CONFIRMED_HIT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome, reason, err := uc.outcome(context.Background(), app(tt.status), nil, model, config)
+			if err != nil {
+				t.Fatalf("outcome: %v", err)
+			}
+			if outcome != tt.want || !strings.Contains(reason, tt.reason) {
+				t.Errorf("outcome = %s (%s), want %s with %q", outcome, reason, tt.want, tt.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/domain/repository"
+	"github.com/fintech-multipass/backend/internal/infrastructure/cache"
+	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+	"github.com/fintech-multipass/backend/internal/infrastructure/watchlist"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Límites de los listados de listas y hits
+const (
+	DefaultWatchlistLimit = 50
+	MaxWatchlistLimit     = 500
+)
+
+// ErrInvalidWatchlistImport la importación no es válida (fuente, tipo, formato o archivo)
+var ErrInvalidWatchlistImport = errors.New("invalid watchlist import")
+
+// ErrWatchlistHitNotFound el hit no existe
+var ErrWatchlistHitNotFound = errors.New("watchlist hit not found")
+
+// ErrWatchlistHitResolved el hit ya fue resuelto
+var ErrWatchlistHitResolved = errors.New("watchlist hit already resolved")
+
+// ErrInvalidHitResolution la resolución debe ser CONFIRMED o DISMISSED
+var ErrInvalidHitResolution = errors.New("invalid watchlist hit resolution")
+
+// watchlistSourcePattern fuentes válidas: UN, OFAC_SDN, PEP_MX...
+var watchlistSourcePattern = regexp.MustCompile(`^[A-Z0-9_-]{2,50}$`)
+
+// WatchlistImportInput archivo de lista a importar
+type WatchlistImportInput struct {
+	Source     string
+	ListType   string
+	Format     string // CSV o XML; vacío = según la extensión del archivo
+	FileName   string
+	File       io.Reader
+	ImportedBy *uuid.UUID
+}
+
+// WatchlistUseCase importación de listas de sanciones y PEP y resolución de hits
+type WatchlistUseCase struct {
+	repo    repository.WatchlistRepository
+	appRepo repository.CreditApplicationRepository
+	cache   cache.CacheService
+	log     *logger.Logger
+}
+
+// NewWatchlistUseCase crea una nueva instancia del caso de uso
+func NewWatchlistUseCase(
+	repo repository.WatchlistRepository,
+	appRepo repository.CreditApplicationRepository,
+	cache cache.CacheService,
+	log *logger.Logger,
+) *WatchlistUseCase {
+	return &WatchlistUseCase{
+		repo:    repo,
+		appRepo: appRepo,
+		cache:   cache,
+		log:     log,
+	}
+}
+
+// Import lee el archivo y reemplaza la carga anterior de la fuente
+// Las entradas repetidas (mismo identificador) se combinan
+func (uc *WatchlistUseCase) Import(ctx context.Context, input WatchlistImportInput) (*entity.WatchlistImport, error) {
+	source := strings.ToUpper(strings.TrimSpace(input.Source))
+	if !watchlistSourcePattern.MatchString(source) {
+		return nil, fmt.Errorf("%w: source must be 2-50 uppercase letters, digits, '_' or '-'", ErrInvalidWatchlistImport)
+	}
+	listType := strings.ToUpper(strings.TrimSpace(input.ListType))
+	if listType != entity.WatchlistTypeSanctions && listType != entity.WatchlistTypePEP {
+		return nil, fmt.Errorf("%w: list_type must be SANCTIONS or PEP", ErrInvalidWatchlistImport)
+	}
+	format := strings.ToUpper(strings.TrimSpace(input.Format))
+	if format == "" {
+		format = strings.ToUpper(strings.TrimPrefix(strings.ToLower(fileExtension(input.FileName)), "."))
+	}
+
+	var parsed []entity.WatchlistEntry
+	var err error
+	switch format {
+	case entity.WatchlistFormatCSV:
+		parsed, err = watchlist.ParseCSV(input.File)
+	case entity.WatchlistFormatXML:
+		parsed, err = watchlist.ParseConsolidatedXML(input.File)
+	default:
+		return nil, fmt.Errorf("%w: format must be CSV or XML", ErrInvalidWatchlistImport)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidWatchlistImport, err.Error())
+	}
+
+	imp := &entity.WatchlistImport{
+		ID:         uuid.New(),
+		Source:     source,
+		ListType:   listType,
+		Format:     format,
+		FileName:   input.FileName,
+		ImportedBy: input.ImportedBy,
+	}
+	entries := mergeWatchlistEntries(parsed)
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: the file has no entries", ErrInvalidWatchlistImport)
+	}
+	for i := range entries {
+		entries[i].ID = uuid.New()
+		imp.Entries++
+		imp.Names += 1 + len(entries[i].Aliases)
+	}
+
+	if err := uc.repo.ReplaceList(ctx, imp, entries); err != nil {
+		return nil, err
+	}
+
+	uc.log.Info().
+		Str("source", imp.Source).
+		Str("list_type", imp.ListType).
+		Str("format", imp.Format).
+		Int("entries", imp.Entries).
+		Int("names", imp.Names).
+		Msg("Watchlist imported")
+
+	return imp, nil
+}
+
+// ListImports lista las últimas importaciones
+func (uc *WatchlistUseCase) ListImports(ctx context.Context, limit int) ([]entity.WatchlistImport, error) {
+	return uc.repo.ListImports(ctx, clampWatchlistLimit(limit))
+}
+
+// ListHits bandeja de hits para los analistas
+func (uc *WatchlistUseCase) ListHits(ctx context.Context, filter entity.WatchlistHitFilter) ([]entity.WatchlistHit, error) {
+	filter.Limit = clampWatchlistLimit(filter.Limit)
+	hits, err := uc.repo.ListHits(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if hits == nil {
+		hits = []entity.WatchlistHit{}
+	}
+	return hits, nil
+}
+
+// ResolveHit confirma o descarta un hit. Un hit confirmado rechaza la solicitud si
+// aún admite la transición; descartar todos los hits la deja en revisión para el analista
+func (uc *WatchlistUseCase) ResolveHit(ctx context.Context, id uuid.UUID, status entity.WatchlistHitStatus, note string, actor *uuid.UUID) (*entity.WatchlistHit, error) {
+	if status != entity.WatchlistHitConfirmed && status != entity.WatchlistHitDismissed {
+		return nil, fmt.Errorf("%w: status must be CONFIRMED or DISMISSED", ErrInvalidHitResolution)
+	}
+
+	hit, err := uc.repo.GetHit(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWatchlistHitNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	screening, err := uc.repo.ResolveHit(ctx, id, status, actor, strings.TrimSpace(note))
+	if errors.Is(err, repository.ErrWatchlistHitResolved) {
+		return nil, ErrWatchlistHitResolved
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if screening == entity.ScreeningConfirmedHit {
+		if err := uc.rejectConfirmedHit(ctx, hit, actor); err != nil {
+			return nil, err
+		}
+	}
+	if uc.cache != nil {
+		_ = uc.cache.InvalidateApplication(ctx, hit.ApplicationID)
+	}
+
+	uc.log.Info().
+		Str("hit_id", id.String()).
+		Str("application_id", hit.ApplicationID.String()).
+		Str("resolution", string(status)).
+		Str("screening_status", string(screening)).
+		Msg("Watchlist hit resolved")
+
+	return uc.repo.GetHit(ctx, id)
+}
+
+// rejectConfirmedHit rechaza la solicitud con un hit confirmado
+func (uc *WatchlistUseCase) rejectConfirmedHit(ctx context.Context, hit *entity.WatchlistHit, actor *uuid.UUID) error {
+	app, err := uc.appRepo.GetByID(ctx, hit.ApplicationID)
+	if err != nil {
+		return fmt.Errorf("failed to get application: %w", err)
+	}
+	if !app.Status.CanTransitionTo(entity.StatusRejected) {
+		uc.log.Warn().
+			Str("application_id", app.ID.String()).
+			Str("current_status", string(app.Status)).
+			Msg("Confirmed watchlist hit but application cannot be rejected - keeping current status")
+		return nil
+	}
+
+	reason := fmt.Sprintf("Rejected: confirmed %s watchlist hit (%s %s)", strings.ToLower(hit.ListType), hit.Source, hit.ExternalID)
+	if err := uc.appRepo.UpdateStatus(ctx, app.ID, entity.StatusRejected, reason); err != nil {
+		return fmt.Errorf("failed to reject application: %w", err)
+	}
+	_ = uc.appRepo.SaveStateTransition(ctx, &entity.StateTransition{
+		ApplicationID: app.ID,
+		FromStatus:    app.Status,
+		ToStatus:      entity.StatusRejected,
+		Reason:        reason,
+		TriggeredBy:   "USER",
+		TriggeredByID: actor,
+	})
+	return nil
+}
+
+// mergeWatchlistEntries combina las entradas con el mismo identificador y normaliza los
+// documentos. Sin identificador se usa el nombre normalizado
+func mergeWatchlistEntries(parsed []entity.WatchlistEntry) []entity.WatchlistEntry {
+	index := make(map[string]int)
+	var entries []entity.WatchlistEntry
+	for _, e := range parsed {
+		if e.ExternalID == "" {
+			e.ExternalID = watchlist.NormalizeName(e.FullName)
+		}
+		if len(e.ExternalID) > 100 {
+			e.ExternalID = e.ExternalID[:100]
+		}
+		docs := e.DocumentNumbers
+		e.DocumentNumbers = nil
+		for _, d := range docs {
+			if n := watchlist.NormalizeDocument(d); n != "" {
+				e.DocumentNumbers = append(e.DocumentNumbers, n)
+			}
+		}
+
+		i, seen := index[e.ExternalID]
+		if !seen {
+			index[e.ExternalID] = len(entries)
+			entries = append(entries, e)
+			continue
+		}
+		existing := &entries[i]
+		if e.FullName != existing.FullName {
+			existing.Aliases = append(existing.Aliases, e.FullName)
+		}
+		existing.Aliases = append(existing.Aliases, e.Aliases...)
+		existing.DocumentNumbers = append(existing.DocumentNumbers, e.DocumentNumbers...)
+	}
+	for i := range entries {
+		entries[i].Aliases = uniqueStrings(entries[i].Aliases, entries[i].FullName)
+		entries[i].DocumentNumbers = uniqueStrings(entries[i].DocumentNumbers, "")
+	}
+	return entries
+}
+
+// uniqueStrings quita los valores repetidos (y exclude) conservando el orden
+func uniqueStrings(values []string, exclude string) []string {
+	seen := map[string]bool{exclude: true}
+	var unique []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// fileExtension extensión del nombre de archivo, con el punto
+func fileExtension(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[i:]
+	}
+	return ""
+}
+
+func clampWatchlistLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultWatchlistLimit
+	}
+	if limit > MaxWatchlistLimit {
+		return MaxWatchlistLimit
+	}
+	return limit
+}
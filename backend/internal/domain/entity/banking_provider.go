@@ -34,16 +34,28 @@ const (
 // ProviderConfig configuración del proveedor
 type ProviderConfig struct {
 	BaseURL          string            `json:"base_url"`
+	Endpoint         string            `json:"endpoint,omitempty"` // Ruta del recurso, sobreescribe la del adaptador
 	Timeout          int               `json:"timeout_seconds"`
 	RetryAttempts    int               `json:"retry_attempts"`
 	RetryDelay       int               `json:"retry_delay_ms"`
 	RateLimitPerMin  int               `json:"rate_limit_per_min"`
 	CacheTTLMinutes  int               `json:"cache_ttl_minutes"`
 	ResponseMapping  map[string]string `json:"response_mapping"` // Mapeo de campos del proveedor a nuestro modelo
+	ResponseFormat   string             `json:"response_format,omitempty"` // JSON, XML (se detecta si está vacío)
+	CurrencyRates    map[string]float64 `json:"currency_rates,omitempty"`  // Tasas de conversión a la moneda del país
 	Headers          map[string]string `json:"headers,omitempty"`
 	AuthType         string            `json:"auth_type"`        // API_KEY, OAUTH2, BASIC
+	Async            bool              `json:"async,omitempty"`  // El reporte llega después por el webhook credit_report_ready
+	CallbackTimeoutMinutes int         `json:"callback_timeout_minutes,omitempty"` // Espera máxima del webhook
+	OnCallbackTimeout string           `json:"on_callback_timeout,omitempty"`      // FALLBACK, ESCALATE
 }
 
+// Acciones cuando el reporte asíncrono no llega a tiempo
+const (
+	CallbackTimeoutFallback = "FALLBACK" // Consultar los proveedores síncronos de la cadena
+	CallbackTimeoutEscalate = "ESCALATE" // Enviar la solicitud a revisión manual
+)
+
 // BankingRequest solicitud a un proveedor bancario
 type BankingRequest struct {
 	ID            uuid.UUID  `json:"id"`
@@ -53,7 +65,11 @@ type BankingRequest struct {
 	Status        string     `json:"status"`        // PENDING, SUCCESS, FAILED
 	RequestData   []byte     `json:"-"`             // Datos enviados (encriptados)
 	ResponseData  []byte     `json:"-"`             // Respuesta (encriptada)
+	EncryptionKeyID string   `json:"encryption_key_id,omitempty"` // Clave maestra usada para cifrar
+	ExternalReference string `json:"external_reference,omitempty"` // Referencia del proveedor en llamadas asíncronas
+	ProviderCode  string     `json:"provider_code,omitempty"`
 	ErrorMessage  string     `json:"error_message,omitempty"`
+	FailureKind   string     `json:"failure_kind,omitempty"` // Causa del fallo: solo TRANSPORT, SERVER y TIMEOUT abren el circuito
 	Duration      int        `json:"duration_ms"`
 	CreatedAt     time.Time  `json:"created_at"`
 	CompletedAt   *time.Time `json:"completed_at,omitempty"`
@@ -73,5 +89,7 @@ type BankingInfoResponse struct {
 	RawData       map[string]interface{} `json:"raw_data,omitempty"`
 	ErrorCode     string                 `json:"error_code,omitempty"`
 	ErrorMessage  string                 `json:"error_message,omitempty"`
+	Pending       bool                   `json:"pending,omitempty"`      // Solicitud aceptada, el reporte llegará por webhook
+	ReferenceID   string                 `json:"reference_id,omitempty"` // Referencia del proveedor para la solicitud asíncrona
 }
 
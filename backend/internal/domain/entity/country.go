@@ -22,12 +22,15 @@ type Country struct {
 
 // CountryConfig contiene la configuración específica del país
 type CountryConfig struct {
-	MinLoanAmount        float64 `json:"min_loan_amount"`
-	MaxLoanAmount        float64 `json:"max_loan_amount"`
-	MinIncomeRequired    float64 `json:"min_income_required"`
-	MaxDebtToIncomeRatio float64 `json:"max_debt_to_income_ratio"`
-	ReviewThreshold      float64 `json:"review_threshold"`       // Monto a partir del cual requiere revisión
-	MinCreditScore       int     `json:"min_credit_score"`
+	MinLoanAmount         float64         `json:"min_loan_amount"`
+	MaxLoanAmount         float64         `json:"max_loan_amount"`
+	MinIncomeRequired     float64         `json:"min_income_required"`
+	MaxDebtToIncomeRatio  float64         `json:"max_debt_to_income_ratio"`
+	ReviewThreshold       float64         `json:"review_threshold"` // Monto a partir del cual requiere revisión
+	MinCreditScore        int             `json:"min_credit_score"`
+	InvalidDocumentAction RuleAction      `json:"invalid_document_action,omitempty"` // REJECT (por defecto) o REQUIRE_REVIEW
+	Velocity              *VelocityConfig `json:"velocity,omitempty"`                // Límites de solicitudes abiertas; nil = sin control
+	Fraud                 *FraudConfig    `json:"fraud,omitempty"`                   // Detección de fraude; nil = valores por defecto, sin forzar revisión
 }
 
 // DocumentType representa los tipos de documentos válidos por país
@@ -67,6 +70,15 @@ const (
 	RuleTypeCustom             RuleType = "CUSTOM"
 )
 
+// RuleAction acción que aplica una regla según su resultado
+type RuleAction string
+
+const (
+	RuleActionPass          RuleAction = "PASS"
+	RuleActionReject        RuleAction = "REJECT"
+	RuleActionRequireReview RuleAction = "REQUIRE_REVIEW"
+)
+
 // ValidationResult resultado de aplicar una regla
 type ValidationResult struct {
 	RuleID      uuid.UUID `json:"rule_id"`
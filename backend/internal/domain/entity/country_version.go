@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CountrySnapshot foto completa de la configuración administrable de un país
+type CountrySnapshot struct {
+	Config        CountryConfig  `json:"config"`
+	Rules         []CountryRule  `json:"rules"` // Incluye las reglas inactivas
+	DocumentTypes []DocumentType `json:"document_types"`
+}
+
+// ConfigChangeType tipo de cambio que originó una versión
+type ConfigChangeType string
+
+const (
+	ConfigChangeInitial             ConfigChangeType = "INITIAL" // Estado previo al primer cambio por API
+	ConfigChangeRuleCreated         ConfigChangeType = "RULE_CREATED"
+	ConfigChangeRuleUpdated         ConfigChangeType = "RULE_UPDATED"
+	ConfigChangeRuleDeleted         ConfigChangeType = "RULE_DELETED"
+	ConfigChangeDocumentTypeCreated ConfigChangeType = "DOCUMENT_TYPE_CREATED"
+	ConfigChangeDocumentTypeUpdated ConfigChangeType = "DOCUMENT_TYPE_UPDATED"
+	ConfigChangeDocumentTypeDeleted ConfigChangeType = "DOCUMENT_TYPE_DELETED"
+	ConfigChangeConfigUpdated       ConfigChangeType = "CONFIG_UPDATED"
+	ConfigChangeConfigScheduled     ConfigChangeType = "CONFIG_SCHEDULED" // Entra en vigor en EffectiveFrom
+	ConfigChangeRollback            ConfigChangeType = "ROLLBACK"
+)
+
+// Acciones de un cambio
+const (
+	ConfigActionCreated = "CREATED"
+	ConfigActionUpdated = "UPDATED"
+	ConfigActionDeleted = "DELETED"
+)
+
+// ConfigChange cambio de un elemento entre dos versiones
+type ConfigChange struct {
+	Entity string                 `json:"entity"` // config, rule, document_type
+	ID     string                 `json:"id,omitempty"`
+	Name   string                 `json:"name,omitempty"`
+	Action string                 `json:"action"`
+	Fields map[string]FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange valor anterior y nuevo de un campo
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// CountryConfigVersion versión inmutable de la configuración de un país
+type CountryConfigVersion struct {
+	ID           uuid.UUID        `json:"id"`
+	CountryID    uuid.UUID        `json:"country_id"`
+	Version      int              `json:"version"`
+	ChangeType   ConfigChangeType `json:"change_type"`
+	Summary      string           `json:"summary,omitempty"`
+	Snapshot     *CountrySnapshot `json:"snapshot,omitempty"` // nil en los listados
+	Diff         []ConfigChange   `json:"diff"`
+	RolledBackTo *int             `json:"rolled_back_to,omitempty"`
+	CreatedBy    *uuid.UUID       `json:"created_by,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+
+	// Vigencia: los cambios inmediatos rigen desde CreatedAt, los programados desde la fecha indicada
+	EffectiveFrom time.Time  `json:"effective_from"`
+	Pending       bool       `json:"pending"` // Programada y aún no vigente
+	CancelledAt   *time.Time `json:"cancelled_at,omitempty"`
+	CancelledBy   *uuid.UUID `json:"cancelled_by,omitempty"`
+}
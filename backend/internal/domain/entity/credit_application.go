@@ -34,6 +34,25 @@ type CreditApplication struct {
 	// Resultados de validación
 	ValidationResults []ValidationResult `json:"validation_results,omitempty"`
 	RiskScore       *float64           `json:"risk_score,omitempty"`
+	ScoringModelID      *uuid.UUID     `json:"scoring_model_id,omitempty"`      // Modelo que produjo el score
+	ScoringModelVersion *int           `json:"scoring_model_version,omitempty"`
+	
+	// Validación del documento de identidad (nil = aún no validado)
+	DocumentValid             *bool      `json:"document_valid,omitempty"`
+	DocumentValidationMessage string     `json:"document_validation_message,omitempty"`
+	DocumentValidatedAt       *time.Time `json:"document_validated_at,omitempty"`
+	
+	// Controles de velocidad al crear la solicitud
+	VelocityCheck   *VelocityCheck     `json:"velocity_check,omitempty"`
+	
+	// Señales de fraude (nil = FRAUD_CHECK aún no se ejecutó)
+	FraudScore      *float64           `json:"fraud_score,omitempty"`
+	FraudSignals    []FraudSignal      `json:"fraud_signals,omitempty"`
+	FraudCheckedAt  *time.Time         `json:"fraud_checked_at,omitempty"`
+	
+	// Screening contra listas de sanciones y PEP (vacío = aún no se hizo)
+	ScreeningStatus ScreeningStatus    `json:"screening_status,omitempty"`
+	ScreenedAt      *time.Time         `json:"screened_at,omitempty"`
 	
 	// Metadatos
 	ApplicationDate time.Time          `json:"application_date"`
@@ -70,7 +89,7 @@ func (s ApplicationStatus) IsTerminal() bool {
 // CanTransitionTo verifica si se puede transicionar a otro estado
 func (s ApplicationStatus) CanTransitionTo(target ApplicationStatus) bool {
 	transitions := map[ApplicationStatus][]ApplicationStatus{
-		StatusPending:         {StatusValidating, StatusCancelled},
+		StatusPending:         {StatusValidating, StatusPendingBankInfo, StatusUnderReview, StatusRejected, StatusCancelled},
 		StatusValidating:      {StatusPendingBankInfo, StatusUnderReview, StatusApproved, StatusRejected},
 		StatusPendingBankInfo: {StatusValidating, StatusUnderReview, StatusRejected, StatusCancelled},
 		StatusUnderReview:     {StatusApproved, StatusRejected, StatusCancelled},
@@ -125,10 +144,21 @@ type BankingInfo struct {
 	// Datos crudos del proveedor (para auditoría, no expuestos)
 	RawResponse       []byte     `json:"-"`
 	
+	// Procedencia: PROVIDER (llamada al proveedor), CACHE (reutilizado de otra solicitud)
+	Source                  string     `json:"source"`
+	ReusedFromID            *uuid.UUID `json:"reused_from_id,omitempty"`
+	ReusedFromApplicationID *uuid.UUID `json:"reused_from_application_id,omitempty"`
+	
 	RetrievedAt       time.Time  `json:"retrieved_at"`
 	ExpiresAt         time.Time  `json:"expires_at"`
 }
 
+// Procedencia de la información bancaria
+const (
+	BankingInfoSourceProvider = "PROVIDER"
+	BankingInfoSourceCache    = "CACHE"
+)
+
 // ApplicationFilter filtros para búsqueda de solicitudes
 type ApplicationFilter struct {
 	CountryID     *uuid.UUID
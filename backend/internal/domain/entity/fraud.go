@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+// Señales de fraude que calcula el trabajo FRAUD_CHECK
+const (
+	FraudSignalDocumentNameMismatch = "DOCUMENT_NAME_MISMATCH" // Mismo documento con otro nombre
+	FraudSignalSharedEmail          = "SHARED_EMAIL"           // Email usado con varios documentos
+	FraudSignalSharedPhone          = "SHARED_PHONE"           // Teléfono usado con varios documentos
+	FraudSignalDisposableEmail      = "DISPOSABLE_EMAIL"       // Dominio de email desechable
+	FraudSignalIncomeMismatch       = "INCOME_MISMATCH"        // Ingreso declarado incoherente con el buró
+	FraudSignalSubnetVelocity       = "SUBNET_VELOCITY"        // Muchas solicitudes desde la misma subred
+)
+
+// FraudConfig configuración por país de la detección de fraude
+// Los campos en 0 usan los valores por defecto del detector
+type FraudConfig struct {
+	ReviewThreshold          float64  `json:"review_threshold"`             // Score (0-100) desde el que se fuerza revisión; 0 = no fuerza
+	LookbackDays             int      `json:"lookback_days"`                // Antigüedad de las solicitudes comparadas
+	MaxDocumentsPerContact   int      `json:"max_documents_per_contact"`    // Documentos distintos por email o teléfono
+	MaxDebtToAnnualIncome    float64  `json:"max_debt_to_annual_income"`    // Deuda del buró sobre el ingreso anual declarado
+	SubnetWindowHours        int      `json:"subnet_window_hours"`          // Ventana de la señal de subred
+	MaxApplicationsPerSubnet int      `json:"max_applications_per_subnet"`  // Solicitudes de la subred (/24 o /64) en la ventana
+	DisposableDomains        []string `json:"disposable_domains,omitempty"` // Se suman a la lista integrada
+}
+
+// FraudSignal señal de fraude detectada en una solicitud
+type FraudSignal struct {
+	Code   string  `json:"code"`
+	Weight float64 `json:"weight"` // Aporte al score
+	Detail string  `json:"detail"`
+}
+
+// FraudAssessment resultado del trabajo FRAUD_CHECK
+type FraudAssessment struct {
+	Score     float64       `json:"score"` // 0-100, donde 100 es más sospechoso
+	Signals   []FraudSignal `json:"signals"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
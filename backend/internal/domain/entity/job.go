@@ -36,6 +36,8 @@ const (
 	JobTypeWebhookCall        JobType = "WEBHOOK_CALL"
 	JobTypeStatusUpdate       JobType = "STATUS_UPDATE"
 	JobTypeReportGeneration   JobType = "REPORT_GENERATION"
+	JobTypeBankingCallbackTimeout JobType = "BANKING_CALLBACK_TIMEOUT" // Vence la espera de un reporte asíncrono
+	JobTypeFraudCheck             JobType = "FRAUD_CHECK"              // Señales de fraude antes de la evaluación de riesgo
 )
 
 // JobStatus estados del trabajo
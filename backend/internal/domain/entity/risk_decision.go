@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RiskDecision desglose de una evaluación de riesgo
+// Se guarda una por evaluación para poder explicar y auditar cada resultado
+type RiskDecision struct {
+	ID                  uuid.UUID          `json:"id"`
+	ApplicationID       uuid.UUID          `json:"application_id"`
+	ScoringModelID      *uuid.UUID         `json:"scoring_model_id,omitempty"`
+	ScoringModelVersion int                `json:"scoring_model_version"`
+	Score               float64            `json:"score"`
+	Decision            ScoringDecision    `json:"decision"` // Decisión del modelo según los umbrales
+	Outcome             ApplicationStatus  `json:"outcome"`  // Estado resultante tras aplicar las políticas del país
+	RequiresReview      bool               `json:"requires_review"`
+	Factors             []RiskFactorResult `json:"factors"`
+	ReasonCodes         []ReasonCode       `json:"reason_codes"` // Motivos adversos, el de mayor impacto primero
+	CreatedAt           time.Time          `json:"created_at"`
+}
+
+// RiskFactorResult resultado de un factor del modelo
+type RiskFactorResult struct {
+	Factor          string      `json:"factor"`
+	Input           string      `json:"input"`
+	Value           interface{} `json:"value,omitempty"`
+	Available       bool        `json:"available"`
+	Bucket          string      `json:"bucket,omitempty"` // Tramo que coincidió, ej. "[50, 150)" o "GOOD"
+	Points          float64     `json:"points"`
+	Weight          float64     `json:"weight"`
+	Contribution    float64     `json:"contribution"`     // weight × points
+	MaxContribution float64     `json:"max_contribution"` // Mejor aporte posible del factor
+	ReasonCode      string      `json:"reason_code,omitempty"`
+}
+
+// ReasonCode motivo estructurado de una decisión (adverse action)
+type ReasonCode struct {
+	Code        string  `json:"code"`
+	Description string  `json:"description"`
+	Factor      string  `json:"factor,omitempty"`
+	PointsLost  float64 `json:"points_lost,omitempty"`
+}
@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RuleSimulationCase solicitud histórica junto con el resultado de su evaluación automática
+type RuleSimulationCase struct {
+	Application     CreditApplication
+	PreviousOutcome ApplicationStatus // Último estado decidido por el sistema (APPROVED, REJECTED o UNDER_REVIEW)
+}
+
+// RuleSimulationChange solicitud cuya decisión cambiaría con la configuración propuesta
+type RuleSimulationChange struct {
+	ApplicationID   uuid.UUID         `json:"application_id"`
+	PreviousOutcome ApplicationStatus `json:"previous_outcome"`
+	NewOutcome      ApplicationStatus `json:"new_outcome"`
+	Reason          string            `json:"reason"`
+}
+
+// RuleSimulationResult resultado de reproducir reglas y scoring sobre solicitudes históricas
+type RuleSimulationResult struct {
+	CountryCode         string                                          `json:"country"`
+	From                time.Time                                       `json:"from"`
+	To                  time.Time                                       `json:"to"`
+	Rules               int                                             `json:"rules"`                           // Reglas activas simuladas
+	ScoringModelVersion *int                                            `json:"scoring_model_version,omitempty"` // nil = definición propuesta
+	Evaluated           int                                             `json:"evaluated"`
+	Truncated           bool                                            `json:"truncated"` // Había más solicitudes que el límite
+	Changed             int                                             `json:"changed"`
+	ChangeRate          float64                                         `json:"change_rate"`
+	PreviousOutcomes    map[ApplicationStatus]int                       `json:"previous_outcomes"`
+	SimulatedOutcomes   map[ApplicationStatus]int                       `json:"simulated_outcomes"`
+	Transitions         map[ApplicationStatus]map[ApplicationStatus]int `json:"transitions"` // Anterior → nuevo, solo decisiones que cambian
+	Changes             []RuleSimulationChange                          `json:"changes"`     // Muestra de solicitudes que cambian
+}
@@ -0,0 +1,119 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScoringModel versión de un modelo de scoring de riesgo de un país
+// Las versiones son inmutables: para cambiar el modelo se crea una nueva versión
+// y se activa. Solo puede haber una versión activa (champion) y una challenger por país
+type ScoringModel struct {
+	ID             uuid.UUID         `json:"id"`
+	CountryID      uuid.UUID         `json:"country_id"`
+	Version        int               `json:"version"`
+	Name           string            `json:"name"`
+	Definition     ScoringDefinition `json:"definition"`
+	IsActive       bool              `json:"is_active"`
+	IsChallenger   bool              `json:"is_challenger"`
+	TrafficPercent int               `json:"traffic_percent"` // % de solicitudes que decide el challenger; 0 = solo sombra
+	Notes          string            `json:"notes,omitempty"`
+	CreatedBy      *uuid.UUID        `json:"created_by,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	ActivatedAt    *time.Time        `json:"activated_at,omitempty"`
+}
+
+// ScoringDefinition definición declarativa del modelo
+// score = base_score + Σ(weight × points del bucket que coincide), acotado a [min_score, max_score]
+type ScoringDefinition struct {
+	BaseScore float64         `json:"base_score"`
+	MinScore  float64         `json:"min_score"`
+	MaxScore  float64         `json:"max_score"`
+	Factors   []ScoringFactor `json:"factors"`
+	Cutoffs   ScoringCutoffs  `json:"cutoffs"`
+}
+
+// ScoringFactor factor del modelo evaluado sobre una variable de entrada
+type ScoringFactor struct {
+	Name          string          `json:"name"`
+	Input         string          `json:"input"` // Variable de entrada (credit_score, months_employed, ...)
+	Weight        float64         `json:"weight"`
+	Buckets       []ScoringBucket `json:"buckets"`
+	MissingPoints *float64        `json:"missing_points,omitempty"` // Puntos si la variable no está disponible
+}
+
+// ScoringBucket tramo de un factor; el primero que coincide asigna los puntos
+// Numérico: min inclusivo, max exclusivo (ambos opcionales). Categórico: value
+type ScoringBucket struct {
+	Min        *float64 `json:"min,omitempty"`
+	Max        *float64 `json:"max,omitempty"`
+	Value      string   `json:"value,omitempty"`
+	Points     float64  `json:"points"`
+	ReasonCode string   `json:"reason_code,omitempty"` // Motivo adverso; por defecto el de la variable
+}
+
+// ScoringCutoffs umbrales de decisión sobre el score final
+type ScoringCutoffs struct {
+	Approve float64 `json:"approve"` // score >= approve: aprobación automática
+	Review  float64 `json:"review"`  // score >= review: revisión manual; por debajo: rechazo
+}
+
+// ScoringDecision resultado de aplicar los umbrales
+type ScoringDecision string
+
+const (
+	ScoringDecisionApprove ScoringDecision = "APPROVE"
+	ScoringDecisionReview  ScoringDecision = "REVIEW"
+	ScoringDecisionReject  ScoringDecision = "REJECT"
+)
+
+// Rol del modelo que tomó la decisión en una evaluación champion/challenger
+const (
+	ScoringRoleChampion   = "CHAMPION"
+	ScoringRoleChallenger = "CHALLENGER"
+)
+
+// ScoringShadowResult resultado de ambos modelos en una evaluación
+type ScoringShadowResult struct {
+	ID                 uuid.UUID       `json:"id"`
+	ApplicationID      uuid.UUID       `json:"application_id"`
+	CountryID          uuid.UUID       `json:"country_id"`
+	ChampionModelID    *uuid.UUID      `json:"champion_model_id,omitempty"`
+	ChampionVersion    int             `json:"champion_version"`
+	ChampionScore      float64         `json:"champion_score"`
+	ChampionDecision   ScoringDecision `json:"champion_decision"`
+	ChallengerModelID  uuid.UUID       `json:"challenger_model_id"`
+	ChallengerVersion  int             `json:"challenger_version"`
+	ChallengerScore    float64         `json:"challenger_score"`
+	ChallengerDecision ScoringDecision `json:"challenger_decision"`
+	DecidedBy          string          `json:"decided_by"`
+	CreatedAt          time.Time       `json:"created_at"`
+}
+
+// ScoringModelStats métricas de un modelo sobre las evaluaciones comparadas
+// Las tasas son fracciones entre 0 y 1
+type ScoringModelStats struct {
+	ApprovalRate float64        `json:"approval_rate"`
+	ReviewRate   float64        `json:"review_rate"`
+	RejectRate   float64        `json:"reject_rate"`
+	AvgScore     float64        `json:"avg_score"`
+	StdDevScore  float64        `json:"stddev_score"`
+	P50Score     float64        `json:"p50_score"`
+	P90Score     float64        `json:"p90_score"`
+	Distribution map[string]int `json:"distribution"` // Evaluaciones por tramo de 10 puntos, ej. "60-70"
+}
+
+// ChallengerStats comparación champion vs challenger de un país
+type ChallengerStats struct {
+	CountryID           uuid.UUID         `json:"country_id"`
+	ChallengerModelID   uuid.UUID         `json:"challenger_model_id"`
+	ChallengerVersion   int               `json:"challenger_version"`
+	ChampionVersions    []int             `json:"champion_versions"` // Versiones champion vigentes durante el periodo
+	Since               *time.Time        `json:"since,omitempty"`
+	Evaluations         int               `json:"evaluations"`
+	DecidedByChallenger int               `json:"decided_by_challenger"`
+	AgreementRate       float64           `json:"agreement_rate"` // Fracción de evaluaciones con la misma decisión
+	Champion            ScoringModelStats `json:"champion"`
+	Challenger          ScoringModelStats `json:"challenger"`
+}
@@ -0,0 +1,53 @@
+package entity
+
+import "time"
+
+// Dimensiones de los controles de velocidad
+const (
+	VelocityDocument = "document"
+	VelocityEmail    = "email"
+	VelocityPhone    = "phone"
+	VelocityIP       = "ip"
+)
+
+// VelocityConfig límites de solicitudes abiertas por documento, email, teléfono e IP
+// dentro de una ventana. Un límite en 0 no se controla
+type VelocityConfig struct {
+	WindowHours    int        `json:"window_hours"`     // 0 = sin ventana (cualquier antigüedad)
+	MaxPerDocument int        `json:"max_per_document"` // Máximo de solicitudes abiertas, incluida la nueva
+	MaxPerEmail    int        `json:"max_per_email"`
+	MaxPerPhone    int        `json:"max_per_phone"`
+	MaxPerIP       int        `json:"max_per_ip"`
+	Action         RuleAction `json:"action,omitempty"` // REJECT (por defecto) o REQUIRE_REVIEW
+}
+
+// VelocityKeys valores de la nueva solicitud con los que se cuentan las anteriores
+type VelocityKeys struct {
+	DocumentNumber string
+	Email          string
+	Phone          string // Solo dígitos
+	IP             string
+}
+
+// VelocityCounts solicitudes abiertas previas del país dentro de la ventana
+type VelocityCounts struct {
+	Document int `json:"document"`
+	Email    int `json:"email"`
+	Phone    int `json:"phone"`
+	IP       int `json:"ip"`
+}
+
+// VelocityCheck resultado de los controles de velocidad al crear una solicitud (auditoría)
+type VelocityCheck struct {
+	WindowHours int            `json:"window_hours"`
+	Counts      VelocityCounts `json:"counts"`
+	Limits      VelocityConfig `json:"limits"`
+	Exceeded    []string       `json:"exceeded,omitempty"` // document, email, phone, ip
+	Action      RuleAction     `json:"action,omitempty"`   // Acción aplicada si se superó algún límite
+	CheckedAt   time.Time      `json:"checked_at"`
+}
+
+// IsExceeded indica si se superó algún límite
+func (c *VelocityCheck) IsExceeded() bool {
+	return c != nil && len(c.Exceeded) > 0
+}
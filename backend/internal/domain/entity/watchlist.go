@@ -0,0 +1,110 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de lista
+const (
+	WatchlistTypeSanctions = "SANCTIONS"
+	WatchlistTypePEP       = "PEP"
+)
+
+// Formatos de importación
+const (
+	WatchlistFormatCSV = "CSV"
+	WatchlistFormatXML = "XML" // Lista consolidada (formato del Consejo de Seguridad de la ONU)
+)
+
+// WatchlistEntry persona o entidad de una lista de sanciones o PEP
+type WatchlistEntry struct {
+	ID              uuid.UUID `json:"id"`
+	ImportID        uuid.UUID `json:"import_id"`
+	Source          string    `json:"source"`
+	ListType        string    `json:"list_type"`
+	ExternalID      string    `json:"external_id"`
+	FullName        string    `json:"full_name"`
+	Aliases         []string  `json:"aliases,omitempty"`
+	DocumentNumbers []string  `json:"document_numbers,omitempty"`
+	Country         string    `json:"country,omitempty"`
+	Program         string    `json:"program,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// WatchlistImport carga de una lista; reemplaza la carga anterior de la misma fuente
+type WatchlistImport struct {
+	ID         uuid.UUID  `json:"id"`
+	Source     string     `json:"source"`
+	ListType   string     `json:"list_type"`
+	Format     string     `json:"format"`
+	FileName   string     `json:"file_name,omitempty"`
+	Entries    int        `json:"entries"`
+	Names      int        `json:"names"` // Nombres y alias indexados
+	ImportedBy *uuid.UUID `json:"imported_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Tipos de coincidencia
+const (
+	WatchlistMatchName     = "NAME"
+	WatchlistMatchDocument = "DOCUMENT"
+)
+
+// WatchlistMatch coincidencia encontrada al buscar un solicitante en las listas
+type WatchlistMatch struct {
+	Entry       WatchlistEntry `json:"entry"`
+	MatchedName string         `json:"matched_name"`
+	MatchType   string         `json:"match_type"`
+	Score       float64        `json:"score"` // Similitud 0-1
+}
+
+// WatchlistHitStatus estado de la resolución de un hit
+type WatchlistHitStatus string
+
+const (
+	WatchlistHitPending   WatchlistHitStatus = "PENDING"
+	WatchlistHitConfirmed WatchlistHitStatus = "CONFIRMED" // Es la persona listada
+	WatchlistHitDismissed WatchlistHitStatus = "DISMISSED" // Falso positivo
+)
+
+// WatchlistHit posible coincidencia de una solicitud con una lista
+// Conserva los datos de la entrada aunque la lista se vuelva a importar
+type WatchlistHit struct {
+	ID             uuid.UUID          `json:"id"`
+	ApplicationID  uuid.UUID          `json:"application_id"`
+	EntryID        *uuid.UUID         `json:"entry_id,omitempty"` // nil si la entrada ya no está en la lista
+	Source         string             `json:"source"`
+	ListType       string             `json:"list_type"`
+	ExternalID     string             `json:"external_id"`
+	EntryName      string             `json:"entry_name"`
+	MatchedName    string             `json:"matched_name"`
+	MatchType      string             `json:"match_type"`
+	Score          float64            `json:"score"`
+	Status         WatchlistHitStatus `json:"status"`
+	ResolutionNote string             `json:"resolution_note,omitempty"`
+	ResolvedBy     *uuid.UUID         `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time         `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+
+	// Datos de la solicitud para la bandeja de analistas
+	ApplicantName  string `json:"applicant_name,omitempty"`
+	DocumentNumber string `json:"document_number,omitempty"`
+}
+
+// WatchlistHitFilter filtros de la bandeja de hits
+type WatchlistHitFilter struct {
+	Status        WatchlistHitStatus
+	ApplicationID *uuid.UUID
+	Limit         int
+}
+
+// ScreeningStatus resultado del screening de una solicitud
+type ScreeningStatus string
+
+const (
+	ScreeningClear        ScreeningStatus = "CLEAR"
+	ScreeningPotentialHit ScreeningStatus = "POTENTIAL_HIT" // Hits pendientes de resolver
+	ScreeningConfirmedHit ScreeningStatus = "CONFIRMED_HIT"
+)
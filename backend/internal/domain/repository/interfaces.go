@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/fintech-multipass/backend/internal/domain/entity"
 	"github.com/google/uuid"
@@ -15,9 +17,26 @@ type CountryRepository interface {
 	Create(ctx context.Context, country *entity.Country) error
 	Update(ctx context.Context, country *entity.Country) error
 	GetRules(ctx context.Context, countryID uuid.UUID) ([]entity.CountryRule, error)
+	GetRuleByID(ctx context.Context, id uuid.UUID) (*entity.CountryRule, error)
+	CreateRule(ctx context.Context, rule *entity.CountryRule) error
+	UpdateRule(ctx context.Context, rule *entity.CountryRule) error
 	GetDocumentTypes(ctx context.Context, countryID uuid.UUID) ([]entity.DocumentType, error)
+
+	// Versiones de configuración
+	GetSnapshot(ctx context.Context, countryID uuid.UUID) (*entity.CountrySnapshot, int, error)
+	SaveConfigVersions(ctx context.Context, expectedVersion int, versions []entity.CountryConfigVersion) error
+	ListConfigVersions(ctx context.Context, countryID uuid.UUID, limit int) ([]entity.CountryConfigVersion, error)
+	GetConfigVersion(ctx context.Context, countryID uuid.UUID, version int) (*entity.CountryConfigVersion, error)
+	CancelConfigVersion(ctx context.Context, countryID uuid.UUID, version int, cancelledBy *uuid.UUID) error
+
+	// Configuración y reglas vigentes en un instante (fecha de la solicitud)
+	GetConfigAt(ctx context.Context, countryID uuid.UUID, at time.Time) (*entity.CountryConfig, error)
+	GetRulesAt(ctx context.Context, countryID uuid.UUID, at time.Time) ([]entity.CountryRule, error)
 }
 
+// ErrConfigVersionConflict otra modificación guardó una versión de la configuración del país antes
+var ErrConfigVersionConflict = errors.New("country config version conflict")
+
 // CreditApplicationRepository interface para operaciones con solicitudes de crédito
 type CreditApplicationRepository interface {
 	Create(ctx context.Context, app *entity.CreditApplication) error
@@ -35,6 +54,17 @@ type CreditApplicationRepository interface {
 	// Información bancaria
 	SaveBankingInfo(ctx context.Context, info *entity.BankingInfo) error
 	GetBankingInfo(ctx context.Context, applicationID uuid.UUID) (*entity.BankingInfo, error)
+
+	// Decisiones de riesgo
+	GetLatestRiskDecision(ctx context.Context, applicationID uuid.UUID) (*entity.RiskDecision, error)
+	ListRiskDecisions(ctx context.Context, applicationID uuid.UUID) ([]entity.RiskDecision, error)
+
+	// Controles de velocidad al crear solicitudes
+	CountOpenApplications(ctx context.Context, countryID uuid.UUID, since *time.Time, keys entity.VelocityKeys) (*entity.VelocityCounts, error)
+	RecordVelocityRejection(ctx context.Context, countryID uuid.UUID, check *entity.VelocityCheck, documentType, ip, userAgent string) error
+
+	// Simulación de reglas: solicitudes decididas por el sistema en un rango de fechas
+	ListForRuleSimulation(ctx context.Context, countryID uuid.UUID, from, to time.Time, limit int) ([]entity.RuleSimulationCase, error)
 }
 
 // BankingProviderRepository interface para operaciones con proveedores bancarios
@@ -42,11 +72,52 @@ type BankingProviderRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.BankingProvider, error)
 	GetByCountryID(ctx context.Context, countryID uuid.UUID) ([]entity.BankingProvider, error)
 	GetActiveByCountry(ctx context.Context, countryID uuid.UUID) (*entity.BankingProvider, error)
+	GetActiveByCountryID(ctx context.Context, countryID uuid.UUID) ([]entity.BankingProvider, error)
 	Create(ctx context.Context, provider *entity.BankingProvider) error
 	Update(ctx context.Context, provider *entity.BankingProvider) error
 	SaveRequest(ctx context.Context, request *entity.BankingRequest) error
+	GetRecentRequests(ctx context.Context, providerID uuid.UUID, since time.Time, limit int) ([]entity.BankingRequest, error)
+	GetRequestsByApplication(ctx context.Context, applicationID uuid.UUID) ([]entity.BankingRequest, error)
+	GetPendingAsyncRequests(ctx context.Context, applicationID uuid.UUID, requestType string) ([]entity.BankingRequest, error)
+
+	// ClaimCircuitProbe reserva la llamada de prueba del circuito; false si otro la tiene desde hace menos de ttl
+	ClaimCircuitProbe(ctx context.Context, providerID uuid.UUID, ttl time.Duration) (bool, error)
 }
 
+// ScoringModelRepository interface para los modelos de scoring versionados por país
+type ScoringModelRepository interface {
+	GetActiveByCountry(ctx context.Context, countryID uuid.UUID) (*entity.ScoringModel, error)
+	GetByVersion(ctx context.Context, countryID uuid.UUID, version int) (*entity.ScoringModel, error)
+	ListByCountry(ctx context.Context, countryID uuid.UUID) ([]entity.ScoringModel, error)
+	Create(ctx context.Context, model *entity.ScoringModel) error
+	Activate(ctx context.Context, countryID uuid.UUID, version int) error
+
+	// Champion/challenger
+	GetChallengerByCountry(ctx context.Context, countryID uuid.UUID) (*entity.ScoringModel, error)
+	SetChallenger(ctx context.Context, countryID uuid.UUID, version int, trafficPercent int) error
+	ClearChallenger(ctx context.Context, countryID uuid.UUID) error
+	GetChallengerStats(ctx context.Context, countryID, challengerModelID uuid.UUID, since *time.Time) (*entity.ChallengerStats, error)
+}
+
+// WatchlistRepository interface para las listas de sanciones y PEP y sus hits
+type WatchlistRepository interface {
+	// ReplaceList guarda la importación y reemplaza todas las entradas de su fuente
+	ReplaceList(ctx context.Context, imp *entity.WatchlistImport, entries []entity.WatchlistEntry) error
+	ListImports(ctx context.Context, limit int) ([]entity.WatchlistImport, error)
+
+	// FindMatches busca por similitud del nombre normalizado (pg_trgm) y por documento exacto
+	FindMatches(ctx context.Context, normalizedName, documentNumber string, minScore float64, limit int) ([]entity.WatchlistMatch, error)
+
+	// SaveScreening guarda los hits nuevos de la solicitud y devuelve su estado de screening
+	SaveScreening(ctx context.Context, applicationID uuid.UUID, hits []entity.WatchlistHit) (entity.ScreeningStatus, error)
+	ListHits(ctx context.Context, filter entity.WatchlistHitFilter) ([]entity.WatchlistHit, error)
+	GetHit(ctx context.Context, id uuid.UUID) (*entity.WatchlistHit, error)
+	ResolveHit(ctx context.Context, id uuid.UUID, status entity.WatchlistHitStatus, resolvedBy *uuid.UUID, note string) (entity.ScreeningStatus, error)
+}
+
+// ErrWatchlistHitResolved el hit ya fue resuelto por otro analista
+var ErrWatchlistHitResolved = errors.New("watchlist hit already resolved")
+
 // UserRepository interface para operaciones con usuarios
 type UserRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error)
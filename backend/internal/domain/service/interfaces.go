@@ -23,17 +23,41 @@ type BankingService interface {
 	
 	// GetProviderForCountry obtiene el proveedor activo para un país
 	GetProviderForCountry(ctx context.Context, countryID uuid.UUID) (*entity.BankingProvider, error)
+
+	// FetchWithFailover recorre los proveedores activos del país por prioridad hasta obtener respuesta
+	FetchWithFailover(ctx context.Context, applicationID, countryID uuid.UUID, docType, docNumber string) (*entity.BankingInfoResponse, *entity.BankingProvider, error)
+
+	// SaveBankingInfo guarda la información bancaria normalizada de una solicitud
+	SaveBankingInfo(ctx context.Context, applicationID, providerID uuid.UUID, response *entity.BankingInfoResponse) error
+
+	// ReuseCachedBankingInfo copia a la solicitud un reporte vigente del mismo documento y país (nil si no hay)
+	ReuseCachedBankingInfo(ctx context.Context, applicationID, countryID uuid.UUID, docType, docNumber string) (*entity.BankingInfo, error)
+
+	// FetchSyncWithFailover recorre la cadena omitiendo proveedores asíncronos
+	FetchSyncWithFailover(ctx context.Context, applicationID, countryID uuid.UUID, docType, docNumber string) (*entity.BankingInfoResponse, *entity.BankingProvider, error)
+
+	// CompleteAsyncReport normaliza el reporte recibido por webhook de una consulta asíncrona pendiente
+	CompleteAsyncReport(ctx context.Context, applicationID uuid.UUID, reference string, report interface{}) (*entity.BankingInfoResponse, *entity.BankingProvider, error)
+
+	// ExpireAsyncRequests da por vencidas las consultas asíncronas pendientes de una solicitud
+	ExpireAsyncRequests(ctx context.Context, applicationID uuid.UUID, reason string) (int, error)
 }
 
 // RiskEvaluator interface para evaluación de riesgo
 type RiskEvaluator interface {
-	// EvaluateRisk evalúa el riesgo de una solicitud
+	// EvaluateRisk evalúa el riesgo de una solicitud y devuelve el score y los códigos de motivo adversos
 	EvaluateRisk(ctx context.Context, app *entity.CreditApplication, bankingInfo *entity.BankingInfo) (float64, []string, error)
 	
 	// ShouldRequireReview determina si la solicitud requiere revisión manual
 	ShouldRequireReview(ctx context.Context, app *entity.CreditApplication, riskScore float64) bool
 }
 
+// WatchlistScreener interface para el screening de solicitantes contra listas de sanciones y PEP
+type WatchlistScreener interface {
+	// Screen busca al solicitante en las listas, guarda los posibles hits y devuelve el estado de screening
+	Screen(ctx context.Context, app *entity.CreditApplication) (entity.ScreeningStatus, error)
+}
+
 // NotificationService interface para envío de notificaciones
 type NotificationService interface {
 	// SendStatusNotification envía notificación de cambio de estado
@@ -69,8 +93,8 @@ type JobQueue interface {
 	// EnqueueWithDelay agrega un trabajo con retraso
 	EnqueueWithDelay(ctx context.Context, job *entity.Job, delaySec int) error
 	
-	// Dequeue obtiene el siguiente trabajo pendiente
-	Dequeue(ctx context.Context) (*entity.Job, error)
+	// Dequeue obtiene y reserva el siguiente trabajo pendiente para un worker
+	Dequeue(ctx context.Context, workerID string) (*entity.Job, error)
 	
 	// Complete marca un trabajo como completado
 	Complete(ctx context.Context, jobID uuid.UUID, result []byte) error
@@ -0,0 +1,104 @@
+package banking
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+)
+
+// BankingQuery datos de la consulta enviada a un proveedor
+type BankingQuery struct {
+	DocumentType   string `json:"document_type"`
+	DocumentNumber string `json:"document_number"`
+	Reference      string `json:"reference,omitempty"`    // Correlación de solicitudes asíncronas
+	CallbackURL    string `json:"callback_url,omitempty"` // Destino del webhook credit_report_ready
+}
+
+// ProviderAdapter adaptador para un proveedor bancario concreto
+// Cada adaptador sabe cómo hablar con la API del proveedor y devolver
+// la respuesta normalizada en BankingInfoResponse
+type ProviderAdapter interface {
+	Fetch(ctx context.Context, provider *entity.BankingProvider, query BankingQuery) (*entity.BankingInfoResponse, error)
+}
+
+// AsyncProviderAdapter adaptador de proveedores que entregan el reporte más tarde
+// Submit solo registra la consulta y devuelve una respuesta con Pending y la
+// referencia del proveedor; el reporte llega por el webhook credit_report_ready
+type AsyncProviderAdapter interface {
+	ProviderAdapter
+	Submit(ctx context.Context, provider *entity.BankingProvider, query BankingQuery) (*entity.BankingInfoResponse, error)
+}
+
+// Registry registro de adaptadores por código y tipo de proveedor
+// La búsqueda se hace primero por código (adaptadores específicos) y
+// después por tipo (adaptadores genéricos)
+type Registry struct {
+	mu       sync.RWMutex
+	byCode   map[string]ProviderAdapter
+	byType   map[entity.ProviderType]ProviderAdapter
+	override ProviderAdapter
+}
+
+// NewRegistry crea un registro vacío
+func NewRegistry() *Registry {
+	return &Registry{
+		byCode: make(map[string]ProviderAdapter),
+		byType: make(map[entity.ProviderType]ProviderAdapter),
+	}
+}
+
+// NewDefaultRegistry crea un registro con los adaptadores HTTP por tipo de proveedor
+// Si useStub está activo, todas las llamadas se dirigen al servidor stub local
+func NewDefaultRegistry(useStub bool) *Registry {
+	r := NewRegistry()
+	r.RegisterType(entity.ProviderTypeCreditBureau, NewHTTPAdapter("POST", "/v1/credit-reports"))
+	r.RegisterType(entity.ProviderTypeBankAPI, NewHTTPAdapter("GET", "/v1/customers/{document_number}/financial-profile"))
+	r.RegisterType(entity.ProviderTypeOpenBanking, NewHTTPAdapter("POST", "/v1/accounts/summary"))
+	r.RegisterType(entity.ProviderTypeAggregator, NewHTTPAdapter("POST", "/v1/profiles"))
+
+	if useStub {
+		r.SetOverride(NewStubAdapter())
+	}
+
+	return r
+}
+
+// RegisterCode registra un adaptador para un proveedor específico
+func (r *Registry) RegisterCode(code string, adapter ProviderAdapter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byCode[code] = adapter
+}
+
+// RegisterType registra un adaptador para un tipo de proveedor
+func (r *Registry) RegisterType(providerType entity.ProviderType, adapter ProviderAdapter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byType[providerType] = adapter
+}
+
+// SetOverride fuerza un adaptador para todos los proveedores (modo stub)
+func (r *Registry) SetOverride(adapter ProviderAdapter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.override = adapter
+}
+
+// Resolve obtiene el adaptador que corresponde a un proveedor
+func (r *Registry) Resolve(provider *entity.BankingProvider) (ProviderAdapter, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.override != nil {
+		return r.override, nil
+	}
+	if adapter, ok := r.byCode[provider.Code]; ok {
+		return adapter, nil
+	}
+	if adapter, ok := r.byType[provider.Type]; ok {
+		return adapter, nil
+	}
+	return nil, fmt.Errorf("no adapter registered for provider %s (type %s)", provider.Code, provider.Type)
+}
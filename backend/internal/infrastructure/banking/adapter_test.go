@@ -0,0 +1,126 @@
+package banking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+)
+
+// namedAdapter adaptador identificable en las pruebas del registro
+type namedAdapter string
+
+func (a namedAdapter) Fetch(ctx context.Context, provider *entity.BankingProvider, query BankingQuery) (*entity.BankingInfoResponse, error) {
+	return &entity.BankingInfoResponse{Success: true, ProviderCode: string(a)}, nil
+}
+
+func TestRegistryResolve(t *testing.T) {
+	bureau := &entity.BankingProvider{Code: "BUREAU_ES", Type: entity.ProviderTypeCreditBureau}
+	bank := &entity.BankingProvider{Code: "BANK_ES", Type: entity.ProviderTypeBankAPI}
+
+	tests := []struct {
+		name     string
+		setup    func(r *Registry)
+		provider *entity.BankingProvider
+		want     namedAdapter
+		wantErr  bool
+	}{
+		{
+			name:     "sin adaptadores",
+			setup:    func(r *Registry) {},
+			provider: bureau,
+			wantErr:  true,
+		},
+		{
+			name: "por tipo",
+			setup: func(r *Registry) {
+				r.RegisterType(entity.ProviderTypeCreditBureau, namedAdapter("type"))
+			},
+			provider: bureau,
+			want:     "type",
+		},
+		{
+			name: "el código gana al tipo",
+			setup: func(r *Registry) {
+				r.RegisterType(entity.ProviderTypeCreditBureau, namedAdapter("type"))
+				r.RegisterCode("BUREAU_ES", namedAdapter("code"))
+			},
+			provider: bureau,
+			want:     "code",
+		},
+		{
+			name: "el código de otro proveedor no aplica",
+			setup: func(r *Registry) {
+				r.RegisterType(entity.ProviderTypeBankAPI, namedAdapter("type"))
+				r.RegisterCode("BUREAU_ES", namedAdapter("code"))
+			},
+			provider: bank,
+			want:     "type",
+		},
+		{
+			name: "tipo sin adaptador",
+			setup: func(r *Registry) {
+				r.RegisterType(entity.ProviderTypeCreditBureau, namedAdapter("type"))
+			},
+			provider: bank,
+			wantErr:  true,
+		},
+		{
+			name: "el override gana a todo",
+			setup: func(r *Registry) {
+				r.RegisterType(entity.ProviderTypeCreditBureau, namedAdapter("type"))
+				r.RegisterCode("BUREAU_ES", namedAdapter("code"))
+				r.SetOverride(namedAdapter("override"))
+			},
+			provider: bureau,
+			want:     "override",
+		},
+		{
+			name: "el override cubre tipos sin adaptador",
+			setup: func(r *Registry) {
+				r.SetOverride(namedAdapter("override"))
+			},
+			provider: bank,
+			want:     "override",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			tt.setup(r)
+
+			adapter, err := r.Resolve(tt.provider)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Resolve = %v, want error", adapter)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			if adapter != tt.want {
+				t.Errorf("Resolve = %v, want %v", adapter, tt.want)
+			}
+		})
+	}
+
+	t.Run("registro por defecto", func(t *testing.T) {
+		for _, useStub := range []bool{false, true} {
+			r := NewDefaultRegistry(useStub)
+			for _, providerType := range []entity.ProviderType{
+				entity.ProviderTypeCreditBureau, entity.ProviderTypeBankAPI,
+				entity.ProviderTypeOpenBanking, entity.ProviderTypeAggregator,
+			} {
+				adapter, err := r.Resolve(&entity.BankingProvider{Code: "X", Type: providerType})
+				if err != nil {
+					t.Fatalf("useStub=%v %s: %v", useStub, providerType, err)
+				}
+				if _, isStub := adapter.(*StubAdapter); isStub != useStub {
+					t.Errorf("useStub=%v %s: adapter %T", useStub, providerType, adapter)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,167 @@
+package banking
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+// CircuitState estado del circuit breaker de un proveedor
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "CLOSED"    // El proveedor recibe tráfico normalmente
+	CircuitOpen     CircuitState = "OPEN"      // El proveedor se omite en la cadena de failover
+	CircuitHalfOpen CircuitState = "HALF_OPEN" // Se permite una llamada de prueba
+)
+
+// Estados de un registro en banking_requests
+const (
+	RequestStatusPending = "PENDING"
+	RequestStatusSuccess = "SUCCESS"
+	RequestStatusFailed  = "FAILED"
+)
+
+// Causa de una llamada FAILED en banking_requests (failure_kind)
+const (
+	FailureTransport = "TRANSPORT" // Conexión rechazada, DNS, TLS...
+	FailureServer    = "SERVER"    // El proveedor respondió 5xx
+	FailureTimeout   = "TIMEOUT"   // Timeout de la llamada o 408
+	FailureClient    = "CLIENT"    // 4xx: consulta inválida o documento no encontrado
+	FailureBusiness  = "BUSINESS"  // El proveedor respondió con success=false
+	FailureResponse  = "RESPONSE"  // Respuesta que no se pudo decodificar o mapear
+	FailureCancelled = "CANCELLED" // La llamada se canceló de nuestro lado
+	FailureExpired   = "EXPIRED"   // Consulta asíncrona sin reporte dentro de la espera
+)
+
+// classifyFailure determina la causa de una llamada fallida
+func classifyFailure(err error) string {
+	var httpErr *ProviderHTTPError
+	if errors.As(err, &httpErr) {
+		switch {
+		case httpErr.StatusCode >= 500:
+			return FailureServer
+		case httpErr.StatusCode == http.StatusRequestTimeout:
+			return FailureTimeout
+		default:
+			return FailureClient
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return FailureTimeout
+	}
+	if errors.Is(err, context.Canceled) {
+		return FailureCancelled
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return FailureTimeout
+		}
+		return FailureTransport
+	}
+	return FailureResponse
+}
+
+// isOutage indica si un fallo significa que el proveedor no está disponible
+// Sin clasificar (registros anteriores a la migración 022) cuenta como caída
+func isOutage(failureKind string) bool {
+	switch failureKind {
+	case FailureTransport, FailureServer, FailureTimeout, "":
+		return true
+	}
+	return false
+}
+
+// CircuitBreakerConfig parámetros del circuit breaker
+type CircuitBreakerConfig struct {
+	FailureThreshold int           // Fallos consecutivos para abrir el circuito
+	OpenDuration     time.Duration // Tiempo en OPEN antes de pasar a HALF_OPEN
+	Window           time.Duration // Ventana de llamadas recientes consideradas
+}
+
+// CircuitBreaker calcula el estado de cada proveedor a partir de banking_requests
+// Al derivarse de la base de datos, el estado es compartido entre la API y los workers
+type CircuitBreaker struct {
+	repo   repository.BankingProviderRepository
+	config CircuitBreakerConfig
+}
+
+// NewCircuitBreaker crea un circuit breaker con valores por defecto razonables
+func NewCircuitBreaker(repo repository.BankingProviderRepository, config CircuitBreakerConfig) *CircuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenDuration <= 0 {
+		config.OpenDuration = time.Minute
+	}
+	if config.Window <= 0 {
+		config.Window = 15 * time.Minute
+	}
+	return &CircuitBreaker{repo: repo, config: config}
+}
+
+// State obtiene el estado actual del circuito de un proveedor
+func (cb *CircuitBreaker) State(ctx context.Context, providerID uuid.UUID) (CircuitState, error) {
+	since := time.Now().Add(-cb.config.Window)
+	requests, err := cb.repo.GetRecentRequests(ctx, providerID, since, cb.config.FailureThreshold+10)
+	if err != nil {
+		return CircuitClosed, err
+	}
+
+	// Contar caídas consecutivas desde la llamada más reciente ya terminada
+	failures := 0
+	var lastFailure time.Time
+	probeInFlight := false
+	for _, req := range requests {
+		if req.Status == RequestStatusPending {
+			// Una llamada pendiente antigua se considera abandonada
+			if failures == 0 && time.Since(req.CreatedAt) < cb.config.OpenDuration {
+				probeInFlight = true
+			}
+			continue
+		}
+		if req.Status == RequestStatusFailed && !isOutage(req.FailureKind) {
+			// Cancelada o vencida sin reporte: no dice nada del proveedor
+			if req.FailureKind == FailureCancelled || req.FailureKind == FailureExpired {
+				continue
+			}
+			// Cualquier otro fallo es una respuesta: el proveedor está disponible
+			break
+		}
+		if req.Status != RequestStatusFailed {
+			break
+		}
+		if failures == 0 {
+			lastFailure = req.CreatedAt
+			if req.CompletedAt != nil {
+				lastFailure = *req.CompletedAt
+			}
+		}
+		failures++
+	}
+
+	if failures < cb.config.FailureThreshold {
+		return CircuitClosed, nil
+	}
+	if time.Since(lastFailure) < cb.config.OpenDuration {
+		return CircuitOpen, nil
+	}
+	// Solo se permite una llamada de prueba a la vez
+	if probeInFlight {
+		return CircuitOpen, nil
+	}
+	return CircuitHalfOpen, nil
+}
+
+// ClaimProbe reserva la llamada de prueba de un circuito HALF_OPEN. Varias
+// instancias pueden ver HALF_OPEN a la vez: solo la que obtiene la reserva llama
+// al proveedor. La reserva vence tras OpenDuration por si la prueba no termina
+func (cb *CircuitBreaker) ClaimProbe(ctx context.Context, providerID uuid.UUID) (bool, error) {
+	return cb.repo.ClaimCircuitProbe(ctx, providerID, cb.config.OpenDuration)
+}
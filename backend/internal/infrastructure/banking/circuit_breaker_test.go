@@ -0,0 +1,283 @@
+package banking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/domain/repository"
+	"github.com/fintech-multipass/backend/internal/infrastructure/encryption"
+	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+	"github.com/google/uuid"
+)
+
+// testEncryptionKey clave maestra de 32 bytes solo para las pruebas
+const testEncryptionKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+// fakeProviderRepo repositorio en memoria con lo que usan el circuit breaker y el failover
+type fakeProviderRepo struct {
+	repository.BankingProviderRepository
+
+	mu        sync.Mutex
+	providers []entity.BankingProvider
+	requests  []entity.BankingRequest // La más reciente primero
+	probes    map[uuid.UUID]time.Time
+}
+
+func (r *fakeProviderRepo) GetActiveByCountryID(ctx context.Context, countryID uuid.UUID) ([]entity.BankingProvider, error) {
+	return r.providers, nil
+}
+
+func (r *fakeProviderRepo) GetRecentRequests(ctx context.Context, providerID uuid.UUID, since time.Time, limit int) ([]entity.BankingRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []entity.BankingRequest
+	for _, req := range r.requests {
+		if req.ProviderID == providerID && !req.CreatedAt.Before(since) && len(out) < limit {
+			out = append(out, req)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeProviderRepo) SaveRequest(ctx context.Context, request *entity.BankingRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.requests {
+		if r.requests[i].ID == request.ID {
+			r.requests[i] = *request
+			return nil
+		}
+	}
+	r.requests = append([]entity.BankingRequest{*request}, r.requests...)
+	return nil
+}
+
+// ClaimCircuitProbe emula la inserción condicional de banking_circuit_probes
+func (r *fakeProviderRepo) ClaimCircuitProbe(ctx context.Context, providerID uuid.UUID, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.probes == nil {
+		r.probes = make(map[uuid.UUID]time.Time)
+	}
+	if claimedAt, ok := r.probes[providerID]; ok && time.Since(claimedAt) < ttl {
+		return false, nil
+	}
+	r.probes[providerID] = time.Now()
+	return true, nil
+}
+
+// failedRequests n llamadas FAILED terminadas hace ago, la más reciente primero
+func failedRequests(providerID uuid.UUID, n int, kind string, ago time.Duration) []entity.BankingRequest {
+	requests := make([]entity.BankingRequest, n)
+	for i := range requests {
+		at := time.Now().Add(-ago - time.Duration(i)*time.Second)
+		requests[i] = entity.BankingRequest{
+			ID: uuid.New(), ProviderID: providerID, Status: RequestStatusFailed,
+			FailureKind: kind, CreatedAt: at, CompletedAt: &at,
+		}
+	}
+	return requests
+}
+
+func requestAt(providerID uuid.UUID, status, kind string, ago time.Duration) entity.BankingRequest {
+	at := time.Now().Add(-ago)
+	return entity.BankingRequest{
+		ID: uuid.New(), ProviderID: providerID, Status: status,
+		FailureKind: kind, CreatedAt: at, CompletedAt: &at,
+	}
+}
+
+func concat(parts ...[]entity.BankingRequest) []entity.BankingRequest {
+	var out []entity.BankingRequest
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+func TestClassifyFailure(t *testing.T) {
+	dialErr := &url.Error{Op: "Post", URL: "https://bureau", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"5xx", &ProviderHTTPError{StatusCode: http.StatusBadGateway}, FailureServer},
+		{"5xx envuelto", fmt.Errorf("token endpoint: %w", &ProviderHTTPError{StatusCode: 503}), FailureServer},
+		{"408", &ProviderHTTPError{StatusCode: http.StatusRequestTimeout}, FailureTimeout},
+		{"404", &ProviderHTTPError{StatusCode: http.StatusNotFound}, FailureClient},
+		{"422", &ProviderHTTPError{StatusCode: http.StatusUnprocessableEntity}, FailureClient},
+		{"429", &ProviderHTTPError{StatusCode: http.StatusTooManyRequests}, FailureClient},
+		{"deadline", fmt.Errorf("provider X request failed: %w", context.DeadlineExceeded), FailureTimeout},
+		{"cancelado", fmt.Errorf("provider X request failed: %w", context.Canceled), FailureCancelled},
+		{"conexión rechazada", dialErr, FailureTransport},
+		{"timeout de red", &url.Error{Op: "Get", URL: "https://bureau", Err: timeoutError{}}, FailureTimeout},
+		{"respuesta ilegible", errors.New("failed to decode provider X response"), FailureResponse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyFailure(tt.err); got != tt.want {
+				t.Errorf("classifyFailure(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// timeoutError error de red con Timeout() = true
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestCircuitBreakerState(t *testing.T) {
+	provider := uuid.New()
+	config := CircuitBreakerConfig{FailureThreshold: 3, OpenDuration: time.Minute, Window: 15 * time.Minute}
+
+	tests := []struct {
+		name     string
+		requests []entity.BankingRequest
+		want     CircuitState
+	}{
+		{"sin llamadas", nil, CircuitClosed},
+		{"bajo el umbral", failedRequests(provider, 2, FailureServer, time.Second), CircuitClosed},
+		{"caídas recientes", failedRequests(provider, 3, FailureServer, time.Second), CircuitOpen},
+		{"timeouts y transporte", concat(
+			failedRequests(provider, 1, FailureTimeout, time.Second),
+			failedRequests(provider, 1, FailureTransport, 2*time.Second),
+			failedRequests(provider, 1, FailureServer, 3*time.Second),
+		), CircuitOpen},
+		{"registros sin clasificar cuentan", failedRequests(provider, 3, "", time.Second), CircuitOpen},
+		{"caídas pasado OpenDuration", failedRequests(provider, 3, FailureServer, 2*time.Minute), CircuitHalfOpen},
+		{"4xx de negocio no abren", failedRequests(provider, 5, FailureClient, time.Second), CircuitClosed},
+		{"success=false no abre", failedRequests(provider, 5, FailureBusiness, time.Second), CircuitClosed},
+		{"respuesta sin mapear no abre", failedRequests(provider, 5, FailureResponse, time.Second), CircuitClosed},
+		{"asíncronas vencidas no abren", failedRequests(provider, 5, FailureExpired, time.Second), CircuitClosed},
+		{"un 4xx reciente corta la racha", concat(
+			failedRequests(provider, 1, FailureClient, time.Second),
+			failedRequests(provider, 3, FailureServer, 2*time.Second),
+		), CircuitClosed},
+		{"un éxito reciente corta la racha", concat(
+			[]entity.BankingRequest{requestAt(provider, RequestStatusSuccess, "", time.Second)},
+			failedRequests(provider, 3, FailureServer, 2*time.Second),
+		), CircuitClosed},
+		{"vencidas y canceladas no cortan la racha", concat(
+			failedRequests(provider, 1, FailureExpired, time.Second),
+			failedRequests(provider, 1, FailureCancelled, time.Second),
+			failedRequests(provider, 3, FailureServer, 2*time.Second),
+		), CircuitOpen},
+		{"prueba en curso", concat(
+			[]entity.BankingRequest{requestAt(provider, RequestStatusPending, "", 5*time.Second)},
+			failedRequests(provider, 3, FailureServer, 2*time.Minute),
+		), CircuitOpen},
+		{"prueba abandonada", concat(
+			[]entity.BankingRequest{requestAt(provider, RequestStatusPending, "", 90*time.Second)},
+			failedRequests(provider, 3, FailureServer, 2*time.Minute),
+		), CircuitHalfOpen},
+		{"fuera de la ventana", failedRequests(provider, 3, FailureServer, 20*time.Minute), CircuitClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb := NewCircuitBreaker(&fakeProviderRepo{requests: tt.requests}, config)
+			got, err := cb.State(context.Background(), provider)
+			if err != nil {
+				t.Fatalf("State: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("State = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerClaimProbeOnce(t *testing.T) {
+	provider := uuid.New()
+	cb := NewCircuitBreaker(&fakeProviderRepo{}, CircuitBreakerConfig{OpenDuration: time.Minute})
+
+	var claimed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := cb.ClaimProbe(context.Background(), provider)
+			if err != nil {
+				t.Errorf("ClaimProbe: %v", err)
+			}
+			if ok {
+				claimed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if claimed.Load() != 1 {
+		t.Errorf("%d instances claimed the probe, want 1", claimed.Load())
+	}
+}
+
+// countingAdapter adaptador que cuenta las llamadas y responde con éxito
+type countingAdapter struct {
+	calls atomic.Int32
+}
+
+func (a *countingAdapter) Fetch(ctx context.Context, provider *entity.BankingProvider, query BankingQuery) (*entity.BankingInfoResponse, error) {
+	a.calls.Add(1)
+	return &entity.BankingInfoResponse{Success: true, ProviderCode: provider.Code}, nil
+}
+
+func TestFailoverHalfOpenSendsOneProbe(t *testing.T) {
+	provider := entity.BankingProvider{ID: uuid.New(), Code: "BUREAU", Type: entity.ProviderTypeCreditBureau, IsActive: true}
+	repo := &fakeProviderRepo{
+		providers: []entity.BankingProvider{provider},
+		requests:  failedRequests(provider.ID, 5, FailureServer, 2*time.Minute),
+	}
+	adapter := &countingAdapter{}
+	registry := NewRegistry()
+	registry.RegisterType(entity.ProviderTypeCreditBureau, adapter)
+	cipher, err := encryption.NewEnvelope(map[string]string{"test": testEncryptionKey}, "test")
+	if err != nil {
+		t.Fatalf("NewEnvelope: %v", err)
+	}
+	log := logger.NewLogger()
+	breaker := NewCircuitBreaker(repo, CircuitBreakerConfig{FailureThreshold: 5, OpenDuration: time.Minute})
+	service := NewProviderService(nil, repo, registry, breaker, NewOutboundClient(nil, log), cipher, "", log)
+
+	// Otra instancia ya reservó la prueba: el proveedor se omite
+	if ok, _ := breaker.ClaimProbe(context.Background(), provider.ID); !ok {
+		t.Fatalf("first claim failed")
+	}
+	_, _, err = service.FetchSyncWithFailover(context.Background(), uuid.Nil, uuid.New(), "DNI", "12345678Z")
+	if !errors.Is(err, ErrNoProviderAvailable) {
+		t.Fatalf("FetchSyncWithFailover error = %v, want ErrNoProviderAvailable", err)
+	}
+	if adapter.calls.Load() != 0 {
+		t.Fatalf("provider called %d times while another instance holds the probe", adapter.calls.Load())
+	}
+
+	// Reserva vencida: esta instancia hace la prueba y el éxito cierra el circuito
+	repo.mu.Lock()
+	repo.probes[provider.ID] = time.Now().Add(-2 * time.Minute)
+	repo.mu.Unlock()
+	if _, _, err := service.FetchSyncWithFailover(context.Background(), uuid.Nil, uuid.New(), "DNI", "12345678Z"); err != nil {
+		t.Fatalf("FetchSyncWithFailover: %v", err)
+	}
+	if adapter.calls.Load() != 1 {
+		t.Errorf("provider called %d times, want one probe", adapter.calls.Load())
+	}
+	if state, _ := breaker.State(context.Background(), provider.ID); state != CircuitClosed {
+		t.Errorf("state after a successful probe = %s, want CLOSED", state)
+	}
+}
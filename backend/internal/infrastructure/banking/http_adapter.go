@@ -0,0 +1,379 @@
+package banking
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// Tipos de autenticación soportados por los proveedores
+const (
+	AuthTypeAPIKey = "API_KEY"
+	AuthTypeOAuth2 = "OAUTH2"
+	AuthTypeBasic  = "BASIC"
+)
+
+// maxResponseSize tamaño máximo de respuesta aceptado de un proveedor
+const maxResponseSize = 1 << 20
+
+// HTTPAdapter adaptador genérico que llama a la API HTTP del proveedor
+// usando ProviderConfig (BaseURL, Headers, AuthType) y las credenciales
+type HTTPAdapter struct {
+	method     string
+	path       string
+	httpClient *http.Client
+
+	tokensMu sync.Mutex
+	tokens   map[uuid.UUID]oauthToken
+}
+
+// oauthToken token OAuth2 cacheado por proveedor
+type oauthToken struct {
+	AccessToken string
+	ExpiresAt   time.Time
+}
+
+// NewHTTPAdapter crea un adaptador HTTP para un método y ruta por defecto
+// La ruta admite los marcadores {document_type} y {document_number}
+func NewHTTPAdapter(method, path string) *HTTPAdapter {
+	return &HTTPAdapter{
+		method: method,
+		path:   path,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		tokens: make(map[uuid.UUID]oauthToken),
+	}
+}
+
+// Fetch realiza la llamada al proveedor y normaliza la respuesta
+func (a *HTTPAdapter) Fetch(ctx context.Context, provider *entity.BankingProvider, query BankingQuery) (*entity.BankingInfoResponse, error) {
+	contentType, body, err := a.do(ctx, provider, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResponse(provider, contentType, body)
+}
+
+// Submit envía una consulta asíncrona; el proveedor responde con una referencia
+// y entrega el reporte después en el callback_url
+func (a *HTTPAdapter) Submit(ctx context.Context, provider *entity.BankingProvider, query BankingQuery) (*entity.BankingInfoResponse, error) {
+	_, body, err := a.do(ctx, provider, query)
+	if err != nil {
+		return nil, err
+	}
+
+	ack := map[string]interface{}{}
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &ack); err != nil {
+			return nil, fmt.Errorf("failed to decode provider %s acknowledgement: %w", provider.Code, err)
+		}
+	}
+
+	// Los proveedores nombran la referencia de distintas formas
+	reference := query.Reference
+	for _, key := range []string{"reference_id", "request_id", "id"} {
+		if v, ok := ack[key].(string); ok && v != "" {
+			reference = v
+			break
+		}
+	}
+
+	return &entity.BankingInfoResponse{
+		Success:      true,
+		Pending:      true,
+		ProviderCode: provider.Code,
+		ReferenceID:  reference,
+		RawData:      ack,
+	}, nil
+}
+
+// do envía la consulta autenticada y devuelve el Content-Type y el cuerpo de la respuesta
+// Un status fuera de 2xx se devuelve como ProviderHTTPError
+func (a *HTTPAdapter) do(ctx context.Context, provider *entity.BankingProvider, query BankingQuery) (string, []byte, error) {
+	req, err := a.buildRequest(ctx, provider, query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := a.authenticate(ctx, provider, req); err != nil {
+		return "", nil, fmt.Errorf("failed to authenticate with provider %s: %w", provider.Code, err)
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("provider %s request failed: %w", provider.Code, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read provider %s response: %w", provider.Code, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", nil, &ProviderHTTPError{
+			ProviderCode: provider.Code,
+			StatusCode:   resp.StatusCode,
+			Body:         truncate(string(body), 500),
+		}
+	}
+
+	return resp.Header.Get("Content-Type"), body, nil
+}
+
+// buildRequest construye la petición HTTP para el proveedor
+func (a *HTTPAdapter) buildRequest(ctx context.Context, provider *entity.BankingProvider, query BankingQuery) (*http.Request, error) {
+	if provider.Config.BaseURL == "" {
+		return nil, fmt.Errorf("provider %s has no base_url configured", provider.Code)
+	}
+
+	path := a.path
+	if provider.Config.Endpoint != "" {
+		path = provider.Config.Endpoint
+	}
+	path = strings.NewReplacer(
+		"{document_type}", url.PathEscape(query.DocumentType),
+		"{document_number}", url.PathEscape(query.DocumentNumber),
+	).Replace(path)
+
+	endpoint := strings.TrimRight(provider.Config.BaseURL, "/") + path
+
+	var body io.Reader
+	if a.method == http.MethodGet {
+		params := url.Values{}
+		params.Set("document_type", query.DocumentType)
+		params.Set("document_number", query.DocumentNumber)
+		endpoint += "?" + params.Encode()
+	} else {
+		payload, err := json.Marshal(query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal provider request: %w", err)
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, a.method, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create provider request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("User-Agent", "Fintech-Multipass-Banking/1.0")
+	for k, v := range provider.Config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	return req, nil
+}
+
+// authenticate agrega las credenciales según el AuthType del proveedor
+func (a *HTTPAdapter) authenticate(ctx context.Context, provider *entity.BankingProvider, req *http.Request) error {
+	creds := provider.Credentials
+
+	switch strings.ToUpper(provider.Config.AuthType) {
+	case AuthTypeAPIKey:
+		header := creds["api_key_header"]
+		if header == "" {
+			header = "X-API-Key"
+		}
+		if creds["api_key"] == "" {
+			return fmt.Errorf("missing api_key credential")
+		}
+		req.Header.Set(header, creds["api_key"])
+	case AuthTypeBasic:
+		if creds["username"] == "" {
+			return fmt.Errorf("missing username credential")
+		}
+		req.SetBasicAuth(creds["username"], creds["password"])
+	case AuthTypeOAuth2:
+		token, err := a.oauthToken(ctx, provider)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	case "", "NONE":
+		// Sin autenticación
+	default:
+		return fmt.Errorf("unsupported auth type %s", provider.Config.AuthType)
+	}
+
+	return nil
+}
+
+// oauthToken obtiene un token OAuth2 (client credentials) cacheándolo hasta su expiración
+func (a *HTTPAdapter) oauthToken(ctx context.Context, provider *entity.BankingProvider) (string, error) {
+	creds := provider.Credentials
+	if creds["access_token"] != "" {
+		return creds["access_token"], nil
+	}
+
+	a.tokensMu.Lock()
+	cached, ok := a.tokens[provider.ID]
+	a.tokensMu.Unlock()
+	if ok && time.Now().Before(cached.ExpiresAt) {
+		return cached.AccessToken, nil
+	}
+
+	if creds["client_id"] == "" {
+		return "", fmt.Errorf("missing client_id credential")
+	}
+
+	tokenURL := creds["token_url"]
+	if tokenURL == "" {
+		tokenURL = strings.TrimRight(provider.Config.BaseURL, "/") + "/oauth/token"
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", creds["client_id"])
+	form.Set("client_secret", creds["client_secret"])
+	if scope := creds["scope"]; scope != "" {
+		form.Set("scope", scope)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// Como ProviderHTTPError: un 5xx del endpoint de token es una caída del proveedor
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 500))
+		return "", fmt.Errorf("token endpoint: %w", &ProviderHTTPError{
+			ProviderCode: provider.Code,
+			StatusCode:   resp.StatusCode,
+			Body:         string(body),
+		})
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&tokenResp); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return "", fmt.Errorf("token endpoint returned empty access_token")
+	}
+
+	expiresIn := tokenResp.ExpiresIn
+	if expiresIn <= 0 {
+		expiresIn = 300
+	}
+
+	a.tokensMu.Lock()
+	a.tokens[provider.ID] = oauthToken{
+		AccessToken: tokenResp.AccessToken,
+		// Renovar un poco antes de la expiración real
+		ExpiresAt: time.Now().Add(time.Duration(expiresIn)*time.Second - 30*time.Second),
+	}
+	a.tokensMu.Unlock()
+
+	return tokenResp.AccessToken, nil
+}
+
+// decodeResponse convierte el cuerpo de la respuesta al formato normalizado
+// Si el proveedor tiene ResponseMapping se aplica el motor de mapeo; si no,
+// se asume que la respuesta ya sigue el formato de BankingInfoResponse
+func decodeResponse(provider *entity.BankingProvider, contentType string, body []byte) (*entity.BankingInfoResponse, error) {
+	doc, err := parseDocument(provider.Config.ResponseFormat, contentType, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode provider %s response: %w", provider.Code, err)
+	}
+
+	var response *entity.BankingInfoResponse
+	if len(provider.Config.ResponseMapping) > 0 {
+		mapper, err := compiledMappings.get(provider.Config)
+		if err != nil {
+			return nil, fmt.Errorf("invalid response mapping for provider %s: %w", provider.Code, err)
+		}
+		response, err = mapper.Apply(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to map provider %s response: %w", provider.Code, err)
+		}
+	} else {
+		if _, ok := doc.(map[string]interface{}); !ok || bytes.HasPrefix(bytes.TrimSpace(body), []byte("<")) {
+			return nil, fmt.Errorf("provider %s response requires a response_mapping", provider.Code)
+		}
+		response = &entity.BankingInfoResponse{}
+		if err := json.Unmarshal(body, response); err != nil {
+			return nil, fmt.Errorf("failed to decode provider %s response: %w", provider.Code, err)
+		}
+	}
+
+	// Conservar siempre la respuesta original
+	if raw, ok := doc.(map[string]interface{}); ok {
+		response.RawData = raw
+	} else {
+		response.RawData = map[string]interface{}{"data": doc}
+	}
+
+	if response.ProviderCode == "" {
+		response.ProviderCode = provider.Code
+	}
+
+	return response, nil
+}
+
+// MapReport normaliza un reporte recibido por webhook con la configuración del
+// proveedor. El reporte puede ser un objeto JSON o un documento XML en texto
+func MapReport(provider *entity.BankingProvider, report interface{}) (*entity.BankingInfoResponse, error) {
+	var body []byte
+	contentType := "application/json"
+	switch r := report.(type) {
+	case nil:
+		return nil, fmt.Errorf("empty report from provider %s", provider.Code)
+	case string:
+		body = []byte(r)
+		contentType = ""
+	default:
+		encoded, err := json.Marshal(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode provider %s report: %w", provider.Code, err)
+		}
+		body = encoded
+	}
+
+	return decodeResponse(provider, contentType, body)
+}
+
+// ProviderHTTPError error devuelto cuando el proveedor responde con un status no exitoso
+type ProviderHTTPError struct {
+	ProviderCode string
+	StatusCode   int
+	Body         string
+}
+
+func (e *ProviderHTTPError) Error() string {
+	return fmt.Sprintf("provider %s returned status %d: %s", e.ProviderCode, e.StatusCode, e.Body)
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
@@ -0,0 +1,332 @@
+package banking
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// recordingStub servidor stub que guarda las cabeceras de la última consulta
+// y cuenta las peticiones de token OAuth2
+type recordingStub struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	header      http.Header
+	tokenCalls  int
+	reportCalls int
+}
+
+func newRecordingStub(t *testing.T) *recordingStub {
+	t.Helper()
+	rs := &recordingStub{}
+	stub := StubHandler()
+	rs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rs.mu.Lock()
+		if r.URL.Path == "/oauth/token" {
+			rs.tokenCalls++
+		} else {
+			rs.reportCalls++
+			rs.header = r.Header.Clone()
+		}
+		rs.mu.Unlock()
+		stub.ServeHTTP(w, r)
+	}))
+	t.Cleanup(rs.Close)
+	return rs
+}
+
+func (rs *recordingStub) lastHeader() http.Header {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.header
+}
+
+func stubTestProvider(baseURL, authType string, creds map[string]string) *entity.BankingProvider {
+	return &entity.BankingProvider{
+		ID:          uuid.New(),
+		Code:        "TEST_BUREAU",
+		Type:        entity.ProviderTypeCreditBureau,
+		Credentials: creds,
+		Config: entity.ProviderConfig{
+			BaseURL:         baseURL,
+			AuthType:        authType,
+			ResponseFormat:  ResponseFormatJSON,
+			ResponseMapping: stubResponseMapping,
+		},
+	}
+}
+
+func TestHTTPAdapterAuthentication(t *testing.T) {
+	tests := []struct {
+		name     string
+		authType string
+		creds    map[string]string
+		check    func(t *testing.T, h http.Header)
+		wantErr  string
+	}{
+		{
+			name:     "API key en la cabecera por defecto",
+			authType: AuthTypeAPIKey,
+			creds:    map[string]string{"api_key": "secret"},
+			check: func(t *testing.T, h http.Header) {
+				if got := h.Get("X-API-Key"); got != "secret" {
+					t.Errorf("X-API-Key = %q, want secret", got)
+				}
+			},
+		},
+		{
+			name:     "API key en cabecera configurada",
+			authType: "api_key",
+			creds:    map[string]string{"api_key": "secret", "api_key_header": "X-Bureau-Key"},
+			check: func(t *testing.T, h http.Header) {
+				if got := h.Get("X-Bureau-Key"); got != "secret" {
+					t.Errorf("X-Bureau-Key = %q, want secret", got)
+				}
+				if got := h.Get("X-API-Key"); got != "" {
+					t.Errorf("X-API-Key = %q, want empty", got)
+				}
+			},
+		},
+		{
+			name:     "API key sin credencial",
+			authType: AuthTypeAPIKey,
+			creds:    map[string]string{},
+			wantErr:  "missing api_key credential",
+		},
+		{
+			name:     "basic",
+			authType: AuthTypeBasic,
+			creds:    map[string]string{"username": "user", "password": "pass"},
+			check: func(t *testing.T, h http.Header) {
+				req := &http.Request{Header: h}
+				user, pass, ok := req.BasicAuth()
+				if !ok || user != "user" || pass != "pass" {
+					t.Errorf("basic auth = %q/%q (%v), want user/pass", user, pass, ok)
+				}
+			},
+		},
+		{
+			name:     "basic sin usuario",
+			authType: AuthTypeBasic,
+			creds:    map[string]string{"password": "pass"},
+			wantErr:  "missing username credential",
+		},
+		{
+			name:     "OAuth2 client credentials",
+			authType: AuthTypeOAuth2,
+			creds:    map[string]string{"client_id": "client", "client_secret": "s3cret"},
+			check: func(t *testing.T, h http.Header) {
+				if got := h.Get("Authorization"); got != "Bearer stub-access-token" {
+					t.Errorf("Authorization = %q, want Bearer stub-access-token", got)
+				}
+			},
+		},
+		{
+			name:     "OAuth2 con token fijo",
+			authType: AuthTypeOAuth2,
+			creds:    map[string]string{"access_token": "static-token"},
+			check: func(t *testing.T, h http.Header) {
+				if got := h.Get("Authorization"); got != "Bearer static-token" {
+					t.Errorf("Authorization = %q, want Bearer static-token", got)
+				}
+			},
+		},
+		{
+			name:     "OAuth2 sin client_id",
+			authType: AuthTypeOAuth2,
+			creds:    map[string]string{},
+			wantErr:  "missing client_id credential",
+		},
+		{
+			name:     "sin autenticación",
+			authType: "NONE",
+			check: func(t *testing.T, h http.Header) {
+				if got := h.Get("Authorization"); got != "" {
+					t.Errorf("Authorization = %q, want empty", got)
+				}
+			},
+		},
+		{
+			name:     "tipo vacío equivale a NONE",
+			authType: "",
+		},
+		{
+			name:     "tipo no soportado",
+			authType: "MTLS",
+			wantErr:  "unsupported auth type MTLS",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newRecordingStub(t)
+			adapter := NewHTTPAdapter(http.MethodPost, "/v1/credit-reports")
+			provider := stubTestProvider(stub.URL, tt.authType, tt.creds)
+
+			response, err := adapter.Fetch(context.Background(), provider, BankingQuery{DocumentType: "DNI", DocumentNumber: "12345678Z"})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Fetch error = %v, want %q", err, tt.wantErr)
+				}
+				if stub.reportCalls != 0 {
+					t.Errorf("provider called %d times after an authentication error", stub.reportCalls)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch: %v", err)
+			}
+			if !response.Success {
+				t.Errorf("response.Success = false, want true")
+			}
+			if tt.check != nil {
+				tt.check(t, stub.lastHeader())
+			}
+		})
+	}
+}
+
+func TestHTTPAdapterCachesOAuthToken(t *testing.T) {
+	stub := newRecordingStub(t)
+	adapter := NewHTTPAdapter(http.MethodPost, "/v1/credit-reports")
+	provider := stubTestProvider(stub.URL, AuthTypeOAuth2, map[string]string{"client_id": "client"})
+
+	for i := 0; i < 3; i++ {
+		if _, err := adapter.Fetch(context.Background(), provider, BankingQuery{DocumentNumber: "12345678Z"}); err != nil {
+			t.Fatalf("Fetch %d: %v", i, err)
+		}
+	}
+	if stub.tokenCalls != 1 {
+		t.Errorf("token requested %d times, want 1", stub.tokenCalls)
+	}
+}
+
+func TestHTTPAdapterStatusErrors(t *testing.T) {
+	stub := NewStubServer()
+	defer stub.Close()
+	adapter := NewHTTPAdapter(http.MethodPost, "/v1/credit-reports")
+	provider := stubTestProvider(stub.URL, "NONE", nil)
+
+	tests := []struct {
+		document  string
+		status    int
+		retryable bool
+	}{
+		{"", http.StatusBadRequest, false},
+		{"HTTP404", http.StatusNotFound, false},
+		{"HTTP408", http.StatusRequestTimeout, true},
+		{"HTTP422", http.StatusUnprocessableEntity, false},
+		{"HTTP429", http.StatusTooManyRequests, true},
+		{"HTTP500", http.StatusInternalServerError, true},
+		{"HTTP503", http.StatusServiceUnavailable, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.document, func(t *testing.T) {
+			for _, submit := range []bool{false, true} {
+				query := BankingQuery{DocumentType: "DNI", DocumentNumber: tt.document}
+				var err error
+				if submit {
+					query.Reference = "ref-1"
+					_, err = adapter.Submit(context.Background(), provider, query)
+				} else {
+					_, err = adapter.Fetch(context.Background(), provider, query)
+				}
+
+				var httpErr *ProviderHTTPError
+				if !errors.As(err, &httpErr) {
+					t.Fatalf("submit=%v: error = %v, want *ProviderHTTPError", submit, err)
+				}
+				if httpErr.StatusCode != tt.status || httpErr.ProviderCode != provider.Code {
+					t.Errorf("submit=%v: got %s/%d, want %s/%d", submit, httpErr.ProviderCode, httpErr.StatusCode, provider.Code, tt.status)
+				}
+				if !strings.Contains(httpErr.Body, `"status":"ERROR"`) {
+					t.Errorf("submit=%v: body = %q, want the provider error document", submit, httpErr.Body)
+				}
+				if got := isRetryable(err); got != tt.retryable {
+					t.Errorf("submit=%v: isRetryable = %v, want %v", submit, got, tt.retryable)
+				}
+			}
+		})
+	}
+
+	t.Run("cuerpo no JSON con 200", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("<html>maintenance"))
+		}))
+		defer server.Close()
+
+		_, err := adapter.Fetch(context.Background(), stubTestProvider(server.URL, "NONE", nil), BankingQuery{DocumentNumber: "1"})
+		var httpErr *ProviderHTTPError
+		if err == nil || errors.As(err, &httpErr) {
+			t.Fatalf("Fetch error = %v, want a decode error", err)
+		}
+	})
+}
+
+func TestStubAdapterRunsResponseMapping(t *testing.T) {
+	adapter := NewStubAdapter()
+	defer adapter.Close()
+
+	// El proveedor configurado no importa: el stub usa su propio mapeo
+	provider := &entity.BankingProvider{
+		ID:   uuid.New(),
+		Code: "REAL_BUREAU",
+		Type: entity.ProviderTypeCreditBureau,
+		Config: entity.ProviderConfig{
+			BaseURL:         "https://bureau.invalid",
+			AuthType:        AuthTypeOAuth2,
+			ResponseFormat:  ResponseFormatXML,
+			ResponseMapping: map[string]string{FieldCreditScore: "$.Report.Score"},
+		},
+	}
+	query := BankingQuery{DocumentType: "DNI", DocumentNumber: "12345678Z"}
+
+	first, err := adapter.Fetch(context.Background(), provider, query)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if !first.Success || first.ProviderCode != provider.Code {
+		t.Errorf("success/provider = %v/%s, want true/%s", first.Success, first.ProviderCode, provider.Code)
+	}
+	if first.CreditScore == nil || *first.CreditScore < 300 || *first.CreditScore > 850 {
+		t.Errorf("credit_score = %v, want a value scaled to 300-850", first.CreditScore)
+	}
+	if first.TotalDebt == nil || first.AvailableCredit == nil || first.MonthsEmployed == nil {
+		t.Errorf("debt/credit/employment not mapped: %+v", first)
+	}
+	if first.PaymentHistory == nil {
+		t.Errorf("payment_history not mapped")
+	} else if h := *first.PaymentHistory; h != "GOOD" && h != "REGULAR" && h != "BAD" {
+		t.Errorf("payment_history = %q, want GOOD, REGULAR or BAD", h)
+	}
+	if _, ok := first.RawData["report"]; !ok {
+		t.Errorf("raw data = %v, want the native stub document", first.RawData)
+	}
+
+	// Respuestas deterministas por documento
+	second, err := adapter.Fetch(context.Background(), provider, query)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if *second.CreditScore != *first.CreditScore || *second.TotalDebt != *first.TotalDebt {
+		t.Errorf("stub responses differ for the same document: %d/%v vs %d/%v",
+			*first.CreditScore, *first.TotalDebt, *second.CreditScore, *second.TotalDebt)
+	}
+
+	ack, err := adapter.Submit(context.Background(), provider, BankingQuery{DocumentNumber: "12345678Z", Reference: "app-1"})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if !ack.Pending || ack.ReferenceID != "STUB-app-1" {
+		t.Errorf("ack pending/reference = %v/%q, want true/STUB-app-1", ack.Pending, ack.ReferenceID)
+	}
+}
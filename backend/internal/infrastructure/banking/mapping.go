@@ -0,0 +1,482 @@
+package banking
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+)
+
+// Formatos de respuesta soportados por el motor de mapeo
+const (
+	ResponseFormatJSON = "JSON"
+	ResponseFormatXML  = "XML"
+)
+
+// Campos destino admitidos en ProviderConfig.ResponseMapping
+const (
+	FieldSuccess         = "success"
+	FieldCreditScore     = "credit_score"
+	FieldTotalDebt       = "total_debt"
+	FieldAvailableCredit = "available_credit"
+	FieldPaymentHistory  = "payment_history"
+	FieldBankAccounts    = "bank_accounts"
+	FieldActiveLoans     = "active_loans"
+	FieldMonthsEmployed  = "months_employed"
+	FieldErrorCode       = "error_code"
+	FieldErrorMessage    = "error_message"
+)
+
+var mappingFields = map[string]bool{
+	FieldSuccess:         true,
+	FieldCreditScore:     true,
+	FieldTotalDebt:       true,
+	FieldAvailableCredit: true,
+	FieldPaymentHistory:  true,
+	FieldBankAccounts:    true,
+	FieldActiveLoans:     true,
+	FieldMonthsEmployed:  true,
+	FieldErrorCode:       true,
+	FieldErrorMessage:    true,
+}
+
+// ResponseMapper mapeo compilado de la respuesta de un proveedor
+//
+// Cada entrada de ResponseMapping tiene la forma:
+//
+//	"<campo>": "<ruta> | <coerción>:<args> | ..."
+//
+// La ruta usa una sintaxis tipo JSONPath ($.a.b[0].c, $.items[*].amount,
+// $['campo con espacios']) y también se aplica a respuestas XML, donde los
+// atributos se acceden como @nombre y el texto de un elemento como #text.
+// Coerciones disponibles:
+//
+//	scale:fromMin:fromMax:toMin:toMax  normaliza una escala (ej. score 0-1000 a 300-850)
+//	currency:USD | currency:$.ruta     convierte con ProviderConfig.CurrencyRates
+//	multiply:x                         multiplica por un factor
+//	enum:A=GOOD,B=BAD,*=REGULAR        mapea valores enumerados (* = por defecto)
+//	count | sum                        cuenta o suma los elementos de una lista
+//	default:valor                      valor si la ruta no existe
+//	round                              redondea al entero más cercano
+type ResponseMapper struct {
+	fields []fieldMapping
+	rates  map[string]float64
+}
+
+type fieldMapping struct {
+	target string
+	path   *jsonPath
+	steps  []coercion
+}
+
+type coercion struct {
+	name string
+	args []string
+}
+
+// CompileMapping compila el mapeo configurado para un proveedor
+func CompileMapping(config entity.ProviderConfig) (*ResponseMapper, error) {
+	mapper := &ResponseMapper{rates: config.CurrencyRates}
+
+	for target, expr := range config.ResponseMapping {
+		if !mappingFields[target] {
+			return nil, fmt.Errorf("unknown mapping target field %q", target)
+		}
+
+		parts := strings.Split(expr, "|")
+		path, err := parsePath(strings.TrimSpace(parts[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid path for %s: %w", target, err)
+		}
+
+		fm := fieldMapping{target: target, path: path}
+		for _, raw := range parts[1:] {
+			step, err := parseCoercion(strings.TrimSpace(raw))
+			if err != nil {
+				return nil, fmt.Errorf("invalid coercion for %s: %w", target, err)
+			}
+			fm.steps = append(fm.steps, step)
+		}
+		mapper.fields = append(mapper.fields, fm)
+	}
+
+	return mapper, nil
+}
+
+// maxCachedMappings mapeos compilados que se conservan antes de vaciar la caché
+const maxCachedMappings = 256
+
+// compiledMappings caché de mapeos compilados por configuración: cada respuesta
+// reutiliza el mapeo y solo se recompila cuando cambian el mapeo o las tasas
+var compiledMappings = &mappingCache{entries: make(map[string]*ResponseMapper)}
+
+type mappingCache struct {
+	mu      sync.RWMutex
+	entries map[string]*ResponseMapper
+}
+
+// get devuelve el mapeo compilado para la configuración, compilándolo la primera vez
+func (c *mappingCache) get(config entity.ProviderConfig) (*ResponseMapper, error) {
+	// json.Marshal ordena las claves: la misma configuración produce la misma clave
+	encoded, err := json.Marshal([]interface{}{config.ResponseMapping, config.CurrencyRates})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response mapping: %w", err)
+	}
+	key := string(encoded)
+
+	c.mu.RLock()
+	mapper, ok := c.entries[key]
+	c.mu.RUnlock()
+	if ok {
+		return mapper, nil
+	}
+
+	mapper, err = CompileMapping(config)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	// Las configuraciones antiguas quedan huérfanas al editar un proveedor
+	if len(c.entries) >= maxCachedMappings {
+		c.entries = make(map[string]*ResponseMapper)
+	}
+	c.entries[key] = mapper
+	c.mu.Unlock()
+
+	return mapper, nil
+}
+
+// parseCoercion interpreta una coerción "nombre:arg1:arg2"
+func parseCoercion(raw string) (coercion, error) {
+	name, rest, _ := strings.Cut(raw, ":")
+	c := coercion{name: strings.ToLower(strings.TrimSpace(name))}
+
+	switch c.name {
+	case "scale":
+		c.args = strings.Split(rest, ":")
+		if len(c.args) != 4 {
+			return c, fmt.Errorf("scale requires 4 arguments")
+		}
+		for _, a := range c.args {
+			if _, err := strconv.ParseFloat(a, 64); err != nil {
+				return c, fmt.Errorf("scale argument %q is not a number", a)
+			}
+		}
+	case "multiply":
+		if _, err := strconv.ParseFloat(rest, 64); err != nil {
+			return c, fmt.Errorf("multiply argument %q is not a number", rest)
+		}
+		c.args = []string{rest}
+	case "currency":
+		if rest == "" {
+			return c, fmt.Errorf("currency requires a currency code or path")
+		}
+		c.args = []string{rest}
+	case "enum":
+		if rest == "" {
+			return c, fmt.Errorf("enum requires at least one mapping")
+		}
+		// Los pares se separan por coma y pueden contener ':' en el valor
+		c.args = strings.Split(rest, ",")
+		for _, pair := range c.args {
+			if !strings.Contains(pair, "=") {
+				return c, fmt.Errorf("enum entry %q must be key=value", pair)
+			}
+		}
+	case "default":
+		c.args = []string{rest}
+	case "count", "sum", "round":
+	default:
+		return c, fmt.Errorf("unknown coercion %q", c.name)
+	}
+
+	return c, nil
+}
+
+// Apply aplica el mapeo a un documento ya decodificado (JSON o XML)
+func (m *ResponseMapper) Apply(doc interface{}) (*entity.BankingInfoResponse, error) {
+	response := &entity.BankingInfoResponse{Success: true}
+
+	for _, fm := range m.fields {
+		value, found := fm.path.Eval(doc)
+
+		for _, step := range fm.steps {
+			var err error
+			value, found, err = m.coerce(step, value, found, doc)
+			if err != nil {
+				return nil, fmt.Errorf("mapping %s: %w", fm.target, err)
+			}
+		}
+
+		if !found || value == nil {
+			continue
+		}
+		if err := assignField(response, fm.target, value); err != nil {
+			return nil, fmt.Errorf("mapping %s: %w", fm.target, err)
+		}
+	}
+
+	return response, nil
+}
+
+// coerce aplica una coerción sobre el valor extraído
+func (m *ResponseMapper) coerce(step coercion, value interface{}, found bool, doc interface{}) (interface{}, bool, error) {
+	switch step.name {
+	case "default":
+		if !found || value == nil {
+			return step.args[0], true, nil
+		}
+		return value, true, nil
+	case "count":
+		if !found || value == nil {
+			return 0, true, nil
+		}
+		if list, ok := value.([]interface{}); ok {
+			return len(list), true, nil
+		}
+		return 1, true, nil
+	}
+
+	if !found || value == nil {
+		return value, found, nil
+	}
+
+	switch step.name {
+	case "sum":
+		list, ok := value.([]interface{})
+		if !ok {
+			list = []interface{}{value}
+		}
+		total := 0.0
+		for _, item := range list {
+			n, err := toFloat(item)
+			if err != nil {
+				return nil, false, err
+			}
+			total += n
+		}
+		return total, true, nil
+	case "scale":
+		n, err := toFloat(value)
+		if err != nil {
+			return nil, false, err
+		}
+		fromMin, _ := strconv.ParseFloat(step.args[0], 64)
+		fromMax, _ := strconv.ParseFloat(step.args[1], 64)
+		toMin, _ := strconv.ParseFloat(step.args[2], 64)
+		toMax, _ := strconv.ParseFloat(step.args[3], 64)
+		if fromMax == fromMin {
+			return nil, false, fmt.Errorf("scale source range is empty")
+		}
+		n = math.Max(fromMin, math.Min(fromMax, n))
+		return toMin + (n-fromMin)*(toMax-toMin)/(fromMax-fromMin), true, nil
+	case "multiply":
+		n, err := toFloat(value)
+		if err != nil {
+			return nil, false, err
+		}
+		factor, _ := strconv.ParseFloat(step.args[0], 64)
+		return n * factor, true, nil
+	case "currency":
+		n, err := toFloat(value)
+		if err != nil {
+			return nil, false, err
+		}
+		code := step.args[0]
+		if strings.HasPrefix(code, "$") {
+			path, err := parsePath(code)
+			if err != nil {
+				return nil, false, err
+			}
+			v, ok := path.Eval(doc)
+			if !ok {
+				return nil, false, fmt.Errorf("currency path %s not found", code)
+			}
+			code = toString(v)
+		}
+		rate, ok := m.rates[strings.ToUpper(code)]
+		if !ok {
+			return nil, false, fmt.Errorf("no currency rate configured for %s", code)
+		}
+		return n * rate, true, nil
+	case "enum":
+		key := toString(value)
+		var fallback *string
+		for _, pair := range step.args {
+			k, v, _ := strings.Cut(pair, "=")
+			k = strings.TrimSpace(k)
+			v = strings.TrimSpace(v)
+			if k == "*" {
+				fallback = &v
+				continue
+			}
+			if strings.EqualFold(k, key) {
+				return v, true, nil
+			}
+		}
+		if fallback != nil {
+			return *fallback, true, nil
+		}
+		return nil, false, fmt.Errorf("value %q has no enum mapping", key)
+	case "round":
+		n, err := toFloat(value)
+		if err != nil {
+			return nil, false, err
+		}
+		return math.Round(n), true, nil
+	}
+
+	return value, found, nil
+}
+
+// assignField asigna el valor al campo destino con la conversión de tipo adecuada
+func assignField(response *entity.BankingInfoResponse, target string, value interface{}) error {
+	switch target {
+	case FieldSuccess:
+		b, err := toBool(value)
+		if err != nil {
+			return err
+		}
+		response.Success = b
+	case FieldCreditScore:
+		n, err := toInt(value)
+		if err != nil {
+			return err
+		}
+		response.CreditScore = &n
+	case FieldTotalDebt:
+		f, err := toFloat(value)
+		if err != nil {
+			return err
+		}
+		response.TotalDebt = &f
+	case FieldAvailableCredit:
+		f, err := toFloat(value)
+		if err != nil {
+			return err
+		}
+		response.AvailableCredit = &f
+	case FieldPaymentHistory:
+		s := strings.ToUpper(toString(value))
+		response.PaymentHistory = &s
+	case FieldBankAccounts:
+		n, err := toInt(value)
+		if err != nil {
+			return err
+		}
+		response.BankAccounts = n
+	case FieldActiveLoans:
+		n, err := toInt(value)
+		if err != nil {
+			return err
+		}
+		response.ActiveLoans = n
+	case FieldMonthsEmployed:
+		n, err := toInt(value)
+		if err != nil {
+			return err
+		}
+		response.MonthsEmployed = &n
+	case FieldErrorCode:
+		response.ErrorCode = toString(value)
+	case FieldErrorMessage:
+		response.ErrorMessage = toString(value)
+	}
+	return nil
+}
+
+// parseDocument decodifica el cuerpo de la respuesta según su formato
+func parseDocument(format, contentType string, body []byte) (interface{}, error) {
+	if format == "" {
+		trimmed := bytes.TrimSpace(body)
+		if strings.Contains(contentType, "xml") || bytes.HasPrefix(trimmed, []byte("<")) {
+			format = ResponseFormatXML
+		} else {
+			format = ResponseFormatJSON
+		}
+	}
+
+	switch strings.ToUpper(format) {
+	case ResponseFormatXML:
+		return parseXML(body)
+	case ResponseFormatJSON:
+		var doc interface{}
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unsupported response format %s", format)
+	}
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case json.Number:
+		return n.Float64()
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		if err != nil {
+			return 0, fmt.Errorf("value %q is not a number", n)
+		}
+		return f, nil
+	case bool:
+		if n {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("value of type %T is not a number", v)
+}
+
+func toInt(v interface{}) (int, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Round(f)), nil
+}
+
+func toBool(v interface{}) (bool, error) {
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case string:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(b))
+		if err != nil {
+			return false, fmt.Errorf("value %q is not a boolean", b)
+		}
+		return parsed, nil
+	}
+	f, err := toFloat(v)
+	if err != nil {
+		return false, err
+	}
+	return f != 0, nil
+}
+
+func toString(v interface{}) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
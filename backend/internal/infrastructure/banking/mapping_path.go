@@ -0,0 +1,236 @@
+package banking
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// jsonPath ruta compilada con un subconjunto de JSONPath
+type jsonPath struct {
+	expr     string
+	segments []pathSegment
+}
+
+// pathSegment segmento de una ruta: clave, índice o comodín
+type pathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parsePath compila expresiones como $.a.b, $.a[0].b, $.items[*].x o $['a b']
+func parsePath(expr string) (*jsonPath, error) {
+	if expr == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	p := &jsonPath{expr: expr}
+	s := strings.TrimPrefix(expr, "$")
+
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '.':
+			i++
+			start := i
+			for i < len(s) && s[i] != '.' && s[i] != '[' {
+				i++
+			}
+			key := s[start:i]
+			if key == "" {
+				return nil, fmt.Errorf("empty key in path %s", expr)
+			}
+			if key == "*" {
+				p.segments = append(p.segments, pathSegment{wildcard: true})
+			} else {
+				p.segments = append(p.segments, pathSegment{key: key})
+			}
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed bracket in path %s", expr)
+			}
+			inner := strings.TrimSpace(s[i+1 : i+end])
+			i += end + 1
+
+			switch {
+			case inner == "*":
+				p.segments = append(p.segments, pathSegment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				p.segments = append(p.segments, pathSegment{key: inner[1 : len(inner)-1]})
+			default:
+				idx, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index %q in path %s", inner, expr)
+				}
+				p.segments = append(p.segments, pathSegment{index: idx, isIndex: true})
+			}
+		default:
+			// Permitir rutas sin "$." inicial (ej. "score.value")
+			if i == 0 {
+				s = "." + s
+				continue
+			}
+			return nil, fmt.Errorf("unexpected character %q in path %s", s[i], expr)
+		}
+	}
+
+	return p, nil
+}
+
+// Eval evalúa la ruta sobre un documento genérico
+// Si la ruta contiene comodines el resultado es una lista
+func (p *jsonPath) Eval(doc interface{}) (interface{}, bool) {
+	current := []interface{}{doc}
+	multi := false
+
+	for _, seg := range p.segments {
+		var next []interface{}
+		for _, node := range current {
+			switch {
+			case seg.wildcard:
+				multi = true
+				switch v := node.(type) {
+				case []interface{}:
+					next = append(next, v...)
+				case map[string]interface{}:
+					for _, child := range v {
+						next = append(next, child)
+					}
+				case nil:
+				default:
+					next = append(next, v)
+				}
+			case seg.isIndex:
+				list, ok := node.([]interface{})
+				if !ok {
+					// Un elemento XML único equivale a una lista de un elemento
+					if seg.index == 0 && node != nil {
+						next = append(next, node)
+					}
+					continue
+				}
+				idx := seg.index
+				if idx < 0 {
+					idx += len(list)
+				}
+				if idx >= 0 && idx < len(list) {
+					next = append(next, list[idx])
+				}
+			default:
+				if m, ok := node.(map[string]interface{}); ok {
+					if child, ok := m[seg.key]; ok {
+						next = append(next, child)
+					}
+				}
+			}
+		}
+		current = next
+		if len(current) == 0 {
+			break
+		}
+	}
+
+	if multi {
+		return current, true
+	}
+	if len(current) == 0 {
+		return nil, false
+	}
+	return current[0], true
+}
+
+// Límites del documento XML: la respuesta la controla el proveedor y el parser es recursivo
+const (
+	maxXMLDepth    = 32
+	maxXMLElements = 10000
+)
+
+// parseXML convierte un documento XML en la misma estructura genérica que JSON
+// Los atributos se guardan como "@nombre", el texto mixto como "#text" y
+// los elementos repetidos como listas
+func parseXML(body []byte) (interface{}, error) {
+	p := &xmlParser{decoder: xml.NewDecoder(bytes.NewReader(body))}
+
+	for {
+		tok, err := p.decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("empty XML document")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			node, err := p.element(start, 1)
+			if err != nil {
+				return nil, fmt.Errorf("invalid XML: %w", err)
+			}
+			return map[string]interface{}{start.Name.Local: node}, nil
+		}
+	}
+}
+
+// xmlParser decodificador con el recuento de elementos del documento
+type xmlParser struct {
+	decoder  *xml.Decoder
+	elements int
+}
+
+func (p *xmlParser) element(start xml.StartElement, depth int) (interface{}, error) {
+	if depth > maxXMLDepth {
+		return nil, fmt.Errorf("document nesting exceeds %d levels", maxXMLDepth)
+	}
+	p.elements++
+	if p.elements > maxXMLElements {
+		return nil, fmt.Errorf("document exceeds %d elements", maxXMLElements)
+	}
+
+	children := make(map[string]interface{})
+	for _, attr := range start.Attr {
+		children["@"+attr.Name.Local] = attr.Value
+	}
+
+	var text strings.Builder
+	hasChildren := false
+
+	for {
+		tok, err := p.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			hasChildren = true
+			child, err := p.element(t, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			name := t.Name.Local
+			if existing, ok := children[name]; ok {
+				if list, ok := existing.([]interface{}); ok {
+					children[name] = append(list, child)
+				} else {
+					children[name] = []interface{}{existing, child}
+				}
+			} else {
+				children[name] = child
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			value := strings.TrimSpace(text.String())
+			if !hasChildren && len(start.Attr) == 0 {
+				return value, nil
+			}
+			if value != "" {
+				children["#text"] = value
+			}
+			return children, nil
+		}
+	}
+}
@@ -0,0 +1,487 @@
+package banking
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+)
+
+func decodeJSON(t *testing.T, body string) interface{} {
+	t.Helper()
+	var doc interface{}
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatalf("invalid test document: %v", err)
+	}
+	return doc
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{"$.a.b", false},
+		{"$.a[0].b", false},
+		{"$.a[-1]", false},
+		{"$.items[*].amount", false},
+		{"$.*", false},
+		{"$['a b'].c", false},
+		{`$["a.b"]`, false},
+		{"score.value", false},
+		{"", true},
+		{"$.a..b", true},
+		{"$.a[0", true},
+		{"$.a[x]", true},
+		{"$.a b", false}, // El espacio forma parte de la clave
+		{"$a", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := parsePath(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parsePath(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJSONPathEval(t *testing.T) {
+	doc := decodeJSON(t, `{
+		"report": {
+			"score": {"value": 712},
+			"debts": [{"amount": 100}, {"amount": 250.5}, {"type": "CARD"}],
+			"empty": [],
+			"with space": "x",
+			"a.b": "dotted",
+			"nothing": null
+		}
+	}`)
+
+	tests := []struct {
+		expr      string
+		want      interface{}
+		wantFound bool
+	}{
+		{"$.report.score.value", 712.0, true},
+		{"report.score.value", 712.0, true},
+		{"$.report.debts[0].amount", 100.0, true},
+		{"$.report.debts[-1].type", "CARD", true},
+		{"$.report.debts[9].amount", nil, false},
+		{"$.report.debts[*].amount", []interface{}{100.0, 250.5}, true},
+		{"$.report.empty[*].amount", []interface{}(nil), true},
+		{"$.report['with space']", "x", true},
+		{`$.report["a.b"]`, "dotted", true},
+		{"$.report.missing", nil, false},
+		{"$.report.score.value.deeper", nil, false},
+		{"$.report.nothing", nil, true},
+		// Un índice 0 sobre un valor único lo devuelve (elemento XML no repetido)
+		{"$.report.score[0].value", 712.0, true},
+		{"$.report.score[1].value", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			path, err := parsePath(tt.expr)
+			if err != nil {
+				t.Fatalf("parsePath: %v", err)
+			}
+			got, found := path.Eval(doc)
+			if found != tt.wantFound || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eval(%q) = %#v, %v; want %#v, %v", tt.expr, got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestParseXML(t *testing.T) {
+	doc, err := parseXML([]byte(`<?xml version="1.0"?>
+		<Report status="OK">
+			<Score scale="1000">812</Score>
+			<Currency>USD</Currency>
+			<Debts>
+				<Debt><Amount>100</Amount></Debt>
+				<Debt><Amount>50</Amount></Debt>
+			</Debts>
+			<Accounts><Account id="1"/></Accounts>
+			<Note lang="es">texto <b>mixto</b></Note>
+			<Empty/>
+		</Report>`))
+	if err != nil {
+		t.Fatalf("parseXML: %v", err)
+	}
+
+	tests := []struct {
+		expr string
+		want interface{}
+	}{
+		{"$.Report['@status']", "OK"},
+		{"$.Report.Score['@scale']", "1000"},
+		{"$.Report.Score['#text']", "812"},
+		{"$.Report.Currency", "USD"},
+		{"$.Report.Debts.Debt[*].Amount", []interface{}{"100", "50"}},
+		{"$.Report.Debts.Debt[1].Amount", "50"},
+		{"$.Report.Accounts.Account[0]['@id']", "1"},
+		{"$.Report.Note['#text']", "texto"},
+		{"$.Report.Note.b", "mixto"},
+		{"$.Report.Empty", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			path, err := parsePath(tt.expr)
+			if err != nil {
+				t.Fatalf("parsePath: %v", err)
+			}
+			got, found := path.Eval(doc)
+			if !found || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eval(%q) = %#v, %v; want %#v", tt.expr, got, found, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseXMLRejectsHostileDocuments(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"vacío", "", "empty XML document"},
+		{"sin cerrar", "<Report><Score>1</Score>", "invalid XML"},
+		{"anidamiento al límite", strings.Repeat("<a>", maxXMLDepth) + strings.Repeat("</a>", maxXMLDepth), ""},
+		{"anidamiento excesivo", strings.Repeat("<a>", maxXMLDepth+1) + strings.Repeat("</a>", maxXMLDepth+1), "nesting exceeds"},
+		{"anidamiento sin cerrar", strings.Repeat("<a>", 100000), "nesting exceeds"},
+		{"demasiados elementos", "<r>" + strings.Repeat("<i/>", maxXMLElements) + "</r>", "exceeds 10000 elements"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseXML([]byte(tt.body))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("parseXML: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseXML error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResponseMapperCoercions(t *testing.T) {
+	doc := decodeJSON(t, `{
+		"status": "OK",
+		"score": 500,
+		"score_text": " 640 ",
+		"debts": [{"amount": 100}, {"amount": "50.5"}],
+		"single_debt": 75,
+		"currency": "usd",
+		"behaviour": "B",
+		"accounts": [{}, {}, {}],
+		"employment": {"months": 18.6},
+		"flag": "true",
+		"bad_number": "n/a"
+	}`)
+	rates := map[string]float64{"USD": 0.5, "EUR": 1}
+
+	intPtr := func(n int) *int { return &n }
+	floatPtr := func(f float64) *float64 { return &f }
+	strPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name    string
+		mapping map[string]string
+		want    entity.BankingInfoResponse
+		wantErr string
+	}{
+		{
+			name:    "scale y round",
+			mapping: map[string]string{FieldCreditScore: "$.score | scale:0:1000:300:850"},
+			want:    entity.BankingInfoResponse{Success: true, CreditScore: intPtr(575)},
+		},
+		{
+			name:    "scale satura fuera de rango",
+			mapping: map[string]string{FieldCreditScore: "$.score | scale:0:100:300:850"},
+			want:    entity.BankingInfoResponse{Success: true, CreditScore: intPtr(850)},
+		},
+		{
+			name:    "texto numérico",
+			mapping: map[string]string{FieldCreditScore: "$.score_text"},
+			want:    entity.BankingInfoResponse{Success: true, CreditScore: intPtr(640)},
+		},
+		{
+			name:    "sum de lista",
+			mapping: map[string]string{FieldTotalDebt: "$.debts[*].amount | sum"},
+			want:    entity.BankingInfoResponse{Success: true, TotalDebt: floatPtr(150.5)},
+		},
+		{
+			name:    "sum de valor único",
+			mapping: map[string]string{FieldTotalDebt: "$.single_debt | sum"},
+			want:    entity.BankingInfoResponse{Success: true, TotalDebt: floatPtr(75)},
+		},
+		{
+			name:    "currency fija",
+			mapping: map[string]string{FieldTotalDebt: "$.debts[*].amount | sum | currency:USD"},
+			want:    entity.BankingInfoResponse{Success: true, TotalDebt: floatPtr(75.25)},
+		},
+		{
+			name:    "currency por ruta",
+			mapping: map[string]string{FieldAvailableCredit: "$.single_debt | currency:$.currency"},
+			want:    entity.BankingInfoResponse{Success: true, AvailableCredit: floatPtr(37.5)},
+		},
+		{
+			name:    "multiply",
+			mapping: map[string]string{FieldAvailableCredit: "$.single_debt | multiply:2"},
+			want:    entity.BankingInfoResponse{Success: true, AvailableCredit: floatPtr(150)},
+		},
+		{
+			name:    "enum",
+			mapping: map[string]string{FieldPaymentHistory: "$.behaviour | enum:A=GOOD,b=regular,*=BAD"},
+			want:    entity.BankingInfoResponse{Success: true, PaymentHistory: strPtr("REGULAR")},
+		},
+		{
+			name: "enum por defecto y success",
+			mapping: map[string]string{
+				FieldPaymentHistory: "$.missing | default:Z | enum:A=GOOD,*=BAD",
+				FieldSuccess:        "$.status | enum:OK=true,*=false",
+			},
+			want: entity.BankingInfoResponse{Success: true, PaymentHistory: strPtr("BAD")},
+		},
+		{
+			name:    "success false",
+			mapping: map[string]string{FieldSuccess: "$.behaviour | enum:OK=true,*=false"},
+			want:    entity.BankingInfoResponse{Success: false},
+		},
+		{
+			name:    "booleano en texto",
+			mapping: map[string]string{FieldSuccess: "$.flag"},
+			want:    entity.BankingInfoResponse{Success: true},
+		},
+		{
+			name: "count",
+			mapping: map[string]string{
+				FieldBankAccounts: "$.accounts | count",
+				FieldActiveLoans:  "$.loans | count",
+			},
+			want: entity.BankingInfoResponse{Success: true, BankAccounts: 3, ActiveLoans: 0},
+		},
+		{
+			name:    "entero redondeado",
+			mapping: map[string]string{FieldMonthsEmployed: "$.employment.months"},
+			want:    entity.BankingInfoResponse{Success: true, MonthsEmployed: intPtr(19)},
+		},
+		{
+			name:    "round",
+			mapping: map[string]string{FieldTotalDebt: "$.employment.months | round"},
+			want:    entity.BankingInfoResponse{Success: true, TotalDebt: floatPtr(19)},
+		},
+		{
+			name:    "ruta ausente se omite",
+			mapping: map[string]string{FieldCreditScore: "$.missing | scale:0:1000:300:850"},
+			want:    entity.BankingInfoResponse{Success: true},
+		},
+		{
+			name:    "default numérico",
+			mapping: map[string]string{FieldCreditScore: "$.missing | default:600"},
+			want:    entity.BankingInfoResponse{Success: true, CreditScore: intPtr(600)},
+		},
+		{
+			name:    "valor no numérico",
+			mapping: map[string]string{FieldCreditScore: "$.bad_number"},
+			wantErr: `value "n/a" is not a number`,
+		},
+		{
+			name:    "enum sin valor por defecto",
+			mapping: map[string]string{FieldPaymentHistory: "$.behaviour | enum:A=GOOD"},
+			wantErr: `value "B" has no enum mapping`,
+		},
+		{
+			name:    "moneda sin tasa",
+			mapping: map[string]string{FieldTotalDebt: "$.single_debt | currency:BRL"},
+			wantErr: "no currency rate configured for BRL",
+		},
+		{
+			name:    "ruta de moneda ausente",
+			mapping: map[string]string{FieldTotalDebt: "$.single_debt | currency:$.nope"},
+			wantErr: "currency path $.nope not found",
+		},
+		{
+			name:    "escala vacía",
+			mapping: map[string]string{FieldCreditScore: "$.score | scale:5:5:300:850"},
+			wantErr: "scale source range is empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapper, err := CompileMapping(entity.ProviderConfig{ResponseMapping: tt.mapping, CurrencyRates: rates})
+			if err != nil {
+				t.Fatalf("CompileMapping: %v", err)
+			}
+			got, err := mapper.Apply(doc)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Apply error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				gotJSON, _ := json.Marshal(got)
+				wantJSON, _ := json.Marshal(tt.want)
+				t.Errorf("Apply = %s, want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func TestCompileMappingErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping map[string]string
+		wantErr string
+	}{
+		{"campo desconocido", map[string]string{"score": "$.a"}, `unknown mapping target field "score"`},
+		{"ruta vacía", map[string]string{FieldCreditScore: " | round"}, "invalid path"},
+		{"coerción desconocida", map[string]string{FieldCreditScore: "$.a | floor"}, `unknown coercion "floor"`},
+		{"scale incompleta", map[string]string{FieldCreditScore: "$.a | scale:0:1000"}, "scale requires 4 arguments"},
+		{"scale no numérica", map[string]string{FieldCreditScore: "$.a | scale:0:x:1:2"}, "is not a number"},
+		{"multiply no numérico", map[string]string{FieldTotalDebt: "$.a | multiply:x"}, "multiply argument"},
+		{"currency vacía", map[string]string{FieldTotalDebt: "$.a | currency"}, "currency requires"},
+		{"enum mal formado", map[string]string{FieldPaymentHistory: "$.a | enum:GOOD"}, "must be key=value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileMapping(entity.ProviderConfig{ResponseMapping: tt.mapping})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CompileMapping error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeResponse(t *testing.T) {
+	mapped := &entity.BankingProvider{
+		Code: "XML_BUREAU",
+		Config: entity.ProviderConfig{
+			CurrencyRates: map[string]float64{"USD": 2},
+			ResponseMapping: map[string]string{
+				FieldCreditScore: "$.Report.Score | scale:0:1000:300:850",
+				FieldTotalDebt:   "$.Report.Debts.Debt[*].Amount | sum | currency:$.Report.Currency",
+				FieldSuccess:     "$.Report['@status'] | enum:OK=true,*=false",
+			},
+		},
+	}
+	native := &entity.BankingProvider{Code: "NATIVE"}
+
+	tests := []struct {
+		name        string
+		provider    *entity.BankingProvider
+		contentType string
+		body        string
+		wantScore   int
+		wantErr     string
+	}{
+		{
+			name:        "XML detectado por Content-Type",
+			provider:    mapped,
+			contentType: "application/xml; charset=utf-8",
+			body:        `<Report status="OK"><Score>1000</Score><Currency>USD</Currency><Debts><Debt><Amount>10</Amount></Debt></Debts></Report>`,
+			wantScore:   850,
+		},
+		{
+			name:      "XML detectado por el cuerpo",
+			provider:  mapped,
+			body:      `  <Report status="OK"><Score>0</Score></Report>`,
+			wantScore: 300,
+		},
+		{
+			name:      "formato nativo",
+			provider:  native,
+			body:      `{"success": true, "credit_score": 700}`,
+			wantScore: 700,
+		},
+		{
+			name:     "XML sin mapeo",
+			provider: native,
+			body:     `<Report/>`,
+			wantErr:  "requires a response_mapping",
+		},
+		{
+			name:     "JSON inválido",
+			provider: native,
+			body:     `{"success":`,
+			wantErr:  "invalid JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeResponse(tt.provider, tt.contentType, []byte(tt.body))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("decodeResponse error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeResponse: %v", err)
+			}
+			if got.CreditScore == nil || *got.CreditScore != tt.wantScore {
+				t.Errorf("credit_score = %v, want %d", got.CreditScore, tt.wantScore)
+			}
+			if got.ProviderCode != tt.provider.Code || got.RawData == nil {
+				t.Errorf("provider_code/raw = %s/%v", got.ProviderCode, got.RawData)
+			}
+		})
+	}
+}
+
+func TestMappingCacheReusesCompiledMapping(t *testing.T) {
+	cache := &mappingCache{entries: make(map[string]*ResponseMapper)}
+	config := entity.ProviderConfig{
+		ResponseMapping: map[string]string{FieldCreditScore: "$.score", FieldTotalDebt: "$.debt"},
+		CurrencyRates:   map[string]float64{"USD": 1},
+	}
+
+	first, err := cache.get(config)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	// Misma configuración en otra instancia (proveedor recargado de base de datos)
+	again, err := cache.get(entity.ProviderConfig{
+		ResponseMapping: map[string]string{FieldTotalDebt: "$.debt", FieldCreditScore: "$.score"},
+		CurrencyRates:   map[string]float64{"USD": 1},
+	})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if first != again {
+		t.Errorf("same configuration compiled twice")
+	}
+
+	config.CurrencyRates = map[string]float64{"USD": 2}
+	changed, err := cache.get(config)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if changed == first {
+		t.Errorf("changed currency rates reused the previous mapping")
+	}
+
+	if _, err := cache.get(entity.ProviderConfig{ResponseMapping: map[string]string{"bogus": "$.a"}}); err == nil {
+		t.Errorf("invalid mapping compiled without error")
+	}
+	if len(cache.entries) != 2 {
+		t.Errorf("cache has %d entries, want 2", len(cache.entries))
+	}
+}
@@ -0,0 +1,132 @@
+package banking
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+)
+
+// Valores por defecto cuando el proveedor no los configura
+const (
+	defaultProviderTimeout = 30 * time.Second
+	defaultRetryDelay      = 500 * time.Millisecond
+	maxRetryDelay          = 10 * time.Second
+)
+
+// OutboundClient capa común para las llamadas salientes a proveedores
+// Aplica el rate limit, el timeout por intento y los reintentos con jitter
+// definidos en ProviderConfig
+type OutboundClient struct {
+	limiter RateLimiter
+	log     *logger.Logger
+}
+
+// NewOutboundClient crea el cliente saliente; limiter puede ser nil (sin límite)
+func NewOutboundClient(limiter RateLimiter, log *logger.Logger) *OutboundClient {
+	return &OutboundClient{limiter: limiter, log: log}
+}
+
+// Do ejecuta call respetando la configuración del proveedor
+// Si el proveedor está limitado antes del primer intento devuelve *RateLimitError
+// sin realizar ninguna llamada
+func (c *OutboundClient) Do(ctx context.Context, provider *entity.BankingProvider, call func(ctx context.Context) (*entity.BankingInfoResponse, error)) (*entity.BankingInfoResponse, error) {
+	cfg := provider.Config
+
+	timeout := defaultProviderTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	baseDelay := defaultRetryDelay
+	if cfg.RetryDelay > 0 {
+		baseDelay = time.Duration(cfg.RetryDelay) * time.Millisecond
+	}
+	attempts := 1
+	if cfg.RetryAttempts > 0 {
+		attempts += cfg.RetryAttempts
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			delay := backoffWithJitter(baseDelay, attempt-1)
+			if err := sleepCtx(ctx, delay); err != nil {
+				return nil, lastErr
+			}
+		}
+
+		if c.limiter != nil {
+			allowed, wait, err := c.limiter.Allow(ctx, provider)
+			if err != nil {
+				c.log.Warn().Err(err).Str("provider", provider.Code).Msg("Rate limiter unavailable, allowing call")
+			} else if !allowed {
+				if attempt == 1 {
+					return nil, &RateLimitError{ProviderCode: provider.Code, Wait: wait}
+				}
+				// Ya hubo una llamada fallida: esperar al siguiente token si cabe en el contexto
+				if err := sleepCtx(ctx, wait); err != nil {
+					return nil, lastErr
+				}
+			}
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		response, err := call(attemptCtx)
+		cancel()
+		if err == nil {
+			return response, nil
+		}
+
+		lastErr = err
+		if !isRetryable(err) || ctx.Err() != nil {
+			return nil, err
+		}
+
+		c.log.Warn().Err(err).
+			Str("provider", provider.Code).
+			Int("attempt", attempt).
+			Int("max_attempts", attempts).
+			Msg("Banking provider call failed, retrying")
+	}
+
+	return nil, lastErr
+}
+
+// isRetryable determina si un error justifica reintentar la llamada
+func isRetryable(err error) bool {
+	var httpErr *ProviderHTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500 || httpErr.StatusCode == http.StatusTooManyRequests ||
+			httpErr.StatusCode == http.StatusRequestTimeout
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// backoffWithJitter backoff exponencial con jitter completo
+func backoffWithJitter(base time.Duration, retry int) time.Duration {
+	max := base << uint(retry-1)
+	if max > maxRetryDelay || max <= 0 {
+		max = maxRetryDelay
+	}
+	return time.Duration(rand.Int63n(int64(max)) + 1)
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,252 @@
+package banking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"500", &ProviderHTTPError{StatusCode: http.StatusInternalServerError}, true},
+		{"503 envuelto", fmt.Errorf("call: %w", &ProviderHTTPError{StatusCode: http.StatusServiceUnavailable}), true},
+		{"429", &ProviderHTTPError{StatusCode: http.StatusTooManyRequests}, true},
+		{"408", &ProviderHTTPError{StatusCode: http.StatusRequestTimeout}, true},
+		{"400", &ProviderHTTPError{StatusCode: http.StatusBadRequest}, false},
+		{"401", &ProviderHTTPError{StatusCode: http.StatusUnauthorized}, false},
+		{"404", &ProviderHTTPError{StatusCode: http.StatusNotFound}, false},
+		{"deadline", fmt.Errorf("provider request failed: %w", context.DeadlineExceeded), true},
+		{"cancelado", context.Canceled, false},
+		{"error de red", &url.Error{Op: "Post", URL: "https://bureau", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, true},
+		{"error de decodificación", errors.New("failed to decode provider response"), false},
+		{"rate limit propio", &RateLimitError{Wait: time.Second}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.err); got != tt.want {
+				t.Errorf("isRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoffWithJitter(t *testing.T) {
+	tests := []struct {
+		base  time.Duration
+		retry int
+		max   time.Duration
+	}{
+		{500 * time.Millisecond, 1, 500 * time.Millisecond},
+		{500 * time.Millisecond, 2, time.Second},
+		{500 * time.Millisecond, 3, 2 * time.Second},
+		{500 * time.Millisecond, 5, 8 * time.Second},
+		{500 * time.Millisecond, 6, maxRetryDelay},
+		{time.Second, 40, maxRetryDelay}, // El desplazamiento desborda: se usa el máximo
+		{time.Second, 70, maxRetryDelay},
+		{time.Nanosecond, 1, time.Nanosecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.base, tt.retry), func(t *testing.T) {
+			for i := 0; i < 200; i++ {
+				d := backoffWithJitter(tt.base, tt.retry)
+				if d < 1 || d > tt.max {
+					t.Fatalf("backoffWithJitter(%s, %d) = %s, want in (0, %s]", tt.base, tt.retry, d, tt.max)
+				}
+			}
+		})
+	}
+}
+
+// fakeLimiter limitador que deniega las consultas indicadas (1 = la primera)
+type fakeLimiter struct {
+	denied map[int32]bool
+	calls  atomic.Int32
+	err    error
+}
+
+func (l *fakeLimiter) Allow(ctx context.Context, provider *entity.BankingProvider) (bool, time.Duration, error) {
+	n := l.calls.Add(1)
+	if l.err != nil {
+		return false, 0, l.err
+	}
+	if l.denied[n] {
+		return false, time.Millisecond, nil
+	}
+	return true, 0, nil
+}
+
+func TestOutboundClientDo(t *testing.T) {
+	serverErr := &ProviderHTTPError{ProviderCode: "P", StatusCode: http.StatusBadGateway}
+	clientErr := &ProviderHTTPError{ProviderCode: "P", StatusCode: http.StatusNotFound}
+
+	tests := []struct {
+		name      string
+		config    entity.ProviderConfig
+		limiter   *fakeLimiter
+		results   []error // Resultado de cada intento; nil = éxito
+		wantCalls int32
+		wantErr   error
+		rateLimit bool
+	}{
+		{
+			name:      "éxito al primer intento",
+			config:    entity.ProviderConfig{RetryAttempts: 3, RetryDelay: 1},
+			results:   []error{nil},
+			wantCalls: 1,
+		},
+		{
+			name:      "reintenta errores 5xx",
+			config:    entity.ProviderConfig{RetryAttempts: 3, RetryDelay: 1},
+			results:   []error{serverErr, serverErr, nil},
+			wantCalls: 3,
+		},
+		{
+			name:      "agota los reintentos",
+			config:    entity.ProviderConfig{RetryAttempts: 2, RetryDelay: 1},
+			results:   []error{serverErr, serverErr, serverErr, nil},
+			wantCalls: 3,
+			wantErr:   serverErr,
+		},
+		{
+			name:      "no reintenta 4xx",
+			config:    entity.ProviderConfig{RetryAttempts: 3, RetryDelay: 1},
+			results:   []error{clientErr, nil},
+			wantCalls: 1,
+			wantErr:   clientErr,
+		},
+		{
+			name:      "sin reintentos configurados",
+			config:    entity.ProviderConfig{RetryDelay: 1},
+			results:   []error{serverErr, nil},
+			wantCalls: 1,
+			wantErr:   serverErr,
+		},
+		{
+			name:      "limitado antes del primer intento",
+			config:    entity.ProviderConfig{RetryAttempts: 3, RetryDelay: 1},
+			limiter:   &fakeLimiter{denied: map[int32]bool{1: true}},
+			results:   []error{nil},
+			wantCalls: 0,
+			rateLimit: true,
+		},
+		{
+			name:      "limitado en un reintento espera el token",
+			config:    entity.ProviderConfig{RetryAttempts: 2, RetryDelay: 1},
+			limiter:   &fakeLimiter{denied: map[int32]bool{2: true}},
+			results:   []error{serverErr, nil},
+			wantCalls: 2,
+		},
+		{
+			name:      "limitador caído permite la llamada",
+			config:    entity.ProviderConfig{RetryDelay: 1},
+			limiter:   &fakeLimiter{err: errors.New("db down")},
+			results:   []error{nil},
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var limiter RateLimiter
+			if tt.limiter != nil {
+				limiter = tt.limiter
+			}
+			client := NewOutboundClient(limiter, logger.NewLogger())
+			provider := &entity.BankingProvider{Code: "P", Config: tt.config}
+
+			var calls atomic.Int32
+			response, err := client.Do(context.Background(), provider, func(ctx context.Context) (*entity.BankingInfoResponse, error) {
+				n := calls.Add(1)
+				if result := tt.results[n-1]; result != nil {
+					return nil, result
+				}
+				return &entity.BankingInfoResponse{Success: true}, nil
+			})
+
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls.Load(), tt.wantCalls)
+			}
+			if tt.rateLimit {
+				var rateErr *RateLimitError
+				if !errors.As(err, &rateErr) || rateErr.RetryAfter() <= 0 {
+					t.Fatalf("Do error = %v, want *RateLimitError", err)
+				}
+				return
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Do error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || response == nil || !response.Success {
+				t.Fatalf("Do = %v, %v; want a successful response", response, err)
+			}
+		})
+	}
+}
+
+func TestOutboundClientAttemptTimeout(t *testing.T) {
+	client := NewOutboundClient(nil, logger.NewLogger())
+	provider := &entity.BankingProvider{Code: "P", Config: entity.ProviderConfig{Timeout: 1, RetryAttempts: 1, RetryDelay: 1}}
+
+	var calls atomic.Int32
+	start := time.Now()
+	_, err := client.Do(context.Background(), provider, func(ctx context.Context) (*entity.BankingInfoResponse, error) {
+		calls.Add(1)
+		deadline, ok := ctx.Deadline()
+		if !ok || time.Until(deadline) > time.Second {
+			t.Errorf("attempt deadline = %v (%v), want at most the provider timeout", deadline, ok)
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Do error = %v, want DeadlineExceeded", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want the timeout retried once", calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Do took %s", elapsed)
+	}
+}
+
+func TestOutboundClientStopsWhenContextEnds(t *testing.T) {
+	client := NewOutboundClient(nil, logger.NewLogger())
+	provider := &entity.BankingProvider{Code: "P", Config: entity.ProviderConfig{RetryAttempts: 5, RetryDelay: 60000}}
+	serverErr := &ProviderHTTPError{ProviderCode: "P", StatusCode: http.StatusServiceUnavailable}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var calls atomic.Int32
+	_, err := client.Do(ctx, provider, func(ctx context.Context) (*entity.BankingInfoResponse, error) {
+		calls.Add(1)
+		return nil, serverErr
+	})
+
+	// El backoff no cabe en el contexto: se devuelve el último error del proveedor
+	if !errors.Is(err, serverErr) {
+		t.Fatalf("Do error = %v, want the last provider error", err)
+	}
+	if calls.Load() > 2 {
+		t.Errorf("calls = %d after the context ended", calls.Load())
+	}
+}
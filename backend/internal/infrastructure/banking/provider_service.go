@@ -2,161 +2,554 @@ package banking
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"math/rand"
 	"time"
 
 	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/domain/repository"
 	"github.com/fintech-multipass/backend/internal/infrastructure/database"
+	"github.com/fintech-multipass/backend/internal/infrastructure/encryption"
 	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// ErrNoProviderAvailable ningún proveedor de la cadena pudo responder
+var ErrNoProviderAvailable = errors.New("no banking provider available")
+
+// Tipos de llamada registrados en banking_requests
+const (
+	RequestTypeBankingInfo      = "BANKING_INFO"
+	RequestTypeBankingInfoAsync = "BANKING_INFO_ASYNC" // Queda PENDING hasta que llega el webhook
+)
+
+// ErrNoPendingAsyncRequest no hay una consulta asíncrona esperando el reporte
+var ErrNoPendingAsyncRequest = errors.New("no pending async banking request")
+
 // ProviderService servicio para integración con proveedores bancarios
 type ProviderService struct {
-	db  *database.PostgresDB
-	log *logger.Logger
+	db          *database.PostgresDB
+	providers   repository.BankingProviderRepository
+	registry    *Registry
+	breaker     *CircuitBreaker
+	client      *OutboundClient
+	cipher      *encryption.Envelope
+	callbackURL string
+	log         *logger.Logger
 }
 
 // NewProviderService crea una nueva instancia del servicio
-func NewProviderService(db *database.PostgresDB, log *logger.Logger) *ProviderService {
+func NewProviderService(db *database.PostgresDB, providers repository.BankingProviderRepository, registry *Registry, breaker *CircuitBreaker, client *OutboundClient, cipher *encryption.Envelope, callbackURL string, log *logger.Logger) *ProviderService {
 	return &ProviderService{
-		db:  db,
-		log: log,
+		db:          db,
+		providers:   providers,
+		registry:    registry,
+		breaker:     breaker,
+		client:      client,
+		cipher:      cipher,
+		callbackURL: callbackURL,
+		log:         log,
 	}
 }
 
 // GetProviderForCountry obtiene el proveedor activo para un país
 func (s *ProviderService) GetProviderForCountry(ctx context.Context, countryID uuid.UUID) (*entity.BankingProvider, error) {
-	query := `
-		SELECT id, country_id, code, name, type, is_active, priority, config, created_at, updated_at
-		FROM banking_providers
-		WHERE country_id = $1 AND is_active = true
-		ORDER BY priority DESC
-		LIMIT 1
-	`
+	return s.providers.GetActiveByCountry(ctx, countryID)
+}
 
-	var provider entity.BankingProvider
-	var configJSON []byte
+// FetchWithFailover consulta la cadena de proveedores activos del país por prioridad
+// Los proveedores con el circuito abierto se omiten y, si uno falla, se pasa al
+// siguiente. Devuelve la respuesta junto con el proveedor que la generó.
+// Si el proveedor elegido es asíncrono la respuesta viene con Pending
+func (s *ProviderService) FetchWithFailover(ctx context.Context, applicationID, countryID uuid.UUID, docType, docNumber string) (*entity.BankingInfoResponse, *entity.BankingProvider, error) {
+	return s.failover(ctx, applicationID, countryID, docType, docNumber, true)
+}
 
-	row := s.db.QueryRow(ctx, query, countryID)
-	err := row.Scan(
-		&provider.ID, &provider.CountryID, &provider.Code, &provider.Name,
-		&provider.Type, &provider.IsActive, &provider.Priority, &configJSON,
-		&provider.CreatedAt, &provider.UpdatedAt,
-	)
+// FetchSyncWithFailover recorre la cadena omitiendo los proveedores asíncronos
+// Se usa como respaldo cuando el reporte asíncrono no llegó a tiempo
+func (s *ProviderService) FetchSyncWithFailover(ctx context.Context, applicationID, countryID uuid.UUID, docType, docNumber string) (*entity.BankingInfoResponse, *entity.BankingProvider, error) {
+	return s.failover(ctx, applicationID, countryID, docType, docNumber, false)
+}
+
+func (s *ProviderService) failover(ctx context.Context, applicationID, countryID uuid.UUID, docType, docNumber string, allowAsync bool) (*entity.BankingInfoResponse, *entity.BankingProvider, error) {
+	providers, err := s.providers.GetActiveByCountryID(ctx, countryID)
 	if err != nil {
-		return nil, fmt.Errorf("no active provider found for country: %w", err)
+		return nil, nil, err
+	}
+	if len(providers) == 0 {
+		return nil, nil, fmt.Errorf("%w: no active providers for country %s", ErrNoProviderAvailable, countryID)
+	}
+
+	var lastErr error
+	var throttled *RateLimitError
+	for i := range providers {
+		provider := &providers[i]
+		if provider.Config.Async && !allowAsync {
+			continue
+		}
+
+		state, err := s.breaker.State(ctx, provider.ID)
+		if err != nil {
+			s.log.Warn().Err(err).Str("provider", provider.Code).Msg("Failed to read circuit breaker state")
+		}
+		if state == CircuitHalfOpen {
+			claimed, err := s.breaker.ClaimProbe(ctx, provider.ID)
+			if err != nil {
+				s.log.Warn().Err(err).Str("provider", provider.Code).Msg("Failed to claim circuit probe")
+			}
+			if !claimed {
+				// Otra instancia está haciendo la llamada de prueba
+				state = CircuitOpen
+			}
+		}
+		if state == CircuitOpen {
+			s.log.Warn().Str("provider", provider.Code).Msg("Circuit open, skipping banking provider")
+			lastErr = fmt.Errorf("provider %s circuit open", provider.Code)
+			continue
+		}
+
+		var response *entity.BankingInfoResponse
+		if provider.Config.Async && applicationID != uuid.Nil {
+			response, err = s.submit(ctx, applicationID, provider, docType, docNumber)
+		} else {
+			response, err = s.fetch(ctx, applicationID, provider, docType, docNumber)
+		}
+		if err == nil {
+			return response, provider, nil
+		}
+
+		var rateErr *RateLimitError
+		if errors.As(err, &rateErr) {
+			if throttled == nil || rateErr.Wait < throttled.Wait {
+				throttled = rateErr
+			}
+			s.log.Warn().Str("provider", provider.Code).Dur("retry_after", rateErr.Wait).
+				Msg("Banking provider rate limited, trying next provider")
+			continue
+		}
+
+		lastErr = err
+		s.log.Warn().Err(err).
+			Str("provider", provider.Code).
+			Str("circuit_state", string(state)).
+			Msg("Banking provider failed, trying next provider")
 	}
 
-	return &provider, nil
+	// Si algún proveedor solo estaba limitado, reintentar más tarde en lugar de fallar
+	if throttled != nil {
+		return nil, nil, throttled
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no synchronous providers for country %s", countryID)
+	}
+	return nil, nil, fmt.Errorf("%w: %v", ErrNoProviderAvailable, lastErr)
 }
 
 // FetchBankingInfo obtiene información bancaria del proveedor
-// En producción, esto haría llamadas reales a APIs de burós de crédito
+// La llamada se delega en el adaptador registrado para el proveedor
 func (s *ProviderService) FetchBankingInfo(ctx context.Context, provider *entity.BankingProvider, docType, docNumber string) (*entity.BankingInfoResponse, error) {
+	return s.fetch(ctx, uuid.Nil, provider, docType, docNumber)
+}
+
+// fetch llama al proveedor a través del cliente saliente (rate limit, timeout y
+// reintentos) y registra cada intento en banking_requests
+func (s *ProviderService) fetch(ctx context.Context, applicationID uuid.UUID, provider *entity.BankingProvider, docType, docNumber string) (*entity.BankingInfoResponse, error) {
 	s.log.Info().
 		Str("provider", provider.Code).
 		Str("document", docNumber).
 		Msg("Fetching banking info from provider")
 
-	// Simular latencia de red
-	time.Sleep(time.Duration(100+rand.Intn(200)) * time.Millisecond)
+	adapter, err := s.registry.Resolve(provider)
+	if err != nil {
+		return nil, err
+	}
 
-	// Simular respuesta del proveedor según el país
-	response := s.simulateProviderResponse(provider.Code, docType, docNumber)
+	query := BankingQuery{
+		DocumentType:   docType,
+		DocumentNumber: docNumber,
+	}
+
+	response, err := s.client.Do(ctx, provider, func(callCtx context.Context) (*entity.BankingInfoResponse, error) {
+		return s.recordCall(callCtx, applicationID, provider, query, func() (*entity.BankingInfoResponse, error) {
+			return adapter.Fetch(callCtx, provider, query)
+		})
+	})
+	if err != nil {
+		s.log.Error().Err(err).
+			Str("provider", provider.Code).
+			Msg("Banking provider call failed")
+		return nil, err
+	}
 
 	s.log.Info().
 		Str("provider", provider.Code).
 		Bool("success", response.Success).
 		Msg("Banking info fetched")
 
+	if !response.Success {
+		return nil, fmt.Errorf("provider %s returned error %s: %s", provider.Code, response.ErrorCode, response.ErrorMessage)
+	}
+
+	return response, nil
+}
+
+// submit envía una consulta a un proveedor asíncrono
+// La llamada queda registrada como PENDING con la referencia del proveedor
+// hasta que el reporte llega por webhook (CompleteAsyncReport) o vence la espera
+func (s *ProviderService) submit(ctx context.Context, applicationID uuid.UUID, provider *entity.BankingProvider, docType, docNumber string) (*entity.BankingInfoResponse, error) {
+	adapter, err := s.registry.Resolve(provider)
+	if err != nil {
+		return nil, err
+	}
+	asyncAdapter, ok := adapter.(AsyncProviderAdapter)
+	if !ok {
+		return nil, fmt.Errorf("adapter for provider %s does not support async reports", provider.Code)
+	}
+
+	request := &entity.BankingRequest{
+		ID:              uuid.New(),
+		ApplicationID:   applicationID,
+		ProviderID:      provider.ID,
+		RequestType:     RequestTypeBankingInfoAsync,
+		Status:          RequestStatusPending,
+		EncryptionKeyID: s.cipher.ActiveKeyID(),
+		CreatedAt:       time.Now(),
+	}
+	query := BankingQuery{
+		DocumentType:   docType,
+		DocumentNumber: docNumber,
+		Reference:      request.ID.String(),
+		CallbackURL:    s.callbackURL,
+	}
+	request.RequestData = s.encryptRecord(provider, map[string]interface{}{
+		"provider_code": provider.Code,
+		"base_url":      provider.Config.BaseURL,
+		"endpoint":      provider.Config.Endpoint,
+		"query":         query,
+	})
+
+	// Registrar antes de llamar: el webhook puede llegar antes de que termine Submit
+	if err := s.providers.SaveRequest(ctx, request); err != nil {
+		s.log.Error().Err(err).Str("provider", provider.Code).Msg("Failed to record async banking request")
+	}
+
+	s.log.Info().
+		Str("provider", provider.Code).
+		Str("application_id", applicationID.String()).
+		Msg("Submitting async banking info request")
+
+	start := time.Now()
+	response, err := s.client.Do(ctx, provider, func(callCtx context.Context) (*entity.BankingInfoResponse, error) {
+		return asyncAdapter.Submit(callCtx, provider, query)
+	})
+	request.Duration = int(time.Since(start).Milliseconds())
+
+	if err != nil {
+		completedAt := time.Now()
+		request.Status = RequestStatusFailed
+		request.ErrorMessage = err.Error()
+		request.FailureKind = classifyFailure(err)
+		request.CompletedAt = &completedAt
+	} else {
+		request.ExternalReference = response.ReferenceID
+		request.ResponseData = s.encryptRecord(provider, response)
+	}
+
+	if saveErr := s.providers.SaveRequest(context.WithoutCancel(ctx), request); saveErr != nil {
+		s.log.Error().Err(saveErr).Str("provider", provider.Code).Msg("Failed to record async banking request")
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	return response, nil
 }
 
-// simulateProviderResponse simula respuestas de diferentes proveedores
-func (s *ProviderService) simulateProviderResponse(providerCode, docType, docNumber string) *entity.BankingInfoResponse {
-	// Generar datos simulados basados en el hash del documento
-	seed := int64(0)
-	for _, c := range docNumber {
-		seed += int64(c)
+// CompleteAsyncReport procesa el reporte recibido por webhook para una consulta
+// asíncrona pendiente. Si reference no está vacía debe coincidir con la
+// referencia del proveedor o con la propia. La consulta se reclama (deja de estar
+// PENDING) en la misma transacción que guarda banking_info: entre entregas
+// concurrentes solo una la reclama y, si el guardado falla, la consulta sigue
+// PENDING para el reintento del proveedor. Una entrega repetida de un reporte ya
+// guardado devuelve la misma respuesta para que el llamador repita sus pasos
+// idempotentes. ReferenceID de la respuesta es el ID de la consulta resuelta
+func (s *ProviderService) CompleteAsyncReport(ctx context.Context, applicationID uuid.UUID, reference string, report interface{}) (*entity.BankingInfoResponse, *entity.BankingProvider, error) {
+	pending, err := s.providers.GetPendingAsyncRequests(ctx, applicationID, RequestTypeBankingInfoAsync)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var request *entity.BankingRequest
+	for i := range pending {
+		if matchesReference(&pending[i], reference) {
+			request = &pending[i]
+			break
+		}
+	}
+	if request == nil {
+		return s.completedAsyncReport(ctx, applicationID, reference)
+	}
+
+	provider, err := s.providers.GetByID(ctx, request.ProviderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response, mapErr := MapReport(provider, report)
+
+	completedAt := time.Now()
+	request.CompletedAt = &completedAt
+	request.Duration = int(completedAt.Sub(request.CreatedAt).Milliseconds())
+	request.Status = RequestStatusSuccess
+	switch {
+	case mapErr != nil:
+		request.Status = RequestStatusFailed
+		request.ErrorMessage = mapErr.Error()
+		request.FailureKind = FailureResponse
+		request.ResponseData = s.encryptRecord(provider, map[string]interface{}{"report": report})
+	case !response.Success:
+		request.Status = RequestStatusFailed
+		request.ErrorMessage = fmt.Sprintf("%s: %s", response.ErrorCode, response.ErrorMessage)
+		request.FailureKind = FailureBusiness
+		request.ResponseData = s.encryptRecord(provider, response)
+	default:
+		request.ResponseData = s.encryptRecord(provider, response)
+	}
+	request.EncryptionKeyID = s.cipher.ActiveKeyID()
+
+	claimed := false
+	err = s.db.WithTx(ctx, func(tx pgx.Tx) error {
+		var id uuid.UUID
+		err := tx.QueryRow(ctx, `
+			UPDATE banking_requests
+			SET status = $2, response_data = $3, error_message = $4, failure_kind = $5,
+				duration_ms = $6, completed_at = $7, encryption_key_id = $8
+			WHERE id = $1 AND status = 'PENDING'
+			RETURNING id
+		`, request.ID, request.Status, request.ResponseData, nullIfEmpty(request.ErrorMessage),
+			nullIfEmpty(request.FailureKind), request.Duration, request.CompletedAt,
+			nullIfEmpty(request.EncryptionKeyID),
+		).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Otra entrega la reclamó (o venció) entre la lectura y el UPDATE
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to claim async banking request: %w", err)
+		}
+		claimed = true
+
+		if request.Status != RequestStatusSuccess {
+			return nil
+		}
+		return saveBankingInfo(ctx, tx, applicationID, provider.ID, response)
+	})
+	if err != nil {
+		return nil, provider, err
+	}
+	if !claimed {
+		return s.completedAsyncReport(ctx, applicationID, reference)
+	}
+
+	if mapErr != nil {
+		return nil, provider, mapErr
+	}
+	if !response.Success {
+		return nil, provider, fmt.Errorf("provider %s returned error %s: %s", provider.Code, response.ErrorCode, response.ErrorMessage)
+	}
+
+	response.ReferenceID = request.ID.String()
+
+	s.log.Info().
+		Str("provider", provider.Code).
+		Str("application_id", applicationID.String()).
+		Dur("waited", completedAt.Sub(request.CreatedAt)).
+		Msg("Async banking report received")
+
+	return response, provider, nil
+}
+
+// completedAsyncReport resuelve una entrega sin consulta pendiente: si el reporte
+// de esa consulta ya se guardó devuelve la respuesta de la entrega original
+func (s *ProviderService) completedAsyncReport(ctx context.Context, applicationID uuid.UUID, reference string) (*entity.BankingInfoResponse, *entity.BankingProvider, error) {
+	requests, err := s.providers.GetRequestsByApplication(ctx, applicationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// La más reciente primero
+	for i := len(requests) - 1; i >= 0; i-- {
+		request := &requests[i]
+		if request.RequestType != RequestTypeBankingInfoAsync || !matchesReference(request, reference) {
+			continue
+		}
+		if request.Status != RequestStatusSuccess {
+			break
+		}
+
+		provider, err := s.providers.GetByID(ctx, request.ProviderID)
+		if err != nil {
+			return nil, nil, err
+		}
+		s.log.Info().
+			Str("provider", provider.Code).
+			Str("application_id", applicationID.String()).
+			Str("banking_request_id", request.ID.String()).
+			Msg("Async banking report already processed, repeating delivery")
+
+		return &entity.BankingInfoResponse{
+			Success:      true,
+			ProviderCode: provider.Code,
+			ReferenceID:  request.ID.String(),
+		}, provider, nil
 	}
-	rng := rand.New(rand.NewSource(seed))
 
-	// Simular diferentes comportamientos por proveedor
-	var response entity.BankingInfoResponse
-	response.Success = true
-	response.ProviderCode = providerCode
+	return nil, nil, fmt.Errorf("%w for application %s", ErrNoPendingAsyncRequest, applicationID)
+}
 
-	// Generar score crediticio (300-850)
-	creditScore := 300 + rng.Intn(550)
-	response.CreditScore = &creditScore
+// matchesReference indica si la referencia del webhook corresponde a la consulta
+// Sin referencia vale la consulta más reciente
+func matchesReference(request *entity.BankingRequest, reference string) bool {
+	return reference == "" || request.ExternalReference == reference || request.ID.String() == reference
+}
 
-	// Generar deuda total
-	totalDebt := float64(rng.Intn(50000))
-	response.TotalDebt = &totalDebt
+// ExpireAsyncRequests marca como fallidas las consultas asíncronas pendientes
+// de una solicitud. Un webhook que llegue después ya no será aceptado
+func (s *ProviderService) ExpireAsyncRequests(ctx context.Context, applicationID uuid.UUID, reason string) (int, error) {
+	pending, err := s.providers.GetPendingAsyncRequests(ctx, applicationID, RequestTypeBankingInfoAsync)
+	if err != nil {
+		return 0, err
+	}
 
-	// Generar crédito disponible
-	availableCredit := float64(rng.Intn(30000))
-	response.AvailableCredit = &availableCredit
+	for i := range pending {
+		request := &pending[i]
+		completedAt := time.Now()
+		request.Status = RequestStatusFailed
+		request.ErrorMessage = reason
+		request.FailureKind = FailureExpired
+		request.CompletedAt = &completedAt
+		request.Duration = int(completedAt.Sub(request.CreatedAt).Milliseconds())
+		if err := s.providers.SaveRequest(ctx, request); err != nil {
+			return i, fmt.Errorf("failed to expire async banking request: %w", err)
+		}
+	}
 
-	// Historial de pagos
-	histories := []string{"GOOD", "REGULAR", "BAD"}
-	paymentHistory := histories[rng.Intn(3)]
-	response.PaymentHistory = &paymentHistory
+	return len(pending), nil
+}
 
-	// Cuentas y préstamos
-	response.BankAccounts = rng.Intn(5)
-	response.ActiveLoans = rng.Intn(3)
+// recordCall registra una llamada individual al proveedor en banking_requests
+// La petición y la respuesta se guardan cifradas
+func (s *ProviderService) recordCall(ctx context.Context, applicationID uuid.UUID, provider *entity.BankingProvider, query BankingQuery, call func() (*entity.BankingInfoResponse, error)) (*entity.BankingInfoResponse, error) {
+	request := &entity.BankingRequest{
+		ID:              uuid.New(),
+		ApplicationID:   applicationID,
+		ProviderID:      provider.ID,
+		RequestType:     RequestTypeBankingInfo,
+		Status:          RequestStatusPending,
+		EncryptionKeyID: s.cipher.ActiveKeyID(),
+		CreatedAt:       time.Now(),
+	}
+	request.RequestData = s.encryptRecord(provider, map[string]interface{}{
+		"provider_code": provider.Code,
+		"base_url":      provider.Config.BaseURL,
+		"endpoint":      provider.Config.Endpoint,
+		"query":         query,
+	})
+	if err := s.providers.SaveRequest(ctx, request); err != nil {
+		s.log.Error().Err(err).Str("provider", provider.Code).Msg("Failed to record banking request")
+	}
 
-	// Meses empleado
-	monthsEmployed := rng.Intn(120)
-	response.MonthsEmployed = &monthsEmployed
+	start := time.Now()
+	response, err := call()
 
-	// Datos crudos simulados
-	response.RawData = map[string]interface{}{
-		"provider":       providerCode,
-		"document_type":  docType,
-		"query_date":     time.Now().Format(time.RFC3339),
-		"response_code":  "OK",
-		"credit_score":   creditScore,
-		"debt_info":      map[string]interface{}{"total": totalDebt, "monthly_payment": totalDebt / 24},
-		"employment":     map[string]interface{}{"months": monthsEmployed, "status": "EMPLOYED"},
+	completedAt := time.Now()
+	request.Duration = int(completedAt.Sub(start).Milliseconds())
+	request.CompletedAt = &completedAt
+	request.Status = RequestStatusSuccess
+	if err != nil {
+		request.Status = RequestStatusFailed
+		request.ErrorMessage = err.Error()
+		request.FailureKind = classifyFailure(err)
+	} else if !response.Success {
+		request.Status = RequestStatusFailed
+		request.ErrorMessage = fmt.Sprintf("%s: %s", response.ErrorCode, response.ErrorMessage)
+		request.FailureKind = FailureBusiness
+	}
+	if response != nil {
+		request.ResponseData = s.encryptRecord(provider, response)
 	}
 
-	// Simular algunos errores aleatorios (5% de probabilidad)
-	if rng.Float32() < 0.05 {
-		response.Success = false
-		response.ErrorCode = "PROVIDER_ERROR"
-		response.ErrorMessage = "Temporary service unavailability"
+	// Registrar aunque el contexto de la llamada haya expirado
+	if saveErr := s.providers.SaveRequest(context.WithoutCancel(ctx), request); saveErr != nil {
+		s.log.Error().Err(saveErr).Str("provider", provider.Code).Msg("Failed to record banking request result")
 	}
 
-	return &response
+	return response, err
+}
+
+// encryptRecord serializa y cifra un valor para banking_requests
+// Si el cifrado falla no se guarda el contenido, nunca en claro
+func (s *ProviderService) encryptRecord(provider *entity.BankingProvider, value interface{}) []byte {
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		s.log.Error().Err(err).Str("provider", provider.Code).Msg("Failed to marshal banking request data")
+		return nil
+	}
+	encrypted, err := s.cipher.Encrypt(plaintext)
+	if err != nil {
+		s.log.Error().Err(err).Str("provider", provider.Code).Msg("Failed to encrypt banking request data")
+		return nil
+	}
+	return encrypted
 }
 
 // SaveBankingInfo guarda la información bancaria obtenida
 func (s *ProviderService) SaveBankingInfo(ctx context.Context, applicationID, providerID uuid.UUID, response *entity.BankingInfoResponse) error {
+	return saveBankingInfo(ctx, s.db.Pool, applicationID, providerID, response)
+}
+
+// execer ejecuta sentencias en el pool o dentro de una transacción
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// saveBankingInfo guarda (o reemplaza) banking_info de la solicitud
+func saveBankingInfo(ctx context.Context, db execer, applicationID, providerID uuid.UUID, response *entity.BankingInfoResponse) error {
 	info := &entity.BankingInfo{
-		ID:             uuid.New(),
-		ApplicationID:  applicationID,
-		ProviderID:     providerID,
-		CreditScore:    response.CreditScore,
-		TotalDebt:      response.TotalDebt,
+		ID:              uuid.New(),
+		ApplicationID:   applicationID,
+		ProviderID:      providerID,
+		CreditScore:     response.CreditScore,
+		TotalDebt:       response.TotalDebt,
 		AvailableCredit: response.AvailableCredit,
-		PaymentHistory: response.PaymentHistory,
-		BankAccounts:   response.BankAccounts,
-		ActiveLoans:    response.ActiveLoans,
-		MonthsEmployed: response.MonthsEmployed,
-		RetrievedAt:    time.Now(),
-		ExpiresAt:      time.Now().Add(24 * time.Hour), // Expira en 24 horas
+		PaymentHistory:  response.PaymentHistory,
+		BankAccounts:    response.BankAccounts,
+		ActiveLoans:     response.ActiveLoans,
+		MonthsEmployed:  response.MonthsEmployed,
+		RetrievedAt:     time.Now(),
+		ExpiresAt:       time.Now().Add(24 * time.Hour), // Expira en 24 horas
+	}
+
+	var rawResponse *string
+	if response.RawData != nil {
+		raw, err := json.Marshal(response.RawData)
+		if err != nil {
+			return fmt.Errorf("failed to marshal raw response: %w", err)
+		}
+		rawJSON := string(raw)
+		rawResponse = &rawJSON
 	}
 
 	query := `
 		INSERT INTO banking_info (
 			id, application_id, provider_id, credit_score, total_debt,
 			available_credit, payment_history, bank_accounts, active_loans,
-			months_employed, retrieved_at, expires_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			months_employed, raw_response, retrieved_at, expires_at, source
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11::jsonb, $12, $13, $14)
 		ON CONFLICT (application_id) DO UPDATE SET
 			provider_id = EXCLUDED.provider_id,
 			credit_score = EXCLUDED.credit_score,
@@ -166,14 +559,101 @@ func (s *ProviderService) SaveBankingInfo(ctx context.Context, applicationID, pr
 			bank_accounts = EXCLUDED.bank_accounts,
 			active_loans = EXCLUDED.active_loans,
 			months_employed = EXCLUDED.months_employed,
+			raw_response = EXCLUDED.raw_response,
+			source = EXCLUDED.source,
+			reused_from_id = NULL,
+			reused_from_application_id = NULL,
 			retrieved_at = EXCLUDED.retrieved_at,
 			expires_at = EXCLUDED.expires_at
 	`
 
-	return s.db.Exec(ctx, query,
+	_, err := db.Exec(ctx, query,
 		info.ID, info.ApplicationID, info.ProviderID, info.CreditScore, info.TotalDebt,
 		info.AvailableCredit, info.PaymentHistory, info.BankAccounts, info.ActiveLoans,
-		info.MonthsEmployed, info.RetrievedAt, info.ExpiresAt,
+		info.MonthsEmployed, rawResponse, info.RetrievedAt, info.ExpiresAt,
+		entity.BankingInfoSourceProvider,
+	)
+	return err
+}
+
+// ReuseCachedBankingInfo reutiliza un reporte vigente de otra solicitud con el
+// mismo documento en el mismo país. El reporte debe no haber expirado y estar
+// dentro del CacheTTLMinutes del proveedor que lo generó (si está configurado).
+// La copia conserva la fecha de obtención original y registra su procedencia
+func (s *ProviderService) ReuseCachedBankingInfo(ctx context.Context, applicationID, countryID uuid.UUID, docType, docNumber string) (*entity.BankingInfo, error) {
+	query := `
+		WITH candidate AS (
+			SELECT bi.*
+			FROM banking_info bi
+			JOIN credit_applications ca ON ca.id = bi.application_id
+			JOIN banking_providers bp ON bp.id = bi.provider_id
+			WHERE ca.country_id = $2
+				AND ca.document_type = $3
+				AND ca.document_number = $4
+				AND bi.application_id <> $1
+				AND bi.expires_at > NOW()
+				AND (
+					COALESCE((bp.config->>'cache_ttl_minutes')::int, 0) <= 0
+					OR bi.retrieved_at > NOW() - make_interval(mins => (bp.config->>'cache_ttl_minutes')::int)
+				)
+			ORDER BY bi.retrieved_at DESC
+			LIMIT 1
+		)
+		INSERT INTO banking_info (
+			id, application_id, provider_id, credit_score, total_debt,
+			available_credit, payment_history, bank_accounts, active_loans,
+			months_employed, raw_response, retrieved_at, expires_at,
+			source, reused_from_id, reused_from_application_id
+		)
+		SELECT $5, $1, provider_id, credit_score, total_debt,
+			available_credit, payment_history, bank_accounts, active_loans,
+			months_employed, raw_response, retrieved_at, expires_at,
+			'CACHE', COALESCE(reused_from_id, id), COALESCE(reused_from_application_id, application_id)
+		FROM candidate
+		ON CONFLICT (application_id) DO UPDATE SET
+			provider_id = EXCLUDED.provider_id,
+			credit_score = EXCLUDED.credit_score,
+			total_debt = EXCLUDED.total_debt,
+			available_credit = EXCLUDED.available_credit,
+			payment_history = EXCLUDED.payment_history,
+			bank_accounts = EXCLUDED.bank_accounts,
+			active_loans = EXCLUDED.active_loans,
+			months_employed = EXCLUDED.months_employed,
+			raw_response = EXCLUDED.raw_response,
+			retrieved_at = EXCLUDED.retrieved_at,
+			expires_at = EXCLUDED.expires_at,
+			source = EXCLUDED.source,
+			reused_from_id = EXCLUDED.reused_from_id,
+			reused_from_application_id = EXCLUDED.reused_from_application_id
+		RETURNING id, provider_id, credit_score, total_debt, available_credit, payment_history,
+			bank_accounts, active_loans, months_employed, retrieved_at, expires_at,
+			reused_from_id, reused_from_application_id
+	`
+
+	info := entity.BankingInfo{
+		ApplicationID: applicationID,
+		Source:        entity.BankingInfoSourceCache,
+	}
+	row := s.db.QueryRow(ctx, query, applicationID, countryID, docType, docNumber, uuid.New())
+	err := row.Scan(
+		&info.ID, &info.ProviderID, &info.CreditScore, &info.TotalDebt, &info.AvailableCredit,
+		&info.PaymentHistory, &info.BankAccounts, &info.ActiveLoans, &info.MonthsEmployed,
+		&info.RetrievedAt, &info.ExpiresAt, &info.ReusedFromID, &info.ReusedFromApplicationID,
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reuse cached banking info: %w", err)
+	}
+
+	return &info, nil
 }
 
+// nullIfEmpty guarda NULL en lugar de una cadena vacía
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,113 @@
+package banking
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+	"github.com/google/uuid"
+)
+
+// GetRequestsByApplication como el repositorio: la más antigua primero
+func (r *fakeProviderRepo) GetRequestsByApplication(ctx context.Context, applicationID uuid.UUID) ([]entity.BankingRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []entity.BankingRequest
+	for i := len(r.requests) - 1; i >= 0; i-- {
+		if r.requests[i].ApplicationID == applicationID {
+			out = append(out, r.requests[i])
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeProviderRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.BankingProvider, error) {
+	for i := range r.providers {
+		if r.providers[i].ID == id {
+			return &r.providers[i], nil
+		}
+	}
+	return nil, errors.New("provider not found")
+}
+
+func TestMatchesReference(t *testing.T) {
+	request := &entity.BankingRequest{ID: uuid.New(), ExternalReference: "EXT-1"}
+
+	tests := []struct {
+		name      string
+		reference string
+		want      bool
+	}{
+		{"sin referencia", "", true},
+		{"referencia externa", "EXT-1", true},
+		{"ID de la consulta", request.ID.String(), true},
+		{"otra referencia", "EXT-2", false},
+		{"otro ID", uuid.New().String(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesReference(request, tt.reference); got != tt.want {
+				t.Errorf("matchesReference(%q) = %v, want %v", tt.reference, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompletedAsyncReport(t *testing.T) {
+	provider := entity.BankingProvider{ID: uuid.New(), Code: "BUREAU"}
+	applicationID := uuid.New()
+
+	asyncRequest := func(reference, status string, ago time.Duration) entity.BankingRequest {
+		return entity.BankingRequest{
+			ID: uuid.New(), ApplicationID: applicationID, ProviderID: provider.ID,
+			RequestType: RequestTypeBankingInfoAsync, ExternalReference: reference,
+			Status: status, CreatedAt: time.Now().Add(-ago),
+		}
+	}
+	processed := asyncRequest("EXT-1", RequestStatusSuccess, time.Minute)
+	expired := asyncRequest("EXT-1", RequestStatusFailed, time.Minute)
+	retried := asyncRequest("EXT-1", RequestStatusSuccess, time.Hour)
+	syncRequest := processed
+	syncRequest.ID = uuid.New()
+	syncRequest.RequestType = RequestTypeBankingInfo
+
+	tests := []struct {
+		name      string
+		requests  []entity.BankingRequest // La más reciente primero
+		reference string
+		wantID    uuid.UUID
+	}{
+		{"entrega repetida", []entity.BankingRequest{processed}, "EXT-1", processed.ID},
+		{"sin referencia", []entity.BankingRequest{processed}, "", processed.ID},
+		{"por ID de la consulta", []entity.BankingRequest{processed}, processed.ID.String(), processed.ID},
+		{"la última consulta venció", []entity.BankingRequest{expired, retried}, "EXT-1", uuid.Nil},
+		{"otra referencia", []entity.BankingRequest{processed}, "EXT-2", uuid.Nil},
+		{"solo consultas síncronas", []entity.BankingRequest{syncRequest}, "EXT-1", uuid.Nil},
+		{"sin consultas", nil, "EXT-1", uuid.Nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeProviderRepo{providers: []entity.BankingProvider{provider}, requests: tt.requests}
+			service := &ProviderService{providers: repo, log: logger.NewLogger()}
+
+			response, got, err := service.completedAsyncReport(context.Background(), applicationID, tt.reference)
+			if tt.wantID == uuid.Nil {
+				if !errors.Is(err, ErrNoPendingAsyncRequest) {
+					t.Fatalf("completedAsyncReport error = %v, want ErrNoPendingAsyncRequest", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("completedAsyncReport: %v", err)
+			}
+			if !response.Success || response.ReferenceID != tt.wantID.String() || got.Code != provider.Code {
+				t.Errorf("completedAsyncReport = %+v from %s, want the processed request %s", response, got.Code, tt.wantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package banking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/infrastructure/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// RateLimitError el proveedor no admite más llamadas por ahora
+// El trabajo que la recibe debe reprogramarse sin consumir un intento
+type RateLimitError struct {
+	ProviderCode string
+	Wait         time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("provider %s rate limited, retry after %s", e.ProviderCode, e.Wait)
+}
+
+// RetryAfter tiempo recomendado antes de reintentar
+func (e *RateLimitError) RetryAfter() time.Duration {
+	return e.Wait
+}
+
+// RateLimiter limitador de llamadas salientes por proveedor
+type RateLimiter interface {
+	// Allow consume un token si hay disponible; si no, devuelve cuánto esperar
+	Allow(ctx context.Context, provider *entity.BankingProvider) (bool, time.Duration, error)
+}
+
+// PostgresRateLimiter token bucket por proveedor almacenado en provider_rate_limits
+// Al vivir en Postgres, el límite es compartido entre la API y todos los workers
+type PostgresRateLimiter struct {
+	db *database.PostgresDB
+}
+
+// NewPostgresRateLimiter crea un limitador basado en Postgres
+func NewPostgresRateLimiter(db *database.PostgresDB) *PostgresRateLimiter {
+	return &PostgresRateLimiter{db: db}
+}
+
+// Allow intenta consumir un token del bucket del proveedor
+// La capacidad del bucket es RateLimitPerMin y se rellena de forma continua
+func (l *PostgresRateLimiter) Allow(ctx context.Context, provider *entity.BankingProvider) (bool, time.Duration, error) {
+	capacity := float64(provider.Config.RateLimitPerMin)
+	if capacity <= 0 {
+		return true, 0, nil
+	}
+	refillPerSec := capacity / 60
+
+	// Rellenar y consumir en una sola sentencia atómica
+	query := `
+		INSERT INTO provider_rate_limits (provider_id, tokens, updated_at)
+		VALUES ($1, $2::float8 - 1, NOW())
+		ON CONFLICT (provider_id) DO UPDATE SET
+			tokens = LEAST($2::float8, provider_rate_limits.tokens +
+				EXTRACT(EPOCH FROM (NOW() - provider_rate_limits.updated_at)) * $3::float8) - 1,
+			updated_at = NOW()
+		WHERE LEAST($2::float8, provider_rate_limits.tokens +
+			EXTRACT(EPOCH FROM (NOW() - provider_rate_limits.updated_at)) * $3::float8) >= 1
+		RETURNING tokens
+	`
+
+	var remaining float64
+	err := l.db.QueryRow(ctx, query, provider.ID, capacity, refillPerSec).Scan(&remaining)
+	if err == nil {
+		return true, 0, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return false, 0, fmt.Errorf("failed to acquire rate limit token: %w", err)
+	}
+
+	// Sin tokens: calcular cuánto falta para el siguiente
+	var tokens float64
+	waitQuery := `
+		SELECT LEAST($2::float8, tokens + EXTRACT(EPOCH FROM (NOW() - updated_at)) * $3::float8)
+		FROM provider_rate_limits
+		WHERE provider_id = $1
+	`
+	if err := l.db.QueryRow(ctx, waitQuery, provider.ID, capacity, refillPerSec).Scan(&tokens); err != nil {
+		return false, time.Minute, nil
+	}
+
+	wait := time.Duration(math.Ceil((1-tokens)/refillPerSec*1000)) * time.Millisecond
+	if wait < time.Second {
+		wait = time.Second
+	}
+	return false, wait, nil
+}
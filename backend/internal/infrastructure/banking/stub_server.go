@@ -0,0 +1,218 @@
+package banking
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+)
+
+// StubHandler handler HTTP que simula la API de un proveedor bancario
+// Las respuestas son deterministas a partir del número de documento, lo que
+// permite ejecutar el flujo completo en desarrollo sin proveedores reales.
+// Responde en su propio formato nativo (ver stubResponseMapping) y un documento
+// "HTTP<status>" (ej. HTTP503) simula un fallo del proveedor con ese status
+func StubHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		writeStubJSON(w, http.StatusOK, map[string]interface{}{
+			"access_token": "stub-access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	})
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		query := BankingQuery{
+			DocumentType:   r.URL.Query().Get("document_type"),
+			DocumentNumber: r.URL.Query().Get("document_number"),
+		}
+		if r.Body != nil && r.Method != http.MethodGet {
+			_ = json.NewDecoder(r.Body).Decode(&query)
+		}
+		if query.DocumentNumber == "" {
+			// Rutas tipo /v1/customers/{document_number}/...
+			parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+			if len(parts) >= 3 {
+				query.DocumentNumber = parts[2]
+			}
+		}
+		if query.DocumentNumber == "" {
+			writeStubError(w, http.StatusBadRequest, "INVALID_REQUEST", "document_number is required")
+			return
+		}
+		if status, ok := stubFailureStatus(query.DocumentNumber); ok {
+			writeStubError(w, status, "SIMULATED_FAILURE", "simulated provider failure")
+			return
+		}
+
+		// Consulta asíncrona: solo se acusa recibo, el reporte se entrega por webhook
+		if query.Reference != "" {
+			writeStubJSON(w, http.StatusAccepted, map[string]interface{}{
+				"reference_id": "STUB-" + query.Reference,
+				"status":       "PROCESSING",
+			})
+			return
+		}
+
+		writeStubJSON(w, http.StatusOK, stubResponse(query))
+	})
+
+	return mux
+}
+
+// NewStubServer inicia un servidor local con StubHandler
+func NewStubServer() *httptest.Server {
+	return httptest.NewServer(StubHandler())
+}
+
+// stubResponseMapping mapeo del formato nativo del stub; pasa por el mismo motor
+// de mapeo que los proveedores reales
+var stubResponseMapping = map[string]string{
+	FieldSuccess:         "$.status | enum:OK=true,*=false",
+	FieldCreditScore:     "$.report.score.value | scale:0:1000:300:850 | round",
+	FieldTotalDebt:       "$.report.debts[*].amount | sum",
+	FieldAvailableCredit: "$.report.available_credit",
+	FieldPaymentHistory:  "$.report.behaviour | enum:A=GOOD,B=REGULAR,*=BAD",
+	FieldBankAccounts:    "$.report.accounts | count",
+	FieldActiveLoans:     "$.report.loans | count",
+	FieldMonthsEmployed:  "$.report.employment.months",
+	FieldErrorCode:       "$.error.code",
+	FieldErrorMessage:    "$.error.message",
+}
+
+// stubFailureStatus status simulado para documentos "HTTP<status>"
+func stubFailureStatus(documentNumber string) (int, bool) {
+	code, ok := strings.CutPrefix(strings.ToUpper(documentNumber), "HTTP")
+	if !ok {
+		return 0, false
+	}
+	status, err := strconv.Atoi(code)
+	if err != nil || status < 400 || status > 599 {
+		return 0, false
+	}
+	return status, true
+}
+
+// stubResponse genera datos simulados basados en el hash del documento
+func stubResponse(query BankingQuery) map[string]interface{} {
+	seed := int64(0)
+	for _, c := range query.DocumentNumber {
+		seed += int64(c)
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	score := rng.Intn(1001)
+	behaviours := []string{"A", "B", "C"}
+	behaviour := behaviours[rng.Intn(3)]
+	monthsEmployed := rng.Intn(120)
+
+	debts := make([]interface{}, rng.Intn(4))
+	for i := range debts {
+		debts[i] = map[string]interface{}{"type": "CARD", "amount": float64(rng.Intn(15000))}
+	}
+	accounts := make([]interface{}, rng.Intn(5))
+	for i := range accounts {
+		accounts[i] = map[string]interface{}{"id": fmt.Sprintf("ACC-%d", i+1)}
+	}
+	loans := make([]interface{}, rng.Intn(3))
+	for i := range loans {
+		loans[i] = map[string]interface{}{"id": fmt.Sprintf("LOAN-%d", i+1), "balance": float64(rng.Intn(20000))}
+	}
+
+	return map[string]interface{}{
+		"status": "OK",
+		"subject": map[string]interface{}{
+			"document_type": query.DocumentType,
+		},
+		"report": map[string]interface{}{
+			"score":            map[string]interface{}{"value": score, "range": "0-1000"},
+			"debts":            debts,
+			"available_credit": float64(rng.Intn(30000)),
+			"behaviour":        behaviour,
+			"accounts":         accounts,
+			"loans":            loans,
+			"employment":       map[string]interface{}{"months": monthsEmployed, "status": "EMPLOYED"},
+			"generated_at":     time.Now().Format(time.RFC3339),
+		},
+	}
+}
+
+// writeStubError responde un error en el formato nativo del stub
+func writeStubError(w http.ResponseWriter, status int, code, message string) {
+	writeStubJSON(w, status, map[string]interface{}{
+		"status": "ERROR",
+		"error":  map[string]interface{}{"code": code, "message": message},
+	})
+}
+
+func writeStubJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// StubAdapter adaptador que dirige las llamadas al servidor stub local
+// El servidor se inicia la primera vez que se usa
+type StubAdapter struct {
+	once   sync.Once
+	server *httptest.Server
+	http   *HTTPAdapter
+}
+
+// NewStubAdapter crea un adaptador contra el servidor stub
+func NewStubAdapter() *StubAdapter {
+	return &StubAdapter{
+		http: NewHTTPAdapter(http.MethodPost, "/v1/credit-reports"),
+	}
+}
+
+// Fetch llama al servidor stub conservando auth y headers del proveedor
+// La respuesta nativa del stub se normaliza con stubResponseMapping
+func (a *StubAdapter) Fetch(ctx context.Context, provider *entity.BankingProvider, query BankingQuery) (*entity.BankingInfoResponse, error) {
+	return a.http.Fetch(ctx, a.stubProvider(provider), query)
+}
+
+// stubProvider copia del proveedor apuntando al servidor stub
+func (a *StubAdapter) stubProvider(provider *entity.BankingProvider) *entity.BankingProvider {
+	a.once.Do(func() {
+		a.server = NewStubServer()
+	})
+
+	stubProvider := *provider
+	stubProvider.Config.BaseURL = a.server.URL
+	stubProvider.Config.Endpoint = ""
+	stubProvider.Config.ResponseMapping = stubResponseMapping
+	stubProvider.Config.ResponseFormat = ResponseFormatJSON
+	stubProvider.Config.CurrencyRates = nil
+	stubProvider.Credentials = map[string]string{
+		"api_key":   "stub-api-key",
+		"username":  "stub",
+		"password":  "stub",
+		"client_id": "stub-client",
+	}
+
+	return &stubProvider
+}
+
+// Submit envía una consulta asíncrona al servidor stub
+// El stub no llama al callback: el reporte se simula enviando el webhook a mano
+func (a *StubAdapter) Submit(ctx context.Context, provider *entity.BankingProvider, query BankingQuery) (*entity.BankingInfoResponse, error) {
+	return a.http.Submit(ctx, a.stubProvider(provider), query)
+}
+
+// Close detiene el servidor stub si fue iniciado
+func (a *StubAdapter) Close() {
+	if a.server != nil {
+		a.server.Close()
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -16,6 +17,8 @@ type Config struct {
 	Queue    QueueConfig    `mapstructure:"queue"`
 	JWT      JWTConfig      `mapstructure:"jwt"`
 	Webhook  WebhookConfig  `mapstructure:"webhook"`
+	Banking  BankingConfig  `mapstructure:"banking"`
+	Encryption EncryptionConfig `mapstructure:"encryption"`
 	Log      LogConfig      `mapstructure:"log"`
 }
 
@@ -55,10 +58,16 @@ type CacheConfig struct {
 type QueueConfig struct {
 	Type           string        `mapstructure:"type"` // postgres, redis
 	WorkerCount    int           `mapstructure:"worker_count"`
-	PollInterval   time.Duration `mapstructure:"poll_interval"`
+	PollInterval   time.Duration `mapstructure:"poll_interval"`          // Sondeo sin LISTEN/NOTIFY o con el listener caído
+	ListenNotify   bool          `mapstructure:"listen_notify"`          // Despertar workers con LISTEN/NOTIFY
+	FallbackPoll   time.Duration `mapstructure:"fallback_poll_interval"` // Sondeo de respaldo con LISTEN activo
+	Pools          string        `mapstructure:"pools"`                  // Pools por tipo: "BANKING_INFO_FETCH:4,NOTIFICATION:2,*:3"
+	MaxInFlight    string        `mapstructure:"max_in_flight"`          // Máximo en PROCESSING por tipo: "BANKING_INFO_FETCH:8"
 	MaxRetries     int           `mapstructure:"max_retries"`
 	RetryDelay     time.Duration `mapstructure:"retry_delay"`
 	JobTimeout     time.Duration `mapstructure:"job_timeout"`
+	JobTimeouts    string        `mapstructure:"job_timeouts"`   // Timeout por tipo: "BANKING_INFO_FETCH:10m,NOTIFICATION:30s"
+	LeaseDuration  time.Duration `mapstructure:"lease_duration"` // Lease de un trabajo en ejecución; el heartbeat lo renueva
 }
 
 // JWTConfig configuración de JWT
@@ -78,6 +87,58 @@ type WebhookConfig struct {
 	CallbackURL    string        `mapstructure:"callback_url"`
 }
 
+// BankingConfig configuración de la integración con proveedores bancarios
+type BankingConfig struct {
+	UseStub                 bool          `mapstructure:"use_stub"` // Dirigir las llamadas al servidor stub local
+	CircuitFailureThreshold int           `mapstructure:"circuit_failure_threshold"`
+	CircuitOpenDuration     time.Duration `mapstructure:"circuit_open_duration"`
+	CircuitWindow           time.Duration `mapstructure:"circuit_window"`
+	CallbackURL             string        `mapstructure:"callback_url"` // URL pública del webhook para proveedores asíncronos
+}
+
+// EncryptionConfig claves maestras para el cifrado por sobre de datos sensibles
+// Para rotar, añadir una clave nueva y cambiar active_key_id; las anteriores
+// deben conservarse mientras existan registros cifrados con ellas
+type EncryptionConfig struct {
+	ActiveKeyID string            `mapstructure:"active_key_id"`
+	Keys        map[string]string `mapstructure:"keys"`        // key_id -> clave AES-256 en base64
+	UseDevKey   bool              `mapstructure:"use_dev_key"` // Solo desarrollo: clave fija y pública si no hay clave activa
+}
+
+// devEncryptionKey clave pública de desarrollo; solo se usa con encryption.use_dev_key
+// y nunca en modo release
+const devEncryptionKey = "ohzoPRwuOGEqB0ad4krbb9+tVxntCNwkAg+sEJmLC0c="
+
+// ParseEncryptionKeys lee "k1:<base64>,k2:<base64>": claves maestras por key_id
+func ParseEncryptionKeys(spec string) (map[string]string, error) {
+	keys := make(map[string]string)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, key, ok := strings.Cut(part, ":")
+		id = strings.TrimSpace(id)
+		key = strings.TrimSpace(key)
+		if !ok || id == "" || key == "" {
+			return nil, fmt.Errorf("invalid ENCRYPTION_KEYS entry %q: must be KEY_ID:BASE64_KEY", truncateKeyEntry(part))
+		}
+		if _, seen := keys[id]; seen {
+			return nil, fmt.Errorf("encryption key %q is repeated in ENCRYPTION_KEYS", id)
+		}
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+// truncateKeyEntry evita volcar una clave completa en los mensajes de error
+func truncateKeyEntry(entry string) string {
+	if len(entry) <= 8 {
+		return entry
+	}
+	return entry[:8] + "..."
+}
+
 // LogConfig configuración de logging
 type LogConfig struct {
 	Level      string `mapstructure:"level"` // debug, info, warn, error
@@ -109,6 +170,8 @@ func Load() (*Config, error) {
 	viper.BindEnv("cache.host", "REDIS_HOST")
 	viper.BindEnv("cache.port", "REDIS_PORT")
 	viper.BindEnv("cache.password", "REDIS_PASSWORD")
+	viper.BindEnv("encryption.active_key_id", "ENCRYPTION_ACTIVE_KEY_ID")
+	viper.BindEnv("encryption.use_dev_key", "ENCRYPTION_USE_DEV_KEY")
 	
 	// Intentar leer archivo de configuración
 	if err := viper.ReadInConfig(); err != nil {
@@ -123,6 +186,39 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
 	
+	// Claves retiradas desde variable de entorno (secreto en producción)
+	if spec := os.Getenv("ENCRYPTION_KEYS"); spec != "" {
+		keys, err := ParseEncryptionKeys(spec)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Encryption.Keys == nil {
+			cfg.Encryption.Keys = make(map[string]string)
+		}
+		for id, key := range keys {
+			cfg.Encryption.Keys[id] = key
+		}
+	}
+	
+	// Clave maestra activa desde variable de entorno (secreto en producción)
+	if key := os.Getenv("ENCRYPTION_KEY"); key != "" {
+		if cfg.Encryption.Keys == nil {
+			cfg.Encryption.Keys = make(map[string]string)
+		}
+		if existing := cfg.Encryption.Keys[cfg.Encryption.ActiveKeyID]; existing != "" && existing != key {
+			return nil, fmt.Errorf("encryption key %q has different values in ENCRYPTION_KEY and ENCRYPTION_KEYS", cfg.Encryption.ActiveKeyID)
+		}
+		cfg.Encryption.Keys[cfg.Encryption.ActiveKeyID] = key
+	}
+	
+	// Sin clave activa solo se arranca con la clave de desarrollo pedida explícitamente
+	if cfg.Encryption.UseDevKey && cfg.Encryption.Keys[cfg.Encryption.ActiveKeyID] == "" {
+		if cfg.Encryption.Keys == nil {
+			cfg.Encryption.Keys = make(map[string]string)
+		}
+		cfg.Encryption.Keys[cfg.Encryption.ActiveKeyID] = devEncryptionKey
+	}
+	
 	// Validar configuración requerida
 	if err := cfg.Validate(); err != nil {
 		return nil, err
@@ -160,9 +256,15 @@ func setDefaults() {
 	viper.SetDefault("queue.type", "postgres")
 	viper.SetDefault("queue.worker_count", 5)
 	viper.SetDefault("queue.poll_interval", 1*time.Second)
+	viper.SetDefault("queue.listen_notify", true)
+	viper.SetDefault("queue.fallback_poll_interval", 30*time.Second)
+	viper.SetDefault("queue.pools", "")
+	viper.SetDefault("queue.max_in_flight", "")
 	viper.SetDefault("queue.max_retries", 3)
 	viper.SetDefault("queue.retry_delay", 30*time.Second)
 	viper.SetDefault("queue.job_timeout", 5*time.Minute)
+	viper.SetDefault("queue.job_timeouts", "")
+	viper.SetDefault("queue.lease_duration", 1*time.Minute)
 	
 	// JWT
 	viper.SetDefault("jwt.secret", "change-me-in-production")
@@ -175,6 +277,13 @@ func setDefaults() {
 	viper.SetDefault("webhook.max_retries", 3)
 	viper.SetDefault("webhook.retry_delay", 5*time.Second)
 	
+	// Banking
+	viper.SetDefault("banking.use_stub", false)
+	viper.SetDefault("banking.circuit_failure_threshold", 5)
+	viper.SetDefault("banking.circuit_open_duration", 1*time.Minute)
+	viper.SetDefault("banking.circuit_window", 15*time.Minute)
+	viper.SetDefault("banking.callback_url", "")
+	
 	// Log
 	viper.SetDefault("log.level", "info")
 	viper.SetDefault("log.format", "json")
@@ -187,6 +296,20 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("database URL is required")
 	}
 	
+	if len(c.Encryption.Keys) == 0 || c.Encryption.Keys[c.Encryption.ActiveKeyID] == "" {
+		return fmt.Errorf("encryption active key %q is not configured (set ENCRYPTION_KEY, or ENCRYPTION_USE_DEV_KEY=true for local development)", c.Encryption.ActiveKeyID)
+	}
+	
+	if c.Encryption.UseDevKey && c.Server.Mode == "release" {
+		return fmt.Errorf("encryption.use_dev_key is not allowed in release mode")
+	}
+	
+	for id, key := range c.Encryption.Keys {
+		if key == devEncryptionKey && !c.Encryption.UseDevKey {
+			return fmt.Errorf("encryption key %q is the public development key", id)
+		}
+	}
+	
 	if c.Server.Port < 1 || c.Server.Port > 65535 {
 		return fmt.Errorf("invalid server port: %d", c.Server.Port)
 	}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEncryptionKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    map[string]string
+		wantErr string
+	}{
+		{"vacío", "", map[string]string{}, ""},
+		{"una clave", "k0:QUJD", map[string]string{"k0": "QUJD"}, ""},
+		{
+			name: "varias claves con espacios y padding",
+			spec: " k0:QUJD== , k00:REVG ,",
+			want: map[string]string{"k0": "QUJD==", "k00": "REVG"},
+		},
+		{"sin separador", "k0QUJD", nil, "must be KEY_ID:BASE64_KEY"},
+		{"sin key_id", ":QUJD", nil, "must be KEY_ID:BASE64_KEY"},
+		{"sin clave", "k0:", nil, "must be KEY_ID:BASE64_KEY"},
+		{"repetida", "k0:QUJD,k0:REVG", nil, `encryption key "k0" is repeated`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEncryptionKeys(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseEncryptionKeys error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseEncryptionKeys: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseEncryptionKeys = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("el error no incluye la clave", func(t *testing.T) {
+		secret := "ohzoPRwuOGEqB0ad4krbb9tVxntCNwkAg"
+		_, err := ParseEncryptionKeys(secret)
+		if err == nil || strings.Contains(err.Error(), secret) {
+			t.Errorf("ParseEncryptionKeys error = %v, must not contain the key", err)
+		}
+	})
+}
@@ -0,0 +1,189 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// dataKeySize tamaño de la clave de datos generada por registro (AES-256)
+const dataKeySize = 32
+
+// Envelope cifrado por sobre (envelope encryption)
+// Cada valor se cifra con una clave de datos aleatoria (DEK) y esa clave se
+// cifra a su vez con la clave maestra activa (KEK) definida en configuración.
+// Rotar la clave maestra solo requiere añadir una nueva y marcarla como activa:
+// los registros antiguos se siguen descifrando con la clave que indica su key_id
+type Envelope struct {
+	keys        map[string][]byte
+	activeKeyID string
+}
+
+// sealed formato serializado de un valor cifrado
+type sealed struct {
+	Version    int    `json:"v"`
+	KeyID      string `json:"kid"`
+	WrappedKey string `json:"dek"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ct"`
+}
+
+// NewEnvelope crea el cifrador a partir de claves maestras en base64
+func NewEnvelope(keys map[string]string, activeKeyID string) (*Envelope, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no encryption keys configured")
+	}
+
+	e := &Envelope{
+		keys:        make(map[string][]byte, len(keys)),
+		activeKeyID: activeKeyID,
+	}
+	for id, encoded := range keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %s is not valid base64: %w", id, err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("encryption key %s must be 32 bytes, got %d", id, len(key))
+		}
+		e.keys[id] = key
+	}
+
+	if _, ok := e.keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active encryption key %q is not configured", activeKeyID)
+	}
+
+	return e, nil
+}
+
+// ActiveKeyID identificador de la clave maestra usada para cifrar
+func (e *Envelope) ActiveKeyID() string {
+	return e.activeKeyID
+}
+
+// Encrypt cifra un valor con una clave de datos nueva
+func (e *Envelope) Encrypt(plaintext []byte) ([]byte, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	// Cifrar la clave de datos con la clave maestra
+	wrapped, err := seal(e.keys[e.activeKeyID], dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	nonce, ciphertext, err := sealWithNonce(dataKey, plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt data: %w", err)
+	}
+
+	return json.Marshal(sealed{
+		Version:    1,
+		KeyID:      e.activeKeyID,
+		WrappedKey: base64.StdEncoding.EncodeToString(wrapped),
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(ciphertext),
+	})
+}
+
+// Decrypt descifra un valor producido por Encrypt
+func (e *Envelope) Decrypt(data []byte) ([]byte, error) {
+	var s sealed
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid encrypted payload: %w", err)
+	}
+
+	masterKey, ok := e.keys[s.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("encryption key %q is not configured", s.KeyID)
+	}
+
+	wrapped, err := base64.StdEncoding.DecodeString(s.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wrapped key: %w", err)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(s.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(s.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+
+	dataKey, err := open(masterKey, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	// gcm.Open entra en pánico con un nonce de otro tamaño
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce size %d", len(nonce))
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// KeyIDOf obtiene el identificador de clave de un valor cifrado sin descifrarlo
+func KeyIDOf(data []byte) string {
+	var s sealed
+	if err := json.Unmarshal(data, &s); err != nil {
+		return ""
+	}
+	return s.KeyID
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal cifra con AES-GCM anteponiendo el nonce al resultado
+func seal(key, plaintext []byte) ([]byte, error) {
+	nonce, ciphertext, err := sealWithNonce(key, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return append(nonce, ciphertext...), nil
+}
+
+func sealWithNonce(key, plaintext []byte) ([]byte, []byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, nil, err
+	}
+	return nonce, gcm.Seal(nil, nonce, plaintext, nil), nil
+}
+
+// open descifra un valor producido por seal
+func open(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
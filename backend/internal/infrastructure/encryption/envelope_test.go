@@ -0,0 +1,196 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func newKey(t *testing.T) string {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("rand: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func mustEnvelope(t *testing.T, keys map[string]string, active string) *Envelope {
+	t.Helper()
+	e, err := NewEnvelope(keys, active)
+	if err != nil {
+		t.Fatalf("NewEnvelope: %v", err)
+	}
+	return e
+}
+
+func TestNewEnvelope(t *testing.T) {
+	valid := newKey(t)
+	short := base64.StdEncoding.EncodeToString(make([]byte, 16))
+
+	tests := []struct {
+		name    string
+		keys    map[string]string
+		active  string
+		wantErr string
+	}{
+		{"una clave", map[string]string{"k1": valid}, "k1", ""},
+		{"sin claves", nil, "k1", "no encryption keys configured"},
+		{"activa ausente", map[string]string{"k1": valid}, "k2", `active encryption key "k2" is not configured`},
+		{"base64 inválido", map[string]string{"k1": "not base64!"}, "k1", "is not valid base64"},
+		{"clave corta", map[string]string{"k1": short}, "k1", "must be 32 bytes, got 16"},
+		{"una clave retirada inválida", map[string]string{"k1": valid, "k0": short}, "k1", "encryption key k0 must be 32 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewEnvelope(tt.keys, tt.active)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("NewEnvelope: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewEnvelope error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	e := mustEnvelope(t, map[string]string{"k1": newKey(t)}, "k1")
+
+	payloads := [][]byte{
+		[]byte(`{"document_number":"12345678Z","credit_score":712}`),
+		{},
+		bytes.Repeat([]byte("x"), 1<<16),
+	}
+
+	for _, plaintext := range payloads {
+		sealedData, err := e.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("Encrypt: %v", err)
+		}
+		if len(plaintext) > 0 && bytes.Contains(sealedData, plaintext) {
+			t.Errorf("sealed payload contains the plaintext")
+		}
+		if got := KeyIDOf(sealedData); got != "k1" {
+			t.Errorf("KeyIDOf = %q, want k1", got)
+		}
+
+		decrypted, err := e.Decrypt(sealedData)
+		if err != nil {
+			t.Fatalf("Decrypt: %v", err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("Decrypt = %d bytes, want the original %d bytes", len(decrypted), len(plaintext))
+		}
+	}
+
+	// Cada valor usa una clave de datos y un nonce nuevos
+	a, _ := e.Encrypt([]byte("same"))
+	b, _ := e.Encrypt([]byte("same"))
+	if bytes.Equal(a, b) {
+		t.Errorf("two encryptions of the same value are identical")
+	}
+}
+
+func TestEnvelopeKeyRotation(t *testing.T) {
+	k1, k2 := newKey(t), newKey(t)
+
+	before := mustEnvelope(t, map[string]string{"k1": k1}, "k1")
+	oldRecord, err := before.Encrypt([]byte("old"))
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	// Rotación: k2 pasa a ser la activa y k1 se conserva como retirada
+	after := mustEnvelope(t, map[string]string{"k1": k1, "k2": k2}, "k2")
+	newRecord, err := after.Encrypt([]byte("new"))
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if after.ActiveKeyID() != "k2" || KeyIDOf(newRecord) != "k2" {
+		t.Errorf("new record key = %q, want k2", KeyIDOf(newRecord))
+	}
+
+	for _, tc := range []struct {
+		record []byte
+		want   string
+	}{{oldRecord, "old"}, {newRecord, "new"}} {
+		got, err := after.Decrypt(tc.record)
+		if err != nil {
+			t.Fatalf("Decrypt %s after rotation: %v", tc.want, err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("Decrypt = %q, want %q", got, tc.want)
+		}
+	}
+
+	// Sin la clave retirada los registros antiguos ya no se pueden leer
+	withoutOld := mustEnvelope(t, map[string]string{"k2": k2}, "k2")
+	if _, err := withoutOld.Decrypt(oldRecord); err == nil || !strings.Contains(err.Error(), `encryption key "k1" is not configured`) {
+		t.Errorf("Decrypt without the retired key error = %v", err)
+	}
+
+	// Misma key_id con otro material: el sobre no abre
+	replaced := mustEnvelope(t, map[string]string{"k1": k2}, "k1")
+	if _, err := replaced.Decrypt(oldRecord); err == nil || !strings.Contains(err.Error(), "failed to unwrap data key") {
+		t.Errorf("Decrypt with a replaced key error = %v", err)
+	}
+}
+
+func TestEnvelopeDecryptRejectsTampering(t *testing.T) {
+	e := mustEnvelope(t, map[string]string{"k1": newKey(t)}, "k1")
+	record, err := e.Encrypt([]byte(`{"credit_score":712}`))
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	tamper := func(change func(s *sealed)) []byte {
+		var s sealed
+		if err := json.Unmarshal(record, &s); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		change(&s)
+		out, _ := json.Marshal(s)
+		return out
+	}
+	flip := func(encoded string) string {
+		raw, _ := base64.StdEncoding.DecodeString(encoded)
+		raw[len(raw)-1] ^= 0xff
+		return base64.StdEncoding.EncodeToString(raw)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"no es JSON", []byte("plaintext"), "invalid encrypted payload"},
+		{"clave desconocida", tamper(func(s *sealed) { s.KeyID = "k9" }), `encryption key "k9" is not configured`},
+		{"clave de datos alterada", tamper(func(s *sealed) { s.WrappedKey = flip(s.WrappedKey) }), "failed to unwrap data key"},
+		{"clave de datos corta", tamper(func(s *sealed) { s.WrappedKey = "AAAA" }), "failed to unwrap data key"},
+		{"texto cifrado alterado", tamper(func(s *sealed) { s.Ciphertext = flip(s.Ciphertext) }), "failed to decrypt data"},
+		{"nonce alterado", tamper(func(s *sealed) { s.Nonce = flip(s.Nonce) }), "failed to decrypt data"},
+		{"nonce de otro tamaño", tamper(func(s *sealed) { s.Nonce = "AAAA" }), "invalid nonce size 3"},
+		{"base64 inválido", tamper(func(s *sealed) { s.Ciphertext = "***" }), "invalid ciphertext"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := e.Decrypt(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Decrypt error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if got := KeyIDOf([]byte("plaintext")); got != "" {
+		t.Errorf("KeyIDOf(plaintext) = %q, want empty", got)
+	}
+}
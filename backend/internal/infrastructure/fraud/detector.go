@@ -0,0 +1,173 @@
+package fraud
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+)
+
+// Valores por defecto de la configuración de fraude
+const (
+	DefaultLookbackDays             = 90
+	DefaultMaxDocumentsPerContact   = 2
+	DefaultMaxDebtToAnnualIncome    = 3.0
+	DefaultSubnetWindowHours        = 24
+	DefaultMaxApplicationsPerSubnet = 10
+)
+
+// signalWeights aporte de cada señal al score (se satura en 100)
+var signalWeights = map[string]float64{
+	entity.FraudSignalDocumentNameMismatch: 45,
+	entity.FraudSignalSharedEmail:          25,
+	entity.FraudSignalSharedPhone:          20,
+	entity.FraudSignalDisposableEmail:      20,
+	entity.FraudSignalIncomeMismatch:       25,
+	entity.FraudSignalSubnetVelocity:       20,
+}
+
+// disposableDomains dominios de email desechable conocidos
+var disposableDomains = map[string]bool{
+	"mailinator.com":    true,
+	"guerrillamail.com": true,
+	"10minutemail.com":  true,
+	"tempmail.com":      true,
+	"temp-mail.org":     true,
+	"yopmail.com":       true,
+	"trashmail.com":     true,
+	"sharklasers.com":   true,
+	"getnada.com":       true,
+	"dispostable.com":   true,
+	"throwawaymail.com": true,
+	"maildrop.cc":       true,
+	"fakeinbox.com":     true,
+	"mailnesia.com":     true,
+	"mintemail.com":     true,
+}
+
+// Facts datos de la solicitud y de las solicitudes relacionadas con los que se calculan las señales
+type Facts struct {
+	Email         string
+	MonthlyIncome float64
+
+	// Solicitudes previas dentro de LookbackDays
+	OtherNamesForDocument int // Nombres distintos con el mismo documento en el país
+	DocumentsForEmail     int // Otros documentos con el mismo email
+	DocumentsForPhone     int // Otros documentos con el mismo teléfono
+
+	// Otras solicitudes desde la misma subred dentro de SubnetWindowHours
+	SubnetApplications int
+
+	// Datos del buró (nil si no hay información bancaria)
+	TotalDebt      *float64
+	MonthsEmployed *int
+}
+
+// WithDefaults completa con los valores por defecto los campos en 0
+func WithDefaults(config *entity.FraudConfig) entity.FraudConfig {
+	var c entity.FraudConfig
+	if config != nil {
+		c = *config
+	}
+	if c.LookbackDays <= 0 {
+		c.LookbackDays = DefaultLookbackDays
+	}
+	if c.MaxDocumentsPerContact <= 0 {
+		c.MaxDocumentsPerContact = DefaultMaxDocumentsPerContact
+	}
+	if c.MaxDebtToAnnualIncome <= 0 {
+		c.MaxDebtToAnnualIncome = DefaultMaxDebtToAnnualIncome
+	}
+	if c.SubnetWindowHours <= 0 {
+		c.SubnetWindowHours = DefaultSubnetWindowHours
+	}
+	if c.MaxApplicationsPerSubnet <= 0 {
+		c.MaxApplicationsPerSubnet = DefaultMaxApplicationsPerSubnet
+	}
+	return c
+}
+
+// Evaluate calcula las señales y el score de fraude
+// config debe venir completada con WithDefaults
+func Evaluate(facts Facts, config entity.FraudConfig) *entity.FraudAssessment {
+	assessment := &entity.FraudAssessment{
+		Signals:   []entity.FraudSignal{},
+		CheckedAt: time.Now(),
+	}
+	add := func(code, detail string) {
+		weight := signalWeights[code]
+		assessment.Signals = append(assessment.Signals, entity.FraudSignal{Code: code, Weight: weight, Detail: detail})
+		assessment.Score += weight
+	}
+
+	if facts.OtherNamesForDocument > 0 {
+		add(entity.FraudSignalDocumentNameMismatch,
+			fmt.Sprintf("document used with %d other name(s)", facts.OtherNamesForDocument))
+	}
+	// Cuenta también el documento de esta solicitud
+	if facts.DocumentsForEmail+1 > config.MaxDocumentsPerContact {
+		add(entity.FraudSignalSharedEmail,
+			fmt.Sprintf("email shared by %d documents", facts.DocumentsForEmail+1))
+	}
+	if facts.DocumentsForPhone+1 > config.MaxDocumentsPerContact {
+		add(entity.FraudSignalSharedPhone,
+			fmt.Sprintf("phone shared by %d documents", facts.DocumentsForPhone+1))
+	}
+	if domain := emailDomain(facts.Email); domain != "" && isDisposable(domain, config.DisposableDomains) {
+		add(entity.FraudSignalDisposableEmail, "disposable email domain "+domain)
+	}
+	if detail := incomeMismatch(facts, config); detail != "" {
+		add(entity.FraudSignalIncomeMismatch, detail)
+	}
+	if facts.SubnetApplications+1 > config.MaxApplicationsPerSubnet {
+		add(entity.FraudSignalSubnetVelocity,
+			fmt.Sprintf("%d applications from the same subnet in %dh", facts.SubnetApplications+1, config.SubnetWindowHours))
+	}
+
+	assessment.Score = math.Min(assessment.Score, 100)
+	return assessment
+}
+
+// incomeMismatch compara el ingreso declarado con el buró: una deuda muy superior al
+// ingreso anual declarado, o ingresos declarados sin historial de empleo
+func incomeMismatch(facts Facts, config entity.FraudConfig) string {
+	annualIncome := facts.MonthlyIncome * 12
+	if facts.TotalDebt != nil && annualIncome > 0 && *facts.TotalDebt > annualIncome*config.MaxDebtToAnnualIncome {
+		return fmt.Sprintf("bureau debt %.2f is %.1fx the declared annual income", *facts.TotalDebt, *facts.TotalDebt/annualIncome)
+	}
+	if facts.MonthsEmployed != nil && *facts.MonthsEmployed == 0 && facts.MonthlyIncome > 0 {
+		return fmt.Sprintf("declared income %.2f with no employment history in bureau", facts.MonthlyIncome)
+	}
+	return ""
+}
+
+// emailDomain dominio del email en minúsculas
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(email[at+1:]))
+}
+
+// isDisposable indica si el dominio (o un dominio padre) es desechable
+func isDisposable(domain string, extra []string) bool {
+	for d := domain; d != ""; {
+		if disposableDomains[d] {
+			return true
+		}
+		for _, e := range extra {
+			if strings.EqualFold(strings.TrimSpace(e), d) {
+				return true
+			}
+		}
+		dot := strings.Index(d, ".")
+		if dot < 0 {
+			break
+		}
+		d = d[dot+1:]
+	}
+	return false
+}
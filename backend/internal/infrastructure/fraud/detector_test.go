@@ -0,0 +1,181 @@
+package fraud
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+)
+
+func floatPtr(v float64) *float64 { return &v }
+func intPtr(v int) *int           { return &v }
+
+func TestWithDefaults(t *testing.T) {
+	defaults := entity.FraudConfig{
+		LookbackDays:             DefaultLookbackDays,
+		MaxDocumentsPerContact:   DefaultMaxDocumentsPerContact,
+		MaxDebtToAnnualIncome:    DefaultMaxDebtToAnnualIncome,
+		SubnetWindowHours:        DefaultSubnetWindowHours,
+		MaxApplicationsPerSubnet: DefaultMaxApplicationsPerSubnet,
+	}
+
+	tests := []struct {
+		name   string
+		config *entity.FraudConfig
+		want   entity.FraudConfig
+	}{
+		{"sin configuración", nil, defaults},
+		{"valores en cero", &entity.FraudConfig{}, defaults},
+		{"valores negativos", &entity.FraudConfig{LookbackDays: -1, MaxDocumentsPerContact: -3, MaxDebtToAnnualIncome: -1}, defaults},
+		{
+			name: "valores propios",
+			config: &entity.FraudConfig{
+				ReviewThreshold: 60, LookbackDays: 30, MaxDocumentsPerContact: 1, MaxDebtToAnnualIncome: 5,
+				SubnetWindowHours: 1, MaxApplicationsPerSubnet: 3, DisposableDomains: []string{"spam.io"},
+			},
+			want: entity.FraudConfig{
+				ReviewThreshold: 60, LookbackDays: 30, MaxDocumentsPerContact: 1, MaxDebtToAnnualIncome: 5,
+				SubnetWindowHours: 1, MaxApplicationsPerSubnet: 3, DisposableDomains: []string{"spam.io"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WithDefaults(tt.config); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WithDefaults = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	config := WithDefaults(&entity.FraudConfig{DisposableDomains: []string{"spam.io"}})
+
+	tests := []struct {
+		name    string
+		facts   Facts
+		signals []string
+		score   float64
+	}{
+		{"sin señales", Facts{Email: "ana@gmail.com", MonthlyIncome: 2000}, nil, 0},
+		{"documento con otro nombre", Facts{OtherNamesForDocument: 1}, []string{entity.FraudSignalDocumentNameMismatch}, 45},
+
+		// El límite cuenta el documento de la solicitud
+		{"email en el límite", Facts{DocumentsForEmail: 1}, nil, 0},
+		{"email compartido", Facts{DocumentsForEmail: 2}, []string{entity.FraudSignalSharedEmail}, 25},
+		{"teléfono en el límite", Facts{DocumentsForPhone: 1}, nil, 0},
+		{"teléfono compartido", Facts{DocumentsForPhone: 2}, []string{entity.FraudSignalSharedPhone}, 20},
+		{"subred en el límite", Facts{SubnetApplications: 9}, nil, 0},
+		{"subred con muchas solicitudes", Facts{SubnetApplications: 10}, []string{entity.FraudSignalSubnetVelocity}, 20},
+
+		{"email desechable", Facts{Email: "x@Mailinator.com"}, []string{entity.FraudSignalDisposableEmail}, 20},
+		{"dominio desechable configurado", Facts{Email: "x@spam.io"}, []string{entity.FraudSignalDisposableEmail}, 20},
+		{"deuda superior al ingreso", Facts{MonthlyIncome: 1000, TotalDebt: floatPtr(40000)}, []string{entity.FraudSignalIncomeMismatch}, 25},
+		{
+			name: "todas las señales saturan en 100",
+			facts: Facts{
+				Email: "x@yopmail.com", MonthlyIncome: 1000, OtherNamesForDocument: 2,
+				DocumentsForEmail: 5, DocumentsForPhone: 5, SubnetApplications: 50, MonthsEmployed: intPtr(0),
+			},
+			signals: []string{
+				entity.FraudSignalDocumentNameMismatch, entity.FraudSignalSharedEmail, entity.FraudSignalSharedPhone,
+				entity.FraudSignalDisposableEmail, entity.FraudSignalIncomeMismatch, entity.FraudSignalSubnetVelocity,
+			},
+			score: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assessment := Evaluate(tt.facts, config)
+			var codes []string
+			var weights float64
+			for _, s := range assessment.Signals {
+				codes = append(codes, s.Code)
+				weights += s.Weight
+				if s.Weight != signalWeights[s.Code] || s.Detail == "" {
+					t.Errorf("signal %s = weight %v detail %q", s.Code, s.Weight, s.Detail)
+				}
+			}
+			if !reflect.DeepEqual(codes, tt.signals) {
+				t.Errorf("Evaluate signals = %v, want %v", codes, tt.signals)
+			}
+			if assessment.Score != tt.score {
+				t.Errorf("Evaluate score = %v, want %v (weights %v)", assessment.Score, tt.score, weights)
+			}
+			if assessment.Signals == nil || assessment.CheckedAt.IsZero() {
+				t.Errorf("Evaluate = %+v, want non-nil signals and CheckedAt", assessment)
+			}
+		})
+	}
+}
+
+func TestIncomeMismatch(t *testing.T) {
+	config := WithDefaults(nil)
+
+	tests := []struct {
+		name  string
+		facts Facts
+		want  string
+	}{
+		{"sin buró", Facts{MonthlyIncome: 1000}, ""},
+		{"deuda en el límite", Facts{MonthlyIncome: 1000, TotalDebt: floatPtr(36000)}, ""},
+		{"deuda superior al límite", Facts{MonthlyIncome: 1000, TotalDebt: floatPtr(36001)}, "bureau debt 36001.00 is 3.0x the declared annual income"},
+		{"sin ingreso declarado", Facts{TotalDebt: floatPtr(50000), MonthsEmployed: intPtr(0)}, ""},
+		{"ingreso sin historial de empleo", Facts{MonthlyIncome: 1500, MonthsEmployed: intPtr(0)}, "declared income 1500.00 with no employment history"},
+		{"ingreso con historial de empleo", Facts{MonthlyIncome: 1500, MonthsEmployed: intPtr(24)}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := incomeMismatch(tt.facts, config)
+			if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+				t.Errorf("incomeMismatch = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmailDomain(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"ana@gmail.com", "gmail.com"},
+		{"Ana@GMail.COM ", "gmail.com"},
+		{`"a@b"@example.org`, "example.org"},
+		{"sin-arroba", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := emailDomain(tt.email); got != tt.want {
+			t.Errorf("emailDomain(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestIsDisposable(t *testing.T) {
+	tests := []struct {
+		domain string
+		extra  []string
+		want   bool
+	}{
+		{"mailinator.com", nil, true},
+		{"eu.yopmail.com", nil, true}, // Subdominio de un dominio desechable
+		{"gmail.com", nil, false},
+		{"notmailinator.com", nil, false},
+		{"com", nil, false},
+		{"spam.io", []string{" Spam.IO "}, true},
+		{"mx.spam.io", []string{"spam.io"}, true},
+		{"spam.io", nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := isDisposable(tt.domain, tt.extra); got != tt.want {
+			t.Errorf("isDisposable(%q, %v) = %v, want %v", tt.domain, tt.extra, got, tt.want)
+		}
+	}
+}
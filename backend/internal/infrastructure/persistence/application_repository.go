@@ -3,6 +3,7 @@ package persistence
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/fintech-multipass/backend/internal/domain/entity"
 	"github.com/fintech-multipass/backend/internal/infrastructure/database"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 // ApplicationRepository implementación de repositorio de solicitudes de crédito
@@ -49,13 +51,24 @@ func (r *ApplicationRepository) Create(ctx context.Context, app *entity.CreditAp
 		}
 	}
 
+	var velocityJSON *string
+	if app.VelocityCheck != nil {
+		data, err := json.Marshal(app.VelocityCheck)
+		if err != nil {
+			return fmt.Errorf("failed to marshal velocity check: %w", err)
+		}
+		velocity := string(data)
+		velocityJSON = &velocity
+	}
+
 	query := `
 		INSERT INTO credit_applications (
 			id, country_id, full_name, document_type, document_number,
 			email, phone, requested_amount, monthly_income, status,
 			status_reason, requires_review, validation_results, risk_score,
-			application_date, created_at, updated_at, created_by_ip, user_agent
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13::jsonb, $14, $15, $16, $17, $18, $19)
+			application_date, created_at, updated_at, created_by_ip, user_agent,
+			velocity_check
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13::jsonb, $14, $15, $16, $17, $18, $19, $20::jsonb)
 	`
 
 	return r.db.Exec(ctx, query,
@@ -63,6 +76,7 @@ func (r *ApplicationRepository) Create(ctx context.Context, app *entity.CreditAp
 		app.Email, app.Phone, app.RequestedAmount, app.MonthlyIncome, app.Status,
 		app.StatusReason, app.RequiresReview, validationJSON, app.RiskScore,
 		app.ApplicationDate, app.CreatedAt, app.UpdatedAt, app.CreatedByIP, app.UserAgent,
+		velocityJSON,
 	)
 }
 
@@ -73,6 +87,10 @@ func (r *ApplicationRepository) GetByID(ctx context.Context, id uuid.UUID) (*ent
 			a.id, a.country_id, a.full_name, a.document_type, a.document_number,
 			a.email, a.phone, a.requested_amount, a.monthly_income, a.status,
 			a.status_reason, a.requires_review, a.validation_results, a.risk_score,
+			a.scoring_model_id, a.scoring_model_version,
+			a.document_valid, a.document_validation_message, a.document_validated_at,
+			a.velocity_check, a.fraud_score, a.fraud_signals, a.fraud_checked_at,
+			COALESCE(a.screening_status, ''), a.screened_at,
 			a.application_date, a.processed_at, a.created_at, a.updated_at,
 			c.code as country_code, c.name as country_name, c.currency
 		FROM credit_applications a
@@ -82,13 +100,18 @@ func (r *ApplicationRepository) GetByID(ctx context.Context, id uuid.UUID) (*ent
 
 	var app entity.CreditApplication
 	app.Country = &entity.Country{}
-	var validationJSON []byte
+	var validationJSON, velocityJSON, fraudJSON []byte
+	var documentMessage *string
 
 	row := r.db.QueryRow(ctx, query, id)
 	err := row.Scan(
 		&app.ID, &app.CountryID, &app.FullName, &app.DocumentType, &app.DocumentNumber,
 		&app.Email, &app.Phone, &app.RequestedAmount, &app.MonthlyIncome, &app.Status,
 		&app.StatusReason, &app.RequiresReview, &validationJSON, &app.RiskScore,
+		&app.ScoringModelID, &app.ScoringModelVersion,
+		&app.DocumentValid, &documentMessage, &app.DocumentValidatedAt,
+		&velocityJSON, &app.FraudScore, &fraudJSON, &app.FraudCheckedAt,
+		&app.ScreeningStatus, &app.ScreenedAt,
 		&app.ApplicationDate, &app.ProcessedAt, &app.CreatedAt, &app.UpdatedAt,
 		&app.Country.Code, &app.Country.Name, &app.Country.Currency,
 	)
@@ -99,6 +122,20 @@ func (r *ApplicationRepository) GetByID(ctx context.Context, id uuid.UUID) (*ent
 	if validationJSON != nil {
 		_ = json.Unmarshal(validationJSON, &app.ValidationResults)
 	}
+	if documentMessage != nil {
+		app.DocumentValidationMessage = *documentMessage
+	}
+	if velocityJSON != nil {
+		app.VelocityCheck = &entity.VelocityCheck{}
+		if err := json.Unmarshal(velocityJSON, app.VelocityCheck); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal velocity check: %w", err)
+		}
+	}
+	if fraudJSON != nil {
+		if err := json.Unmarshal(fraudJSON, &app.FraudSignals); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal fraud signals: %w", err)
+		}
+	}
 
 	// Obtener información bancaria si existe
 	bankingInfo, _ := r.GetBankingInfo(ctx, id)
@@ -450,7 +487,8 @@ func (r *ApplicationRepository) GetBankingInfo(ctx context.Context, applicationI
 	query := `
 		SELECT bi.id, bi.application_id, bi.provider_id, bi.credit_score, bi.total_debt,
 			bi.available_credit, bi.payment_history, bi.bank_accounts, bi.active_loans,
-			bi.months_employed, bi.retrieved_at, bi.expires_at, bp.name as provider_name
+			bi.months_employed, bi.retrieved_at, bi.expires_at, bp.name as provider_name,
+			bi.source, bi.reused_from_id, bi.reused_from_application_id
 		FROM banking_info bi
 		JOIN banking_providers bp ON bi.provider_id = bp.id
 		WHERE bi.application_id = $1
@@ -462,6 +500,7 @@ func (r *ApplicationRepository) GetBankingInfo(ctx context.Context, applicationI
 		&info.ID, &info.ApplicationID, &info.ProviderID, &info.CreditScore, &info.TotalDebt,
 		&info.AvailableCredit, &info.PaymentHistory, &info.BankAccounts, &info.ActiveLoans,
 		&info.MonthsEmployed, &info.RetrievedAt, &info.ExpiresAt, &info.ProviderName,
+		&info.Source, &info.ReusedFromID, &info.ReusedFromApplicationID,
 	)
 	if err != nil {
 		return nil, err
@@ -470,3 +509,190 @@ func (r *ApplicationRepository) GetBankingInfo(ctx context.Context, applicationI
 	return &info, nil
 }
 
+const riskDecisionColumns = `id, application_id, scoring_model_id, scoring_model_version, score,
+	decision, outcome, requires_review, factors, reason_codes, created_at`
+
+// GetLatestRiskDecision obtiene la última evaluación de riesgo de una solicitud (nil si no hay ninguna)
+func (r *ApplicationRepository) GetLatestRiskDecision(ctx context.Context, applicationID uuid.UUID) (*entity.RiskDecision, error) {
+	query := `SELECT ` + riskDecisionColumns + ` FROM risk_decisions
+		WHERE application_id = $1 ORDER BY created_at DESC LIMIT 1`
+
+	decision, err := scanRiskDecision(r.db.QueryRow(ctx, query, applicationID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get risk decision: %w", err)
+	}
+	return decision, nil
+}
+
+// ListRiskDecisions obtiene todas las evaluaciones de riesgo de una solicitud, la más reciente primero
+func (r *ApplicationRepository) ListRiskDecisions(ctx context.Context, applicationID uuid.UUID) ([]entity.RiskDecision, error) {
+	query := `SELECT ` + riskDecisionColumns + ` FROM risk_decisions
+		WHERE application_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, applicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query risk decisions: %w", err)
+	}
+	defer rows.Close()
+
+	var decisions []entity.RiskDecision
+	for rows.Next() {
+		decision, err := scanRiskDecision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan risk decision: %w", err)
+		}
+		decisions = append(decisions, *decision)
+	}
+
+	return decisions, rows.Err()
+}
+
+// ListForRuleSimulation obtiene las solicitudes del país creadas en [from, to) con su
+// información bancaria, el resultado del screening guardado y la última decisión automática
+// (transición SYSTEM a un estado decidido)
+// Las solicitudes que el sistema aún no decidió no se incluyen
+func (r *ApplicationRepository) ListForRuleSimulation(ctx context.Context, countryID uuid.UUID, from, to time.Time, limit int) ([]entity.RuleSimulationCase, error) {
+	query := `
+		SELECT a.id, a.country_id, a.full_name, a.document_type, a.document_number,
+		       a.requested_amount, a.monthly_income, a.status, a.document_valid, a.created_at,
+		       a.velocity_check, a.fraud_score, COALESCE(a.screening_status, ''),
+		       bi.id, bi.credit_score, bi.total_debt, bi.available_credit, bi.payment_history,
+		       bi.bank_accounts, bi.active_loans, bi.months_employed,
+		       d.to_status
+		FROM credit_applications a
+		JOIN LATERAL (
+			SELECT st.to_status FROM state_transitions st
+			WHERE st.application_id = a.id AND st.triggered_by = 'SYSTEM'
+			  AND st.to_status IN ('APPROVED', 'REJECTED', 'UNDER_REVIEW')
+			ORDER BY st.created_at DESC
+			LIMIT 1
+		) d ON true
+		LEFT JOIN banking_info bi ON bi.application_id = a.id
+		WHERE a.country_id = $1 AND a.created_at >= $2 AND a.created_at < $3
+		ORDER BY a.created_at
+		LIMIT $4
+	`
+
+	rows, err := r.db.Query(ctx, query, countryID, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query applications for simulation: %w", err)
+	}
+	defer rows.Close()
+
+	var cases []entity.RuleSimulationCase
+	for rows.Next() {
+		var c entity.RuleSimulationCase
+		app := &c.Application
+		var bankingID *uuid.UUID
+		var bankAccounts, activeLoans *int
+		var velocityJSON []byte
+		var info entity.BankingInfo
+
+		if err := rows.Scan(
+			&app.ID, &app.CountryID, &app.FullName, &app.DocumentType, &app.DocumentNumber,
+			&app.RequestedAmount, &app.MonthlyIncome, &app.Status, &app.DocumentValid, &app.CreatedAt,
+			&velocityJSON, &app.FraudScore, &app.ScreeningStatus,
+			&bankingID, &info.CreditScore, &info.TotalDebt, &info.AvailableCredit, &info.PaymentHistory,
+			&bankAccounts, &activeLoans, &info.MonthsEmployed,
+			&c.PreviousOutcome,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan application for simulation: %w", err)
+		}
+
+		if bankingID != nil {
+			info.ID = *bankingID
+			info.ApplicationID = app.ID
+			if bankAccounts != nil {
+				info.BankAccounts = *bankAccounts
+			}
+			if activeLoans != nil {
+				info.ActiveLoans = *activeLoans
+			}
+			app.BankingInfo = &info
+		}
+		if velocityJSON != nil {
+			app.VelocityCheck = &entity.VelocityCheck{}
+			if err := json.Unmarshal(velocityJSON, app.VelocityCheck); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal velocity check: %w", err)
+			}
+		}
+		cases = append(cases, c)
+	}
+
+	return cases, rows.Err()
+}
+
+func scanRiskDecision(row pgx.Row) (*entity.RiskDecision, error) {
+	var d entity.RiskDecision
+	var factors, reasons []byte
+
+	if err := row.Scan(
+		&d.ID, &d.ApplicationID, &d.ScoringModelID, &d.ScoringModelVersion, &d.Score,
+		&d.Decision, &d.Outcome, &d.RequiresReview, &factors, &reasons, &d.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(factors, &d.Factors); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal risk factors: %w", err)
+	}
+	if err := json.Unmarshal(reasons, &d.ReasonCodes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reason codes: %w", err)
+	}
+
+	return &d, nil
+}
+
+// CountOpenApplications cuenta las solicitudes abiertas del país (no rechazadas, canceladas,
+// expiradas ni desembolsadas) con el mismo documento, email, teléfono o IP desde since
+func (r *ApplicationRepository) CountOpenApplications(ctx context.Context, countryID uuid.UUID, since *time.Time, keys entity.VelocityKeys) (*entity.VelocityCounts, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE document_number = $3),
+			COUNT(*) FILTER (WHERE $4 <> '' AND lower(email) = $4),
+			COUNT(*) FILTER (WHERE $5 <> '' AND regexp_replace(phone, '[^0-9]', '', 'g') = $5),
+			COUNT(*) FILTER (WHERE created_by_ip = NULLIF($6, '')::inet)
+		FROM credit_applications
+		WHERE country_id = $1
+		  AND ($2::timestamptz IS NULL OR created_at >= $2)
+		  AND status NOT IN ('REJECTED', 'CANCELLED', 'EXPIRED', 'DISBURSED')
+		  AND (document_number = $3
+		       OR ($4 <> '' AND lower(email) = $4)
+		       OR ($5 <> '' AND regexp_replace(phone, '[^0-9]', '', 'g') = $5)
+		       OR created_by_ip = NULLIF($6, '')::inet)
+	`
+
+	var counts entity.VelocityCounts
+	err := r.db.QueryRow(ctx, query,
+		countryID, since, keys.DocumentNumber, strings.ToLower(keys.Email), keys.Phone, keys.IP,
+	).Scan(&counts.Document, &counts.Email, &counts.Phone, &counts.IP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open applications: %w", err)
+	}
+	return &counts, nil
+}
+
+// RecordVelocityRejection registra en audit_logs una solicitud rechazada al crearse por velocidad
+// La solicitud no llega a existir: la entrada se asocia al país
+func (r *ApplicationRepository) RecordVelocityRejection(ctx context.Context, countryID uuid.UUID, check *entity.VelocityCheck, documentType, ip, userAgent string) error {
+	values, err := json.Marshal(map[string]interface{}{
+		"document_type":  documentType,
+		"velocity_check": check,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal velocity rejection: %w", err)
+	}
+
+	var ipAddress *string
+	if ip != "" {
+		ipAddress = &ip
+	}
+	query := `
+		INSERT INTO audit_logs (entity_type, entity_id, action, actor_type, new_values, ip_address, user_agent)
+		VALUES ('country', $1, 'VELOCITY_REJECTED', 'SYSTEM', $2::jsonb, $3::inet, $4)
+	`
+	return r.db.Exec(ctx, query, countryID, string(values), ipAddress, userAgent)
+}
@@ -0,0 +1,364 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/infrastructure/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// BankingProviderRepository implementación de repositorio de proveedores bancarios
+type BankingProviderRepository struct {
+	db *database.PostgresDB
+}
+
+// NewBankingProviderRepository crea una nueva instancia del repositorio
+func NewBankingProviderRepository(db *database.PostgresDB) *BankingProviderRepository {
+	return &BankingProviderRepository{db: db}
+}
+
+const bankingProviderColumns = `id, country_id, code, name, type, is_active, priority, config, credentials, created_at, updated_at`
+
+// GetByID obtiene un proveedor por ID
+func (r *BankingProviderRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.BankingProvider, error) {
+	query := `SELECT ` + bankingProviderColumns + ` FROM banking_providers WHERE id = $1`
+
+	provider, err := scanBankingProvider(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, fmt.Errorf("banking provider not found: %w", err)
+	}
+	return provider, nil
+}
+
+// GetByCountryID obtiene los proveedores de un país ordenados por prioridad
+func (r *BankingProviderRepository) GetByCountryID(ctx context.Context, countryID uuid.UUID) ([]entity.BankingProvider, error) {
+	query := `
+		SELECT ` + bankingProviderColumns + `
+		FROM banking_providers
+		WHERE country_id = $1
+		ORDER BY priority DESC, created_at
+	`
+	return r.queryProviders(ctx, query, countryID)
+}
+
+// GetActiveByCountryID obtiene la cadena de failover: proveedores activos por prioridad
+func (r *BankingProviderRepository) GetActiveByCountryID(ctx context.Context, countryID uuid.UUID) ([]entity.BankingProvider, error) {
+	query := `
+		SELECT ` + bankingProviderColumns + `
+		FROM banking_providers
+		WHERE country_id = $1 AND is_active = true
+		ORDER BY priority DESC, created_at
+	`
+	return r.queryProviders(ctx, query, countryID)
+}
+
+// GetActiveByCountry obtiene el proveedor activo de mayor prioridad de un país
+func (r *BankingProviderRepository) GetActiveByCountry(ctx context.Context, countryID uuid.UUID) (*entity.BankingProvider, error) {
+	query := `
+		SELECT ` + bankingProviderColumns + `
+		FROM banking_providers
+		WHERE country_id = $1 AND is_active = true
+		ORDER BY priority DESC, created_at
+		LIMIT 1
+	`
+
+	provider, err := scanBankingProvider(r.db.QueryRow(ctx, query, countryID))
+	if err != nil {
+		return nil, fmt.Errorf("no active provider found for country: %w", err)
+	}
+	return provider, nil
+}
+
+// Create crea un nuevo proveedor
+func (r *BankingProviderRepository) Create(ctx context.Context, provider *entity.BankingProvider) error {
+	if provider.ID == uuid.Nil {
+		provider.ID = uuid.New()
+	}
+
+	configJSON, credentialsJSON, err := marshalProviderJSON(provider)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO banking_providers (id, country_id, code, name, type, is_active, priority, config, credentials)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8::jsonb, $9::jsonb)
+	`
+
+	return r.db.Exec(ctx, query,
+		provider.ID, provider.CountryID, provider.Code, provider.Name, provider.Type,
+		provider.IsActive, provider.Priority, configJSON, credentialsJSON,
+	)
+}
+
+// Update actualiza un proveedor
+func (r *BankingProviderRepository) Update(ctx context.Context, provider *entity.BankingProvider) error {
+	configJSON, credentialsJSON, err := marshalProviderJSON(provider)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE banking_providers
+		SET code = $2, name = $3, type = $4, is_active = $5, priority = $6,
+			config = $7::jsonb, credentials = $8::jsonb, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	return r.db.Exec(ctx, query,
+		provider.ID, provider.Code, provider.Name, provider.Type,
+		provider.IsActive, provider.Priority, configJSON, credentialsJSON,
+	)
+}
+
+// SaveRequest registra una llamada a un proveedor
+// Se invoca al iniciar la llamada (PENDING) y al terminarla, actualizando el mismo registro.
+// Un registro terminado no se modifica: el reporte asíncrono puede llegar por webhook
+// antes de que Submit guarde el acuse del proveedor
+func (r *BankingProviderRepository) SaveRequest(ctx context.Context, request *entity.BankingRequest) error {
+	if request.ID == uuid.Nil {
+		request.ID = uuid.New()
+	}
+	if request.CreatedAt.IsZero() {
+		request.CreatedAt = time.Now()
+	}
+
+	var applicationID *uuid.UUID
+	if request.ApplicationID != uuid.Nil {
+		applicationID = &request.ApplicationID
+	}
+
+	query := `
+		INSERT INTO banking_requests (
+			id, application_id, provider_id, request_type, status,
+			request_data, response_data, error_message, duration_ms, created_at, completed_at,
+			encryption_key_id, external_reference, failure_kind
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			failure_kind = EXCLUDED.failure_kind,
+			response_data = EXCLUDED.response_data,
+			encryption_key_id = EXCLUDED.encryption_key_id,
+			external_reference = COALESCE(EXCLUDED.external_reference, banking_requests.external_reference),
+			error_message = EXCLUDED.error_message,
+			duration_ms = EXCLUDED.duration_ms,
+			completed_at = EXCLUDED.completed_at
+		WHERE banking_requests.status = 'PENDING'
+	`
+
+	return r.db.Exec(ctx, query,
+		request.ID, applicationID, request.ProviderID, request.RequestType, request.Status,
+		request.RequestData, request.ResponseData, nullIfEmpty(request.ErrorMessage),
+		request.Duration, request.CreatedAt, request.CompletedAt,
+		nullIfEmpty(request.EncryptionKeyID), nullIfEmpty(request.ExternalReference),
+		nullIfEmpty(request.FailureKind),
+	)
+}
+
+// GetRequestsByApplication obtiene las llamadas a proveedores de una solicitud
+// RequestData y ResponseData se devuelven cifrados
+func (r *BankingProviderRepository) GetRequestsByApplication(ctx context.Context, applicationID uuid.UUID) ([]entity.BankingRequest, error) {
+	query := `
+		SELECT br.id, br.application_id, br.provider_id, bp.code, br.request_type, br.status,
+			br.request_data, br.response_data, COALESCE(br.error_message, ''), COALESCE(br.failure_kind, ''),
+			COALESCE(br.duration_ms, 0), COALESCE(br.encryption_key_id, ''), COALESCE(br.external_reference, ''),
+			br.created_at, br.completed_at
+		FROM banking_requests br
+		JOIN banking_providers bp ON bp.id = br.provider_id
+		WHERE br.application_id = $1
+		ORDER BY br.created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, applicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query banking requests: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []entity.BankingRequest
+	for rows.Next() {
+		var req entity.BankingRequest
+		if err := rows.Scan(
+			&req.ID, &req.ApplicationID, &req.ProviderID, &req.ProviderCode, &req.RequestType, &req.Status,
+			&req.RequestData, &req.ResponseData, &req.ErrorMessage, &req.FailureKind,
+			&req.Duration, &req.EncryptionKeyID, &req.ExternalReference,
+			&req.CreatedAt, &req.CompletedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan banking request: %w", err)
+		}
+		requests = append(requests, req)
+	}
+
+	return requests, rows.Err()
+}
+
+// GetPendingAsyncRequests obtiene las llamadas asíncronas de una solicitud que
+// siguen esperando el reporte del proveedor, la más reciente primero
+func (r *BankingProviderRepository) GetPendingAsyncRequests(ctx context.Context, applicationID uuid.UUID, requestType string) ([]entity.BankingRequest, error) {
+	query := `
+		SELECT br.id, br.application_id, br.provider_id, bp.code, br.request_type, br.status,
+			br.request_data, COALESCE(br.encryption_key_id, ''), COALESCE(br.external_reference, ''),
+			br.created_at
+		FROM banking_requests br
+		JOIN banking_providers bp ON bp.id = br.provider_id
+		WHERE br.application_id = $1 AND br.request_type = $2 AND br.status = 'PENDING'
+		ORDER BY br.created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, applicationID, requestType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending banking requests: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []entity.BankingRequest
+	for rows.Next() {
+		var req entity.BankingRequest
+		if err := rows.Scan(
+			&req.ID, &req.ApplicationID, &req.ProviderID, &req.ProviderCode, &req.RequestType, &req.Status,
+			&req.RequestData, &req.EncryptionKeyID, &req.ExternalReference,
+			&req.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan banking request: %w", err)
+		}
+		requests = append(requests, req)
+	}
+
+	return requests, rows.Err()
+}
+
+// GetRecentRequests obtiene las llamadas más recientes a un proveedor desde una fecha
+func (r *BankingProviderRepository) GetRecentRequests(ctx context.Context, providerID uuid.UUID, since time.Time, limit int) ([]entity.BankingRequest, error) {
+	query := `
+		SELECT id, application_id, provider_id, request_type, status,
+			COALESCE(error_message, ''), COALESCE(failure_kind, ''), COALESCE(duration_ms, 0), created_at, completed_at
+		FROM banking_requests
+		WHERE provider_id = $1 AND created_at >= $2
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(ctx, query, providerID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query banking requests: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []entity.BankingRequest
+	for rows.Next() {
+		var req entity.BankingRequest
+		var applicationID *uuid.UUID
+		if err := rows.Scan(
+			&req.ID, &applicationID, &req.ProviderID, &req.RequestType, &req.Status,
+			&req.ErrorMessage, &req.FailureKind, &req.Duration, &req.CreatedAt, &req.CompletedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan banking request: %w", err)
+		}
+		if applicationID != nil {
+			req.ApplicationID = *applicationID
+		}
+		requests = append(requests, req)
+	}
+
+	return requests, rows.Err()
+}
+
+// ClaimCircuitProbe reserva la llamada de prueba de un circuito HALF_OPEN
+// La inserción condicional es atómica: entre llamadas concurrentes solo una obtiene la fila
+func (r *BankingProviderRepository) ClaimCircuitProbe(ctx context.Context, providerID uuid.UUID, ttl time.Duration) (bool, error) {
+	query := `
+		INSERT INTO banking_circuit_probes (provider_id, claimed_at)
+		VALUES ($1, NOW())
+		ON CONFLICT (provider_id) DO UPDATE SET claimed_at = NOW()
+		WHERE banking_circuit_probes.claimed_at < NOW() - INTERVAL '1 millisecond' * $2
+		RETURNING provider_id
+	`
+
+	var claimed uuid.UUID
+	err := r.db.QueryRow(ctx, query, providerID, ttl.Milliseconds()).Scan(&claimed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim circuit probe: %w", err)
+	}
+	return true, nil
+}
+
+func (r *BankingProviderRepository) queryProviders(ctx context.Context, query string, args ...interface{}) ([]entity.BankingProvider, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query banking providers: %w", err)
+	}
+	defer rows.Close()
+
+	var providers []entity.BankingProvider
+	for rows.Next() {
+		provider, err := scanBankingProvider(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan banking provider: %w", err)
+		}
+		providers = append(providers, *provider)
+	}
+
+	return providers, rows.Err()
+}
+
+func scanBankingProvider(row pgx.Row) (*entity.BankingProvider, error) {
+	var provider entity.BankingProvider
+	var configJSON, credentialsJSON []byte
+
+	err := row.Scan(
+		&provider.ID, &provider.CountryID, &provider.Code, &provider.Name,
+		&provider.Type, &provider.IsActive, &provider.Priority, &configJSON,
+		&credentialsJSON, &provider.CreatedAt, &provider.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(configJSON) > 0 {
+		if err := json.Unmarshal(configJSON, &provider.Config); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+	}
+	if len(credentialsJSON) > 0 {
+		if err := json.Unmarshal(credentialsJSON, &provider.Credentials); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal credentials: %w", err)
+		}
+	}
+
+	return &provider, nil
+}
+
+func marshalProviderJSON(provider *entity.BankingProvider) (string, *string, error) {
+	configJSON, err := json.Marshal(provider.Config)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var credentials *string
+	if provider.Credentials != nil {
+		credentialsJSON, err := json.Marshal(provider.Credentials)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to marshal credentials: %w", err)
+		}
+		c := string(credentialsJSON)
+		credentials = &c
+	}
+
+	return string(configJSON), credentials, nil
+}
+
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
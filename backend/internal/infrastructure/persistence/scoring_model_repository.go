@@ -0,0 +1,282 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/infrastructure/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ScoringModelRepository implementación de repositorio de modelos de scoring
+type ScoringModelRepository struct {
+	db *database.PostgresDB
+}
+
+// NewScoringModelRepository crea una nueva instancia del repositorio
+func NewScoringModelRepository(db *database.PostgresDB) *ScoringModelRepository {
+	return &ScoringModelRepository{db: db}
+}
+
+const scoringModelColumns = `id, country_id, version, name, definition, is_active, is_challenger, traffic_percent,
+	COALESCE(notes, ''), created_by, created_at, activated_at`
+
+// GetActiveByCountry obtiene la versión activa del país (nil si no hay ninguna)
+func (r *ScoringModelRepository) GetActiveByCountry(ctx context.Context, countryID uuid.UUID) (*entity.ScoringModel, error) {
+	query := `SELECT ` + scoringModelColumns + ` FROM scoring_models WHERE country_id = $1 AND is_active = true`
+
+	model, err := scanScoringModel(r.db.QueryRow(ctx, query, countryID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active scoring model: %w", err)
+	}
+	return model, nil
+}
+
+// GetByVersion obtiene una versión concreta del modelo de un país
+func (r *ScoringModelRepository) GetByVersion(ctx context.Context, countryID uuid.UUID, version int) (*entity.ScoringModel, error) {
+	query := `SELECT ` + scoringModelColumns + ` FROM scoring_models WHERE country_id = $1 AND version = $2`
+
+	model, err := scanScoringModel(r.db.QueryRow(ctx, query, countryID, version))
+	if err != nil {
+		return nil, fmt.Errorf("scoring model not found: %w", err)
+	}
+	return model, nil
+}
+
+// ListByCountry obtiene todas las versiones de un país, la más reciente primero
+func (r *ScoringModelRepository) ListByCountry(ctx context.Context, countryID uuid.UUID) ([]entity.ScoringModel, error) {
+	query := `SELECT ` + scoringModelColumns + ` FROM scoring_models WHERE country_id = $1 ORDER BY version DESC`
+
+	rows, err := r.db.Query(ctx, query, countryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scoring models: %w", err)
+	}
+	defer rows.Close()
+
+	var models []entity.ScoringModel
+	for rows.Next() {
+		model, err := scanScoringModel(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scoring model: %w", err)
+		}
+		models = append(models, *model)
+	}
+
+	return models, rows.Err()
+}
+
+// Create guarda una nueva versión (inactiva) asignando el siguiente número de versión
+func (r *ScoringModelRepository) Create(ctx context.Context, model *entity.ScoringModel) error {
+	if model.ID == uuid.Nil {
+		model.ID = uuid.New()
+	}
+
+	definition, err := json.Marshal(model.Definition)
+	if err != nil {
+		return fmt.Errorf("failed to marshal scoring definition: %w", err)
+	}
+
+	query := `
+		INSERT INTO scoring_models (id, country_id, version, name, definition, is_active, notes, created_by)
+		SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4::jsonb, false, $5, $6
+		FROM scoring_models WHERE country_id = $2
+		RETURNING version, created_at
+	`
+
+	model.IsActive = false
+	return r.db.QueryRow(ctx, query,
+		model.ID, model.CountryID, model.Name, string(definition), nullIfEmpty(model.Notes), model.CreatedBy,
+	).Scan(&model.Version, &model.CreatedAt)
+}
+
+// Activate activa una versión y desactiva la anterior en una transacción
+// Si la versión era el challenger del país deja de serlo (promoción)
+func (r *ScoringModelRepository) Activate(ctx context.Context, countryID uuid.UUID, version int) error {
+	return r.db.WithTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx,
+			`UPDATE scoring_models SET is_active = false WHERE country_id = $1 AND is_active = true`,
+			countryID,
+		); err != nil {
+			return fmt.Errorf("failed to deactivate scoring model: %w", err)
+		}
+
+		tag, err := tx.Exec(ctx,
+			`UPDATE scoring_models SET is_active = true, is_challenger = false, traffic_percent = 0, activated_at = NOW()
+			 WHERE country_id = $1 AND version = $2`,
+			countryID, version,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to activate scoring model: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("scoring model not found: version %d", version)
+		}
+		return nil
+	})
+}
+
+// GetChallengerByCountry obtiene la versión challenger del país (nil si no hay ninguna)
+func (r *ScoringModelRepository) GetChallengerByCountry(ctx context.Context, countryID uuid.UUID) (*entity.ScoringModel, error) {
+	query := `SELECT ` + scoringModelColumns + ` FROM scoring_models WHERE country_id = $1 AND is_challenger = true`
+
+	model, err := scanScoringModel(r.db.QueryRow(ctx, query, countryID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get challenger scoring model: %w", err)
+	}
+	return model, nil
+}
+
+// SetChallenger marca una versión como challenger del país, sustituyendo a la anterior
+func (r *ScoringModelRepository) SetChallenger(ctx context.Context, countryID uuid.UUID, version int, trafficPercent int) error {
+	return r.db.WithTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx,
+			`UPDATE scoring_models SET is_challenger = false, traffic_percent = 0 WHERE country_id = $1 AND is_challenger = true`,
+			countryID,
+		); err != nil {
+			return fmt.Errorf("failed to clear challenger: %w", err)
+		}
+
+		tag, err := tx.Exec(ctx,
+			`UPDATE scoring_models SET is_challenger = true, traffic_percent = $3
+			 WHERE country_id = $1 AND version = $2 AND is_active = false`,
+			countryID, version, trafficPercent,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to set challenger: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("scoring model not found or active: version %d", version)
+		}
+		return nil
+	})
+}
+
+// ClearChallenger retira el challenger del país
+func (r *ScoringModelRepository) ClearChallenger(ctx context.Context, countryID uuid.UUID) error {
+	return r.db.Exec(ctx,
+		`UPDATE scoring_models SET is_challenger = false, traffic_percent = 0 WHERE country_id = $1 AND is_challenger = true`,
+		countryID,
+	)
+}
+
+// GetChallengerStats compara champion y challenger sobre las evaluaciones en sombra
+func (r *ScoringModelRepository) GetChallengerStats(ctx context.Context, countryID, challengerModelID uuid.UUID, since *time.Time) (*entity.ChallengerStats, error) {
+	stats := &entity.ChallengerStats{
+		CountryID:         countryID,
+		ChallengerModelID: challengerModelID,
+		Since:             since,
+		ChampionVersions:  []int{},
+	}
+	stats.Champion.Distribution = make(map[string]int)
+	stats.Challenger.Distribution = make(map[string]int)
+
+	summaryQuery := `
+		SELECT COUNT(*),
+			COUNT(*) FILTER (WHERE decided_by = 'CHALLENGER'),
+			COUNT(*) FILTER (WHERE champion_decision = challenger_decision),
+			COALESCE(MAX(challenger_version), 0),
+			COALESCE(ARRAY_AGG(DISTINCT champion_version), '{}'),
+			COUNT(*) FILTER (WHERE champion_decision = 'APPROVE'),
+			COUNT(*) FILTER (WHERE champion_decision = 'REVIEW'),
+			COALESCE(AVG(champion_score), 0)::float8,
+			COALESCE(STDDEV_POP(champion_score), 0)::float8,
+			COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY champion_score), 0)::float8,
+			COALESCE(PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY champion_score), 0)::float8,
+			COUNT(*) FILTER (WHERE challenger_decision = 'APPROVE'),
+			COUNT(*) FILTER (WHERE challenger_decision = 'REVIEW'),
+			COALESCE(AVG(challenger_score), 0)::float8,
+			COALESCE(STDDEV_POP(challenger_score), 0)::float8,
+			COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY challenger_score), 0)::float8,
+			COALESCE(PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY challenger_score), 0)::float8
+		FROM scoring_shadow_results
+		WHERE country_id = $1 AND challenger_model_id = $2
+		  AND ($3::timestamptz IS NULL OR created_at >= $3)
+	`
+
+	var agreements, championApproved, championReview, challengerApproved, challengerReview int
+	if err := r.db.QueryRow(ctx, summaryQuery, countryID, challengerModelID, since).Scan(
+		&stats.Evaluations, &stats.DecidedByChallenger, &agreements,
+		&stats.ChallengerVersion, &stats.ChampionVersions,
+		&championApproved, &championReview,
+		&stats.Champion.AvgScore, &stats.Champion.StdDevScore, &stats.Champion.P50Score, &stats.Champion.P90Score,
+		&challengerApproved, &challengerReview,
+		&stats.Challenger.AvgScore, &stats.Challenger.StdDevScore, &stats.Challenger.P50Score, &stats.Challenger.P90Score,
+	); err != nil {
+		return nil, fmt.Errorf("failed to get challenger stats: %w", err)
+	}
+
+	if stats.Evaluations == 0 {
+		return stats, nil
+	}
+	total := float64(stats.Evaluations)
+	stats.AgreementRate = float64(agreements) / total
+	stats.Champion.ApprovalRate = float64(championApproved) / total
+	stats.Champion.ReviewRate = float64(championReview) / total
+	stats.Champion.RejectRate = float64(stats.Evaluations-championApproved-championReview) / total
+	stats.Challenger.ApprovalRate = float64(challengerApproved) / total
+	stats.Challenger.ReviewRate = float64(challengerReview) / total
+	stats.Challenger.RejectRate = float64(stats.Evaluations-challengerApproved-challengerReview) / total
+
+	// Distribución de scores en tramos de 10 puntos
+	distributionQuery := `
+		SELECT 'champion', LEAST(GREATEST(FLOOR(champion_score / 10), 0), 9)::int AS bucket, COUNT(*)
+		FROM scoring_shadow_results
+		WHERE country_id = $1 AND challenger_model_id = $2 AND ($3::timestamptz IS NULL OR created_at >= $3)
+		GROUP BY bucket
+		UNION ALL
+		SELECT 'challenger', LEAST(GREATEST(FLOOR(challenger_score / 10), 0), 9)::int AS bucket, COUNT(*)
+		FROM scoring_shadow_results
+		WHERE country_id = $1 AND challenger_model_id = $2 AND ($3::timestamptz IS NULL OR created_at >= $3)
+		GROUP BY bucket
+	`
+	rows, err := r.db.Query(ctx, distributionQuery, countryID, challengerModelID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query score distribution: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var side string
+		var bucket, count int
+		if err := rows.Scan(&side, &bucket, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan score distribution: %w", err)
+		}
+		label := fmt.Sprintf("%d-%d", bucket*10, (bucket+1)*10)
+		if side == "champion" {
+			stats.Champion.Distribution[label] = count
+		} else {
+			stats.Challenger.Distribution[label] = count
+		}
+	}
+
+	return stats, rows.Err()
+}
+
+func scanScoringModel(row pgx.Row) (*entity.ScoringModel, error) {
+	var model entity.ScoringModel
+	var definition []byte
+
+	if err := row.Scan(
+		&model.ID, &model.CountryID, &model.Version, &model.Name, &definition,
+		&model.IsActive, &model.IsChallenger, &model.TrafficPercent, &model.Notes, &model.CreatedBy, &model.CreatedAt, &model.ActivatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(definition, &model.Definition); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal scoring definition: %w", err)
+	}
+
+	return &model, nil
+}
@@ -0,0 +1,273 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/domain/repository"
+	"github.com/fintech-multipass/backend/internal/infrastructure/database"
+	"github.com/fintech-multipass/backend/internal/infrastructure/watchlist"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// WatchlistRepository implementación de repositorio de listas de sanciones y PEP
+type WatchlistRepository struct {
+	db *database.PostgresDB
+}
+
+// NewWatchlistRepository crea una nueva instancia del repositorio
+func NewWatchlistRepository(db *database.PostgresDB) *WatchlistRepository {
+	return &WatchlistRepository{db: db}
+}
+
+const watchlistHitColumns = `h.id, h.application_id, h.entry_id, h.source, h.list_type, h.external_id,
+	h.entry_name, h.matched_name, h.match_type, h.score::float8, h.status, COALESCE(h.resolution_note, ''),
+	h.resolved_by, h.resolved_at, h.created_at, a.full_name, a.document_number`
+
+// screeningStatusSQL estado de screening de la solicitud según sus hits
+const screeningStatusSQL = `
+	UPDATE credit_applications a
+	SET screening_status = CASE
+	        WHEN EXISTS (SELECT 1 FROM watchlist_hits h WHERE h.application_id = a.id AND h.status = 'CONFIRMED') THEN 'CONFIRMED_HIT'
+	        WHEN EXISTS (SELECT 1 FROM watchlist_hits h WHERE h.application_id = a.id AND h.status = 'PENDING') THEN 'POTENTIAL_HIT'
+	        ELSE 'CLEAR'
+	    END,
+	    screened_at = CASE WHEN $2 THEN NOW() ELSE screened_at END,
+	    updated_at = NOW()
+	WHERE a.id = $1
+	RETURNING a.screening_status
+`
+
+// ReplaceList guarda la importación y reemplaza todas las entradas de su fuente
+// Los hits existentes conservan sus datos; su entry_id queda en NULL
+func (r *WatchlistRepository) ReplaceList(ctx context.Context, imp *entity.WatchlistImport, entries []entity.WatchlistEntry) error {
+	return r.db.WithTx(ctx, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO watchlist_imports (id, source, list_type, format, file_name, entries, names, imported_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING created_at
+		`
+		if err := tx.QueryRow(ctx, query,
+			imp.ID, imp.Source, imp.ListType, imp.Format, imp.FileName, imp.Entries, imp.Names, imp.ImportedBy,
+		).Scan(&imp.CreatedAt); err != nil {
+			return fmt.Errorf("failed to save watchlist import: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM watchlist_entries WHERE source = $1`, imp.Source); err != nil {
+			return fmt.Errorf("failed to delete previous watchlist entries: %w", err)
+		}
+
+		entryRows := make([][]interface{}, 0, len(entries))
+		nameRows := make([][]interface{}, 0, imp.Names)
+		for _, e := range entries {
+			entryRows = append(entryRows, []interface{}{
+				e.ID, imp.ID, imp.Source, imp.ListType, e.ExternalID, e.FullName,
+				nonNil(e.Aliases), nonNil(e.DocumentNumbers), nullIfEmpty(e.Country), nullIfEmpty(e.Program),
+			})
+			for _, name := range append([]string{e.FullName}, e.Aliases...) {
+				if normalized := watchlist.NormalizeName(name); normalized != "" {
+					nameRows = append(nameRows, []interface{}{e.ID, name, normalized})
+				}
+			}
+		}
+
+		if _, err := tx.CopyFrom(ctx,
+			pgx.Identifier{"watchlist_entries"},
+			[]string{"id", "import_id", "source", "list_type", "external_id", "full_name", "aliases", "document_numbers", "country", "program"},
+			pgx.CopyFromRows(entryRows),
+		); err != nil {
+			return fmt.Errorf("failed to copy watchlist entries: %w", err)
+		}
+		if _, err := tx.CopyFrom(ctx,
+			pgx.Identifier{"watchlist_names"},
+			[]string{"entry_id", "name", "normalized_name"},
+			pgx.CopyFromRows(nameRows),
+		); err != nil {
+			return fmt.Errorf("failed to copy watchlist names: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListImports lista las últimas importaciones
+func (r *WatchlistRepository) ListImports(ctx context.Context, limit int) ([]entity.WatchlistImport, error) {
+	query := `
+		SELECT id, source, list_type, format, COALESCE(file_name, ''), entries, names, imported_by, created_at
+		FROM watchlist_imports
+		ORDER BY created_at DESC
+		LIMIT $1
+	`
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watchlist imports: %w", err)
+	}
+	defer rows.Close()
+
+	var imports []entity.WatchlistImport
+	for rows.Next() {
+		var imp entity.WatchlistImport
+		if err := rows.Scan(&imp.ID, &imp.Source, &imp.ListType, &imp.Format, &imp.FileName,
+			&imp.Entries, &imp.Names, &imp.ImportedBy, &imp.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan watchlist import: %w", err)
+		}
+		imports = append(imports, imp)
+	}
+	return imports, rows.Err()
+}
+
+// FindMatches busca por similitud del nombre normalizado y por documento exacto,
+// con la mejor coincidencia por entrada. El operador % filtra con el índice
+// trigram (umbral pg_trgm.similarity_threshold, 0.3 por defecto) antes de aplicar minScore
+func (r *WatchlistRepository) FindMatches(ctx context.Context, normalizedName, documentNumber string, minScore float64, limit int) ([]entity.WatchlistMatch, error) {
+	query := `
+		WITH candidates AS (
+			SELECT n.entry_id, n.name AS matched_name, 'NAME' AS match_type,
+			       similarity(n.normalized_name, $1)::float8 AS score
+			FROM watchlist_names n
+			WHERE $1 <> '' AND n.normalized_name % $1 AND similarity(n.normalized_name, $1) >= $3
+			UNION ALL
+			SELECT e.id, e.full_name, 'DOCUMENT', 1.0::float8
+			FROM watchlist_entries e
+			WHERE $2 <> '' AND e.document_numbers @> ARRAY[$2]::text[]
+		), best AS (
+			SELECT DISTINCT ON (entry_id) entry_id, matched_name, match_type, score
+			FROM candidates
+			ORDER BY entry_id, score DESC, match_type
+		)
+		SELECT e.id, e.import_id, e.source, e.list_type, e.external_id, e.full_name, e.aliases, e.document_numbers,
+		       COALESCE(e.country, ''), COALESCE(e.program, ''), e.created_at,
+		       b.matched_name, b.match_type, b.score
+		FROM best b
+		JOIN watchlist_entries e ON e.id = b.entry_id
+		ORDER BY b.score DESC
+		LIMIT $4
+	`
+	rows, err := r.db.Query(ctx, query, normalizedName, documentNumber, minScore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search watchlists: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []entity.WatchlistMatch
+	for rows.Next() {
+		var m entity.WatchlistMatch
+		e := &m.Entry
+		if err := rows.Scan(&e.ID, &e.ImportID, &e.Source, &e.ListType, &e.ExternalID, &e.FullName, &e.Aliases, &e.DocumentNumbers,
+			&e.Country, &e.Program, &e.CreatedAt, &m.MatchedName, &m.MatchType, &m.Score); err != nil {
+			return nil, fmt.Errorf("failed to scan watchlist match: %w", err)
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// SaveScreening guarda los hits nuevos de la solicitud (un hit por entrada; los ya
+// existentes conservan su resolución) y actualiza el estado de screening
+func (r *WatchlistRepository) SaveScreening(ctx context.Context, applicationID uuid.UUID, hits []entity.WatchlistHit) (entity.ScreeningStatus, error) {
+	var status entity.ScreeningStatus
+	err := r.db.WithTx(ctx, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO watchlist_hits (id, application_id, entry_id, source, list_type, external_id,
+			                            entry_name, matched_name, match_type, score)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			ON CONFLICT (application_id, source, external_id) DO NOTHING
+		`
+		for _, h := range hits {
+			if _, err := tx.Exec(ctx, query, h.ID, applicationID, h.EntryID, h.Source, h.ListType, h.ExternalID,
+				h.EntryName, h.MatchedName, h.MatchType, h.Score); err != nil {
+				return fmt.Errorf("failed to save watchlist hit: %w", err)
+			}
+		}
+		if err := tx.QueryRow(ctx, screeningStatusSQL, applicationID, true).Scan(&status); err != nil {
+			return fmt.Errorf("failed to update screening status: %w", err)
+		}
+		return nil
+	})
+	return status, err
+}
+
+// ListHits lista hits con los datos del solicitante, los más recientes primero
+func (r *WatchlistRepository) ListHits(ctx context.Context, filter entity.WatchlistHitFilter) ([]entity.WatchlistHit, error) {
+	query := `
+		SELECT ` + watchlistHitColumns + `
+		FROM watchlist_hits h
+		JOIN credit_applications a ON a.id = h.application_id
+		WHERE ($1 = '' OR h.status = $1) AND ($2::uuid IS NULL OR h.application_id = $2)
+		ORDER BY h.created_at DESC
+		LIMIT $3
+	`
+	rows, err := r.db.Query(ctx, query, string(filter.Status), filter.ApplicationID, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watchlist hits: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []entity.WatchlistHit
+	for rows.Next() {
+		hit, err := scanWatchlistHit(rows)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, *hit)
+	}
+	return hits, rows.Err()
+}
+
+// GetHit obtiene un hit por ID
+func (r *WatchlistRepository) GetHit(ctx context.Context, id uuid.UUID) (*entity.WatchlistHit, error) {
+	query := `
+		SELECT ` + watchlistHitColumns + `
+		FROM watchlist_hits h
+		JOIN credit_applications a ON a.id = h.application_id
+		WHERE h.id = $1
+	`
+	return scanWatchlistHit(r.db.QueryRow(ctx, query, id))
+}
+
+// ResolveHit confirma o descarta un hit pendiente y devuelve el nuevo estado de
+// screening de la solicitud. Si el hit ya estaba resuelto devuelve ErrWatchlistHitResolved
+func (r *WatchlistRepository) ResolveHit(ctx context.Context, id uuid.UUID, status entity.WatchlistHitStatus, resolvedBy *uuid.UUID, note string) (entity.ScreeningStatus, error) {
+	var screening entity.ScreeningStatus
+	err := r.db.WithTx(ctx, func(tx pgx.Tx) error {
+		query := `
+			UPDATE watchlist_hits
+			SET status = $2, resolved_by = $3, resolution_note = $4, resolved_at = NOW()
+			WHERE id = $1 AND status = 'PENDING'
+			RETURNING application_id
+		`
+		var applicationID uuid.UUID
+		err := tx.QueryRow(ctx, query, id, status, resolvedBy, nullIfEmpty(note)).Scan(&applicationID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ErrWatchlistHitResolved
+		}
+		if err != nil {
+			return fmt.Errorf("failed to resolve watchlist hit: %w", err)
+		}
+		if err := tx.QueryRow(ctx, screeningStatusSQL, applicationID, false).Scan(&screening); err != nil {
+			return fmt.Errorf("failed to update screening status: %w", err)
+		}
+		return nil
+	})
+	return screening, err
+}
+
+func scanWatchlistHit(row pgx.Row) (*entity.WatchlistHit, error) {
+	var h entity.WatchlistHit
+	if err := row.Scan(&h.ID, &h.ApplicationID, &h.EntryID, &h.Source, &h.ListType, &h.ExternalID,
+		&h.EntryName, &h.MatchedName, &h.MatchType, &h.Score, &h.Status, &h.ResolutionNote,
+		&h.ResolvedBy, &h.ResolvedAt, &h.CreatedAt, &h.ApplicantName, &h.DocumentNumber); err != nil {
+		return nil, fmt.Errorf("failed to scan watchlist hit: %w", err)
+	}
+	return &h, nil
+}
+
+// nonNil evita guardar NULL en columnas TEXT[] NOT NULL
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
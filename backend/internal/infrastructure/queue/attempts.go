@@ -0,0 +1,133 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// Resultados de una ejecución (job_attempts.outcome)
+const (
+	AttemptRunning   = "RUNNING" // En ejecución o el worker murió sin terminar
+	AttemptSucceeded = "SUCCEEDED"
+	AttemptFailed    = "FAILED"    // El handler devolvió error (o no hay handler)
+	AttemptThrottled = "THROTTLED" // Reprogramado sin consumir intento
+	AttemptPanicked  = "PANICKED"  // El handler entró en pánico; se guarda el stack
+	AttemptTimedOut  = "TIMED_OUT" // El handler superó el timeout de su tipo
+	AttemptAbandoned = "ABANDONED" // El lease venció o se perdió; el trabajo se recuperó como huérfano
+)
+
+// maxStackBytes tamaño máximo del stack guardado de un pánico
+const maxStackBytes = 8 << 10
+
+// JobAttempt ejecución de un trabajo por un worker
+type JobAttempt struct {
+	ID         int64      `json:"id"`
+	Attempt    int        `json:"attempt"`
+	WorkerID   string     `json:"worker_id"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	DurationMs *int64     `json:"duration_ms,omitempty"`
+	Outcome    string     `json:"outcome"`
+	Error      *string    `json:"error,omitempty"`
+	Stack      *string    `json:"stack,omitempty"`
+}
+
+// panicError pánico de un handler convertido en error
+type panicError struct {
+	value interface{}
+	stack string
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+// runHandler ejecuta el handler convirtiendo un pánico en error con el stack truncado
+func runHandler(ctx context.Context, handler JobHandler, job *entity.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			if len(stack) > maxStackBytes {
+				stack = stack[:maxStackBytes]
+			}
+			err = &panicError{value: r, stack: string(stack)}
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// startAttempt registra el inicio de una ejecución. Devuelve 0 si no se pudo guardar
+// (el trabajo se ejecuta igual)
+func (q *PostgresQueue) startAttempt(ctx context.Context, job *entity.Job, workerID string) int64 {
+	var id int64
+	err := q.db.QueryRow(ctx, `
+		INSERT INTO job_attempts (job_id, attempt, worker_id)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, job.ID, job.Attempts, workerID).Scan(&id)
+	if err != nil {
+		q.log.Warn().Err(err).Str("job_id", job.ID.String()).Msg("Failed to record job attempt")
+		return 0
+	}
+	return id
+}
+
+// finishAttempt cierra la ejecución con su resultado
+func (q *PostgresQueue) finishAttempt(ctx context.Context, id int64, outcome string, handlerErr error) {
+	if id == 0 {
+		return
+	}
+	var errMsg, stack *string
+	if handlerErr != nil {
+		msg := handlerErr.Error()
+		errMsg = &msg
+		var p *panicError
+		if errors.As(handlerErr, &p) {
+			stack = &p.stack
+		}
+	}
+
+	err := q.db.Exec(ctx, `
+		UPDATE job_attempts
+		SET outcome = $2,
+			error = $3,
+			stack = $4,
+			finished_at = NOW(),
+			duration_ms = (EXTRACT(EPOCH FROM NOW() - started_at) * 1000)::bigint
+		WHERE id = $1
+	`, id, outcome, errMsg, stack)
+	if err != nil {
+		q.log.Warn().Err(err).Int64("attempt_id", id).Msg("Failed to finish job attempt")
+	}
+}
+
+// listAttempts ejecuciones de un trabajo, de la primera a la última
+func (q *PostgresQueue) listAttempts(ctx context.Context, jobID uuid.UUID) ([]JobAttempt, error) {
+	rows, err := q.db.Query(ctx, `
+		SELECT id, attempt, worker_id, started_at, finished_at, duration_ms, outcome, error, stack
+		FROM job_attempts
+		WHERE job_id = $1
+		ORDER BY started_at, id
+	`, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job attempts: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := []JobAttempt{}
+	for rows.Next() {
+		var a JobAttempt
+		if err := rows.Scan(&a.ID, &a.Attempt, &a.WorkerID, &a.StartedAt, &a.FinishedAt, &a.DurationMs,
+			&a.Outcome, &a.Error, &a.Stack); err != nil {
+			return nil, fmt.Errorf("failed to scan job attempt: %w", err)
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
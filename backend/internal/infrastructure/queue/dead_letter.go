@@ -0,0 +1,411 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrJobNotFound el trabajo no existe
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrJobStateConflict el estado del trabajo no permite la acción (ej. cancelar uno en PROCESSING)
+var ErrJobStateConflict = errors.New("job state does not allow this action")
+
+// ErrInvalidJobRequest filtro o payload no válido
+var ErrInvalidJobRequest = errors.New("invalid job request")
+
+// Eventos del historial de un trabajo (job_events)
+const (
+	JobEventRetrying      = "RETRYING"       // Falló un intento y se reintentará
+	JobEventFailed        = "FAILED"         // Agotó los intentos: pasa al dead-letter
+	JobEventThrottled     = "THROTTLED"      // Reprogramado sin consumir intento
+	JobEventRequeued      = "REQUEUED"       // Reencolado por un admin
+	JobEventPayloadEdited = "PAYLOAD_EDITED" // Payload corregido por un admin
+	JobEventCancelled     = "CANCELLED"      // Cancelado por un admin
+)
+
+// Límites del dead-letter
+const (
+	DefaultDeadLetterLimit = 50
+	MaxDeadLetterLimit     = 500
+	MaxBulkRequeue         = 1000
+)
+
+// JobEvent evento del historial de un trabajo
+type JobEvent struct {
+	ID        int64           `json:"id"`
+	Event     string          `json:"event"`
+	Attempt   *int            `json:"attempt,omitempty"`
+	Message   *string         `json:"message,omitempty"`
+	ActorID   *uuid.UUID      `json:"actor_id,omitempty"`
+	Details   json.RawMessage `json:"details,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// JobDetail trabajo con su payload, ejecuciones e historial
+type JobDetail struct {
+	RecentJobInfo
+	Priority int             `json:"priority"`
+	Payload  json.RawMessage `json:"payload"`
+	Result   json.RawMessage `json:"result,omitempty"`
+	WorkerID *string         `json:"worker_id,omitempty"`
+	// No puede llamarse Attempts: ocultaría RecentJobInfo.Attempts (número de intentos)
+	AttemptHistory []JobAttempt `json:"attempt_history"`
+	Events         []JobEvent   `json:"events"`
+}
+
+// DeadLetterFilter filtros del dead-letter
+type DeadLetterFilter struct {
+	Status entity.JobStatus // FAILED (por defecto) o CANCELLED
+	Type   entity.JobType
+	Error  string     // Texto contenido en el último error (sin distinguir mayúsculas)
+	From   *time.Time // Fallidos o cancelados desde
+	To     *time.Time // Fallidos o cancelados antes de
+	Limit  int
+	Offset int
+}
+
+// DeadLetterPage página del dead-letter
+type DeadLetterPage struct {
+	Jobs   []RecentJobInfo `json:"jobs"`
+	Total  int64           `json:"total"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+}
+
+// deadLetterWhere filtro común del listado y del reencolado masivo ($1-$5)
+const deadLetterWhere = `
+	status = $1
+	AND ($2 = '' OR type = $2)
+	AND ($3 = '' OR strpos(lower(COALESCE(error_message, '')), lower($3)) > 0)
+	AND ($4::timestamptz IS NULL OR completed_at >= $4)
+	AND ($5::timestamptz IS NULL OR completed_at < $5)
+`
+
+// execer conexión, pool o transacción
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+}
+
+// normalize valida el estado y acota el límite
+func (f *DeadLetterFilter) normalize(maxLimit int) error {
+	if f.Status == "" {
+		f.Status = entity.JobStatusFailed
+	}
+	if f.Status != entity.JobStatusFailed && f.Status != entity.JobStatusCancelled {
+		return fmt.Errorf("%w: dead-letter status must be FAILED or CANCELLED", ErrInvalidJobRequest)
+	}
+	if f.Limit <= 0 {
+		f.Limit = DefaultDeadLetterLimit
+	}
+	if f.Limit > maxLimit {
+		f.Limit = maxLimit
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	return nil
+}
+
+func (f *DeadLetterFilter) args() []interface{} {
+	return []interface{}{string(f.Status), string(f.Type), f.Error, f.From, f.To}
+}
+
+// ListDeadLetters lista los trabajos fallidos (o cancelados), los más recientes primero, con el total
+func (q *PostgresQueue) ListDeadLetters(ctx context.Context, filter DeadLetterFilter) (*DeadLetterPage, error) {
+	if err := filter.normalize(MaxDeadLetterLimit); err != nil {
+		return nil, err
+	}
+
+	page := &DeadLetterPage{Jobs: []RecentJobInfo{}, Limit: filter.Limit, Offset: filter.Offset}
+	if err := q.db.QueryRow(ctx, `SELECT COUNT(*) FROM jobs_queue WHERE `+deadLetterWhere, filter.args()...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("failed to count dead-letter jobs: %w", err)
+	}
+
+	query := `
+		SELECT id, type, status, attempts, max_attempts, error_message,
+		       created_at, scheduled_at, started_at, completed_at
+		FROM jobs_queue
+		WHERE ` + deadLetterWhere + `
+		ORDER BY completed_at DESC NULLS LAST
+		LIMIT $6 OFFSET $7
+	`
+	rows, err := q.db.Query(ctx, query, append(filter.args(), filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dead-letter jobs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var job RecentJobInfo
+		if err := rows.Scan(&job.ID, &job.Type, &job.Status, &job.Attempts, &job.MaxAttempts,
+			&job.ErrorMessage, &job.CreatedAt, &job.ScheduledAt, &job.StartedAt, &job.CompletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan dead-letter job: %w", err)
+		}
+		page.Jobs = append(page.Jobs, job)
+	}
+	return page, rows.Err()
+}
+
+// jobDetailQuery columnas de jobs_queue que lee scanJobDetail
+const jobDetailQuery = `
+	SELECT id, type, status, priority, attempts, max_attempts, error_message, payload, result, worker_id,
+	       created_at, scheduled_at, started_at, completed_at
+	FROM jobs_queue
+	WHERE id = $1
+`
+
+// scanJobDetail lee el trabajo devuelto por jobDetailQuery (sin ejecuciones ni eventos)
+func scanJobDetail(row pgx.Row) (*JobDetail, error) {
+	var d JobDetail
+	var payload, result []byte
+	err := row.Scan(&d.ID, &d.Type, &d.Status, &d.Priority, &d.RecentJobInfo.Attempts, &d.MaxAttempts, &d.ErrorMessage,
+		&payload, &result, &d.WorkerID, &d.CreatedAt, &d.ScheduledAt, &d.StartedAt, &d.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	d.Result = result
+	return &d, nil
+}
+
+// GetJobDetail obtiene un trabajo con su payload, sus ejecuciones e historial de eventos
+func (q *PostgresQueue) GetJobDetail(ctx context.Context, id uuid.UUID) (*JobDetail, error) {
+	d, err := scanJobDetail(q.db.QueryRow(ctx, jobDetailQuery, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	if d.AttemptHistory, err = q.listAttempts(ctx, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := q.db.Query(ctx, `
+		SELECT id, event, attempt, message, actor_id, details, created_at
+		FROM job_events
+		WHERE job_id = $1
+		ORDER BY created_at, id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job events: %w", err)
+	}
+	defer rows.Close()
+
+	d.Events = []JobEvent{}
+	for rows.Next() {
+		var e JobEvent
+		var details []byte
+		if err := rows.Scan(&e.ID, &e.Event, &e.Attempt, &e.Message, &e.ActorID, &details, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan job event: %w", err)
+		}
+		e.Details = details
+		d.Events = append(d.Events, e)
+	}
+	return d, rows.Err()
+}
+
+// Requeue reencola un trabajo fallido, cancelado o en espera de reintento para
+// ejecutarlo ya, reiniciando sus intentos
+func (q *PostgresQueue) Requeue(ctx context.Context, id uuid.UUID, actorID *uuid.UUID) error {
+	return q.db.WithTx(ctx, func(tx pgx.Tx) error {
+		if _, err := lockJob(ctx, tx, id, entity.JobStatusFailed, entity.JobStatusCancelled, entity.JobStatusRetrying); err != nil {
+			return err
+		}
+		return requeueJob(ctx, tx, id, actorID)
+	})
+}
+
+// RequeueDeadLetters reencola en bloque los trabajos que cumplen el filtro (hasta filter.Limit,
+// máximo 1000) y devuelve sus IDs
+func (q *PostgresQueue) RequeueDeadLetters(ctx context.Context, filter DeadLetterFilter, actorID *uuid.UUID) ([]uuid.UUID, error) {
+	if err := filter.normalize(MaxBulkRequeue); err != nil {
+		return nil, err
+	}
+
+	ids := []uuid.UUID{}
+	err := q.db.WithTx(ctx, func(tx pgx.Tx) error {
+		query := `
+			WITH target AS (
+				SELECT id FROM jobs_queue
+				WHERE ` + deadLetterWhere + `
+				ORDER BY completed_at
+				LIMIT $6
+				FOR UPDATE SKIP LOCKED
+			)
+			UPDATE jobs_queue j
+			SET status = 'PENDING',
+				attempts = 0,
+				scheduled_at = NOW(),
+				started_at = NULL,
+				completed_at = NULL,
+				worker_id = NULL,
+				updated_at = NOW()
+			FROM target
+			WHERE j.id = target.id
+			RETURNING j.id
+		`
+		rows, err := tx.Query(ctx, query, append(filter.args(), filter.Limit)...)
+		if err != nil {
+			return fmt.Errorf("failed to requeue dead-letter jobs: %w", err)
+		}
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan requeued job: %w", err)
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to requeue dead-letter jobs: %w", err)
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+		idStrings := make([]string, len(ids))
+		for i, id := range ids {
+			idStrings[i] = id.String()
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO job_events (job_id, event, message, actor_id)
+			SELECT id, $2, $3, $4 FROM unnest($1::uuid[]) AS id
+		`, idStrings, JobEventRequeued, "bulk requeue", actorID)
+		if err != nil {
+			return fmt.Errorf("failed to record requeue events: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	q.log.Info().
+		Int("count", len(ids)).
+		Str("status", string(filter.Status)).
+		Str("type", string(filter.Type)).
+		Msg("Dead-letter jobs requeued")
+	return ids, nil
+}
+
+// EditPayload reemplaza el payload de un trabajo fallido o cancelado (el anterior queda
+// en el historial) y opcionalmente lo reencola
+func (q *PostgresQueue) EditPayload(ctx context.Context, id uuid.UUID, payload json.RawMessage, requeue bool, actorID *uuid.UUID) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &object); err != nil || object == nil {
+		return fmt.Errorf("%w: payload must be a JSON object", ErrInvalidJobRequest)
+	}
+	return q.db.WithTx(ctx, func(tx pgx.Tx) error {
+		previous, err := lockJob(ctx, tx, id, entity.JobStatusFailed, entity.JobStatusCancelled)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE jobs_queue SET payload = $2::jsonb, updated_at = NOW() WHERE id = $1`, id, string(payload)); err != nil {
+			return fmt.Errorf("failed to update job payload: %w", err)
+		}
+		details, _ := json.Marshal(map[string]json.RawMessage{"previous_payload": previous})
+		if err := recordJobEvent(ctx, tx, id, JobEventPayloadEdited, nil, "", actorID, details); err != nil {
+			return err
+		}
+
+		if requeue {
+			return requeueJob(ctx, tx, id, actorID)
+		}
+		return nil
+	})
+}
+
+// Cancel cancela un trabajo pendiente, en espera de reintento o fallido. Los que están
+// en PROCESSING no se pueden cancelar
+func (q *PostgresQueue) Cancel(ctx context.Context, id uuid.UUID, reason string, actorID *uuid.UUID) error {
+	return q.db.WithTx(ctx, func(tx pgx.Tx) error {
+		if _, err := lockJob(ctx, tx, id, entity.JobStatusPending, entity.JobStatusRetrying, entity.JobStatusFailed); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `
+			UPDATE jobs_queue
+			SET status = 'CANCELLED',
+				completed_at = NOW(),
+				worker_id = NULL,
+				updated_at = NOW()
+			WHERE id = $1
+		`, id)
+		if err != nil {
+			return fmt.Errorf("failed to cancel job: %w", err)
+		}
+		return recordJobEvent(ctx, tx, id, JobEventCancelled, nil, reason, actorID, nil)
+	})
+}
+
+// lockJob bloquea el trabajo y verifica que su estado sea uno de los permitidos.
+// Devuelve el payload actual
+func lockJob(ctx context.Context, tx pgx.Tx, id uuid.UUID, allowed ...entity.JobStatus) (json.RawMessage, error) {
+	var status entity.JobStatus
+	var payload []byte
+	err := tx.QueryRow(ctx, `SELECT status, payload FROM jobs_queue WHERE id = $1 FOR UPDATE`, id).Scan(&status, &payload)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock job: %w", err)
+	}
+	for _, s := range allowed {
+		if status == s {
+			return payload, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: job is %s", ErrJobStateConflict, status)
+}
+
+// requeueJob deja el trabajo listo para ejecutarse con los intentos reiniciados
+func requeueJob(ctx context.Context, tx pgx.Tx, id uuid.UUID, actorID *uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE jobs_queue
+		SET status = 'PENDING',
+			attempts = 0,
+			scheduled_at = NOW(),
+			started_at = NULL,
+			completed_at = NULL,
+			worker_id = NULL,
+			updated_at = NOW()
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to requeue job: %w", err)
+	}
+	return recordJobEvent(ctx, tx, id, JobEventRequeued, nil, "", actorID, nil)
+}
+
+// recordJobEvent agrega un evento al historial del trabajo
+func recordJobEvent(ctx context.Context, db execer, jobID uuid.UUID, event string, attempt *int, message string, actorID *uuid.UUID, details []byte) error {
+	var detailsJSON *string
+	if len(details) > 0 {
+		s := string(details)
+		detailsJSON = &s
+	}
+	var messagePtr *string
+	if message != "" {
+		messagePtr = &message
+	}
+	_, err := db.Exec(ctx, `
+		INSERT INTO job_events (job_id, event, attempt, message, actor_id, details)
+		VALUES ($1, $2, $3, $4, $5, $6::jsonb)
+	`, jobID, event, attempt, messagePtr, actorID, detailsJSON)
+	if err != nil {
+		return fmt.Errorf("failed to record job event: %w", err)
+	}
+	return nil
+}
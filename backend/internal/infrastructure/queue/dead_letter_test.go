@@ -0,0 +1,107 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// fakeRow fila con valores fijos; como pgx, falla si un destino no admite el tipo de la columna
+type fakeRow []interface{}
+
+func (r fakeRow) Scan(dest ...interface{}) error {
+	if len(dest) != len(r) {
+		return fmt.Errorf("expected %d destinations, got %d", len(r), len(dest))
+	}
+	for i, d := range dest {
+		target := reflect.ValueOf(d).Elem()
+		value := reflect.ValueOf(r[i])
+		if !r.assign(target, value) {
+			return fmt.Errorf("cannot scan %T into %T (column %d)", r[i], d, i)
+		}
+	}
+	return nil
+}
+
+// assign copia el valor en el destino; un destino puntero recibe un puntero al valor
+func (r fakeRow) assign(target, value reflect.Value) bool {
+	if !value.IsValid() {
+		target.Set(reflect.Zero(target.Type()))
+		return true
+	}
+	if value.Type().AssignableTo(target.Type()) {
+		target.Set(value)
+		return true
+	}
+	if target.Kind() == reflect.Ptr && value.Type().AssignableTo(target.Type().Elem()) {
+		ptr := reflect.New(target.Type().Elem())
+		ptr.Elem().Set(value)
+		target.Set(ptr)
+		return true
+	}
+	if value.Type().ConvertibleTo(target.Type()) && value.Kind() == target.Kind() {
+		target.Set(value.Convert(target.Type()))
+		return true
+	}
+	return false
+}
+
+func TestScanJobDetail(t *testing.T) {
+	id := uuid.New()
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	started := created.Add(time.Minute)
+	completed := started.Add(time.Second)
+
+	row := fakeRow{
+		id, "BANKING_INFO_FETCH", "FAILED", 5, 3, 3, "provider timeout",
+		[]byte(`{"application_id":"a"}`), nil, "banking_info_fetch-1",
+		created, created, started, completed,
+	}
+
+	d, err := scanJobDetail(row)
+	if err != nil {
+		t.Fatalf("scanJobDetail: %v", err)
+	}
+
+	if d.ID != id || d.Type != entity.JobTypeBankingInfoFetch || d.Status != entity.JobStatusFailed {
+		t.Errorf("unexpected job identity: %+v", d.RecentJobInfo)
+	}
+	if d.Priority != 5 || d.Attempts != 3 || d.MaxAttempts != 3 {
+		t.Errorf("priority/attempts = %d/%d/%d, want 5/3/3", d.Priority, d.Attempts, d.MaxAttempts)
+	}
+	if d.ErrorMessage == nil || *d.ErrorMessage != "provider timeout" {
+		t.Errorf("error_message = %v, want provider timeout", d.ErrorMessage)
+	}
+	if d.WorkerID == nil || *d.WorkerID != "banking_info_fetch-1" {
+		t.Errorf("worker_id = %v, want banking_info_fetch-1", d.WorkerID)
+	}
+	if d.CompletedAt == nil || !d.CompletedAt.Equal(completed) {
+		t.Errorf("completed_at = %v, want %v", d.CompletedAt, completed)
+	}
+
+	// La respuesta lleva el número de intentos y el historial de ejecuciones por separado
+	d.AttemptHistory = []JobAttempt{{ID: 1, Attempt: 1, WorkerID: "banking_info_fetch-1", Outcome: AttemptFailed}}
+	d.Events = []JobEvent{}
+	body, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded["attempts"] != float64(3) {
+		t.Errorf("attempts = %v, want 3", decoded["attempts"])
+	}
+	if history, ok := decoded["attempt_history"].([]interface{}); !ok || len(history) != 1 {
+		t.Errorf("attempt_history = %v, want one attempt", decoded["attempt_history"])
+	}
+	if payload, ok := decoded["payload"].(map[string]interface{}); !ok || payload["application_id"] != "a" {
+		t.Errorf("payload = %v", decoded["payload"])
+	}
+}
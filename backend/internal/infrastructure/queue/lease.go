@@ -0,0 +1,136 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// Valores por defecto del timeout de los handlers y del lease de los trabajos en ejecución
+const (
+	defaultJobTimeout    = 5 * time.Minute
+	defaultLeaseDuration = 1 * time.Minute
+	minLeaseDuration     = 3 * time.Second
+)
+
+// errLeaseLost el trabajo ya no está reservado por esta ejecución (se recuperó como
+// huérfano y quizá lo tomó otro worker)
+var errLeaseLost = errors.New("job lease lost while running; job recovered by another worker")
+
+// leaseHeartbeats renovaciones por lease: con una fallida aún queda margen antes de que venza
+const leaseHeartbeats = 3
+
+// legacyStaleMinutes antigüedad tras la que se recuperan los trabajos PROCESSING sin lease
+// (reservados por workers anteriores a la migración 021)
+const legacyStaleMinutes = 5
+
+// ParseJobTimeouts lee "BANKING_INFO_FETCH:10m,NOTIFICATION:30s": timeout del handler
+// de cada tipo. Los tipos sin entrada usan el timeout general
+func ParseJobTimeouts(spec string) (map[entity.JobType]time.Duration, error) {
+	timeouts := make(map[entity.JobType]time.Duration)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, ":")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if !ok || name == "" || name == anyJobType || err != nil || d <= 0 {
+			return nil, fmt.Errorf("%w: %q must be TYPE:DURATION with DURATION > 0", ErrInvalidPoolSpec, part)
+		}
+		if _, seen := timeouts[entity.JobType(name)]; seen {
+			return nil, fmt.Errorf("%w: %s is repeated", ErrInvalidPoolSpec, name)
+		}
+		timeouts[entity.JobType(name)] = d
+	}
+	return timeouts, nil
+}
+
+// jobTimeout timeout del handler para el tipo de trabajo
+func (q *PostgresQueue) jobTimeout(jobType entity.JobType) time.Duration {
+	if d, ok := q.opts.JobTimeouts[jobType]; ok {
+		return d
+	}
+	return q.opts.JobTimeout
+}
+
+// leaseMillis duración del lease en milisegundos para las consultas
+func (q *PostgresQueue) leaseMillis() int64 {
+	return q.opts.LeaseDuration.Milliseconds()
+}
+
+// jobLease heartbeat de un trabajo en ejecución
+type jobLease struct {
+	done chan struct{}
+	wg   sync.WaitGroup
+	lost atomic.Bool
+}
+
+// keepLease renueva el lease del trabajo cada LeaseDuration/3 hasta release. Si la
+// renovación no encuentra el trabajo con el token de esta reserva (se recuperó como
+// huérfano, quizá lo tomó otro worker) llama a onLost para cancelar el handler
+func (q *PostgresQueue) keepLease(ctx context.Context, jobID, leaseToken uuid.UUID, onLost func()) *jobLease {
+	l := &jobLease{done: make(chan struct{})}
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		ticker := time.NewTicker(q.opts.LeaseDuration / leaseHeartbeats)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-l.done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			renewed, err := q.renewLease(ctx, jobID, leaseToken)
+			if err != nil {
+				// Error transitorio: el lease aún no vence, se reintenta en el siguiente tick
+				q.log.Warn().Err(err).Str("job_id", jobID.String()).Msg("Failed to renew job lease")
+				continue
+			}
+			if !renewed {
+				l.lost.Store(true)
+				q.log.Error().
+					Str("job_id", jobID.String()).
+					Str("lease_token", leaseToken.String()).
+					Msg("Job lease lost, cancelling handler")
+				onLost()
+				return
+			}
+		}
+	}()
+	return l
+}
+
+// release detiene el heartbeat; devuelve true si el lease se perdió durante la ejecución
+func (l *jobLease) release() bool {
+	close(l.done)
+	l.wg.Wait()
+	return l.lost.Load()
+}
+
+// renewLease extiende el lease si el trabajo sigue reservado con el token. Los IDs de
+// worker se repiten entre procesos, así que no sirven para saber si la reserva es nuestra
+func (q *PostgresQueue) renewLease(ctx context.Context, jobID, leaseToken uuid.UUID) (bool, error) {
+	tag, err := q.db.Pool.Exec(ctx, `
+		UPDATE jobs_queue
+		SET lease_expires_at = NOW() + INTERVAL '1 millisecond' * $3
+		WHERE id = $1 AND lease_token = $2 AND status = 'PROCESSING'
+	`, jobID, leaseToken, q.leaseMillis())
+	if err != nil {
+		return false, fmt.Errorf("failed to renew job lease: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
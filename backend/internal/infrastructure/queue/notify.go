@@ -0,0 +1,265 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/infrastructure/config"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// notifyChannel canal en el que el trigger de jobs_queue publica los trabajos listos (migración 018)
+const notifyChannel = "jobs_queue"
+
+// Espera entre reconexiones del listener
+const (
+	listenMinBackoff = 1 * time.Second
+	listenMaxBackoff = 30 * time.Second
+)
+
+// minDelayedWake evita despertar en bucle si el reloj local va por delante del de la base
+const minDelayedWake = 50 * time.Millisecond
+
+// Options ajustes de los workers
+type Options struct {
+	Listen               bool          // Despertar a los workers con LISTEN/NOTIFY
+	PollInterval         time.Duration // Sondeo sin LISTEN o con el listener desconectado
+	FallbackPollInterval time.Duration // Sondeo de respaldo con el listener conectado
+
+	// Máximo de trabajos de cada tipo en PROCESSING entre todos los workers que usan el límite
+	MaxInFlight map[entity.JobType]int
+
+	JobTimeout    time.Duration                    // Timeout del handler
+	JobTimeouts   map[entity.JobType]time.Duration // Timeout por tipo, sustituye a JobTimeout
+	LeaseDuration time.Duration                    // Lease que renueva el heartbeat mientras el handler se ejecuta
+}
+
+// DefaultOptions LISTEN activo con sondeo de respaldo cada 30s, handlers con
+// timeout de 5m y lease de 1m
+func DefaultOptions() Options {
+	return Options{
+		Listen:               true,
+		PollInterval:         1 * time.Second,
+		FallbackPollInterval: 30 * time.Second,
+		JobTimeout:           defaultJobTimeout,
+		LeaseDuration:        defaultLeaseDuration,
+	}
+}
+
+// OptionsFromConfig ajustes de los workers según la configuración de la cola
+func OptionsFromConfig(cfg config.QueueConfig) (Options, error) {
+	limits, err := ParseMaxInFlight(cfg.MaxInFlight)
+	if err != nil {
+		return Options{}, err
+	}
+	timeouts, err := ParseJobTimeouts(cfg.JobTimeouts)
+	if err != nil {
+		return Options{}, err
+	}
+	return Options{
+		Listen:               cfg.ListenNotify,
+		PollInterval:         cfg.PollInterval,
+		FallbackPollInterval: cfg.FallbackPoll,
+		MaxInFlight:          limits,
+		JobTimeout:           cfg.JobTimeout,
+		JobTimeouts:          timeouts,
+		LeaseDuration:        cfg.LeaseDuration,
+	}, nil
+}
+
+// jobNotification payload del trigger notify_job_ready
+type jobNotification struct {
+	ID          uuid.UUID      `json:"id"`
+	Type        entity.JobType `json:"type"`
+	ScheduledAt time.Time      `json:"scheduled_at"`
+}
+
+// SetOptions cambia los ajustes de los workers; debe llamarse antes de StartWorkers o StartPools
+func (q *PostgresQueue) SetOptions(opts Options) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	defaults := DefaultOptions()
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaults.PollInterval
+	}
+	if opts.FallbackPollInterval < opts.PollInterval {
+		opts.FallbackPollInterval = opts.PollInterval
+	}
+	if opts.JobTimeout <= 0 {
+		opts.JobTimeout = defaults.JobTimeout
+	}
+	if opts.LeaseDuration <= 0 {
+		opts.LeaseDuration = defaults.LeaseDuration
+	}
+	if opts.LeaseDuration < minLeaseDuration {
+		opts.LeaseDuration = minLeaseDuration
+	}
+	q.opts = opts
+}
+
+// wakeOne despierta a un worker inactivo del pool (si todos están ocupados el aviso
+// se descarta: al terminar vuelven a buscar trabajos)
+func (p *workerPool) wakeOne() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// activePools pools en ejecución. StartPools y StopWorkers los cambian con q.mu tomado
+// mientras el listener y los workers los recorren
+func (q *PostgresQueue) activePools() []*workerPool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.pools
+}
+
+// wakeType despierta a un worker del pool que atiende el tipo de trabajo
+func (q *PostgresQueue) wakeType(jobType entity.JobType) {
+	for _, pool := range q.activePools() {
+		if pool.accepts(jobType) {
+			pool.wakeOne()
+			return
+		}
+	}
+}
+
+// wakeEach despierta a un worker de cada pool
+func (q *PostgresQueue) wakeEach() {
+	for _, pool := range q.activePools() {
+		pool.wakeOne()
+	}
+}
+
+// wakeAll despierta a todos los workers
+func (q *PostgresQueue) wakeAll() {
+	for _, pool := range q.activePools() {
+		for i := 0; i < cap(pool.wake); i++ {
+			pool.wakeOne()
+		}
+	}
+}
+
+// listenLoop mantiene la conexión dedicada de LISTEN y reconecta con backoff si se cae.
+// Mientras está desconectado los workers vuelven al sondeo normal
+func (q *PostgresQueue) listenLoop(ctx context.Context) {
+	backoff := listenMinBackoff
+	for {
+		connected, err := q.listen(ctx)
+		q.listening.Store(false)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			backoff = listenMinBackoff
+		}
+
+		q.log.Warn().Err(err).Dur("retry_in", backoff).Msg("Job queue listener disconnected, falling back to polling")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > listenMaxBackoff {
+			backoff = listenMaxBackoff
+		}
+	}
+}
+
+// listen abre una conexión propia (fuera del pool), hace LISTEN y procesa los avisos
+// hasta que la conexión falla. connected indica si llegó a escuchar
+func (q *PostgresQueue) listen(ctx context.Context) (connected bool, err error) {
+	conn, err := pgx.ConnectConfig(ctx, q.db.Pool.Config().ConnConfig.Copy())
+	if err != nil {
+		return false, fmt.Errorf("failed to connect listener: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+		return false, fmt.Errorf("failed to listen on %s: %w", notifyChannel, err)
+	}
+	q.listening.Store(true)
+	q.log.Info().Str("channel", notifyChannel).Msg("Job queue listener connected")
+
+	// Los trabajos encolados mientras no se escuchaba no llegaron a avisar
+	q.wakeAll()
+	q.armNextScheduled(ctx)
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		q.onNotification(ctx, notification.Payload)
+	}
+}
+
+// onNotification despierta a un worker si el trabajo ya puede ejecutarse o
+// programa el despertar para su scheduled_at
+func (q *PostgresQueue) onNotification(ctx context.Context, payload string) {
+	var n jobNotification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		q.log.Warn().Err(err).Str("payload", payload).Msg("Invalid job queue notification")
+		q.wakeEach()
+		return
+	}
+
+	if delay := time.Until(n.ScheduledAt); delay > 0 {
+		q.armDelayedWake(ctx, delay)
+		return
+	}
+	q.wakeType(n.Type)
+}
+
+// armDelayedWake programa un despertar si es anterior al ya programado
+func (q *PostgresQueue) armDelayedWake(ctx context.Context, delay time.Duration) {
+	if delay < minDelayedWake {
+		delay = minDelayedWake
+	}
+	at := time.Now().Add(delay)
+
+	q.delayedMu.Lock()
+	defer q.delayedMu.Unlock()
+	if q.delayed != nil && !q.delayedAt.IsZero() && !at.Before(q.delayedAt) {
+		return
+	}
+	if q.delayed != nil {
+		q.delayed.Stop()
+	}
+	q.delayedAt = at
+	q.delayed = time.AfterFunc(delay, func() {
+		q.delayedMu.Lock()
+		q.delayedAt = time.Time{}
+		q.delayedMu.Unlock()
+
+		q.wakeEach()
+		q.armNextScheduled(ctx)
+	})
+}
+
+// armNextScheduled programa el despertar para el próximo trabajo diferido.
+// La espera se calcula con el reloj de la base, el mismo que usa Dequeue
+func (q *PostgresQueue) armNextScheduled(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+	var seconds *float64
+	err := q.db.QueryRow(ctx, `
+		SELECT EXTRACT(EPOCH FROM MIN(scheduled_at) - NOW())::float8
+		FROM jobs_queue
+		WHERE status IN ('PENDING', 'RETRYING') AND scheduled_at > NOW()
+	`).Scan(&seconds)
+	if err != nil {
+		q.log.Warn().Err(err).Msg("Failed to find next scheduled job")
+		return
+	}
+	if seconds != nil {
+		q.armDelayedWake(ctx, time.Duration(*seconds*float64(time.Second)))
+	}
+}
@@ -0,0 +1,245 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/jackc/pgx/v5"
+)
+
+// anyJobType comodín del pool general en las especificaciones ("*:3")
+const anyJobType = "*"
+
+// ErrInvalidPoolSpec la especificación de pools o límites no es válida
+var ErrInvalidPoolSpec = errors.New("invalid worker pool spec")
+
+// Pool grupo de workers dedicado a un tipo de trabajo. Sin Type es el pool general,
+// que atiende todos los tipos sin pool propio
+type Pool struct {
+	Type    entity.JobType
+	Workers int
+}
+
+// workerPool pool en ejecución con su canal de avisos
+type workerPool struct {
+	name    string
+	types   []string // Tipos que atiende (vacío = todos menos exclude)
+	exclude []string // Tipos con pool propio, solo en el pool general
+	wake    chan struct{}
+}
+
+// ParsePools lee "BANKING_INFO_FETCH:4,NOTIFICATION:2"; "*:N" añade un pool general
+// para el resto de tipos. Sin "*" el proceso solo atiende los tipos indicados
+func ParsePools(spec string) ([]Pool, error) {
+	counts, order, err := parseTypeCounts(spec, true)
+	if err != nil {
+		return nil, err
+	}
+	pools := make([]Pool, 0, len(order))
+	for _, t := range order {
+		pool := Pool{Workers: counts[t]}
+		if t != anyJobType {
+			pool.Type = entity.JobType(t)
+		}
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
+
+// ParseMaxInFlight lee "BANKING_INFO_FETCH:8,WEBHOOK_CALL:4": máximo de trabajos de
+// cada tipo en PROCESSING entre todos los workers
+func ParseMaxInFlight(spec string) (map[entity.JobType]int, error) {
+	counts, _, err := parseTypeCounts(spec, false)
+	if err != nil {
+		return nil, err
+	}
+	limits := make(map[entity.JobType]int, len(counts))
+	for t, n := range counts {
+		limits[entity.JobType(t)] = n
+	}
+	return limits, nil
+}
+
+// parseTypeCounts lee pares TIPO:N separados por comas
+func parseTypeCounts(spec string, allowAny bool) (map[string]int, []string, error) {
+	counts := make(map[string]int)
+	var order []string
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, ":")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || name == "" || err != nil || n <= 0 {
+			return nil, nil, fmt.Errorf("%w: %q must be TYPE:N with N > 0", ErrInvalidPoolSpec, part)
+		}
+		if name == anyJobType && !allowAny {
+			return nil, nil, fmt.Errorf("%w: %q needs a job type", ErrInvalidPoolSpec, part)
+		}
+		if _, seen := counts[name]; seen {
+			return nil, nil, fmt.Errorf("%w: %s is repeated", ErrInvalidPoolSpec, name)
+		}
+		counts[name] = n
+		order = append(order, name)
+	}
+	return counts, order, nil
+}
+
+// StartPools inicia un grupo de workers por pool. Los tipos deben tener handler
+func (q *PostgresQueue) StartPools(ctx context.Context, pools []Pool) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(pools) == 0 {
+		return fmt.Errorf("%w: no pools", ErrInvalidPoolSpec)
+	}
+	var dedicated []string
+	general := false
+	for _, p := range pools {
+		if p.Workers <= 0 {
+			return fmt.Errorf("%w: pool %q needs at least one worker", ErrInvalidPoolSpec, p.Type)
+		}
+		if p.Type == "" {
+			if general {
+				return fmt.Errorf("%w: only one general pool is allowed", ErrInvalidPoolSpec)
+			}
+			general = true
+			continue
+		}
+		if _, ok := q.handlers[p.Type]; !ok {
+			return fmt.Errorf("%w: no handler for job type %s", ErrInvalidPoolSpec, p.Type)
+		}
+		for _, t := range dedicated {
+			if t == string(p.Type) {
+				return fmt.Errorf("%w: %s is repeated", ErrInvalidPoolSpec, p.Type)
+			}
+		}
+		dedicated = append(dedicated, string(p.Type))
+	}
+	for t := range q.opts.MaxInFlight {
+		if _, ok := q.handlers[t]; !ok {
+			return fmt.Errorf("%w: no handler for job type %s", ErrInvalidPoolSpec, t)
+		}
+	}
+	for t := range q.opts.JobTimeouts {
+		if _, ok := q.handlers[t]; !ok {
+			return fmt.Errorf("%w: no handler for job type %s", ErrInvalidPoolSpec, t)
+		}
+	}
+
+	// Recuperar jobs huérfanos al inicio (lease vencido)
+	if recovered, err := q.RecoverOrphanedJobs(ctx, legacyStaleMinutes); err != nil {
+		q.log.Error().Err(err).Msg("Failed to recover orphaned jobs")
+	} else if recovered > 0 {
+		q.log.Info().Int64("recovered", recovered).Msg("Recovered orphaned jobs at startup")
+	}
+
+	for _, p := range pools {
+		pool := &workerPool{
+			name: "worker",
+			// Un aviso pendiente por worker: con todos ocupados los avisos sobrantes se descartan
+			wake: make(chan struct{}, p.Workers),
+		}
+		if p.Type != "" {
+			pool.name = strings.ToLower(string(p.Type))
+			pool.types = []string{string(p.Type)}
+		} else {
+			pool.exclude = dedicated
+		}
+		q.pools = append(q.pools, pool)
+
+		for i := 0; i < p.Workers; i++ {
+			id := fmt.Sprintf("%s-%d", pool.name, i+1)
+			worker := &Worker{
+				id:       id,
+				queue:    q,
+				pool:     pool,
+				stopChan: make(chan struct{}),
+				log:      q.log.WithWorkerID(id),
+			}
+			q.workers = append(q.workers, worker)
+			go worker.Start(ctx)
+		}
+
+		q.log.Info().
+			Str("pool", pool.name).
+			Int("workers", p.Workers).
+			Strs("exclude", pool.exclude).
+			Msg("Worker pool started")
+	}
+
+	// Iniciar goroutine para recuperar jobs huérfanos periódicamente
+	go q.orphanRecoveryLoop(ctx)
+
+	// Escuchar los avisos de trabajos nuevos en una conexión dedicada
+	if q.opts.Listen {
+		listenCtx, cancel := context.WithCancel(ctx)
+		q.stopListen = cancel
+		go q.listenLoop(listenCtx)
+	}
+
+	q.log.Info().
+		Int("count", len(q.workers)).
+		Int("pools", len(q.pools)).
+		Bool("listen", q.opts.Listen).
+		Dur("poll_interval", q.opts.PollInterval).
+		Dur("fallback_poll_interval", q.opts.FallbackPollInterval).
+		Dur("job_timeout", q.opts.JobTimeout).
+		Dur("lease_duration", q.opts.LeaseDuration).
+		Msg("Workers started")
+	return nil
+}
+
+// accepts indica si el pool atiende el tipo de trabajo
+func (p *workerPool) accepts(jobType entity.JobType) bool {
+	if len(p.types) > 0 {
+		return containsString(p.types, string(jobType))
+	}
+	return !containsString(p.exclude, string(jobType))
+}
+
+// cappedTypes tipos que alcanzaron su máximo de trabajos en PROCESSING
+// Se consulta con el bloqueo de dequeue tomado para que dos workers no pasen el límite a la vez
+func (q *PostgresQueue) cappedTypes(ctx context.Context, tx pgx.Tx) ([]string, error) {
+	types := make([]string, 0, len(q.opts.MaxInFlight))
+	limits := make([]int32, 0, len(q.opts.MaxInFlight))
+	for t, n := range q.opts.MaxInFlight {
+		types = append(types, string(t))
+		limits = append(limits, int32(n))
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT l.type
+		FROM unnest($1::text[], $2::int[]) AS l(type, max_in_flight)
+		WHERE (SELECT COUNT(*) FROM jobs_queue j WHERE j.type = l.type AND j.status = 'PROCESSING') >= l.max_in_flight
+	`, types, limits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count in-flight jobs: %w", err)
+	}
+	defer rows.Close()
+
+	capped := []string{}
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, fmt.Errorf("failed to scan in-flight job type: %w", err)
+		}
+		capped = append(capped, t)
+	}
+	return capped, rows.Err()
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
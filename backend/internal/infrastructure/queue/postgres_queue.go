@@ -3,45 +3,104 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/domain/repository"
+	"github.com/fintech-multipass/backend/internal/domain/service"
 	"github.com/fintech-multipass/backend/internal/infrastructure/database"
+	"github.com/fintech-multipass/backend/internal/infrastructure/fraud"
 	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+	"github.com/fintech-multipass/backend/internal/infrastructure/scoring"
+	"github.com/fintech-multipass/backend/internal/infrastructure/validation"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 // PostgresQueue implementación de cola de trabajos usando PostgreSQL
 // Diseñada para escalabilidad con múltiples workers concurrentes
 type PostgresQueue struct {
 	db      *database.PostgresDB
+	banking service.BankingService
+	scoring *scoring.Engine
+	rules   service.RuleValidator
+	country repository.CountryRepository
+	screen  service.WatchlistScreener
 	log     *logger.Logger
 	workers []*Worker
 	mu      sync.Mutex
 	
 	// Handlers de trabajos registrados
 	handlers map[entity.JobType]JobHandler
+
+	// Despertar de workers con LISTEN/NOTIFY
+	opts       Options
+	pools      []*workerPool
+	listening  atomic.Bool
+	stopListen context.CancelFunc
+	delayedMu  sync.Mutex
+	delayed    *time.Timer
+	delayedAt  time.Time
 }
 
+// PostgresQueue implementa la interfaz de dominio de cola de trabajos
+var _ service.JobQueue = (*PostgresQueue)(nil)
+
+// defaultCallbackTimeoutMinutes espera del webhook si el proveedor no la configura
+const defaultCallbackTimeoutMinutes = 30
+
 // JobHandler función que procesa un trabajo
 type JobHandler func(ctx context.Context, job *entity.Job) error
 
+// retryLater errores que piden reprogramar el trabajo sin consumir un intento
+// (ej. banking.RateLimitError cuando el proveedor está limitado)
+type retryLater interface {
+	RetryAfter() time.Duration
+}
+
+// documentPendingRetryDelay espera de la evaluación de riesgo mientras el documento se valida
+const documentPendingRetryDelay = 5 * time.Second
+
+// documentPendingError la evaluación de riesgo llegó antes que el resultado de
+// DOCUMENT_VALIDATION; se reprograma sin consumir un intento
+type documentPendingError struct {
+	applicationID uuid.UUID
+}
+
+func (e *documentPendingError) Error() string {
+	return fmt.Sprintf("document validation of application %s is still pending", e.applicationID)
+}
+
+func (e *documentPendingError) RetryAfter() time.Duration {
+	return documentPendingRetryDelay
+}
+
 // Worker representa un worker que procesa trabajos
 type Worker struct {
 	id       string
 	queue    *PostgresQueue
+	pool     *workerPool
 	stopChan chan struct{}
 	log      *logger.Logger
 }
 
 // NewPostgresQueue crea una nueva instancia de cola PostgreSQL
-func NewPostgresQueue(db *database.PostgresDB, log *logger.Logger) *PostgresQueue {
+func NewPostgresQueue(db *database.PostgresDB, banking service.BankingService, scoringEngine *scoring.Engine, validator service.RuleValidator, countries repository.CountryRepository, screener service.WatchlistScreener, log *logger.Logger) *PostgresQueue {
 	q := &PostgresQueue{
 		db:       db,
+		banking:  banking,
+		scoring:  scoringEngine,
+		rules:    validator,
+		country:  countries,
+		screen:   screener,
 		log:      log,
 		handlers: make(map[entity.JobType]JobHandler),
+		opts:     DefaultOptions(),
 	}
 	
 	// Registrar handlers por defecto
@@ -65,9 +124,12 @@ func (q *PostgresQueue) RegisterDefaultHandlers() {
 	q.RegisterHandler(entity.JobTypeNotification, q.handleNotification)
 	q.RegisterHandler(entity.JobTypeAuditLog, q.handleAuditLog)
 	q.RegisterHandler(entity.JobTypeWebhookCall, q.handleWebhookCall)
+	q.RegisterHandler(entity.JobTypeBankingCallbackTimeout, q.handleBankingCallbackTimeout)
+	q.RegisterHandler(entity.JobTypeFraudCheck, q.handleFraudCheck)
 }
 
-// Enqueue agrega un trabajo a la cola
+// Enqueue agrega un trabajo a la cola. Es idempotente por ID: si ya existe un
+// trabajo con el mismo ID no se inserta otro
 func (q *PostgresQueue) Enqueue(ctx context.Context, job *entity.Job) error {
 	if job.ID == uuid.Nil {
 		job.ID = uuid.New()
@@ -76,7 +138,10 @@ func (q *PostgresQueue) Enqueue(ctx context.Context, job *entity.Job) error {
 		job.MaxAttempts = 3
 	}
 	job.Status = entity.JobStatusPending
-	job.ScheduledAt = time.Now()
+	// Respetar una programación futura (EnqueueWithDelay)
+	if job.ScheduledAt.Before(time.Now()) {
+		job.ScheduledAt = time.Now()
+	}
 	job.CreatedAt = time.Now()
 	job.UpdatedAt = time.Now()
 
@@ -96,13 +161,21 @@ func (q *PostgresQueue) Enqueue(ctx context.Context, job *entity.Job) error {
 	query := `
 		INSERT INTO jobs_queue (id, type, status, priority, payload, max_attempts, scheduled_at, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5::jsonb, $6, $7, $8, $9)
+		ON CONFLICT (id) DO NOTHING
 	`
 	
-	err := q.db.Exec(ctx, query, job.ID, job.Type, job.Status, job.Priority, payloadStr, job.MaxAttempts, job.ScheduledAt, job.CreatedAt, job.UpdatedAt)
+	tag, err := q.db.Pool.Exec(ctx, query, job.ID, job.Type, job.Status, job.Priority, payloadStr, job.MaxAttempts, job.ScheduledAt, job.CreatedAt, job.UpdatedAt)
 	if err != nil {
 		q.log.Error().Err(err).Str("payload", payloadStr).Msg("Failed to insert job")
 		return fmt.Errorf("failed to enqueue job: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		q.log.Info().
+			Str("job_id", job.ID.String()).
+			Str("type", string(job.Type)).
+			Msg("Job already enqueued")
+		return nil
+	}
 
 	q.log.Info().
 		Str("job_id", job.ID.String()).
@@ -120,11 +193,17 @@ func (q *PostgresQueue) EnqueueWithDelay(ctx context.Context, job *entity.Job, d
 
 // Dequeue obtiene y reserva el siguiente trabajo pendiente
 func (q *PostgresQueue) Dequeue(ctx context.Context, workerID string) (*entity.Job, error) {
-	// Usar transacción con bloqueo para evitar condiciones de carrera
-	query := `
+	return q.dequeue(ctx, workerID, nil, uuid.New())
+}
+
+// dequeueQuery reserva el siguiente trabajo listo de los tipos $2 (vacío = todos) salvo los de $3
+// con un lease de $4 milisegundos identificado por el token $5
+const dequeueQuery = `
 		UPDATE jobs_queue
 		SET status = 'PROCESSING', 
 			started_at = NOW(),
+			lease_expires_at = NOW() + INTERVAL '1 millisecond' * $4,
+			lease_token = $5,
 			worker_id = $1,
 			attempts = attempts + 1,
 			updated_at = NOW()
@@ -132,6 +211,8 @@ func (q *PostgresQueue) Dequeue(ctx context.Context, workerID string) (*entity.J
 			SELECT id FROM jobs_queue
 			WHERE status IN ('PENDING', 'RETRYING')
 			AND scheduled_at <= NOW()
+			AND (cardinality($2::text[]) = 0 OR type = ANY($2::text[]))
+			AND type <> ALL($3::text[])
 			ORDER BY priority DESC, scheduled_at ASC
 			FOR UPDATE SKIP LOCKED
 			LIMIT 1
@@ -139,12 +220,62 @@ func (q *PostgresQueue) Dequeue(ctx context.Context, workerID string) (*entity.J
 		RETURNING id, type, status, priority, payload, result, error_message, attempts, max_attempts, scheduled_at, started_at, completed_at, created_at, updated_at
 	`
 
+// inFlightLockKey bloqueo que serializa los dequeue cuando hay límites de trabajos en PROCESSING
+const inFlightLockKey = "jobs_queue_in_flight"
+
+// dequeue reserva el siguiente trabajo que atiende el pool (nil = cualquier tipo)
+// respetando los máximos en PROCESSING por tipo. leaseToken identifica esta reserva
+// para el heartbeat
+func (q *PostgresQueue) dequeue(ctx context.Context, workerID string, pool *workerPool, leaseToken uuid.UUID) (*entity.Job, error) {
+	types, exclude := []string{}, []string{}
+	if pool != nil {
+		types = append(types, pool.types...)
+		exclude = append(exclude, pool.exclude...)
+	}
+
+	var job *entity.Job
+	var err error
+	if len(q.opts.MaxInFlight) == 0 {
+		job, err = scanDequeuedJob(q.db.QueryRow(ctx, dequeueQuery, workerID, types, exclude, q.leaseMillis(), leaseToken))
+	} else {
+		err = q.db.WithTx(ctx, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", inFlightLockKey); err != nil {
+				return fmt.Errorf("failed to lock in-flight limits: %w", err)
+			}
+			capped, err := q.cappedTypes(ctx, tx)
+			if err != nil {
+				return err
+			}
+			job, err = scanDequeuedJob(tx.QueryRow(ctx, dequeueQuery, workerID, types, append(exclude, capped...), q.leaseMillis(), leaseToken))
+			return err
+		})
+	}
+
+	if err != nil {
+		// pgx devuelve ErrNoRows cuando no hay filas, lo cual es normal
+		// Para otros errores, logearlos
+		if !errors.Is(err, pgx.ErrNoRows) {
+			q.log.Error().Err(err).Str("worker_id", workerID).Msg("Error dequeuing job")
+		}
+		return nil, nil // No hay trabajos disponibles
+	}
+
+	q.log.Debug().
+		Str("job_id", job.ID.String()).
+		Str("type", string(job.Type)).
+		Str("worker_id", workerID).
+		Msg("Job dequeued successfully")
+
+	return job, nil
+}
+
+// scanDequeuedJob lee el trabajo devuelto por dequeueQuery
+func scanDequeuedJob(row pgx.Row) (*entity.Job, error) {
 	var job entity.Job
 	var payloadJSON, resultJSON []byte
 	var errorMessage *string
 	var startedAt, completedAt *time.Time
 
-	row := q.db.QueryRow(ctx, query, workerID)
 	err := row.Scan(
 		&job.ID, &job.Type, &job.Status, &job.Priority,
 		&payloadJSON, &resultJSON, &errorMessage,
@@ -152,14 +283,8 @@ func (q *PostgresQueue) Dequeue(ctx context.Context, workerID string) (*entity.J
 		&job.ScheduledAt, &startedAt, &completedAt,
 		&job.CreatedAt, &job.UpdatedAt,
 	)
-
 	if err != nil {
-		// pgx devuelve ErrNoRows cuando no hay filas, lo cual es normal
-		// Para otros errores, logearlos
-		if err.Error() != "no rows in result set" {
-			q.log.Error().Err(err).Str("worker_id", workerID).Msg("Error dequeuing job")
-		}
-		return nil, nil // No hay trabajos disponibles
+		return nil, err
 	}
 
 	job.Payload = payloadJSON
@@ -173,18 +298,17 @@ func (q *PostgresQueue) Dequeue(ctx context.Context, workerID string) (*entity.J
 	if completedAt != nil {
 		job.CompletedAt = completedAt
 	}
-
-	q.log.Debug().
-		Str("job_id", job.ID.String()).
-		Str("type", string(job.Type)).
-		Str("worker_id", workerID).
-		Msg("Job dequeued successfully")
-
 	return &job, nil
 }
 
 // Complete marca un trabajo como completado
 func (q *PostgresQueue) Complete(ctx context.Context, jobID uuid.UUID, result []byte) error {
+	return q.complete(ctx, jobID, nil, result)
+}
+
+// complete marca el trabajo como completado; con leaseToken solo si sigue reservado
+// por esa ejecución (si no, devuelve errLeaseLost)
+func (q *PostgresQueue) complete(ctx context.Context, jobID uuid.UUID, leaseToken *uuid.UUID, result []byte) error {
 	query := `
 		UPDATE jobs_queue
 		SET status = 'COMPLETED', 
@@ -192,12 +316,26 @@ func (q *PostgresQueue) Complete(ctx context.Context, jobID uuid.UUID, result []
 			completed_at = NOW(),
 			updated_at = NOW()
 		WHERE id = $1
+			AND ($3::uuid IS NULL OR (lease_token = $3 AND status = 'PROCESSING'))
 	`
-	return q.db.Exec(ctx, query, jobID, result)
+	tag, err := q.db.Pool.Exec(ctx, query, jobID, result, leaseToken)
+	if err != nil {
+		return err
+	}
+	if leaseToken != nil && tag.RowsAffected() == 0 {
+		return errLeaseLost
+	}
+	return nil
 }
 
 // Fail marca un trabajo como fallido o lo reencola
 func (q *PostgresQueue) Fail(ctx context.Context, jobID uuid.UUID, errorMsg string) error {
+	return q.fail(ctx, jobID, nil, errorMsg)
+}
+
+// fail marca el trabajo como fallido o lo reencola; con leaseToken solo si sigue
+// reservado por esa ejecución (si no, devuelve errLeaseLost)
+func (q *PostgresQueue) fail(ctx context.Context, jobID uuid.UUID, leaseToken *uuid.UUID, errorMsg string) error {
 	// Verificar si debe reintentar
 	var attempts, maxAttempts int
 	row := q.db.QueryRow(ctx, "SELECT attempts, max_attempts FROM jobs_queue WHERE id = $1", jobID)
@@ -226,8 +364,75 @@ func (q *PostgresQueue) Fail(ctx context.Context, jobID uuid.UUID, errorMsg stri
 			completed_at = CASE WHEN $2 = 'FAILED' THEN NOW() ELSE NULL END,
 			updated_at = NOW()
 		WHERE id = $1
+			AND ($5::uuid IS NULL OR (lease_token = $5 AND status = 'PROCESSING'))
+	`
+	tag, err := q.db.Pool.Exec(ctx, query, jobID, status, errorMsg, scheduledAt, leaseToken)
+	if err != nil {
+		return err
+	}
+	if leaseToken != nil && tag.RowsAffected() == 0 {
+		return errLeaseLost
+	}
+
+	// Historial: el error de cada intento queda aunque el siguiente lo sobrescriba
+	event := JobEventRetrying
+	if status == entity.JobStatusFailed {
+		event = JobEventFailed
+	}
+	if err := recordJobEvent(ctx, q.db.Pool, jobID, event, &attempts, errorMsg, nil, nil); err != nil {
+		q.log.Warn().Err(err).Str("job_id", jobID.String()).Msg("Failed to record job event")
+	}
+	return nil
+}
+
+// Retry reencola un trabajo para reintento inmediato, reiniciando sus intentos
+func (q *PostgresQueue) Retry(ctx context.Context, jobID uuid.UUID) error {
+	query := `
+		UPDATE jobs_queue
+		SET status = 'PENDING',
+			attempts = 0,
+			scheduled_at = NOW(),
+			started_at = NULL,
+			completed_at = NULL,
+			worker_id = NULL,
+			updated_at = NOW()
+		WHERE id = $1 AND status IN ('FAILED', 'RETRYING', 'CANCELLED')
 	`
-	return q.db.Exec(ctx, query, jobID, status, errorMsg, scheduledAt)
+	return q.db.Exec(ctx, query, jobID)
+}
+
+// Reschedule devuelve un trabajo a la cola sin consumir un intento
+// Se usa cuando el handler no pudo ejecutarse por limitaciones externas (ej. rate limit)
+func (q *PostgresQueue) Reschedule(ctx context.Context, jobID uuid.UUID, delay time.Duration, reason string) error {
+	return q.reschedule(ctx, jobID, nil, delay, reason)
+}
+
+// reschedule devuelve el trabajo a la cola; con leaseToken solo si sigue reservado
+// por esa ejecución (si no, devuelve errLeaseLost)
+func (q *PostgresQueue) reschedule(ctx context.Context, jobID uuid.UUID, leaseToken *uuid.UUID, delay time.Duration, reason string) error {
+	query := `
+		UPDATE jobs_queue
+		SET status = 'PENDING',
+			attempts = GREATEST(attempts - 1, 0),
+			error_message = $2,
+			scheduled_at = $3,
+			started_at = NULL,
+			worker_id = NULL,
+			updated_at = NOW()
+		WHERE id = $1
+			AND ($4::uuid IS NULL OR (lease_token = $4 AND status = 'PROCESSING'))
+	`
+	tag, err := q.db.Pool.Exec(ctx, query, jobID, reason, time.Now().Add(delay), leaseToken)
+	if err != nil {
+		return err
+	}
+	if leaseToken != nil && tag.RowsAffected() == 0 {
+		return errLeaseLost
+	}
+	if err := recordJobEvent(ctx, q.db.Pool, jobID, JobEventThrottled, nil, reason, nil, nil); err != nil {
+		q.log.Warn().Err(err).Str("job_id", jobID.String()).Msg("Failed to record job event")
+	}
+	return nil
 }
 
 // Stats obtiene estadísticas de la cola
@@ -324,60 +529,56 @@ func (q *PostgresQueue) GetFailedJobs(ctx context.Context, limit int) ([]RecentJ
 	return jobs, nil
 }
 
-// RecoverOrphanedJobs recupera jobs que quedaron en PROCESSING por más de X minutos
+// RecoverOrphanedJobs recupera los jobs PROCESSING cuyo lease venció (el worker dejó de
+// renovarlo). Los reservados sin lease, por workers anteriores a la migración 021, se
+// recuperan tras staleMinutes en PROCESSING
 func (q *PostgresQueue) RecoverOrphanedJobs(ctx context.Context, staleMinutes int) (int64, error) {
+	// Las ejecuciones que quedaron en RUNNING se cierran como ABANDONED
 	query := `
-		UPDATE jobs_queue 
-		SET status = 'PENDING', 
-			worker_id = NULL, 
-			started_at = NULL
-		WHERE status = 'PROCESSING' 
-		AND started_at < NOW() - INTERVAL '1 minute' * $1
+		WITH recovered AS (
+			UPDATE jobs_queue 
+			SET status = 'PENDING', 
+				worker_id = NULL, 
+				started_at = NULL,
+				lease_expires_at = NULL,
+				lease_token = NULL
+			WHERE status = 'PROCESSING' 
+			AND (lease_expires_at < NOW()
+				OR (lease_expires_at IS NULL AND started_at < NOW() - INTERVAL '1 minute' * $1))
+			RETURNING id
+		), abandoned AS (
+			UPDATE job_attempts a
+			SET outcome = $2,
+				error = 'job lease expired before the worker finished; job recovered as orphan',
+				finished_at = NOW(),
+				duration_ms = (EXTRACT(EPOCH FROM NOW() - a.started_at) * 1000)::bigint
+			FROM recovered r
+			WHERE a.job_id = r.id AND a.outcome = 'RUNNING'
+			RETURNING a.id
+		)
+		SELECT COUNT(*) FROM recovered
 	`
-	result, err := q.db.Pool.Exec(ctx, query, staleMinutes)
-	if err != nil {
+	var count int64
+	if err := q.db.QueryRow(ctx, query, staleMinutes, AttemptAbandoned).Scan(&count); err != nil {
 		return 0, err
 	}
 
-	count := result.RowsAffected()
 	if count > 0 {
-		q.log.Warn().Int64("count", count).Int("stale_minutes", staleMinutes).Msg("Recovered orphaned jobs")
+		q.log.Warn().Int64("count", count).Msg("Recovered orphaned jobs with expired lease")
 	}
 	return count, nil
 }
 
-// StartWorkers inicia los workers de procesamiento
+// StartWorkers inicia un pool general de workers para todos los tipos de trabajo
 func (q *PostgresQueue) StartWorkers(ctx context.Context, count int) {
-	q.mu.Lock()
-	defer q.mu.Unlock()
-
-	// Recuperar jobs huérfanos al inicio (más de 5 minutos en PROCESSING)
-	if recovered, err := q.RecoverOrphanedJobs(ctx, 5); err != nil {
-		q.log.Error().Err(err).Msg("Failed to recover orphaned jobs")
-	} else if recovered > 0 {
-		q.log.Info().Int64("recovered", recovered).Msg("Recovered orphaned jobs at startup")
+	if err := q.StartPools(ctx, []Pool{{Workers: count}}); err != nil {
+		q.log.Error().Err(err).Msg("Failed to start workers")
 	}
-
-	for i := 0; i < count; i++ {
-		worker := &Worker{
-			id:       fmt.Sprintf("worker-%d", i+1),
-			queue:    q,
-			stopChan: make(chan struct{}),
-			log:      q.log.WithWorkerID(fmt.Sprintf("worker-%d", i+1)),
-		}
-		q.workers = append(q.workers, worker)
-		go worker.Start(ctx)
-	}
-
-	// Iniciar goroutine para recuperar jobs huérfanos periódicamente
-	go q.orphanRecoveryLoop(ctx)
-
-	q.log.Info().Int("count", count).Msg("Workers started")
 }
 
-// orphanRecoveryLoop verifica periódicamente si hay jobs huérfanos
+// orphanRecoveryLoop verifica periódicamente si hay jobs huérfanos, una vez por lease
 func (q *PostgresQueue) orphanRecoveryLoop(ctx context.Context) {
-	ticker := time.NewTicker(2 * time.Minute)
+	ticker := time.NewTicker(q.opts.LeaseDuration)
 	defer ticker.Stop()
 
 	for {
@@ -385,7 +586,7 @@ func (q *PostgresQueue) orphanRecoveryLoop(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			if _, err := q.RecoverOrphanedJobs(ctx, 5); err != nil {
+			if _, err := q.RecoverOrphanedJobs(ctx, legacyStaleMinutes); err != nil {
 				q.log.Error().Err(err).Msg("Failed to recover orphaned jobs in loop")
 			}
 		}
@@ -397,17 +598,31 @@ func (q *PostgresQueue) StopWorkers() {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
+	if q.stopListen != nil {
+		q.stopListen()
+		q.stopListen = nil
+	}
+	q.delayedMu.Lock()
+	if q.delayed != nil {
+		q.delayed.Stop()
+	}
+	q.delayedMu.Unlock()
 	for _, worker := range q.workers {
 		close(worker.stopChan)
 	}
 	q.workers = nil
+	q.pools = nil
 }
 
 // Start inicia el worker
+// Con el listener conectado espera los avisos de LISTEN/NOTIFY y solo sondea cada
+// FallbackPollInterval; sin él sondea cada PollInterval
 func (w *Worker) Start(ctx context.Context) {
 	w.log.Info().Msg("Worker started")
-	ticker := time.NewTicker(1 * time.Second)
+	opts := w.queue.opts
+	ticker := time.NewTicker(opts.PollInterval)
 	defer ticker.Stop()
+	lastPoll := time.Now()
 
 	for {
 		select {
@@ -417,13 +632,33 @@ func (w *Worker) Start(ctx context.Context) {
 		case <-w.stopChan:
 			w.log.Info().Msg("Worker stopped")
 			return
+		case <-w.pool.wake:
 		case <-ticker.C:
-			w.processNextJob(ctx)
+			if w.queue.listening.Load() && time.Since(lastPoll) < opts.FallbackPollInterval {
+				continue
+			}
+		}
+		lastPoll = time.Now()
+		w.drain(ctx)
+	}
+}
+
+// drain procesa trabajos hasta que no quede ninguno listo
+func (w *Worker) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		select {
+		case <-w.stopChan:
+			return
+		default:
+		}
+		if !w.processNextJob(ctx) {
+			return
 		}
 	}
 }
 
-func (w *Worker) processNextJob(ctx context.Context) {
+// processNextJob procesa el siguiente trabajo; devuelve false si no había ninguno listo
+func (w *Worker) processNextJob(ctx context.Context) (processed bool) {
 	// Recuperar de cualquier panic para evitar que el worker muera
 	defer func() {
 		if r := recover(); r != nil {
@@ -431,13 +666,20 @@ func (w *Worker) processNextJob(ctx context.Context) {
 		}
 	}()
 
-	job, err := w.queue.Dequeue(ctx, w.id)
+	leaseToken := uuid.New()
+	job, err := w.queue.dequeue(ctx, w.id, w.pool, leaseToken)
 	if err != nil {
 		w.log.Error().Err(err).Msg("Failed to dequeue job")
-		return
+		return false
 	}
 	if job == nil {
-		return // No hay trabajos disponibles
+		return false // No hay trabajos disponibles
+	}
+	processed = true
+
+	// Con límite de trabajos en PROCESSING, al terminar se avisa a quien espere un hueco del tipo
+	if _, limited := w.queue.opts.MaxInFlight[job.Type]; limited {
+		defer w.queue.wakeType(job.Type)
 	}
 
 	w.log.Info().
@@ -446,44 +688,110 @@ func (w *Worker) processNextJob(ctx context.Context) {
 		Int("attempt", job.Attempts).
 		Msg("Processing job - starting")
 
+	// Registrar la ejecución en job_attempts
+	attemptID := w.queue.startAttempt(ctx, job, w.id)
+
 	// Buscar handler
 	handler, exists := w.queue.handlers[job.Type]
 	if !exists {
 		w.log.Error().Str("type", string(job.Type)).Msg("No handler for job type")
-		if err := w.queue.Fail(ctx, job.ID, "no handler for job type"); err != nil {
+		err := w.queue.fail(ctx, job.ID, &leaseToken, "no handler for job type")
+		if errors.Is(err, errLeaseLost) {
+			w.abandon(ctx, job, attemptID)
+			return
+		}
+		if err != nil {
 			w.log.Error().Err(err).Msg("Failed to mark job as failed")
 		}
+		w.queue.finishAttempt(ctx, attemptID, AttemptFailed, errors.New("no handler for job type"))
 		return
 	}
 
-	// Ejecutar handler con timeout
-	jobCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	// Ejecutar handler con el timeout de su tipo; el heartbeat renueva el lease mientras
+	// tanto y cancela el handler si otro proceso recuperó el trabajo
+	timeout := w.queue.jobTimeout(job.Type)
+	jobCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
+	lease := w.queue.keepLease(ctx, job.ID, leaseToken, cancel)
 
-	w.log.Debug().Str("job_id", job.ID.String()).Msg("Executing handler")
+	w.log.Debug().Str("job_id", job.ID.String()).Dur("timeout", timeout).Msg("Executing handler")
 
-	handlerErr := handler(jobCtx, job)
+	// Un pánico del handler cuenta como intento fallido (con stack) en vez de matar el worker
+	handlerErr := runHandler(jobCtx, handler, job)
 
 	w.log.Debug().
 		Str("job_id", job.ID.String()).
 		Bool("handler_success", handlerErr == nil).
 		Msg("Handler execution finished")
 
+	// Si el lease se perdió el trabajo ya es de otro worker: no se toca su estado.
+	// Un error al renovar no prueba que siga siendo nuestro; lo deciden las
+	// actualizaciones de cierre, que solo se aplican con el token de esta reserva
+	if lease.release() {
+		w.abandon(ctx, job, attemptID)
+		return
+	}
+
+	// Un timeout queda como error explícito del intento aunque el handler devuelva otro error
+	timedOut := errors.Is(jobCtx.Err(), context.DeadlineExceeded)
+	if handlerErr != nil && timedOut {
+		handlerErr = fmt.Errorf("job timed out after %s: %w", timeout, handlerErr)
+	}
+
+	var throttled retryLater
+	if handlerErr != nil && errors.As(handlerErr, &throttled) {
+		w.log.Warn().
+			Err(handlerErr).
+			Str("job_id", job.ID.String()).
+			Str("type", string(job.Type)).
+			Dur("retry_after", throttled.RetryAfter()).
+			Msg("Job throttled, rescheduling without consuming an attempt")
+		err := w.queue.reschedule(ctx, job.ID, &leaseToken, throttled.RetryAfter(), handlerErr.Error())
+		if errors.Is(err, errLeaseLost) {
+			w.abandon(ctx, job, attemptID)
+			return
+		}
+		if err != nil {
+			w.log.Error().Err(err).Str("job_id", job.ID.String()).Msg("Failed to reschedule job")
+		}
+		w.queue.finishAttempt(ctx, attemptID, AttemptThrottled, handlerErr)
+		return
+	}
+
 	if handlerErr != nil {
+		outcome := AttemptFailed
+		var panicked *panicError
+		if errors.As(handlerErr, &panicked) {
+			outcome = AttemptPanicked
+		} else if timedOut {
+			outcome = AttemptTimedOut
+		}
 		w.log.Error().
 			Err(handlerErr).
 			Str("job_id", job.ID.String()).
 			Str("type", string(job.Type)).
+			Str("outcome", outcome).
 			Msg("Job handler returned error")
-		if err := w.queue.Fail(ctx, job.ID, handlerErr.Error()); err != nil {
+		err := w.queue.fail(ctx, job.ID, &leaseToken, handlerErr.Error())
+		if errors.Is(err, errLeaseLost) {
+			w.abandon(ctx, job, attemptID)
+			return
+		}
+		if err != nil {
 			w.log.Error().Err(err).Str("job_id", job.ID.String()).Msg("Failed to mark job as failed")
 		}
+		w.queue.finishAttempt(ctx, attemptID, outcome, handlerErr)
 		return
 	}
 
 	// Marcar como completado
 	w.log.Debug().Str("job_id", job.ID.String()).Msg("Marking job as completed")
-	if err := w.queue.Complete(ctx, job.ID, nil); err != nil {
+	err = w.queue.complete(ctx, job.ID, &leaseToken, nil)
+	if errors.Is(err, errLeaseLost) {
+		w.abandon(ctx, job, attemptID)
+		return
+	}
+	if err != nil {
 		w.log.Error().
 			Err(err).
 			Str("job_id", job.ID.String()).
@@ -494,6 +802,18 @@ func (w *Worker) processNextJob(ctx context.Context) {
 			Str("type", string(job.Type)).
 			Msg("Job completed successfully")
 	}
+	w.queue.finishAttempt(ctx, attemptID, AttemptSucceeded, nil)
+	return
+}
+
+// abandon cierra el intento de un trabajo cuyo lease se perdió: otro worker lo
+// recuperó y el resultado de esta ejecución se descarta
+func (w *Worker) abandon(ctx context.Context, job *entity.Job, attemptID int64) {
+	w.log.Error().
+		Str("job_id", job.ID.String()).
+		Str("type", string(job.Type)).
+		Msg("Job lease lost while running, discarding result")
+	w.queue.finishAttempt(ctx, attemptID, AttemptAbandoned, errLeaseLost)
 }
 
 // Handlers por defecto - Implementaciones reales
@@ -520,22 +840,25 @@ func (q *PostgresQueue) handleRiskEvaluation(ctx context.Context, job *entity.Jo
 		return fmt.Errorf("invalid application ID: %w", err)
 	}
 
-	// Obtener solicitud junto con la configuración del país
+	// Obtener solicitud junto con la configuración del país vigente en la fecha de la solicitud
 	var app entity.CreditApplication
 	var countryConfig []byte
-	var countryCurrency string
+	var countryCode, countryCurrency string
+	var velocityExceeded bool
+	var fraudScore *float64
 	query := `
 		SELECT ca.id, ca.country_id, ca.full_name, ca.document_type, ca.document_number, 
-		       ca.requested_amount, ca.monthly_income, ca.status,
-		       c.config, c.currency
+		       ca.requested_amount, ca.monthly_income, ca.status, ca.document_valid, ca.application_date,
+		       COALESCE(jsonb_array_length(ca.velocity_check->'exceeded'), 0) > 0, ca.fraud_score,
+		       c.code, country_config_at(c.id, ca.application_date), c.currency
 		FROM credit_applications ca
 		JOIN countries c ON c.id = ca.country_id
 		WHERE ca.id = $1
 	`
 	row := q.db.QueryRow(ctx, query, appID)
 	if err := row.Scan(&app.ID, &app.CountryID, &app.FullName, &app.DocumentType,
-		&app.DocumentNumber, &app.RequestedAmount, &app.MonthlyIncome, &app.Status,
-		&countryConfig, &countryCurrency); err != nil {
+		&app.DocumentNumber, &app.RequestedAmount, &app.MonthlyIncome, &app.Status, &app.DocumentValid, &app.ApplicationDate,
+		&velocityExceeded, &fraudScore, &countryCode, &countryConfig, &countryCurrency); err != nil {
 		q.log.Error().Err(err).Str("application_id", appID.String()).Msg("Failed to get application with country config")
 		return fmt.Errorf("failed to get application: %w", err)
 	}
@@ -556,6 +879,23 @@ func (q *PostgresQueue) handleRiskEvaluation(ctx context.Context, job *entity.Jo
 		return nil // No es un error, simplemente ya fue procesada
 	}
 
+	// La validación del documento corre en paralelo con la consulta bancaria: si
+	// sigue en cola la evaluación espera a su resultado sin consumir un intento.
+	// Sin trabajo pendiente (p. ej. terminó en dead letter) el documento queda sin
+	// validar y la solicitud va a revisión manual
+	if app.DocumentValid == nil {
+		pending, err := q.documentValidationPending(ctx, appID)
+		if err != nil {
+			return err
+		}
+		if pending {
+			return &documentPendingError{applicationID: appID}
+		}
+		q.log.Warn().
+			Str("application_id", appID.String()).
+			Msg("Document was never validated - forcing manual review")
+	}
+
 	// Parsear configuración del país
 	var config countryRiskConfig
 	if err := json.Unmarshal(countryConfig, &config); err != nil {
@@ -586,59 +926,146 @@ func (q *PostgresQueue) handleRiskEvaluation(ctx context.Context, job *entity.Jo
 		&bankingInfo.AvailableCredit, &bankingInfo.PaymentHistory,
 		&bankingInfo.BankAccounts, &bankingInfo.ActiveLoans, &bankingInfo.MonthsEmployed); err == nil {
 		app.BankingInfo = &bankingInfo
-		q.log.Debug().
-			Int("credit_score", *bankingInfo.CreditScore).
-			Float64("total_debt", *bankingInfo.TotalDebt).
-			Msg("Banking info found for application")
+		// El mapeo del proveedor omite los campos que no encuentra: pueden venir nulos
+		logEvent := q.log.Debug()
+		if bankingInfo.CreditScore != nil {
+			logEvent = logEvent.Int("credit_score", *bankingInfo.CreditScore)
+		}
+		if bankingInfo.TotalDebt != nil {
+			logEvent = logEvent.Float64("total_debt", *bankingInfo.TotalDebt)
+		}
+		logEvent.Msg("Banking info found for application")
 	} else {
 		q.log.Warn().Err(err).Str("application_id", appID.String()).Msg("No banking info found - proceeding without it")
 	}
 
-	// Calcular score de riesgo usando la configuración del país (0-100, donde 100 es bajo riesgo)
-	riskScore := q.calculateRiskScoreWithConfig(&app, &config)
+	// Evaluar las reglas del país por prioridad; un fallo puede rechazar directamente o forzar revisión
+	app.Country = &entity.Country{ID: app.CountryID, Code: countryCode, Currency: countryCurrency}
+	validationResults, err := q.validateCountryRules(ctx, &app)
+	if err != nil {
+		return err
+	}
+	validationJSON, err := json.Marshal(validationResults)
+	if err != nil {
+		return fmt.Errorf("failed to marshal validation results: %w", err)
+	}
+	rejectedBy, reviewRules := validation.ClassifyResults(validationResults)
+	if rejectedBy != nil {
+		return q.rejectByRule(ctx, &app, rejectedBy, string(validationJSON))
+	}
+	// Un documento inválido configurado para revisión, o sin validar, mantiene la solicitud en revisión manual
+	if app.DocumentValid == nil || !*app.DocumentValid {
+		reviewRules = append(reviewRules, string(entity.JobTypeDocumentValidation))
+	}
+	// Superar un límite de velocidad configurado para revisión también la fuerza
+	if velocityExceeded {
+		reviewRules = append(reviewRules, "VELOCITY")
+	}
+	// Y un score de fraude que alcanza el umbral del país
+	if config.Fraud != nil && config.Fraud.ReviewThreshold > 0 && fraudScore != nil && *fraudScore >= config.Fraud.ReviewThreshold {
+		reviewRules = append(reviewRules, string(entity.JobTypeFraudCheck))
+	}
+	// Un posible hit en listas de sanciones o PEP impide la aprobación automática
+	if q.screen != nil {
+		screening, err := q.screen.Screen(ctx, &app)
+		if err != nil {
+			return fmt.Errorf("failed to screen applicant against watchlists: %w", err)
+		}
+		if screening != entity.ScreeningClear {
+			reviewRules = append(reviewRules, "WATCHLIST")
+		}
+	}
+
+	// Evaluar con el modelo de scoring activo del país (0-100, donde 100 es bajo riesgo)
+	model, err := q.scoring.ModelForCountry(ctx, app.CountryID)
+	if err != nil {
+		return fmt.Errorf("failed to load scoring model: %w", err)
+	}
+	inputs := scoring.BuildInputs(&app, scoring.CountryParams{
+		MaxDebtToIncomeRatio: config.MaxDebtToIncomeRatio,
+		MinCreditScore:       config.MinCreditScore,
+	})
+	result := model.Evaluate(inputs)
+
+	// Evaluar en sombra el challenger del país; decide solo sobre su porcentaje de tráfico
+	shadow, result := q.evaluateChallenger(ctx, &app, inputs, result)
+	riskScore := result.Score
 
-	// Determinar resultado basado en el score y configuración del país
-	var newStatus entity.ApplicationStatus
+	// Determinar resultado basado en la decisión del modelo y la configuración del país
 	var statusReason string
-	requiresReview := false
 
 	// Verificar si el monto supera el umbral de revisión del país
-	if app.RequestedAmount >= config.ReviewThreshold {
-		requiresReview = true
+	requiresReview := app.RequestedAmount >= config.ReviewThreshold
+	reasonCodes := result.ReasonCodes
+	if requiresReview {
+		reasonCodes = append([]entity.ReasonCode{scoring.NewReasonCode(scoring.ReasonAmountReviewPolicy, "", 0)}, reasonCodes...)
 	}
 
-	// Verificar score crediticio mínimo del país
-	if app.BankingInfo != nil && app.BankingInfo.CreditScore != nil {
-		if *app.BankingInfo.CreditScore < config.MinCreditScore {
-			riskScore -= 20 // Penalizar si no cumple el mínimo del país
-		}
-	}
+	// Las reglas del país que piden revisión la fuerzan aunque el modelo apruebe o rechace
+	ruleReview := len(reviewRules) > 0
 
-	if riskScore >= 70 && !requiresReview {
-		newStatus = entity.StatusApproved
-		statusReason = fmt.Sprintf("Auto-approved with risk score %.0f (currency: %s)", riskScore, countryCurrency)
-	} else if riskScore >= 40 || requiresReview {
-		newStatus = entity.StatusUnderReview
+	newStatus := scoring.Outcome(result.Decision, requiresReview || ruleReview)
+	switch newStatus {
+	case entity.StatusApproved:
+		statusReason = fmt.Sprintf("Auto-approved with risk score %.0f (model v%d, currency: %s)", riskScore, result.ModelVersion, countryCurrency)
+	case entity.StatusUnderReview:
 		if requiresReview {
-			statusReason = fmt.Sprintf("Manual review required - amount %.2f %s exceeds threshold %.2f %s (risk score: %.0f)",
-				app.RequestedAmount, countryCurrency, config.ReviewThreshold, countryCurrency, riskScore)
+			statusReason = fmt.Sprintf("Manual review required - amount %.2f %s exceeds threshold %.2f %s (risk score: %.0f, model v%d)",
+				app.RequestedAmount, countryCurrency, config.ReviewThreshold, countryCurrency, riskScore, result.ModelVersion)
+		} else if ruleReview {
+			statusReason = fmt.Sprintf("Manual review required by rules %s (risk score: %.0f, model v%d)",
+				strings.Join(reviewRules, ", "), riskScore, result.ModelVersion)
 		} else {
-			statusReason = fmt.Sprintf("Manual review required - risk score %.0f", riskScore)
+			statusReason = fmt.Sprintf("Manual review required - risk score %.0f (model v%d)", riskScore, result.ModelVersion)
 		}
 		requiresReview = true
-	} else {
-		newStatus = entity.StatusRejected
-		statusReason = fmt.Sprintf("Auto-rejected due to high risk - score %.0f (min credit score required: %d)", riskScore, config.MinCreditScore)
+	default:
+		statusReason = fmt.Sprintf("Auto-rejected due to high risk - score %.0f (model v%d, min credit score required: %d)", riskScore, result.ModelVersion, config.MinCreditScore)
+		if len(reasonCodes) > 0 {
+			codes := make([]string, 0, len(reasonCodes))
+			for _, rc := range reasonCodes {
+				codes = append(codes, rc.Code)
+			}
+			statusReason += " - reasons: " + strings.Join(codes, ", ")
+		}
+	}
+
+	var modelID *uuid.UUID
+	if id, err := uuid.Parse(result.ModelID); err == nil {
+		modelID = &id
 	}
 
-	// Actualizar solicitud
+	// Guardar el desglose de la decisión para explicarla y auditarla
+	decision := &entity.RiskDecision{
+		ID:                  uuid.New(),
+		ApplicationID:       appID,
+		ScoringModelID:      modelID,
+		ScoringModelVersion: result.ModelVersion,
+		Score:               riskScore,
+		Decision:            result.Decision,
+		Outcome:             newStatus,
+		RequiresReview:      requiresReview,
+		Factors:             result.Factors,
+		ReasonCodes:         reasonCodes,
+	}
+	if err := q.saveRiskDecision(ctx, decision); err != nil {
+		return err
+	}
+	if shadow != nil {
+		if err := q.saveShadowResult(ctx, shadow); err != nil {
+			q.log.Error().Err(err).Str("application_id", appID.String()).Msg("Failed to save challenger shadow result")
+		}
+	}
+
+	// Actualizar solicitud con el modelo que produjo la decisión
 	updateQuery := `
 		UPDATE credit_applications 
 		SET status = $2, status_reason = $3, requires_review = $4, risk_score = $5,
+		    scoring_model_id = $6, scoring_model_version = $7, validation_results = $8::jsonb,
 		    processed_at = NOW(), updated_at = NOW()
 		WHERE id = $1
 	`
-	if err := q.db.Exec(ctx, updateQuery, appID, newStatus, statusReason, requiresReview, riskScore); err != nil {
+	if err := q.db.Exec(ctx, updateQuery, appID, newStatus, statusReason, requiresReview, riskScore, modelID, result.ModelVersion, string(validationJSON)); err != nil {
 		return fmt.Errorf("failed to update application: %w", err)
 	}
 
@@ -654,198 +1081,169 @@ func (q *PostgresQueue) handleRiskEvaluation(ctx context.Context, job *entity.Jo
 	q.log.Info().
 		Str("application_id", appID.String()).
 		Float64("risk_score", riskScore).
+		Int("reason_codes", len(reasonCodes)).
+		Int("scoring_model_version", result.ModelVersion).
 		Str("new_status", string(newStatus)).
 		Msg("Risk evaluation completed")
 
 	return nil
 }
 
-// calculateRiskScore calcula el score de riesgo de una solicitud
-func (q *PostgresQueue) calculateRiskScore(app *entity.CreditApplication) float64 {
-	score := 50.0 // Base score
-
-	// Factor 1: Relación monto/ingreso (peso: 25%)
-	if app.MonthlyIncome > 0 {
-		ratio := app.RequestedAmount / (app.MonthlyIncome * 12)
-		if ratio < 0.2 {
-			score += 25
-		} else if ratio < 0.4 {
-			score += 15
-		} else if ratio < 0.6 {
-			score += 5
-		} else if ratio > 0.8 {
-			score -= 15
-		}
+// validateCountryRules evalúa las reglas activas del país vigentes en la fecha de la solicitud, por prioridad
+func (q *PostgresQueue) validateCountryRules(ctx context.Context, app *entity.CreditApplication) ([]entity.ValidationResult, error) {
+	if q.rules == nil || q.country == nil {
+		return []entity.ValidationResult{}, nil
 	}
 
-	// Factor 2: Score crediticio (peso: 35%)
-	if app.BankingInfo != nil && app.BankingInfo.CreditScore != nil {
-		creditScore := *app.BankingInfo.CreditScore
-		if creditScore >= 750 {
-			score += 35
-		} else if creditScore >= 650 {
-			score += 20
-		} else if creditScore >= 550 {
-			score += 5
-		} else {
-			score -= 20
-		}
-	}
-
-	// Factor 3: Historial de pagos (peso: 20%)
-	if app.BankingInfo != nil && app.BankingInfo.PaymentHistory != nil {
-		switch *app.BankingInfo.PaymentHistory {
-		case "GOOD":
-			score += 20
-		case "REGULAR":
-			score += 5
-		case "BAD":
-			score -= 25
-		}
+	rules, err := q.country.GetRulesAt(ctx, app.CountryID, app.ApplicationDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load country rules: %w", err)
 	}
 
-	// Factor 4: Deuda existente (peso: 10%)
-	if app.BankingInfo != nil && app.BankingInfo.TotalDebt != nil {
-		debt := *app.BankingInfo.TotalDebt
-		if debt == 0 {
-			score += 10
-		} else if debt < 5000 {
-			score += 5
-		} else if debt > 20000 {
-			score -= 10
-		}
+	results, err := q.rules.ValidateApplication(ctx, app, rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate country rules: %w", err)
 	}
-
-	// Factor 5: Estabilidad laboral (peso: 10%)
-	if app.BankingInfo != nil && app.BankingInfo.MonthsEmployed != nil {
-		months := *app.BankingInfo.MonthsEmployed
-		if months >= 24 {
-			score += 10
-		} else if months >= 12 {
-			score += 5
-		} else if months < 6 {
-			score -= 5
-		}
+	if results == nil {
+		results = []entity.ValidationResult{}
 	}
 
-	// Limitar score entre 0 y 100
-	if score < 0 {
-		score = 0
-	} else if score > 100 {
-		score = 100
-	}
+	q.log.Debug().
+		Str("application_id", app.ID.String()).
+		Int("rules", len(rules)).
+		Msg("Country rules evaluated")
 
-	return score
+	return results, nil
 }
 
-// countryRiskConfig configuración de riesgo por país
-type countryRiskConfig struct {
-	MinLoanAmount        float64 `json:"min_loan_amount"`
-	MaxLoanAmount        float64 `json:"max_loan_amount"`
-	MinIncomeRequired    float64 `json:"min_income_required"`
-	MaxDebtToIncomeRatio float64 `json:"max_debt_to_income_ratio"`
-	ReviewThreshold      float64 `json:"review_threshold"`
-	MinCreditScore       int     `json:"min_credit_score"`
-}
+// rejectByRule rechaza la solicitud por una regla del país sin evaluar el scoring
+func (q *PostgresQueue) rejectByRule(ctx context.Context, app *entity.CreditApplication, rule *entity.ValidationResult, validationJSON string) error {
+	statusReason := fmt.Sprintf("Rejected by rule %s: %s", rule.RuleName, rule.Message)
 
-// calculateRiskScoreWithConfig calcula el score de riesgo usando la configuración del país
-func (q *PostgresQueue) calculateRiskScoreWithConfig(app *entity.CreditApplication, config *countryRiskConfig) float64 {
-	score := 50.0 // Base score
+	updateQuery := `
+		UPDATE credit_applications
+		SET status = $2, status_reason = $3, requires_review = false, validation_results = $4::jsonb,
+		    processed_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`
+	if err := q.db.Exec(ctx, updateQuery, app.ID, entity.StatusRejected, statusReason, validationJSON); err != nil {
+		return fmt.Errorf("failed to update application: %w", err)
+	}
 
-	// Factor 1: Relación monto/ingreso usando el ratio máximo del país (peso: 25%)
-	if app.MonthlyIncome > 0 {
-		// Calcular ratio de endeudamiento
-		annualIncome := app.MonthlyIncome * 12
-		requestedRatio := app.RequestedAmount / annualIncome
+	transitionQuery := `
+		INSERT INTO state_transitions (id, application_id, from_status, to_status, reason, triggered_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, 'SYSTEM', NOW())
+	`
+	if err := q.db.Exec(ctx, transitionQuery, uuid.New(), app.ID, app.Status, entity.StatusRejected, statusReason); err != nil {
+		q.log.Error().Err(err).Msg("Failed to save state transition")
+	}
 
-		// Usar el max_debt_to_income_ratio del país como referencia
-		maxRatio := config.MaxDebtToIncomeRatio
-		if maxRatio == 0 {
-			maxRatio = 0.4 // Default
-		}
+	q.log.Info().
+		Str("application_id", app.ID.String()).
+		Str("rule", rule.RuleName).
+		Msg("Application rejected by country rule")
 
-		if requestedRatio < maxRatio*0.5 { // Menos del 50% del máximo permitido
-			score += 25
-		} else if requestedRatio < maxRatio*0.75 { // Entre 50% y 75% del máximo
-			score += 15
-		} else if requestedRatio < maxRatio { // Entre 75% y 100% del máximo
-			score += 5
-		} else if requestedRatio > maxRatio*1.25 { // Supera el máximo por más del 25%
-			score -= 15
-		}
-	}
+	return nil
+}
 
-	// Factor 2: Score crediticio vs mínimo requerido del país (peso: 35%)
-	if app.BankingInfo != nil && app.BankingInfo.CreditScore != nil {
-		creditScore := *app.BankingInfo.CreditScore
-		minScore := config.MinCreditScore
-		if minScore == 0 {
-			minScore = 600 // Default
-		}
+// evaluateChallenger evalúa el challenger del país sobre las mismas entradas
+// Devuelve el resultado comparado (nil si no hay challenger) y el resultado que decide
+func (q *PostgresQueue) evaluateChallenger(ctx context.Context, app *entity.CreditApplication, inputs scoring.Inputs, champion *scoring.Result) (*entity.ScoringShadowResult, *scoring.Result) {
+	challenger, trafficPercent, err := q.scoring.ChallengerForCountry(ctx, app.CountryID)
+	if err != nil {
+		q.log.Warn().Err(err).Str("country_id", app.CountryID.String()).Msg("Failed to load challenger scoring model")
+		return nil, champion
+	}
+	if challenger == nil {
+		return nil, champion
+	}
 
-		// Comparar con el mínimo del país
-		if creditScore >= minScore+150 { // Muy por encima del mínimo
-			score += 35
-		} else if creditScore >= minScore+50 { // Por encima del mínimo
-			score += 20
-		} else if creditScore >= minScore { // Cumple el mínimo
-			score += 5
-		} else { // Por debajo del mínimo
-			score -= 20
-		}
+	challengerID, err := uuid.Parse(challenger.ID)
+	if err != nil {
+		return nil, champion
 	}
+	result := challenger.Evaluate(inputs)
 
-	// Factor 3: Historial de pagos (peso: 20%)
-	if app.BankingInfo != nil && app.BankingInfo.PaymentHistory != nil {
-		switch *app.BankingInfo.PaymentHistory {
-		case "GOOD":
-			score += 20
-		case "REGULAR":
-			score += 5
-		case "BAD":
-			score -= 25
-		}
+	shadow := &entity.ScoringShadowResult{
+		ID:                 uuid.New(),
+		ApplicationID:      app.ID,
+		CountryID:          app.CountryID,
+		ChampionVersion:    champion.ModelVersion,
+		ChampionScore:      champion.Score,
+		ChampionDecision:   champion.Decision,
+		ChallengerModelID:  challengerID,
+		ChallengerVersion:  result.ModelVersion,
+		ChallengerScore:    result.Score,
+		ChallengerDecision: result.Decision,
+		DecidedBy:          entity.ScoringRoleChampion,
+	}
+	if id, err := uuid.Parse(champion.ModelID); err == nil {
+		shadow.ChampionModelID = &id
 	}
 
-	// Factor 4: Deuda existente vs relación máxima del país (peso: 10%)
-	if app.BankingInfo != nil && app.BankingInfo.TotalDebt != nil && app.MonthlyIncome > 0 {
-		debt := *app.BankingInfo.TotalDebt
-		// Calcular ratio de deuda actual sobre ingreso
-		currentDebtRatio := debt / (app.MonthlyIncome * 12)
-		maxRatio := config.MaxDebtToIncomeRatio
-		if maxRatio == 0 {
-			maxRatio = 0.4
-		}
+	q.log.Debug().
+		Str("application_id", app.ID.String()).
+		Float64("champion_score", champion.Score).
+		Float64("challenger_score", result.Score).
+		Int("traffic_percent", trafficPercent).
+		Msg("Challenger scoring model evaluated")
 
-		if currentDebtRatio < maxRatio*0.25 { // Deuda muy baja
-			score += 10
-		} else if currentDebtRatio < maxRatio*0.5 { // Deuda moderada
-			score += 5
-		} else if currentDebtRatio > maxRatio { // Deuda supera el máximo
-			score -= 10
-		}
+	if scoring.RoutesToChallenger(app.ID, trafficPercent) {
+		shadow.DecidedBy = entity.ScoringRoleChallenger
+		return shadow, result
 	}
+	return shadow, champion
+}
 
-	// Factor 5: Estabilidad laboral (peso: 10%)
-	if app.BankingInfo != nil && app.BankingInfo.MonthsEmployed != nil {
-		months := *app.BankingInfo.MonthsEmployed
-		if months >= 24 {
-			score += 10
-		} else if months >= 12 {
-			score += 5
-		} else if months < 6 {
-			score -= 5
-		}
+// saveShadowResult guarda la comparación champion/challenger de una evaluación
+func (q *PostgresQueue) saveShadowResult(ctx context.Context, r *entity.ScoringShadowResult) error {
+	query := `
+		INSERT INTO scoring_shadow_results (id, application_id, country_id,
+		                                    champion_model_id, champion_version, champion_score, champion_decision,
+		                                    challenger_model_id, challenger_version, challenger_score, challenger_decision,
+		                                    decided_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW())
+	`
+	return q.db.Exec(ctx, query, r.ID, r.ApplicationID, r.CountryID,
+		r.ChampionModelID, r.ChampionVersion, r.ChampionScore, r.ChampionDecision,
+		r.ChallengerModelID, r.ChallengerVersion, r.ChallengerScore, r.ChallengerDecision,
+		r.DecidedBy)
+}
+
+// saveRiskDecision guarda el desglose de una evaluación de riesgo
+func (q *PostgresQueue) saveRiskDecision(ctx context.Context, d *entity.RiskDecision) error {
+	factors, err := json.Marshal(d.Factors)
+	if err != nil {
+		return fmt.Errorf("failed to marshal risk factors: %w", err)
+	}
+	reasons, err := json.Marshal(d.ReasonCodes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal reason codes: %w", err)
 	}
 
-	// Limitar score entre 0 y 100
-	if score < 0 {
-		score = 0
-	} else if score > 100 {
-		score = 100
+	query := `
+		INSERT INTO risk_decisions (id, application_id, scoring_model_id, scoring_model_version, score,
+		                            decision, outcome, requires_review, factors, reason_codes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::jsonb, $10::jsonb, NOW())
+	`
+	if err := q.db.Exec(ctx, query, d.ID, d.ApplicationID, d.ScoringModelID, d.ScoringModelVersion, d.Score,
+		d.Decision, d.Outcome, d.RequiresReview, string(factors), string(reasons)); err != nil {
+		return fmt.Errorf("failed to save risk decision: %w", err)
 	}
+	return nil
+}
+
+// countryRiskConfig configuración de riesgo por país
+type countryRiskConfig struct {
+	MinLoanAmount        float64 `json:"min_loan_amount"`
+	MaxLoanAmount        float64 `json:"max_loan_amount"`
+	MinIncomeRequired    float64 `json:"min_income_required"`
+	MaxDebtToIncomeRatio float64 `json:"max_debt_to_income_ratio"`
+	ReviewThreshold      float64 `json:"review_threshold"`
+	MinCreditScore       int     `json:"min_credit_score"`
 
-	return score
+	InvalidDocumentAction entity.RuleAction `json:"invalid_document_action"`
+	Fraud                 *entity.FraudConfig `json:"fraud"`
 }
 
 // handleBankingInfoFetch obtiene información bancaria del proveedor
@@ -860,6 +1258,7 @@ func (q *PostgresQueue) handleBankingInfoFetch(ctx context.Context, job *entity.
 		DocumentType   string `json:"document_type"`
 		DocumentNumber string `json:"document_number"`
 		CountryID      string `json:"country_id"`
+		ForceRefresh   bool   `json:"force_refresh,omitempty"` // Ignorar reportes reutilizables
 	}
 	if err := json.Unmarshal(job.Payload, &payload); err != nil {
 		q.log.Error().Err(err).Str("raw_payload", string(job.Payload)).Msg("Failed to parse job payload")
@@ -870,6 +1269,7 @@ func (q *PostgresQueue) handleBankingInfoFetch(ctx context.Context, job *entity.
 		Str("application_id", payload.ApplicationID).
 		Str("country_id", payload.CountryID).
 		Str("document_type", payload.DocumentType).
+		Bool("force_refresh", payload.ForceRefresh).
 		Msg("Payload parsed successfully")
 
 	appID, err := uuid.Parse(payload.ApplicationID)
@@ -884,78 +1284,45 @@ func (q *PostgresQueue) handleBankingInfoFetch(ctx context.Context, job *entity.
 		return fmt.Errorf("invalid country_id: %w", err)
 	}
 
-	// Obtener proveedor activo para el país
-	providerQuery := `
-		SELECT id, code, name, config
-		FROM banking_providers
-		WHERE country_id = $1 AND is_active = true
-		ORDER BY priority DESC
-		LIMIT 1
-	`
-	var providerID uuid.UUID
-	var providerCode, providerName string
-	var providerConfig []byte
+	// Reutilizar un reporte vigente del mismo documento si no se fuerza la actualización
+	if !payload.ForceRefresh {
+		cached, err := q.banking.ReuseCachedBankingInfo(ctx, appID, countryID, payload.DocumentType, payload.DocumentNumber)
+		if err != nil {
+			q.log.Warn().Err(err).Str("application_id", appID.String()).Msg("Failed to look up reusable banking info")
+		}
+		if cached != nil {
+			q.log.Info().
+				Str("application_id", appID.String()).
+				Str("reused_from_application_id", cached.ReusedFromApplicationID.String()).
+				Time("retrieved_at", cached.RetrievedAt).
+				Msg("Reusing cached banking info, skipping provider call")
+			return q.afterBankingInfoSaved(ctx, appID, job.Payload)
+		}
+	}
 
-	row := q.db.QueryRow(ctx, providerQuery, countryID)
-	if err := row.Scan(&providerID, &providerCode, &providerName, &providerConfig); err != nil {
+	// Consultar la cadena de proveedores del país (failover por prioridad)
+	response, provider, err := q.banking.FetchWithFailover(ctx, appID, countryID, payload.DocumentType, payload.DocumentNumber)
+	if err != nil {
 		q.log.Warn().
 			Err(err).
 			Str("country_id", countryID.String()).
-			Msg("No active banking provider found for country")
-		return fmt.Errorf("no active banking provider for country %s: %w", countryID, err)
+			Msg("No banking provider could serve the request")
+		return fmt.Errorf("failed to fetch banking info: %w", err)
 	}
 
-	q.log.Debug().
-		Str("provider_id", providerID.String()).
-		Str("provider_code", providerCode).
-		Msg("Banking provider found")
-
-	// Simular llamada al proveedor (en producción sería una llamada real)
-	// Generar datos basados en el hash del documento para consistencia
-	seed := int64(0)
-	for _, c := range payload.DocumentNumber {
-		seed += int64(c)
-	}
-
-	creditScore := 300 + int(seed%550)
-	totalDebt := float64(int(seed*13) % 50000)
-	availableCredit := float64(int(seed*7) % 30000)
-	histories := []string{"GOOD", "REGULAR", "BAD"}
-	paymentHistory := histories[int(seed)%3]
-	bankAccounts := int(seed) % 5
-	activeLoans := int(seed) % 3
-	monthsEmployed := int(seed*3) % 120
+	// Proveedor asíncrono: el reporte llegará por el webhook credit_report_ready
+	if response.Pending {
+		return q.awaitBankingCallback(ctx, appID, provider, response.ReferenceID, job.Payload)
+	}
 
-	// Guardar información bancaria
-	bankingID := uuid.New()
-	saveQuery := `
-		INSERT INTO banking_info (
-			id, application_id, provider_id, credit_score, total_debt,
-			available_credit, payment_history, bank_accounts, active_loans,
-			months_employed, retrieved_at, expires_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW() + INTERVAL '24 hours')
-		ON CONFLICT (application_id) DO UPDATE SET
-			provider_id = EXCLUDED.provider_id,
-			credit_score = EXCLUDED.credit_score,
-			total_debt = EXCLUDED.total_debt,
-			available_credit = EXCLUDED.available_credit,
-			payment_history = EXCLUDED.payment_history,
-			bank_accounts = EXCLUDED.bank_accounts,
-			active_loans = EXCLUDED.active_loans,
-			months_employed = EXCLUDED.months_employed,
-			retrieved_at = NOW(),
-			expires_at = NOW() + INTERVAL '24 hours'
-	`
-	
 	q.log.Debug().
-		Str("banking_id", bankingID.String()).
 		Str("application_id", appID.String()).
-		Str("provider_id", providerID.String()).
-		Int("credit_score", creditScore).
-		Msg("Saving banking info to database")
+		Str("provider_id", provider.ID.String()).
+		Str("provider_code", provider.Code).
+		Msg("Banking provider responded, saving banking info to database")
 
-	if err := q.db.Exec(ctx, saveQuery, bankingID, appID, providerID, creditScore, totalDebt,
-		availableCredit, paymentHistory, bankAccounts, activeLoans, monthsEmployed); err != nil {
+	// Guardar información bancaria
+	if err := q.banking.SaveBankingInfo(ctx, appID, provider.ID, response); err != nil {
 		q.log.Error().
 			Err(err).
 			Str("application_id", appID.String()).
@@ -963,47 +1330,350 @@ func (q *PostgresQueue) handleBankingInfoFetch(ctx context.Context, job *entity.
 		return fmt.Errorf("failed to save banking info: %w", err)
 	}
 
+	logEvent := q.log.Info().
+		Str("application_id", appID.String()).
+		Str("provider", provider.Code)
+	if response.CreditScore != nil {
+		logEvent = logEvent.Int("credit_score", *response.CreditScore)
+	}
+	logEvent.Msg("Banking info fetch completed successfully")
+
+	return q.afterBankingInfoSaved(ctx, appID, job.Payload)
+}
+
+// awaitBankingCallback deja la solicitud en PENDING_BANK_INFO y programa el
+// trabajo que actuará si el webhook del proveedor no llega a tiempo
+// Si el webhook llegó antes (la consulta asíncrona ya no está pendiente) no se espera
+func (q *PostgresQueue) awaitBankingCallback(ctx context.Context, appID uuid.UUID, provider *entity.BankingProvider, referenceID string, payload []byte) error {
+	updateQuery := `
+		UPDATE credit_applications SET status = 'PENDING_BANK_INFO', updated_at = NOW()
+		WHERE id = $1 AND status IN ('PENDING', 'VALIDATING')
+		AND EXISTS (
+			SELECT 1 FROM banking_requests
+			WHERE application_id = $1 AND request_type = 'BANKING_INFO_ASYNC' AND status = 'PENDING'
+		)
+		RETURNING id
+	`
+	var updatedID uuid.UUID
+	if err := q.db.QueryRow(ctx, updateQuery, appID).Scan(&updatedID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			q.log.Info().
+				Str("application_id", appID.String()).
+				Str("provider", provider.Code).
+				Str("reference_id", referenceID).
+				Msg("Async credit report already received, not waiting for callback")
+			return nil
+		}
+		return fmt.Errorf("failed to set application to PENDING_BANK_INFO: %w", err)
+	}
+
+	timeoutMinutes := provider.Config.CallbackTimeoutMinutes
+	if timeoutMinutes <= 0 {
+		timeoutMinutes = defaultCallbackTimeoutMinutes
+	}
+	action := provider.Config.OnCallbackTimeout
+	if action == "" {
+		action = entity.CallbackTimeoutFallback
+	}
+
+	// El trabajo de timeout conserva el payload original para poder reconsultar
+	var timeoutPayload map[string]interface{}
+	if err := json.Unmarshal(payload, &timeoutPayload); err != nil {
+		return fmt.Errorf("failed to parse job payload: %w", err)
+	}
+	timeoutPayload["provider_id"] = provider.ID.String()
+	timeoutPayload["reference_id"] = referenceID
+	timeoutPayload["on_timeout"] = action
+	delete(timeoutPayload, "force_refresh")
+	timeoutJSON, err := json.Marshal(timeoutPayload)
+	if err != nil {
+		return fmt.Errorf("failed to build timeout payload: %w", err)
+	}
+
+	timeoutJob := &entity.Job{
+		ID:       uuid.New(),
+		Type:     entity.JobTypeBankingCallbackTimeout,
+		Priority: 8,
+		Payload:  timeoutJSON,
+	}
+	if err := q.EnqueueWithDelay(ctx, timeoutJob, timeoutMinutes*60); err != nil {
+		return fmt.Errorf("failed to schedule callback timeout: %w", err)
+	}
+
 	q.log.Info().
 		Str("application_id", appID.String()).
-		Str("banking_id", bankingID.String()).
-		Msg("Banking info saved successfully")
+		Str("provider", provider.Code).
+		Str("reference_id", referenceID).
+		Int("timeout_minutes", timeoutMinutes).
+		Str("on_timeout", action).
+		Msg("Async banking request submitted, waiting for credit_report_ready webhook")
+
+	return nil
+}
 
+// handleBankingCallbackTimeout actúa cuando el reporte asíncrono no llegó
+// FALLBACK consulta los proveedores síncronos de la cadena; ESCALATE (o si el
+// respaldo también falla) envía la solicitud a revisión manual
+func (q *PostgresQueue) handleBankingCallbackTimeout(ctx context.Context, job *entity.Job) error {
+	var payload struct {
+		ApplicationID  string `json:"application_id"`
+		CountryID      string `json:"country_id"`
+		DocumentType   string `json:"document_type"`
+		DocumentNumber string `json:"document_number"`
+		ReferenceID    string `json:"reference_id"`
+		OnTimeout      string `json:"on_timeout"`
+	}
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("failed to parse job payload: %w", err)
+	}
+
+	appID, err := uuid.Parse(payload.ApplicationID)
+	if err != nil {
+		return fmt.Errorf("invalid application_id: %w", err)
+	}
+	countryID, err := uuid.Parse(payload.CountryID)
+	if err != nil {
+		return fmt.Errorf("invalid country_id: %w", err)
+	}
+
+	var status entity.ApplicationStatus
+	if err := q.db.QueryRow(ctx, `SELECT status FROM credit_applications WHERE id = $1`, appID).Scan(&status); err != nil {
+		return fmt.Errorf("failed to get application: %w", err)
+	}
+	if status != entity.StatusPendingBankInfo {
+		q.log.Debug().
+			Str("application_id", appID.String()).
+			Str("status", string(status)).
+			Msg("Credit report already handled, callback timeout is a no-op")
+		return nil
+	}
+
+	// A partir de aquí un webhook tardío ya no se acepta
+	expired, err := q.banking.ExpireAsyncRequests(ctx, appID, "callback timeout: credit report not received")
+	if err != nil {
+		return fmt.Errorf("failed to expire async banking requests: %w", err)
+	}
+
+	q.log.Warn().
+		Str("application_id", appID.String()).
+		Str("reference_id", payload.ReferenceID).
+		Int("expired_requests", expired).
+		Str("on_timeout", payload.OnTimeout).
+		Msg("Async credit report not received in time")
+
+	if payload.OnTimeout != entity.CallbackTimeoutEscalate {
+		response, provider, err := q.banking.FetchSyncWithFailover(ctx, appID, countryID, payload.DocumentType, payload.DocumentNumber)
+		var throttled retryLater
+		if err != nil && errors.As(err, &throttled) {
+			return err
+		}
+		if err == nil {
+			if err := q.banking.SaveBankingInfo(ctx, appID, provider.ID, response); err != nil {
+				return fmt.Errorf("failed to save banking info: %w", err)
+			}
+			q.log.Info().
+				Str("application_id", appID.String()).
+				Str("provider", provider.Code).
+				Msg("Fallback provider served the banking info")
+			return q.afterBankingInfoSaved(ctx, appID, job.Payload)
+		}
+
+		q.log.Warn().Err(err).Str("application_id", appID.String()).Msg("Fallback banking fetch failed, escalating")
+	}
+
+	reason := "Credit report not received from banking provider in time"
+	escalateQuery := `
+		UPDATE credit_applications SET status = 'UNDER_REVIEW', status_reason = $2, updated_at = NOW()
+		WHERE id = $1 AND status = 'PENDING_BANK_INFO'
+		RETURNING id
+	`
+	var escalatedID uuid.UUID
+	if err := q.db.QueryRow(ctx, escalateQuery, appID, reason).Scan(&escalatedID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil // Otro proceso ya movió la solicitud
+		}
+		return fmt.Errorf("failed to escalate application: %w", err)
+	}
+
+	// Registrar transición de estado
+	transitionQuery := `
+		INSERT INTO state_transitions (id, application_id, from_status, to_status, reason, triggered_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, 'SYSTEM', NOW())
+	`
+	if err := q.db.Exec(ctx, transitionQuery, uuid.New(), appID, entity.StatusPendingBankInfo, entity.StatusUnderReview, reason); err != nil {
+		q.log.Error().Err(err).Msg("Failed to save state transition")
+	}
+
+	q.log.Info().Str("application_id", appID.String()).Msg("Application escalated to manual review")
+	return nil
+}
+
+// afterBankingInfoSaved pasa la solicitud a VALIDATING y encola la detección de fraude,
+// que a su vez encola la evaluación de riesgo
+func (q *PostgresQueue) afterBankingInfoSaved(ctx context.Context, appID uuid.UUID, payload []byte) error {
 	// Actualizar estado de la solicitud
-	updateQuery := `UPDATE credit_applications SET status = 'VALIDATING', updated_at = NOW() WHERE id = $1`
+	// Una solicitud ya rechazada o en revisión (p. ej. por documento inválido) conserva su estado
+	updateQuery := `
+		UPDATE credit_applications SET status = 'VALIDATING', updated_at = NOW()
+		WHERE id = $1 AND status IN ('PENDING', 'PENDING_BANK_INFO', 'VALIDATING')
+	`
 	if err := q.db.Exec(ctx, updateQuery, appID); err != nil {
 		q.log.Error().Err(err).Str("application_id", appID.String()).Msg("Failed to update application status")
 	} else {
 		q.log.Info().Str("application_id", appID.String()).Msg("Application status updated to VALIDATING")
 	}
 
-	// Encolar evaluación de riesgo
-	riskJob := &entity.Job{
+	// Encolar detección de fraude (usa la información bancaria recién guardada)
+	fraudJob := &entity.Job{
 		ID:       uuid.New(),
-		Type:     entity.JobTypeRiskEvaluation,
+		Type:     entity.JobTypeFraudCheck,
 		Priority: 10,
-		Payload:  job.Payload,
+		Payload:  payload,
 	}
-	if err := q.Enqueue(ctx, riskJob); err != nil {
-		q.log.Error().Err(err).Str("application_id", appID.String()).Msg("Failed to enqueue risk evaluation")
+	if err := q.Enqueue(ctx, fraudJob); err != nil {
+		q.log.Error().Err(err).Str("application_id", appID.String()).Msg("Failed to enqueue fraud check")
 	} else {
 		q.log.Info().
 			Str("application_id", appID.String()).
-			Str("risk_job_id", riskJob.ID.String()).
-			Msg("Risk evaluation job enqueued")
+			Str("fraud_job_id", fraudJob.ID.String()).
+			Msg("Fraud check job enqueued")
 	}
 
+	return nil
+}
+
+// handleFraudCheck calcula las señales de fraude de la solicitud, guarda el score y
+// encola la evaluación de riesgo, que fuerza revisión si el score alcanza el umbral del país
+func (q *PostgresQueue) handleFraudCheck(ctx context.Context, job *entity.Job) error {
+	var payload struct {
+		ApplicationID string `json:"application_id"`
+	}
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		q.log.Error().Err(err).Str("raw_payload", string(job.Payload)).Msg("Failed to parse fraud check payload")
+		return fmt.Errorf("failed to parse job payload: %w", err)
+	}
+	appID, err := uuid.Parse(payload.ApplicationID)
+	if err != nil {
+		q.log.Error().Err(err).Str("application_id", payload.ApplicationID).Msg("Invalid application_id UUID")
+		return fmt.Errorf("invalid application ID: %w", err)
+	}
+
+	// Configuración del país vigente en la fecha de la solicitud
+	var countryConfig []byte
+	configQuery := `
+		SELECT country_config_at(ca.country_id, ca.application_date)
+		FROM credit_applications ca
+		WHERE ca.id = $1
+	`
+	if err := q.db.QueryRow(ctx, configQuery, appID).Scan(&countryConfig); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			q.log.Warn().Str("application_id", payload.ApplicationID).Msg("Application not found - skipping fraud check")
+			return nil
+		}
+		return fmt.Errorf("failed to get country config: %w", err)
+	}
+	var config countryRiskConfig
+	if err := json.Unmarshal(countryConfig, &config); err != nil {
+		q.log.Warn().Err(err).Msg("Failed to parse country config, using default fraud settings")
+	}
+	fraudConfig := fraud.WithDefaults(config.Fraud)
+
+	facts, err := q.fraudFacts(ctx, appID, fraudConfig)
+	if err != nil {
+		return err
+	}
+	assessment := fraud.Evaluate(*facts, fraudConfig)
+
+	signalsJSON, err := json.Marshal(assessment.Signals)
+	if err != nil {
+		return fmt.Errorf("failed to marshal fraud signals: %w", err)
+	}
+	updateQuery := `
+		UPDATE credit_applications
+		SET fraud_score = $2, fraud_signals = $3::jsonb, fraud_checked_at = $4, updated_at = NOW()
+		WHERE id = $1
+	`
+	if err := q.db.Exec(ctx, updateQuery, appID, assessment.Score, string(signalsJSON), assessment.CheckedAt); err != nil {
+		return fmt.Errorf("failed to save fraud assessment: %w", err)
+	}
+
+	codes := make([]string, 0, len(assessment.Signals))
+	for _, signal := range assessment.Signals {
+		codes = append(codes, signal.Code)
+	}
+	q.log.Info().
+		Str("application_id", appID.String()).
+		Float64("fraud_score", assessment.Score).
+		Strs("signals", codes).
+		Msg("Fraud check completed")
+
+	riskJob := &entity.Job{
+		ID:       uuid.New(),
+		Type:     entity.JobTypeRiskEvaluation,
+		Priority: 10,
+		Payload:  job.Payload,
+	}
+	if err := q.Enqueue(ctx, riskJob); err != nil {
+		return fmt.Errorf("failed to enqueue risk evaluation: %w", err)
+	}
 	q.log.Info().
 		Str("application_id", appID.String()).
-		Str("provider", providerCode).
-		Int("credit_score", creditScore).
-		Float64("total_debt", totalDebt).
-		Str("payment_history", paymentHistory).
-		Msg("Banking info fetch completed successfully")
+		Str("risk_job_id", riskJob.ID.String()).
+		Msg("Risk evaluation job enqueued")
 
 	return nil
 }
 
+// fraudFacts reúne los datos de la solicitud, de las solicitudes relacionadas y del buró
+// Documento, email y teléfono se comparan dentro del país (idx_applications_document,
+// idx_applications_email, idx_applications_phone); la subred, /24 en IPv4 y /64 en
+// IPv6, en todos los países con el índice gist idx_applications_ip_inet
+// Los nombres se comparan sin mayúsculas ni espacios extremos
+func (q *PostgresQueue) fraudFacts(ctx context.Context, appID uuid.UUID, config entity.FraudConfig) (*fraud.Facts, error) {
+	now := time.Now()
+	lookbackSince := now.AddDate(0, 0, -config.LookbackDays)
+	subnetSince := now.Add(-time.Duration(config.SubnetWindowHours) * time.Hour)
+
+	query := `
+		SELECT a.email, a.monthly_income,
+		       (SELECT COUNT(DISTINCT lower(trim(o.full_name))) FROM credit_applications o
+		        WHERE o.country_id = a.country_id AND o.document_number = a.document_number
+		          AND o.id <> a.id AND o.created_at >= $2
+		          AND lower(trim(o.full_name)) <> lower(trim(a.full_name))),
+		       (SELECT COUNT(DISTINCT o.document_number) FROM credit_applications o
+		        WHERE o.country_id = a.country_id AND o.email IS NOT NULL
+		          AND lower(o.email) = lower(a.email)
+		          AND o.document_number <> a.document_number AND o.created_at >= $2),
+		       (SELECT COUNT(DISTINCT o.document_number) FROM credit_applications o
+		        WHERE o.country_id = a.country_id AND o.phone IS NOT NULL AND COALESCE(a.phone, '') <> ''
+		          AND regexp_replace(o.phone, '[^0-9]', '', 'g') = regexp_replace(a.phone, '[^0-9]', '', 'g')
+		          AND o.document_number <> a.document_number AND o.created_at >= $2),
+		       (SELECT COUNT(*) FROM credit_applications o
+		        WHERE a.created_by_ip IS NOT NULL AND o.id <> a.id AND o.created_at >= $3
+		          AND o.created_by_ip <<= network(set_masklen(a.created_by_ip,
+		              CASE family(a.created_by_ip) WHEN 4 THEN 24 ELSE 64 END))),
+		       bi.total_debt, bi.months_employed
+		FROM credit_applications a
+		LEFT JOIN banking_info bi ON bi.application_id = a.id
+		WHERE a.id = $1
+	`
+	var facts fraud.Facts
+	err := q.db.QueryRow(ctx, query, appID, lookbackSince, subnetSince).Scan(
+		&facts.Email, &facts.MonthlyIncome,
+		&facts.OtherNamesForDocument, &facts.DocumentsForEmail, &facts.DocumentsForPhone,
+		&facts.SubnetApplications,
+		&facts.TotalDebt, &facts.MonthsEmployed,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect fraud signals: %w", err)
+	}
+	return &facts, nil
+}
+
 // handleDocumentValidation valida documentos de identidad
+// Aplica el regex del tipo de documento y el dígito de control del país, guarda el
+// resultado en la solicitud y, si el documento no es válido, la rechaza o la envía
+// a revisión según invalid_document_action de la configuración del país
 func (q *PostgresQueue) handleDocumentValidation(ctx context.Context, job *entity.Job) error {
 	q.log.Info().
 		Str("job_id", job.ID.String()).
@@ -1027,34 +1697,51 @@ func (q *PostgresQueue) handleDocumentValidation(ctx context.Context, job *entit
 		Str("document_type", payload.DocumentType).
 		Msg("Document validation payload parsed")
 
+	appID, err := uuid.Parse(payload.ApplicationID)
+	if err != nil {
+		q.log.Error().Err(err).Str("application_id", payload.ApplicationID).Msg("Invalid application_id UUID")
+		return fmt.Errorf("invalid application ID: %w", err)
+	}
 	countryID, err := uuid.Parse(payload.CountryID)
 	if err != nil {
 		q.log.Error().Err(err).Str("country_id", payload.CountryID).Msg("Invalid country_id UUID")
 		return fmt.Errorf("invalid country_id: %w", err)
 	}
 
-	// Obtener regex de validación del tipo de documento usando country_id
-	var validationRegex *string
-	regexQuery := `
-		SELECT dt.validation_regex 
-		FROM document_types dt
-		WHERE dt.country_id = $1 AND dt.code = $2
+	// Configuración del país vigente en la fecha de la solicitud
+	var countryCode string
+	var countryConfig []byte
+	countryQuery := `
+		SELECT c.code, country_config_at(c.id, ca.application_date)
+		FROM credit_applications ca
+		JOIN countries c ON c.id = $2
+		WHERE ca.id = $1
 	`
-	row := q.db.QueryRow(ctx, regexQuery, countryID, payload.DocumentType)
-	if err := row.Scan(&validationRegex); err != nil {
-		q.log.Warn().
-			Err(err).
-			Str("doc_type", payload.DocumentType).
-			Str("country_id", countryID.String()).
-			Msg("Document type not found - continuing without validation regex")
+	if err := q.db.QueryRow(ctx, countryQuery, appID, countryID).Scan(&countryCode, &countryConfig); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			q.log.Warn().Str("application_id", payload.ApplicationID).Msg("Application not found - skipping document validation")
+			return nil
+		}
+		return fmt.Errorf("failed to get country: %w", err)
 	}
 
-	// La validación real se hace en el servicio de validación
-	// Aquí solo registramos el resultado
-	isValid := true
-	if validationRegex != nil && *validationRegex != "" {
-		// En producción, usar regexp.MatchString para validar
-		q.log.Debug().Str("regex", *validationRegex).Msg("Validation regex found")
+	// Regex del tipo de documento + dígito de control del país
+	isValid, message, err := q.rules.ValidateDocument(ctx, payload.DocumentType, payload.DocumentNumber, countryCode)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to validate document: %w", err)
+		}
+		// El tipo de documento no está configurado para el país: no se puede dar por válido
+		isValid = false
+	}
+
+	resultQuery := `
+		UPDATE credit_applications
+		SET document_valid = $2, document_validation_message = $3, document_validated_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`
+	if err := q.db.Exec(ctx, resultQuery, appID, isValid, message); err != nil {
+		return fmt.Errorf("failed to save document validation result: %w", err)
 	}
 
 	q.log.Info().
@@ -1062,11 +1749,114 @@ func (q *PostgresQueue) handleDocumentValidation(ctx context.Context, job *entit
 		Str("document_type", payload.DocumentType).
 		Str("country_id", countryID.String()).
 		Bool("valid", isValid).
-		Msg("Document validation completed successfully")
+		Str("message", message).
+		Msg("Document validation completed")
+
+	if isValid {
+		return nil
+	}
+
+	var config countryRiskConfig
+	if err := json.Unmarshal(countryConfig, &config); err != nil {
+		q.log.Warn().Err(err).Msg("Failed to parse country config, rejecting invalid document by default")
+	}
+	return q.applyInvalidDocument(ctx, appID, payload.DocumentType, message, config.InvalidDocumentAction)
+}
+
+// applyInvalidDocument rechaza la solicitud con documento inválido o la envía a revisión
+// Una solicitud ya rechazada o cerrada conserva su estado; una ya aprobada devuelve error
+func (q *PostgresQueue) applyInvalidDocument(ctx context.Context, appID uuid.UUID, docType, message string, action entity.RuleAction) error {
+	var current entity.ApplicationStatus
+	if err := q.db.QueryRow(ctx, `SELECT status FROM credit_applications WHERE id = $1`, appID).Scan(&current); err != nil {
+		return fmt.Errorf("failed to get application: %w", err)
+	}
+
+	target, changes, err := invalidDocumentTarget(current, action)
+	if err != nil {
+		return fmt.Errorf("application %s: %w", appID, err)
+	}
+	if !changes {
+		q.log.Info().
+			Str("application_id", appID.String()).
+			Str("current_status", string(current)).
+			Msg("Invalid document but application needs no status change - keeping current status")
+		return nil
+	}
+
+	statusReason := fmt.Sprintf("Rejected: invalid identity document (%s): %s", docType, message)
+	if target == entity.StatusUnderReview {
+		statusReason = fmt.Sprintf("Manual review required - invalid identity document (%s): %s", docType, message)
+	}
+
+	// La condición sobre el estado evita pisar un cambio concurrente (p. ej. la evaluación de riesgo)
+	updateQuery := `
+		UPDATE credit_applications
+		SET status = $2, status_reason = $3, requires_review = $4,
+		    processed_at = CASE WHEN $4 THEN processed_at ELSE NOW() END, updated_at = NOW()
+		WHERE id = $1 AND status = $5
+		RETURNING id
+	`
+	var updatedID uuid.UUID
+	err = q.db.QueryRow(ctx, updateQuery, appID, target, statusReason, target == entity.StatusUnderReview, current).Scan(&updatedID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("application %s changed status concurrently, retrying document outcome", appID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update application: %w", err)
+	}
+
+	transitionQuery := `
+		INSERT INTO state_transitions (id, application_id, from_status, to_status, reason, triggered_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, 'SYSTEM', NOW())
+	`
+	if err := q.db.Exec(ctx, transitionQuery, uuid.New(), appID, current, target, statusReason); err != nil {
+		q.log.Error().Err(err).Msg("Failed to save state transition")
+	}
+
+	q.log.Info().
+		Str("application_id", appID.String()).
+		Str("new_status", string(target)).
+		Msg("Application moved due to invalid document")
 
 	return nil
 }
 
+// invalidDocumentTarget estado al que pasa una solicitud con documento inválido
+// Ya en el destino, o cerrada sin conceder el crédito, no cambia (changes=false).
+// Aprobada o desembolsada devuelve error: el trabajo acaba en dead letter para
+// que operaciones la revise
+func invalidDocumentTarget(current entity.ApplicationStatus, action entity.RuleAction) (entity.ApplicationStatus, bool, error) {
+	target := entity.StatusRejected
+	if action == entity.RuleActionRequireReview {
+		target = entity.StatusUnderReview
+	}
+	if current.CanTransitionTo(target) {
+		return target, true, nil
+	}
+
+	switch current {
+	case target, entity.StatusRejected, entity.StatusCancelled, entity.StatusExpired:
+		return current, false, nil
+	}
+	return "", false, fmt.Errorf("invalid document but application is already %s and cannot move to %s", current, target)
+}
+
+// documentValidationPending indica si la solicitud tiene un DOCUMENT_VALIDATION que aún se ejecutará
+func (q *PostgresQueue) documentValidationPending(ctx context.Context, appID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM jobs_queue
+			WHERE type = $1 AND payload->>'application_id' = $2
+			  AND status IN ('PENDING', 'PROCESSING', 'RETRYING')
+		)
+	`
+	var pending bool
+	if err := q.db.QueryRow(ctx, query, entity.JobTypeDocumentValidation, appID.String()).Scan(&pending); err != nil {
+		return false, fmt.Errorf("failed to check pending document validation: %w", err)
+	}
+	return pending, nil
+}
+
 // handleNotification procesa notificaciones
 func (q *PostgresQueue) handleNotification(ctx context.Context, job *entity.Job) error {
 	q.log.Info().Str("job_id", job.ID.String()).Msg("Sending notification")
@@ -0,0 +1,69 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+func TestInvalidDocumentTarget(t *testing.T) {
+	tests := []struct {
+		current entity.ApplicationStatus
+		action  entity.RuleAction
+		target  entity.ApplicationStatus
+		changes bool
+		wantErr string
+	}{
+		{entity.StatusPending, "", entity.StatusRejected, true, ""},
+		{entity.StatusValidating, entity.RuleActionReject, entity.StatusRejected, true, ""},
+		{entity.StatusPendingBankInfo, entity.RuleActionReject, entity.StatusRejected, true, ""},
+		{entity.StatusPendingBankInfo, entity.RuleActionRequireReview, entity.StatusUnderReview, true, ""},
+		{entity.StatusUnderReview, entity.RuleActionReject, entity.StatusRejected, true, ""},
+
+		// Nada que cambiar
+		{entity.StatusUnderReview, entity.RuleActionRequireReview, entity.StatusUnderReview, false, ""},
+		{entity.StatusRejected, entity.RuleActionReject, entity.StatusRejected, false, ""},
+		{entity.StatusRejected, entity.RuleActionRequireReview, entity.StatusRejected, false, ""},
+		{entity.StatusCancelled, entity.RuleActionReject, entity.StatusCancelled, false, ""},
+		{entity.StatusExpired, entity.RuleActionRequireReview, entity.StatusExpired, false, ""},
+
+		// Crédito ya concedido: no se puede ignorar
+		{entity.StatusApproved, entity.RuleActionReject, "", false, "already APPROVED and cannot move to REJECTED"},
+		{entity.StatusApproved, entity.RuleActionRequireReview, "", false, "already APPROVED and cannot move to UNDER_REVIEW"},
+		{entity.StatusDisbursed, entity.RuleActionReject, "", false, "already DISBURSED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%s", tt.current, tt.action), func(t *testing.T) {
+			target, changes, err := invalidDocumentTarget(tt.current, tt.action)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("invalidDocumentTarget error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("invalidDocumentTarget: %v", err)
+			}
+			if target != tt.target || changes != tt.changes {
+				t.Errorf("invalidDocumentTarget = %s %v, want %s %v", target, changes, tt.target, tt.changes)
+			}
+		})
+	}
+}
+
+func TestDocumentPendingErrorIsRescheduled(t *testing.T) {
+	err := fmt.Errorf("risk evaluation: %w", &documentPendingError{applicationID: uuid.New()})
+
+	var throttled retryLater
+	if !errors.As(err, &throttled) {
+		t.Fatalf("documentPendingError is not rescheduled by the worker")
+	}
+	if throttled.RetryAfter() != documentPendingRetryDelay {
+		t.Errorf("RetryAfter = %s, want %s", throttled.RetryAfter(), documentPendingRetryDelay)
+	}
+}
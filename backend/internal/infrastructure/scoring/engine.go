@@ -0,0 +1,235 @@
+package scoring
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/domain/repository"
+	"github.com/fintech-multipass/backend/internal/domain/service"
+	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+	"github.com/google/uuid"
+)
+
+var _ service.RiskEvaluator = (*Engine)(nil)
+
+// BuiltinVersion versión del modelo por defecto cuando un país no tiene ninguno activo
+const BuiltinVersion = 0
+
+// Engine carga el modelo activo de cada país y lo evalúa
+// Las versiones son inmutables, por lo que los modelos compilados se cachean por ID
+type Engine struct {
+	repo repository.ScoringModelRepository
+	log  *logger.Logger
+
+	mu       sync.RWMutex
+	compiled map[uuid.UUID]*Model
+	builtin  *Model
+}
+
+// NewEngine crea el motor de scoring
+func NewEngine(repo repository.ScoringModelRepository, log *logger.Logger) *Engine {
+	builtin, err := Compile("", BuiltinVersion, "builtin", DefaultDefinition())
+	if err != nil {
+		panic("invalid builtin scoring model: " + err.Error())
+	}
+	return &Engine{
+		repo:     repo,
+		log:      log,
+		compiled: make(map[uuid.UUID]*Model),
+		builtin:  builtin,
+	}
+}
+
+// ModelForCountry obtiene el modelo activo del país
+// Si el país no tiene modelo activo (o el almacenado es inválido) se usa el
+// modelo por defecto, con versión 0
+func (e *Engine) ModelForCountry(ctx context.Context, countryID uuid.UUID) (*Model, error) {
+	stored, err := e.repo.GetActiveByCountry(ctx, countryID)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		e.log.Warn().Str("country_id", countryID.String()).Msg("No active scoring model for country, using builtin model")
+		return e.builtin, nil
+	}
+
+	model, err := e.compile(stored)
+	if err != nil {
+		e.log.Error().Err(err).
+			Str("country_id", countryID.String()).
+			Int("version", stored.Version).
+			Msg("Active scoring model is invalid, using builtin model")
+		return e.builtin, nil
+	}
+	return model, nil
+}
+
+// ChallengerForCountry obtiene el challenger del país y su porcentaje de tráfico
+// Devuelve nil si el país no tiene challenger o si su definición es inválida
+func (e *Engine) ChallengerForCountry(ctx context.Context, countryID uuid.UUID) (*Model, int, error) {
+	stored, err := e.repo.GetChallengerByCountry(ctx, countryID)
+	if err != nil || stored == nil {
+		return nil, 0, err
+	}
+
+	model, err := e.compile(stored)
+	if err != nil {
+		e.log.Error().Err(err).
+			Str("country_id", countryID.String()).
+			Int("version", stored.Version).
+			Msg("Challenger scoring model is invalid, skipping shadow evaluation")
+		return nil, 0, nil
+	}
+	return model, stored.TrafficPercent, nil
+}
+
+// compile devuelve el modelo compilado de una versión almacenada, usando la caché
+func (e *Engine) compile(stored *entity.ScoringModel) (*Model, error) {
+	e.mu.RLock()
+	model, ok := e.compiled[stored.ID]
+	e.mu.RUnlock()
+	if ok {
+		return model, nil
+	}
+
+	model, err := CompileModel(stored)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	e.compiled[stored.ID] = model
+	e.mu.Unlock()
+
+	return model, nil
+}
+
+// RoutesToChallenger indica si el challenger decide sobre la solicitud
+// El reparto es determinista por solicitud: reevaluarla usa siempre el mismo modelo
+func RoutesToChallenger(applicationID uuid.UUID, trafficPercent int) bool {
+	if trafficPercent <= 0 {
+		return false
+	}
+	h := fnv.New32a()
+	h.Write(applicationID[:])
+	return int(h.Sum32()%100) < trafficPercent
+}
+
+// EvaluateRisk evalúa la solicitud con el modelo activo de su país
+// Devuelve el score y los códigos de motivo adversos (el de mayor impacto primero)
+func (e *Engine) EvaluateRisk(ctx context.Context, app *entity.CreditApplication, bankingInfo *entity.BankingInfo) (float64, []string, error) {
+	model, err := e.ModelForCountry(ctx, app.CountryID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	subject := *app
+	if bankingInfo != nil {
+		subject.BankingInfo = bankingInfo
+	}
+	result := model.Evaluate(BuildInputs(&subject, paramsFor(app)))
+
+	reasons := make([]string, 0, len(result.ReasonCodes))
+	for _, rc := range result.ReasonCodes {
+		reasons = append(reasons, rc.Code)
+	}
+	return result.Score, reasons, nil
+}
+
+// ShouldRequireReview indica si el score no alcanza la aprobación automática o
+// si el monto supera el umbral de revisión del país
+func (e *Engine) ShouldRequireReview(ctx context.Context, app *entity.CreditApplication, riskScore float64) bool {
+	if app.Country != nil && app.Country.Config.ReviewThreshold > 0 && app.RequestedAmount >= app.Country.Config.ReviewThreshold {
+		return true
+	}
+	model, err := e.ModelForCountry(ctx, app.CountryID)
+	if err != nil {
+		return true
+	}
+	return riskScore < model.Cutoffs().Approve
+}
+
+// Outcome estado que resulta de la decisión del modelo
+// forceReview (umbral de monto del país o reglas que piden revisión) lleva a
+// revisión manual cualquier decisión, incluido un rechazo del modelo
+func Outcome(decision entity.ScoringDecision, forceReview bool) entity.ApplicationStatus {
+	switch {
+	case forceReview || decision == entity.ScoringDecisionReview:
+		return entity.StatusUnderReview
+	case decision == entity.ScoringDecisionApprove:
+		return entity.StatusApproved
+	}
+	return entity.StatusRejected
+}
+
+// paramsFor parámetros del país de la solicitud (por defecto si no está cargado)
+func paramsFor(app *entity.CreditApplication) CountryParams {
+	if app.Country == nil {
+		return CountryParams{}
+	}
+	return CountryParams{
+		MaxDebtToIncomeRatio: app.Country.Config.MaxDebtToIncomeRatio,
+		MinCreditScore:       app.Country.Config.MinCreditScore,
+	}
+}
+
+// DefaultDefinition modelo equivalente al cálculo histórico de cinco factores
+// Es también la versión 1 que la migración siembra para cada país
+func DefaultDefinition() entity.ScoringDefinition {
+	return entity.ScoringDefinition{
+		BaseScore: 50,
+		MinScore:  0,
+		MaxScore:  100,
+		Cutoffs:   entity.ScoringCutoffs{Approve: 70, Review: 40},
+		Factors: []entity.ScoringFactor{
+			{
+				Name: "requested_amount_ratio", Input: InputRequestedRatioVsMax, Weight: 1,
+				Buckets: []entity.ScoringBucket{
+					{Max: f(0.5), Points: 25},
+					{Min: f(0.5), Max: f(0.75), Points: 15},
+					{Min: f(0.75), Max: f(1), Points: 5},
+					{Min: f(1.25), Points: -15},
+				},
+			},
+			{
+				Name: "credit_score", Input: InputCreditScoreMargin, Weight: 1,
+				Buckets: []entity.ScoringBucket{
+					{Min: f(150), Points: 35},
+					{Min: f(50), Max: f(150), Points: 20},
+					{Min: f(0), Max: f(50), Points: 5},
+					{Max: f(0), Points: -40}, // Incluye la penalización por no alcanzar el mínimo del país
+				},
+			},
+			{
+				Name: "payment_history", Input: InputPaymentHistory, Weight: 1,
+				Buckets: []entity.ScoringBucket{
+					{Value: "GOOD", Points: 20},
+					{Value: "REGULAR", Points: 5},
+					{Value: "BAD", Points: -25},
+				},
+			},
+			{
+				Name: "existing_debt", Input: InputDebtRatioVsMax, Weight: 1,
+				Buckets: []entity.ScoringBucket{
+					{Max: f(0.25), Points: 10},
+					{Min: f(0.25), Max: f(0.5), Points: 5},
+					{Min: f(1), Points: -10},
+				},
+			},
+			{
+				Name: "employment_stability", Input: InputMonthsEmployed, Weight: 1,
+				Buckets: []entity.ScoringBucket{
+					{Min: f(24), Points: 10},
+					{Min: f(12), Max: f(24), Points: 5},
+					{Max: f(6), Points: -5},
+				},
+			},
+		},
+	}
+}
+
+func f(v float64) *float64 {
+	return &v
+}
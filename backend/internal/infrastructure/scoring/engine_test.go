@@ -0,0 +1,225 @@
+package scoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/domain/repository"
+	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+	"github.com/google/uuid"
+)
+
+// fakeModelRepo repositorio con el modelo activo y el challenger de un único país
+type fakeModelRepo struct {
+	repository.ScoringModelRepository
+
+	active     *entity.ScoringModel
+	challenger *entity.ScoringModel
+	err        error
+}
+
+func (r *fakeModelRepo) GetActiveByCountry(ctx context.Context, countryID uuid.UUID) (*entity.ScoringModel, error) {
+	return r.active, r.err
+}
+
+func (r *fakeModelRepo) GetChallengerByCountry(ctx context.Context, countryID uuid.UUID) (*entity.ScoringModel, error) {
+	return r.challenger, r.err
+}
+
+func storedModel(version int, def entity.ScoringDefinition) *entity.ScoringModel {
+	return &entity.ScoringModel{ID: uuid.New(), Version: version, Name: "model", Definition: def, IsActive: true}
+}
+
+func TestEngineModelForCountry(t *testing.T) {
+	invalid := validDefinition()
+	invalid.Factors = nil
+	dbErr := errors.New("db down")
+
+	tests := []struct {
+		name        string
+		repo        *fakeModelRepo
+		wantVersion int
+		wantErr     error
+	}{
+		{"modelo activo", &fakeModelRepo{active: storedModel(4, validDefinition())}, 4, nil},
+		{"sin modelo usa el de por defecto", &fakeModelRepo{}, BuiltinVersion, nil},
+		{"modelo inválido usa el de por defecto", &fakeModelRepo{active: storedModel(5, invalid)}, BuiltinVersion, nil},
+		{"error del repositorio", &fakeModelRepo{err: dbErr}, 0, dbErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewEngine(tt.repo, logger.NewLogger())
+			model, err := engine.ModelForCountry(context.Background(), uuid.New())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ModelForCountry error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ModelForCountry: %v", err)
+			}
+			if model.Version != tt.wantVersion {
+				t.Errorf("ModelForCountry version = %d, want %d", model.Version, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestEngineCachesCompiledVersions(t *testing.T) {
+	repo := &fakeModelRepo{active: storedModel(1, validDefinition())}
+	engine := NewEngine(repo, logger.NewLogger())
+
+	first, _ := engine.ModelForCountry(context.Background(), uuid.New())
+	second, _ := engine.ModelForCountry(context.Background(), uuid.New())
+	if first != second {
+		t.Errorf("the same stored version was compiled twice")
+	}
+
+	// Activar otra versión cambia el ID: se compila la nueva
+	repo.active = storedModel(2, validDefinition())
+	third, _ := engine.ModelForCountry(context.Background(), uuid.New())
+	if third == first || third.Version != 2 {
+		t.Errorf("ModelForCountry after activation = version %d", third.Version)
+	}
+}
+
+func TestEngineShouldRequireReview(t *testing.T) {
+	engine := NewEngine(&fakeModelRepo{}, logger.NewLogger())
+	country := &entity.Country{Config: entity.CountryConfig{ReviewThreshold: 10000}}
+
+	tests := []struct {
+		name  string
+		app   entity.CreditApplication
+		score float64
+		want  bool
+	}{
+		{"score de aprobación", entity.CreditApplication{RequestedAmount: 5000, Country: country}, 70, false},
+		{"score bajo el umbral de aprobación", entity.CreditApplication{RequestedAmount: 5000, Country: country}, 69.9, true},
+		{"monto en el umbral de revisión", entity.CreditApplication{RequestedAmount: 10000, Country: country}, 100, true},
+		{"sin país cargado", entity.CreditApplication{RequestedAmount: 50000}, 90, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := engine.ShouldRequireReview(context.Background(), &tt.app, tt.score); got != tt.want {
+				t.Errorf("ShouldRequireReview = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	failing := NewEngine(&fakeModelRepo{err: errors.New("db down")}, logger.NewLogger())
+	if !failing.ShouldRequireReview(context.Background(), &entity.CreditApplication{}, 100) {
+		t.Errorf("ShouldRequireReview without a model must require review")
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	tests := []struct {
+		decision    entity.ScoringDecision
+		forceReview bool
+		want        entity.ApplicationStatus
+	}{
+		{entity.ScoringDecisionApprove, false, entity.StatusApproved},
+		{entity.ScoringDecisionReview, false, entity.StatusUnderReview},
+		{entity.ScoringDecisionReject, false, entity.StatusRejected},
+		{entity.ScoringDecisionApprove, true, entity.StatusUnderReview},
+		{entity.ScoringDecisionReject, true, entity.StatusUnderReview},
+	}
+
+	for _, tt := range tests {
+		if got := Outcome(tt.decision, tt.forceReview); got != tt.want {
+			t.Errorf("Outcome(%s, %v) = %s, want %s", tt.decision, tt.forceReview, got, tt.want)
+		}
+	}
+}
+
+func TestEngineChallengerForCountry(t *testing.T) {
+	challenger := storedModel(3, validDefinition())
+	challenger.IsActive, challenger.IsChallenger, challenger.TrafficPercent = false, true, 20
+	invalid := storedModel(4, entity.ScoringDefinition{})
+	invalid.IsChallenger, invalid.TrafficPercent = true, 50
+	dbErr := errors.New("db down")
+
+	tests := []struct {
+		name        string
+		repo        *fakeModelRepo
+		wantVersion int // 0 = sin challenger
+		wantTraffic int
+		wantErr     error
+	}{
+		{"challenger con tráfico", &fakeModelRepo{challenger: challenger}, 3, 20, nil},
+		{"sin challenger", &fakeModelRepo{}, 0, 0, nil},
+		{"challenger inválido se omite", &fakeModelRepo{challenger: invalid}, 0, 0, nil},
+		{"error del repositorio", &fakeModelRepo{err: dbErr}, 0, 0, dbErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewEngine(tt.repo, logger.NewLogger())
+			model, traffic, err := engine.ChallengerForCountry(context.Background(), uuid.New())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChallengerForCountry error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantVersion == 0 {
+				if model != nil || traffic != 0 {
+					t.Errorf("ChallengerForCountry = %+v at %d%%, want none", model, traffic)
+				}
+				return
+			}
+			if model == nil || model.Version != tt.wantVersion || traffic != tt.wantTraffic {
+				t.Fatalf("ChallengerForCountry = %+v at %d%%, want version %d at %d%%", model, traffic, tt.wantVersion, tt.wantTraffic)
+			}
+		})
+	}
+}
+
+func TestRoutesToChallenger(t *testing.T) {
+	const samples = 10000
+	ids := make([]uuid.UUID, samples)
+	for i := range ids {
+		ids[i] = uuid.NewSHA1(uuid.NameSpaceOID, []byte(fmt.Sprint(i)))
+	}
+
+	tests := []struct {
+		traffic int
+		min     int // Solicitudes que decide el challenger
+		max     int
+	}{
+		{-10, 0, 0},
+		{0, 0, 0},
+		{1, 50, 150},
+		{20, 1800, 2200},
+		{50, 4800, 5200},
+		{100, samples, samples},
+		{150, samples, samples},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d%%", tt.traffic), func(t *testing.T) {
+			routed := 0
+			for _, id := range ids {
+				if RoutesToChallenger(id, tt.traffic) {
+					routed++
+				}
+			}
+			if routed < tt.min || routed > tt.max {
+				t.Errorf("RoutesToChallenger(%d%%) routed %d of %d, want between %d and %d", tt.traffic, routed, samples, tt.min, tt.max)
+			}
+		})
+	}
+
+	// El reparto es estable por solicitud y monótono: subir el porcentaje no saca a nadie
+	for _, id := range ids[:500] {
+		if RoutesToChallenger(id, 30) != RoutesToChallenger(id, 30) {
+			t.Fatalf("RoutesToChallenger is not deterministic for %s", id)
+		}
+		if RoutesToChallenger(id, 30) && !RoutesToChallenger(id, 60) {
+			t.Fatalf("application %s leaves the challenger when the traffic grows", id)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package scoring
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+)
+
+// Variables de entrada disponibles para los factores del modelo
+const (
+	InputRequestedAmount         = "requested_amount"
+	InputMonthlyIncome           = "monthly_income"
+	InputRequestedToAnnualIncome = "requested_to_annual_income"
+	InputRequestedRatioVsMax     = "requested_ratio_vs_max" // requested_to_annual_income / max_debt_to_income_ratio del país
+	InputCreditScore             = "credit_score"
+	InputCreditScoreMargin       = "credit_score_margin" // credit_score - min_credit_score del país
+	InputPaymentHistory          = "payment_history"
+	InputTotalDebt               = "total_debt"
+	InputDebtToAnnualIncome      = "debt_to_annual_income"
+	InputDebtRatioVsMax          = "debt_ratio_vs_max" // debt_to_annual_income / max_debt_to_income_ratio del país
+	InputAvailableCredit         = "available_credit"
+	InputMonthsEmployed          = "months_employed"
+	InputActiveLoans             = "active_loans"
+	InputBankAccounts            = "bank_accounts"
+)
+
+// categoricalInputs variables que se comparan por valor en lugar de por tramos
+var categoricalInputs = map[string]bool{
+	InputPaymentHistory: true,
+}
+
+var knownInputs = map[string]bool{
+	InputRequestedAmount: true, InputMonthlyIncome: true, InputRequestedToAnnualIncome: true,
+	InputRequestedRatioVsMax: true, InputCreditScore: true, InputCreditScoreMargin: true,
+	InputPaymentHistory: true, InputTotalDebt: true, InputDebtToAnnualIncome: true,
+	InputDebtRatioVsMax: true, InputAvailableCredit: true, InputMonthsEmployed: true,
+	InputActiveLoans: true, InputBankAccounts: true,
+}
+
+// Valores por defecto de la configuración del país usados por las variables relativas
+const (
+	defaultMaxDebtToIncomeRatio = 0.4
+	defaultMinCreditScore       = 600
+)
+
+// Inputs valores de entrada de una evaluación; una variable ausente no está disponible
+type Inputs map[string]interface{}
+
+// CountryParams parámetros del país que alimentan las variables relativas
+type CountryParams struct {
+	MaxDebtToIncomeRatio float64
+	MinCreditScore       int
+}
+
+// BuildInputs calcula las variables de entrada a partir de la solicitud
+// Las variables del buró solo existen si hay información bancaria
+func BuildInputs(app *entity.CreditApplication, params CountryParams) Inputs {
+	maxRatio := params.MaxDebtToIncomeRatio
+	if maxRatio == 0 {
+		maxRatio = defaultMaxDebtToIncomeRatio
+	}
+	minScore := params.MinCreditScore
+	if minScore == 0 {
+		minScore = defaultMinCreditScore
+	}
+
+	in := Inputs{
+		InputRequestedAmount: app.RequestedAmount,
+		InputMonthlyIncome:   app.MonthlyIncome,
+	}
+	annualIncome := app.MonthlyIncome * 12
+	if annualIncome > 0 {
+		ratio := app.RequestedAmount / annualIncome
+		in[InputRequestedToAnnualIncome] = ratio
+		in[InputRequestedRatioVsMax] = ratio / maxRatio
+	}
+
+	info := app.BankingInfo
+	if info == nil {
+		return in
+	}
+	if info.CreditScore != nil {
+		in[InputCreditScore] = float64(*info.CreditScore)
+		in[InputCreditScoreMargin] = float64(*info.CreditScore - minScore)
+	}
+	if info.PaymentHistory != nil {
+		in[InputPaymentHistory] = *info.PaymentHistory
+	}
+	if info.TotalDebt != nil {
+		in[InputTotalDebt] = *info.TotalDebt
+		if annualIncome > 0 {
+			debtRatio := *info.TotalDebt / annualIncome
+			in[InputDebtToAnnualIncome] = debtRatio
+			in[InputDebtRatioVsMax] = debtRatio / maxRatio
+		}
+	}
+	if info.AvailableCredit != nil {
+		in[InputAvailableCredit] = *info.AvailableCredit
+	}
+	if info.MonthsEmployed != nil {
+		in[InputMonthsEmployed] = float64(*info.MonthsEmployed)
+	}
+	in[InputActiveLoans] = float64(info.ActiveLoans)
+	in[InputBankAccounts] = float64(info.BankAccounts)
+
+	return in
+}
+
+// Model modelo de scoring validado y listo para evaluar
+type Model struct {
+	ID         string
+	Version    int
+	Name       string
+	definition entity.ScoringDefinition
+}
+
+// Result resultado de evaluar el modelo
+type Result struct {
+	ModelID      string                    `json:"model_id,omitempty"`
+	ModelVersion int                       `json:"model_version"`
+	Score        float64                   `json:"score"`
+	Decision     entity.ScoringDecision    `json:"decision"`
+	Factors      []entity.RiskFactorResult `json:"factors"`
+	ReasonCodes  []entity.ReasonCode       `json:"reason_codes"` // Motivos adversos, el de mayor impacto primero
+}
+
+// Compile valida una definición y construye el modelo
+func Compile(id string, version int, name string, def entity.ScoringDefinition) (*Model, error) {
+	if def.MaxScore <= def.MinScore {
+		return nil, fmt.Errorf("max_score must be greater than min_score")
+	}
+	if def.Cutoffs.Approve < def.Cutoffs.Review {
+		return nil, fmt.Errorf("approve cutoff (%.2f) must be >= review cutoff (%.2f)", def.Cutoffs.Approve, def.Cutoffs.Review)
+	}
+	if len(def.Factors) == 0 {
+		return nil, fmt.Errorf("model has no factors")
+	}
+
+	names := make(map[string]bool, len(def.Factors))
+	for i, f := range def.Factors {
+		if f.Name == "" {
+			return nil, fmt.Errorf("factor %d has no name", i)
+		}
+		if names[f.Name] {
+			return nil, fmt.Errorf("duplicate factor %q", f.Name)
+		}
+		names[f.Name] = true
+
+		if !knownInputs[f.Input] {
+			return nil, fmt.Errorf("factor %q: unknown input %q", f.Name, f.Input)
+		}
+		if len(f.Buckets) == 0 {
+			return nil, fmt.Errorf("factor %q has no buckets", f.Name)
+		}
+		for j, b := range f.Buckets {
+			if categoricalInputs[f.Input] {
+				if b.Value == "" {
+					return nil, fmt.Errorf("factor %q bucket %d: categorical input requires value", f.Name, j)
+				}
+				continue
+			}
+			if b.Value != "" {
+				return nil, fmt.Errorf("factor %q bucket %d: numeric input does not accept value", f.Name, j)
+			}
+			if b.Min != nil && b.Max != nil && *b.Min >= *b.Max {
+				return nil, fmt.Errorf("factor %q bucket %d: min must be lower than max", f.Name, j)
+			}
+		}
+	}
+
+	return &Model{ID: id, Version: version, Name: name, definition: def}, nil
+}
+
+// CompileModel valida y construye un modelo almacenado
+func CompileModel(m *entity.ScoringModel) (*Model, error) {
+	return Compile(m.ID.String(), m.Version, m.Name, m.Definition)
+}
+
+// Evaluate calcula el score, la decisión y el desglose por factor
+func (m *Model) Evaluate(in Inputs) *Result {
+	def := m.definition
+	score := def.BaseScore
+	factors := make([]entity.RiskFactorResult, 0, len(def.Factors))
+
+	for _, f := range def.Factors {
+		r := entity.RiskFactorResult{
+			Factor:          f.Name,
+			Input:           f.Input,
+			Weight:          f.Weight,
+			MaxContribution: maxContribution(f),
+		}
+		value, ok := in[f.Input]
+		if ok {
+			r.Available = true
+			r.Value = value
+			if b := matchBucket(f.Buckets, value); b != nil {
+				r.Points = b.Points
+				r.Bucket = bucketLabel(b)
+				r.ReasonCode = b.ReasonCode
+			}
+		} else {
+			if f.MissingPoints != nil {
+				r.Points = *f.MissingPoints
+			}
+			r.ReasonCode = missingReasonCode(f.Input)
+		}
+		if r.ReasonCode == "" {
+			r.ReasonCode = inputReasonCodes[f.Input]
+		}
+		r.Contribution = r.Points * f.Weight
+		score += r.Contribution
+		factors = append(factors, r)
+	}
+
+	score = math.Max(def.MinScore, math.Min(def.MaxScore, score))
+
+	decision := entity.ScoringDecisionReject
+	switch {
+	case score >= def.Cutoffs.Approve:
+		decision = entity.ScoringDecisionApprove
+	case score >= def.Cutoffs.Review:
+		decision = entity.ScoringDecisionReview
+	}
+
+	return &Result{
+		ModelID:      m.ID,
+		ModelVersion: m.Version,
+		Score:        score,
+		Decision:     decision,
+		Factors:      factors,
+		ReasonCodes:  adverseReasons(factors),
+	}
+}
+
+// Cutoffs umbrales de decisión del modelo
+func (m *Model) Cutoffs() entity.ScoringCutoffs {
+	return m.definition.Cutoffs
+}
+
+// matchBucket devuelve el primer tramo que coincide (nil si ninguno)
+func matchBucket(buckets []entity.ScoringBucket, value interface{}) *entity.ScoringBucket {
+	switch v := value.(type) {
+	case string:
+		for i := range buckets {
+			if buckets[i].Value == v {
+				return &buckets[i]
+			}
+		}
+	case float64:
+		for i := range buckets {
+			b := &buckets[i]
+			if b.Min != nil && v < *b.Min {
+				continue
+			}
+			if b.Max != nil && v >= *b.Max {
+				continue
+			}
+			return b
+		}
+	}
+	return nil
+}
+
+// maxContribution mejor aporte posible del factor entre sus tramos
+// Un valor que no coincide con ningún tramo aporta 0, que también cuenta como opción
+func maxContribution(f entity.ScoringFactor) float64 {
+	best := 0.0
+	for _, b := range f.Buckets {
+		best = math.Max(best, b.Points*f.Weight)
+	}
+	return best
+}
+
+// missingReasonCode motivo de una variable no disponible
+// Las relaciones monto/ingreso solo faltan si el ingreso es cero; el resto viene del buró
+func missingReasonCode(input string) string {
+	switch input {
+	case InputRequestedToAnnualIncome, InputRequestedRatioVsMax:
+		return ReasonIncome
+	case InputRequestedAmount, InputMonthlyIncome:
+		return inputReasonCodes[input]
+	}
+	return ReasonBureauUnavailable
+}
+
+// bucketLabel representación legible de un tramo, ej. "[50, 150)" o "GOOD"
+func bucketLabel(b *entity.ScoringBucket) string {
+	if b.Value != "" {
+		return b.Value
+	}
+	lower, upper := "-inf", "+inf"
+	if b.Min != nil {
+		lower = strconv.FormatFloat(*b.Min, 'f', -1, 64)
+	}
+	if b.Max != nil {
+		upper = strconv.FormatFloat(*b.Max, 'f', -1, 64)
+	}
+	return "[" + lower + ", " + upper + ")"
+}
@@ -0,0 +1,346 @@
+package scoring
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+)
+
+func intPtr(v int) *int       { return &v }
+func strPtr(v string) *string { return &v }
+
+// validDefinition modelo mínimo válido sobre el que cada caso cambia algo
+func validDefinition() entity.ScoringDefinition {
+	return entity.ScoringDefinition{
+		BaseScore: 50, MinScore: 0, MaxScore: 100,
+		Cutoffs: entity.ScoringCutoffs{Approve: 70, Review: 40},
+		Factors: []entity.ScoringFactor{
+			{Name: "score", Input: InputCreditScore, Weight: 1, Buckets: []entity.ScoringBucket{{Min: f(700), Points: 20}}},
+		},
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(d *entity.ScoringDefinition)
+		wantErr string
+	}{
+		{"válido", func(d *entity.ScoringDefinition) {}, ""},
+		{"modelo por defecto", func(d *entity.ScoringDefinition) { *d = DefaultDefinition() }, ""},
+		{"rango de score vacío", func(d *entity.ScoringDefinition) { d.MaxScore = d.MinScore }, "max_score must be greater than min_score"},
+		{"umbrales invertidos", func(d *entity.ScoringDefinition) { d.Cutoffs.Approve = 30 }, "approve cutoff (30.00) must be >= review cutoff (40.00)"},
+		{"umbrales iguales", func(d *entity.ScoringDefinition) { d.Cutoffs.Approve = 40 }, ""},
+		{"sin factores", func(d *entity.ScoringDefinition) { d.Factors = nil }, "model has no factors"},
+		{"factor sin nombre", func(d *entity.ScoringDefinition) { d.Factors[0].Name = "" }, "factor 0 has no name"},
+		{"factor repetido", func(d *entity.ScoringDefinition) { d.Factors = append(d.Factors, d.Factors[0]) }, `duplicate factor "score"`},
+		{"variable desconocida", func(d *entity.ScoringDefinition) { d.Factors[0].Input = "salary" }, `unknown input "salary"`},
+		{"sin tramos", func(d *entity.ScoringDefinition) { d.Factors[0].Buckets = nil }, `factor "score" has no buckets`},
+		{
+			name: "tramo con min >= max",
+			change: func(d *entity.ScoringDefinition) {
+				d.Factors[0].Buckets = []entity.ScoringBucket{{Min: f(700), Max: f(700)}}
+			},
+			wantErr: "bucket 0: min must be lower than max",
+		},
+		{
+			name:    "valor en variable numérica",
+			change:  func(d *entity.ScoringDefinition) { d.Factors[0].Buckets[0].Value = "GOOD" },
+			wantErr: "numeric input does not accept value",
+		},
+		{
+			name: "categórica sin valor",
+			change: func(d *entity.ScoringDefinition) {
+				d.Factors[0].Input = InputPaymentHistory
+				d.Factors[0].Buckets = []entity.ScoringBucket{{Value: "GOOD"}, {Min: f(1)}}
+			},
+			wantErr: "bucket 1: categorical input requires value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def := validDefinition()
+			tt.change(&def)
+			model, err := Compile("id", 3, "model", def)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Compile error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			if model.Version != 3 || model.Cutoffs() != def.Cutoffs {
+				t.Errorf("Compile = version %d cutoffs %+v", model.Version, model.Cutoffs())
+			}
+		})
+	}
+}
+
+func TestMatchBucket(t *testing.T) {
+	numeric := []entity.ScoringBucket{
+		{Max: f(0), Points: -10},
+		{Min: f(0), Max: f(50), Points: 5},
+		{Min: f(100), Points: 20},
+	}
+	categorical := []entity.ScoringBucket{{Value: "GOOD", Points: 20}, {Value: "BAD", Points: -25}}
+
+	tests := []struct {
+		name    string
+		buckets []entity.ScoringBucket
+		value   interface{}
+		want    float64
+		found   bool
+	}{
+		{"sin mínimo", numeric, -1e9, -10, true},
+		{"min inclusivo", numeric, 0.0, 5, true},
+		{"max exclusivo", numeric, 50.0, 0, false},
+		{"hueco entre tramos", numeric, 75.0, 0, false},
+		{"sin máximo", numeric, 100.0, 20, true},
+		{"categórica", categorical, "BAD", -25, true},
+		{"categórica desconocida", categorical, "REGULAR", 0, false},
+		{"categórica distingue mayúsculas", categorical, "good", 0, false},
+		{"tipo distinto", numeric, "10", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := matchBucket(tt.buckets, tt.value)
+			if (b != nil) != tt.found {
+				t.Fatalf("matchBucket(%v) = %+v, want found=%v", tt.value, b, tt.found)
+			}
+			if b != nil && b.Points != tt.want {
+				t.Errorf("matchBucket(%v) points = %v, want %v", tt.value, b.Points, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildInputs(t *testing.T) {
+	info := &entity.BankingInfo{
+		CreditScore:     intPtr(720),
+		PaymentHistory:  strPtr("GOOD"),
+		TotalDebt:       f(4800),
+		AvailableCredit: f(3000),
+		MonthsEmployed:  intPtr(18),
+		ActiveLoans:     2,
+		BankAccounts:    1,
+	}
+
+	tests := []struct {
+		name   string
+		app    entity.CreditApplication
+		params CountryParams
+		want   Inputs
+	}{
+		{
+			name: "sin información bancaria",
+			app:  entity.CreditApplication{RequestedAmount: 4800, MonthlyIncome: 2000},
+			want: Inputs{
+				InputRequestedAmount: 4800.0, InputMonthlyIncome: 2000.0,
+				InputRequestedToAnnualIncome: 0.2, InputRequestedRatioVsMax: 0.5,
+			},
+		},
+		{
+			name: "sin ingresos no hay relaciones",
+			app:  entity.CreditApplication{RequestedAmount: 4800, BankingInfo: &entity.BankingInfo{TotalDebt: f(100)}},
+			want: Inputs{
+				InputRequestedAmount: 4800.0, InputMonthlyIncome: 0.0, InputTotalDebt: 100.0,
+				InputActiveLoans: 0.0, InputBankAccounts: 0.0,
+			},
+		},
+		{
+			name:   "con buró y parámetros del país",
+			app:    entity.CreditApplication{RequestedAmount: 4800, MonthlyIncome: 2000, BankingInfo: info},
+			params: CountryParams{MaxDebtToIncomeRatio: 0.5, MinCreditScore: 650},
+			want: Inputs{
+				InputRequestedAmount: 4800.0, InputMonthlyIncome: 2000.0,
+				InputRequestedToAnnualIncome: 0.2, InputRequestedRatioVsMax: 0.4,
+				InputCreditScore: 720.0, InputCreditScoreMargin: 70.0,
+				InputPaymentHistory: "GOOD",
+				InputTotalDebt:      4800.0, InputDebtToAnnualIncome: 0.2, InputDebtRatioVsMax: 0.4,
+				InputAvailableCredit: 3000.0, InputMonthsEmployed: 18.0,
+				InputActiveLoans: 2.0, InputBankAccounts: 1.0,
+			},
+		},
+		{
+			name: "parámetros por defecto",
+			app:  entity.CreditApplication{RequestedAmount: 4800, MonthlyIncome: 2000, BankingInfo: &entity.BankingInfo{CreditScore: intPtr(550)}},
+			want: Inputs{
+				InputRequestedAmount: 4800.0, InputMonthlyIncome: 2000.0,
+				InputRequestedToAnnualIncome: 0.2, InputRequestedRatioVsMax: 0.5,
+				InputCreditScore: 550.0, InputCreditScoreMargin: -50.0,
+				InputActiveLoans: 0.0, InputBankAccounts: 0.0,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildInputs(&tt.app, tt.params)
+			if len(got) != len(tt.want) {
+				t.Errorf("BuildInputs = %v, want %v", got, tt.want)
+			}
+			for k, want := range tt.want {
+				v, ok := got[k]
+				if !ok {
+					t.Errorf("BuildInputs missing %s", k)
+					continue
+				}
+				if wf, isFloat := want.(float64); isFloat {
+					if gf, _ := v.(float64); math.Abs(gf-wf) > 1e-9 {
+						t.Errorf("%s = %v, want %v", k, v, want)
+					}
+				} else if v != want {
+					t.Errorf("%s = %v, want %v", k, v, want)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateDefaultModel(t *testing.T) {
+	model, err := Compile("", BuiltinVersion, "builtin", DefaultDefinition())
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		app      entity.CreditApplication
+		want     float64
+		decision entity.ScoringDecision
+	}{
+		{
+			name: "buen perfil se acota al máximo",
+			app: entity.CreditApplication{RequestedAmount: 5000, MonthlyIncome: 2000, BankingInfo: &entity.BankingInfo{
+				CreditScore: intPtr(750), PaymentHistory: strPtr("GOOD"), TotalDebt: f(2000), MonthsEmployed: intPtr(36),
+			}},
+			want: 100, decision: entity.ScoringDecisionApprove,
+		},
+		{
+			name: "mal perfil se acota al mínimo",
+			app: entity.CreditApplication{RequestedAmount: 30000, MonthlyIncome: 2000, BankingInfo: &entity.BankingInfo{
+				CreditScore: intPtr(550), PaymentHistory: strPtr("BAD"), TotalDebt: f(20000), MonthsEmployed: intPtr(3),
+			}},
+			want: 0, decision: entity.ScoringDecisionReject,
+		},
+		{
+			// 50 + 15 (monto) + 5 (score) + 5 (historial) + 0 (deuda en el hueco) + 5 (empleo)
+			name: "perfil medio",
+			app: entity.CreditApplication{RequestedAmount: 5000, MonthlyIncome: 2000, BankingInfo: &entity.BankingInfo{
+				CreditScore: intPtr(620), PaymentHistory: strPtr("REGULAR"), TotalDebt: f(7200), MonthsEmployed: intPtr(12),
+			}},
+			want: 80, decision: entity.ScoringDecisionApprove,
+		},
+		{
+			name: "sin buró solo puntúa el monto",
+			app:  entity.CreditApplication{RequestedAmount: 5000, MonthlyIncome: 2000},
+			want: 65, decision: entity.ScoringDecisionReview,
+		},
+		{
+			name: "monto en el hueco entre tramos no suma",
+			app:  entity.CreditApplication{RequestedAmount: 10500, MonthlyIncome: 2000},
+			want: 50, decision: entity.ScoringDecisionReview,
+		},
+		{
+			name: "monto excesivo sin buró",
+			app:  entity.CreditApplication{RequestedAmount: 20000, MonthlyIncome: 2000},
+			want: 35, decision: entity.ScoringDecisionReject,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := model.Evaluate(BuildInputs(&tt.app, CountryParams{}))
+			if result.Score != tt.want || result.Decision != tt.decision {
+				t.Errorf("Evaluate = %v %s, want %v %s", result.Score, result.Decision, tt.want, tt.decision)
+			}
+			if result.ModelVersion != BuiltinVersion || len(result.Factors) != 5 {
+				t.Errorf("Evaluate = version %d with %d factors", result.ModelVersion, len(result.Factors))
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	def := entity.ScoringDefinition{
+		BaseScore: 10, MinScore: 0, MaxScore: 100,
+		Cutoffs: entity.ScoringCutoffs{Approve: 60, Review: 30},
+		Factors: []entity.ScoringFactor{
+			{
+				Name: "score", Input: InputCreditScore, Weight: 2, MissingPoints: f(-5),
+				Buckets: []entity.ScoringBucket{{Min: f(700), Points: 20}, {Max: f(700), Points: 5}},
+			},
+			{
+				Name: "history", Input: InputPaymentHistory, Weight: 0.5,
+				Buckets: []entity.ScoringBucket{{Value: "GOOD", Points: 20}},
+			},
+		},
+	}
+	model, err := Compile("m", 2, "test", def)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		in       Inputs
+		want     float64
+		decision entity.ScoringDecision
+		factors  []entity.RiskFactorResult
+	}{
+		{
+			name: "pesos aplicados", in: Inputs{InputCreditScore: 720.0, InputPaymentHistory: "GOOD"},
+			want: 60, decision: entity.ScoringDecisionApprove, // 10 + 2×20 + 0.5×20, justo en el umbral
+			factors: []entity.RiskFactorResult{
+				{Factor: "score", Input: InputCreditScore, Value: 720.0, Available: true, Bucket: "[700, +inf)", Points: 20, Weight: 2, Contribution: 40, MaxContribution: 40, ReasonCode: ReasonCreditScore},
+				{Factor: "history", Input: InputPaymentHistory, Value: "GOOD", Available: true, Bucket: "GOOD", Points: 20, Weight: 0.5, Contribution: 10, MaxContribution: 10, ReasonCode: ReasonPaymentHistory},
+			},
+		},
+		{
+			name: "valor sin tramo", in: Inputs{InputCreditScore: 650.0, InputPaymentHistory: "BAD"},
+			want: 20, decision: entity.ScoringDecisionReject,
+			factors: []entity.RiskFactorResult{
+				{Factor: "score", Input: InputCreditScore, Value: 650.0, Available: true, Bucket: "[-inf, 700)", Points: 5, Weight: 2, Contribution: 10, MaxContribution: 40, ReasonCode: ReasonCreditScore},
+				{Factor: "history", Input: InputPaymentHistory, Value: "BAD", Available: true, Weight: 0.5, MaxContribution: 10, ReasonCode: ReasonPaymentHistory},
+			},
+		},
+		{
+			name: "variable ausente usa missing_points", in: Inputs{InputPaymentHistory: "GOOD"},
+			want: 10, decision: entity.ScoringDecisionReject,
+			factors: []entity.RiskFactorResult{
+				{Factor: "score", Input: InputCreditScore, Points: -5, Weight: 2, Contribution: -10, MaxContribution: 40, ReasonCode: ReasonBureauUnavailable},
+				{Factor: "history", Input: InputPaymentHistory, Value: "GOOD", Available: true, Bucket: "GOOD", Points: 20, Weight: 0.5, Contribution: 10, MaxContribution: 10, ReasonCode: ReasonPaymentHistory},
+			},
+		},
+		{
+			name: "se acota al mínimo", in: Inputs{},
+			want: 0, decision: entity.ScoringDecisionReject,
+			factors: []entity.RiskFactorResult{
+				{Factor: "score", Input: InputCreditScore, Points: -5, Weight: 2, Contribution: -10, MaxContribution: 40, ReasonCode: ReasonBureauUnavailable},
+				{Factor: "history", Input: InputPaymentHistory, Weight: 0.5, MaxContribution: 10, ReasonCode: ReasonBureauUnavailable},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := model.Evaluate(tt.in)
+			if result.Score != tt.want || result.Decision != tt.decision {
+				t.Errorf("Evaluate = %v %s, want %v %s", result.Score, result.Decision, tt.want, tt.decision)
+			}
+			if result.ModelID != "m" || result.ModelVersion != 2 {
+				t.Errorf("Evaluate model = %s v%d", result.ModelID, result.ModelVersion)
+			}
+			if !reflect.DeepEqual(result.Factors, tt.factors) {
+				t.Errorf("Evaluate factors =\n%+v\nwant\n%+v", result.Factors, tt.factors)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package scoring
+
+import (
+	"sort"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+)
+
+// Códigos de motivo (adverse action) comunicables al solicitante
+const (
+	ReasonAmountVsIncome     = "RC01"
+	ReasonCreditScore        = "RC02"
+	ReasonPaymentHistory     = "RC03"
+	ReasonDebtLevel          = "RC04"
+	ReasonEmploymentLength   = "RC05"
+	ReasonAvailableCredit    = "RC06"
+	ReasonActiveLoans        = "RC07"
+	ReasonBankRelationship   = "RC08"
+	ReasonIncome             = "RC09"
+	ReasonBureauUnavailable  = "RC10"
+	ReasonAmountReviewPolicy = "RC20"
+)
+
+// MaxReasonCodes número máximo de motivos que se informan por decisión
+const MaxReasonCodes = 4
+
+// reasonDescriptions catálogo de descripciones de cada código
+var reasonDescriptions = map[string]string{
+	ReasonAmountVsIncome:     "Requested amount is high relative to income",
+	ReasonCreditScore:        "Credit score is below the required level",
+	ReasonPaymentHistory:     "Payment history with creditors",
+	ReasonDebtLevel:          "Existing debt is high relative to income",
+	ReasonEmploymentLength:   "Length of current employment",
+	ReasonAvailableCredit:    "Insufficient available credit",
+	ReasonActiveLoans:        "Number of active loans",
+	ReasonBankRelationship:   "Limited banking relationship",
+	ReasonIncome:             "Income is insufficient for the requested amount",
+	ReasonBureauUnavailable:  "Credit bureau information was not available",
+	ReasonAmountReviewPolicy: "Requested amount requires manual review by country policy",
+}
+
+// inputReasonCodes código por defecto de cada variable de entrada
+var inputReasonCodes = map[string]string{
+	InputRequestedAmount:         ReasonAmountVsIncome,
+	InputMonthlyIncome:           ReasonIncome,
+	InputRequestedToAnnualIncome: ReasonAmountVsIncome,
+	InputRequestedRatioVsMax:     ReasonAmountVsIncome,
+	InputCreditScore:             ReasonCreditScore,
+	InputCreditScoreMargin:       ReasonCreditScore,
+	InputPaymentHistory:          ReasonPaymentHistory,
+	InputTotalDebt:               ReasonDebtLevel,
+	InputDebtToAnnualIncome:      ReasonDebtLevel,
+	InputDebtRatioVsMax:          ReasonDebtLevel,
+	InputAvailableCredit:         ReasonAvailableCredit,
+	InputMonthsEmployed:          ReasonEmploymentLength,
+	InputActiveLoans:             ReasonActiveLoans,
+	InputBankAccounts:            ReasonBankRelationship,
+}
+
+// NewReasonCode construye un motivo con la descripción del catálogo
+func NewReasonCode(code, factor string, pointsLost float64) entity.ReasonCode {
+	return entity.ReasonCode{
+		Code:        code,
+		Description: reasonDescriptions[code],
+		Factor:      factor,
+		PointsLost:  pointsLost,
+	}
+}
+
+// adverseReasons motivos de los factores que restaron respecto a su mejor aporte
+// Ordenados por puntos perdidos (mayor primero) y sin códigos repetidos
+func adverseReasons(factors []entity.RiskFactorResult) []entity.ReasonCode {
+	candidates := make([]entity.RiskFactorResult, 0, len(factors))
+	for _, f := range factors {
+		if f.ReasonCode != "" && f.MaxContribution-f.Contribution > 0 {
+			candidates = append(candidates, f)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].MaxContribution-candidates[i].Contribution >
+			candidates[j].MaxContribution-candidates[j].Contribution
+	})
+
+	reasons := make([]entity.ReasonCode, 0, MaxReasonCodes)
+	seen := make(map[string]bool)
+	for _, f := range candidates {
+		if seen[f.ReasonCode] {
+			continue
+		}
+		seen[f.ReasonCode] = true
+		reasons = append(reasons, NewReasonCode(f.ReasonCode, f.Factor, f.MaxContribution-f.Contribution))
+		if len(reasons) == MaxReasonCodes {
+			break
+		}
+	}
+	return reasons
+}
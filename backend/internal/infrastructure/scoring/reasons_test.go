@@ -0,0 +1,219 @@
+package scoring
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+	"github.com/google/uuid"
+)
+
+func factorResult(name, code string, contribution, max float64) entity.RiskFactorResult {
+	return entity.RiskFactorResult{Factor: name, ReasonCode: code, Contribution: contribution, MaxContribution: max}
+}
+
+func TestAdverseReasons(t *testing.T) {
+	tests := []struct {
+		name    string
+		factors []entity.RiskFactorResult
+		want    []string // Código/factor en orden
+	}{
+		{
+			name:    "sin pérdidas no hay motivos",
+			factors: []entity.RiskFactorResult{factorResult("score", ReasonCreditScore, 35, 35)},
+			want:    []string{},
+		},
+		{
+			name: "ordenados por puntos perdidos",
+			factors: []entity.RiskFactorResult{
+				factorResult("employment", ReasonEmploymentLength, 5, 10),
+				factorResult("score", ReasonCreditScore, -40, 35),
+				factorResult("history", ReasonPaymentHistory, 5, 20),
+			},
+			want: []string{"RC02/score", "RC03/history", "RC05/employment"},
+		},
+		{
+			name: "empate conserva el orden del modelo",
+			factors: []entity.RiskFactorResult{
+				factorResult("debt", ReasonDebtLevel, 0, 10),
+				factorResult("employment", ReasonEmploymentLength, 0, 10),
+			},
+			want: []string{"RC04/debt", "RC05/employment"},
+		},
+		{
+			name: "código repetido se informa una vez con el factor de mayor impacto",
+			factors: []entity.RiskFactorResult{
+				factorResult("debt_ratio", ReasonDebtLevel, 5, 10),
+				factorResult("total_debt", ReasonDebtLevel, -10, 10),
+			},
+			want: []string{"RC04/total_debt"},
+		},
+		{
+			name: "factor sin código no genera motivo",
+			factors: []entity.RiskFactorResult{
+				factorResult("custom", "", 0, 50),
+				factorResult("score", ReasonCreditScore, 20, 35),
+			},
+			want: []string{"RC02/score"},
+		},
+		{
+			name: "como máximo MaxReasonCodes",
+			factors: []entity.RiskFactorResult{
+				factorResult("a", ReasonAmountVsIncome, 0, 60),
+				factorResult("b", ReasonCreditScore, 0, 50),
+				factorResult("c", ReasonPaymentHistory, 0, 40),
+				factorResult("d", ReasonDebtLevel, 0, 30),
+				factorResult("e", ReasonEmploymentLength, 0, 20),
+			},
+			want: []string{"RC01/a", "RC02/b", "RC03/c", "RC04/d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reasons := adverseReasons(tt.factors)
+			got := make([]string, 0, len(reasons))
+			for _, r := range reasons {
+				got = append(got, r.Code+"/"+r.Factor)
+				if r.Description == "" || r.PointsLost <= 0 {
+					t.Errorf("reason %s = %+v, want a description and lost points", r.Code, r)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("adverseReasons = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateReasonCodes(t *testing.T) {
+	def := entity.ScoringDefinition{
+		BaseScore: 50, MinScore: 0, MaxScore: 100,
+		Cutoffs: entity.ScoringCutoffs{Approve: 70, Review: 40},
+		Factors: []entity.ScoringFactor{
+			{
+				Name: "score", Input: InputCreditScoreMargin, Weight: 1,
+				Buckets: []entity.ScoringBucket{
+					{Min: f(100), Points: 30},
+					{Max: f(0), Points: -30, ReasonCode: "RC99"}, // Código propio del tramo
+				},
+			},
+			{
+				Name: "amount", Input: InputRequestedRatioVsMax, Weight: 1,
+				Buckets: []entity.ScoringBucket{{Max: f(0.5), Points: 20}},
+			},
+			{
+				Name: "employment", Input: InputMonthsEmployed, Weight: 1,
+				Buckets: []entity.ScoringBucket{{Min: f(24), Points: 10}},
+			},
+		},
+	}
+	model, err := Compile("", 1, "test", def)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		in   Inputs
+		want []string
+	}{
+		{"sin pérdidas", Inputs{InputCreditScoreMargin: 150.0, InputRequestedRatioVsMax: 0.1, InputMonthsEmployed: 36.0}, []string{}},
+		{"código del tramo", Inputs{InputCreditScoreMargin: -10.0, InputRequestedRatioVsMax: 0.1, InputMonthsEmployed: 36.0}, []string{"RC99"}},
+		{"código por defecto de la variable", Inputs{InputCreditScoreMargin: 50.0, InputRequestedRatioVsMax: 0.8, InputMonthsEmployed: 36.0}, []string{ReasonCreditScore, ReasonAmountVsIncome}},
+		{"sin buró", Inputs{InputRequestedRatioVsMax: 0.1}, []string{ReasonBureauUnavailable}},
+		{"sin ingresos", Inputs{InputCreditScoreMargin: 150.0, InputMonthsEmployed: 36.0}, []string{ReasonIncome}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := model.Evaluate(tt.in)
+			got := make([]string, 0, len(result.ReasonCodes))
+			for _, r := range result.ReasonCodes {
+				got = append(got, r.Code)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReasonCodes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingReasonCode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{InputRequestedToAnnualIncome, ReasonIncome},
+		{InputRequestedRatioVsMax, ReasonIncome},
+		{InputRequestedAmount, ReasonAmountVsIncome},
+		{InputMonthlyIncome, ReasonIncome},
+		{InputCreditScore, ReasonBureauUnavailable},
+		{InputPaymentHistory, ReasonBureauUnavailable},
+		{InputMonthsEmployed, ReasonBureauUnavailable},
+	}
+
+	for _, tt := range tests {
+		if got := missingReasonCode(tt.input); got != tt.want {
+			t.Errorf("missingReasonCode(%s) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestReasonCatalog(t *testing.T) {
+	for input := range knownInputs {
+		code, ok := inputReasonCodes[input]
+		if !ok {
+			t.Errorf("input %s has no default reason code", input)
+			continue
+		}
+		if reasonDescriptions[code] == "" {
+			t.Errorf("reason code %s has no description", code)
+		}
+	}
+	for _, code := range []string{ReasonBureauUnavailable, ReasonAmountReviewPolicy} {
+		if NewReasonCode(code, "", 0).Description == "" {
+			t.Errorf("reason code %s has no description", code)
+		}
+	}
+}
+
+func TestBucketLabel(t *testing.T) {
+	tests := []struct {
+		bucket entity.ScoringBucket
+		want   string
+	}{
+		{entity.ScoringBucket{Min: f(50), Max: f(150)}, "[50, 150)"},
+		{entity.ScoringBucket{Max: f(0.25)}, "[-inf, 0.25)"},
+		{entity.ScoringBucket{Min: f(-10)}, "[-10, +inf)"},
+		{entity.ScoringBucket{Value: "GOOD"}, "GOOD"},
+	}
+
+	for _, tt := range tests {
+		if got := bucketLabel(&tt.bucket); got != tt.want {
+			t.Errorf("bucketLabel = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestEngineEvaluateRisk(t *testing.T) {
+	engine := NewEngine(&fakeModelRepo{}, logger.NewLogger())
+	app := &entity.CreditApplication{CountryID: uuid.New(), RequestedAmount: 5000, MonthlyIncome: 2000}
+	info := &entity.BankingInfo{CreditScore: intPtr(580), PaymentHistory: strPtr("GOOD"), MonthsEmployed: intPtr(36)}
+
+	// 50 + 15 (monto) - 40 (score bajo el mínimo) + 20 (historial) + 0 (sin deuda) + 10 (empleo)
+	score, reasons, err := engine.EvaluateRisk(context.Background(), app, info)
+	if err != nil {
+		t.Fatalf("EvaluateRisk: %v", err)
+	}
+	if score != 55 {
+		t.Errorf("EvaluateRisk score = %v, want 55", score)
+	}
+	// Monto (25-15) y deuda ausente (10-0) pierden lo mismo: manda el orden del modelo
+	want := []string{ReasonCreditScore, ReasonAmountVsIncome, ReasonBureauUnavailable}
+	if !reflect.DeepEqual(reasons, want) {
+		t.Errorf("EvaluateRisk reasons = %v, want %v", reasons, want)
+	}
+}
@@ -0,0 +1,206 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/infrastructure/validation/expr"
+)
+
+// Límites de evaluación de las reglas CUSTOM
+const (
+	customRuleTimeout  = 50 * time.Millisecond
+	customRuleMaxSteps = 5000
+)
+
+// CustomRuleSchema variables disponibles en las expresiones de reglas CUSTOM
+// Las variables banking.* valen null si la solicitud no tiene información bancaria
+var CustomRuleSchema = expr.Schema{
+	"requested_amount":         expr.TypeNumber,
+	"monthly_income":           expr.TypeNumber,
+	"annual_income":            expr.TypeNumber,
+	"document_type":            expr.TypeString,
+	"country":                  expr.TypeString,
+	"banking.available":        expr.TypeBool,
+	"banking.credit_score":     expr.TypeNumber,
+	"banking.total_debt":       expr.TypeNumber,
+	"banking.available_credit": expr.TypeNumber,
+	"banking.payment_history":  expr.TypeString,
+	"banking.bank_accounts":    expr.TypeNumber,
+	"banking.active_loans":     expr.TypeNumber,
+	"banking.months_employed":  expr.TypeNumber,
+}
+
+// CustomRuleConfig configuración de una regla CUSTOM
+// Si la expresión es verdadera se aplica on_true (PASS por defecto); si es falsa,
+// on_false (REJECT). Un error de evaluación, como una variable no disponible,
+// aplica on_error (REQUIRE_REVIEW)
+type CustomRuleConfig struct {
+	Expression string
+	OnTrue     entity.RuleAction
+	OnFalse    entity.RuleAction
+	OnError    entity.RuleAction
+	Message    string
+}
+
+// ParseCustomRuleConfig lee y valida la configuración de una regla CUSTOM
+func ParseCustomRuleConfig(config map[string]interface{}) (*CustomRuleConfig, error) {
+	cfg := &CustomRuleConfig{
+		OnTrue:  entity.RuleActionPass,
+		OnFalse: entity.RuleActionReject,
+		OnError: entity.RuleActionRequireReview,
+	}
+
+	expression, _ := config["expression"].(string)
+	if expression == "" {
+		return nil, fmt.Errorf("custom rule requires an expression")
+	}
+	cfg.Expression = expression
+	cfg.Message, _ = config["message"].(string)
+
+	for key, dest := range map[string]*entity.RuleAction{
+		"on_true":  &cfg.OnTrue,
+		"on_false": &cfg.OnFalse,
+		"on_error": &cfg.OnError,
+	} {
+		raw, ok := config[key]
+		if !ok {
+			continue
+		}
+		action, _ := raw.(string)
+		switch entity.RuleAction(action) {
+		case entity.RuleActionPass, entity.RuleActionReject, entity.RuleActionRequireReview:
+			*dest = entity.RuleAction(action)
+		default:
+			return nil, fmt.Errorf("%s must be PASS, REJECT or REQUIRE_REVIEW", key)
+		}
+	}
+
+	return cfg, nil
+}
+
+// CompileRule verifica la configuración de una regla antes de guardarla
+// Para las reglas CUSTOM compila la expresión contra CustomRuleSchema
+func CompileRule(rule *entity.CountryRule) error {
+	switch rule.RuleType {
+	case entity.RuleTypeDocumentValidation, entity.RuleTypeIncomeCheck, entity.RuleTypeDebtRatio,
+		entity.RuleTypeCreditScore, entity.RuleTypeAmountThreshold:
+		if raw, ok := rule.Config["on_fail"]; ok {
+			onFail, _ := raw.(string)
+			if entity.RuleAction(onFail) != entity.RuleActionReject && entity.RuleAction(onFail) != entity.RuleActionRequireReview {
+				return fmt.Errorf("on_fail must be REJECT or REQUIRE_REVIEW")
+			}
+		}
+		return nil
+	case entity.RuleTypeCustom:
+		cfg, err := ParseCustomRuleConfig(rule.Config)
+		if err != nil {
+			return err
+		}
+		if _, err := expr.Compile(cfg.Expression, CustomRuleSchema); err != nil {
+			return fmt.Errorf("invalid expression: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown rule type %q", rule.RuleType)
+}
+
+// customRuleVars valores de las variables para una solicitud
+func customRuleVars(app *entity.CreditApplication) expr.Vars {
+	vars := expr.Vars{
+		"requested_amount":  app.RequestedAmount,
+		"monthly_income":    app.MonthlyIncome,
+		"annual_income":     app.MonthlyIncome * 12,
+		"document_type":     app.DocumentType,
+		"banking.available": app.BankingInfo != nil,
+	}
+	if app.Country != nil {
+		vars["country"] = app.Country.Code
+	}
+	if info := app.BankingInfo; info != nil {
+		vars["banking.credit_score"] = info.CreditScore
+		vars["banking.total_debt"] = info.TotalDebt
+		vars["banking.available_credit"] = info.AvailableCredit
+		vars["banking.payment_history"] = info.PaymentHistory
+		vars["banking.bank_accounts"] = info.BankAccounts
+		vars["banking.active_loans"] = info.ActiveLoans
+		vars["banking.months_employed"] = info.MonthsEmployed
+	}
+	return vars
+}
+
+// programCache expresiones compiladas por código fuente
+type programCache struct {
+	mu       sync.RWMutex
+	programs map[string]*expr.Program
+}
+
+func (c *programCache) get(src string) (*expr.Program, error) {
+	c.mu.RLock()
+	program, ok := c.programs[src]
+	c.mu.RUnlock()
+	if ok {
+		return program, nil
+	}
+
+	program, err := expr.Compile(src, CustomRuleSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.programs == nil {
+		c.programs = make(map[string]*expr.Program)
+	}
+	c.programs[src] = program
+	c.mu.Unlock()
+	return program, nil
+}
+
+// validateCustom evalúa una regla CUSTOM con tiempo y pasos acotados
+func (v *RuleValidator) validateCustom(ctx context.Context, app *entity.CreditApplication, rule entity.CountryRule) entity.ValidationResult {
+	cfg, err := ParseCustomRuleConfig(rule.Config)
+	if err != nil {
+		return customOutcome(entity.RuleActionRequireReview, fmt.Sprintf("Invalid custom rule: %s", err.Error()))
+	}
+
+	program, err := v.programs.get(cfg.Expression)
+	if err != nil {
+		return customOutcome(cfg.OnError, fmt.Sprintf("Invalid custom rule expression: %s", err.Error()))
+	}
+
+	evalCtx, cancel := context.WithTimeout(ctx, customRuleTimeout)
+	defer cancel()
+
+	passed, err := program.Eval(evalCtx, customRuleVars(app), customRuleMaxSteps)
+	if err != nil {
+		v.log.Warn().Err(err).Str("rule", rule.Name).Msg("Custom rule evaluation failed")
+		return customOutcome(cfg.OnError, fmt.Sprintf("Custom rule could not be evaluated: %s", err.Error()))
+	}
+
+	action := cfg.OnFalse
+	if passed {
+		action = cfg.OnTrue
+	}
+
+	message := cfg.Message
+	if message == "" || action == entity.RuleActionPass {
+		message = fmt.Sprintf("Custom rule evaluated to %t: %s", passed, action)
+	}
+	return customOutcome(action, message)
+}
+
+// customOutcome traduce la acción al resultado de validación
+func customOutcome(action entity.RuleAction, message string) entity.ValidationResult {
+	result := entity.ValidationResult{Passed: true, Message: message}
+	switch action {
+	case entity.RuleActionReject:
+		result.Passed = false
+	case entity.RuleActionRequireReview:
+		result.RequiresReview = true
+	}
+	return result
+}
@@ -0,0 +1,236 @@
+package validation
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+)
+
+func TestParseCustomRuleConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		want    CustomRuleConfig
+		wantErr string
+	}{
+		{
+			name:   "acciones por defecto",
+			config: map[string]interface{}{"expression": "requested_amount < 1000"},
+			want: CustomRuleConfig{
+				Expression: "requested_amount < 1000",
+				OnTrue:     entity.RuleActionPass, OnFalse: entity.RuleActionReject, OnError: entity.RuleActionRequireReview,
+			},
+		},
+		{
+			name: "acciones y mensaje propios",
+			config: map[string]interface{}{
+				"expression": "banking.active_loans >= 3", "message": "Too many loans",
+				"on_true": "REQUIRE_REVIEW", "on_false": "PASS", "on_error": "REJECT",
+			},
+			want: CustomRuleConfig{
+				Expression: "banking.active_loans >= 3", Message: "Too many loans",
+				OnTrue: entity.RuleActionRequireReview, OnFalse: entity.RuleActionPass, OnError: entity.RuleActionReject,
+			},
+		},
+		{"sin expresión", map[string]interface{}{}, CustomRuleConfig{}, "custom rule requires an expression"},
+		{"expresión no textual", map[string]interface{}{"expression": 42}, CustomRuleConfig{}, "custom rule requires an expression"},
+		{"acción desconocida", map[string]interface{}{"expression": "true", "on_false": "DELETE"}, CustomRuleConfig{}, "on_false must be PASS, REJECT or REQUIRE_REVIEW"},
+		{"acción no textual", map[string]interface{}{"expression": "true", "on_error": true}, CustomRuleConfig{}, "on_error must be PASS, REJECT or REQUIRE_REVIEW"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseCustomRuleConfig(tt.config)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseCustomRuleConfig error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCustomRuleConfig: %v", err)
+			}
+			if *cfg != tt.want {
+				t.Errorf("ParseCustomRuleConfig = %+v, want %+v", *cfg, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    entity.CountryRule
+		wantErr string
+	}{
+		{
+			name: "custom válida",
+			rule: entity.CountryRule{RuleType: entity.RuleTypeCustom, Config: map[string]interface{}{
+				"expression": "monthly_income * 12 > requested_amount * 3 && banking.active_loans < 3",
+			}},
+		},
+		{
+			name:    "custom con variable desconocida",
+			rule:    entity.CountryRule{RuleType: entity.RuleTypeCustom, Config: map[string]interface{}{"expression": "salary > 1000"}},
+			wantErr: `invalid expression: at position 0: unknown variable "salary"`,
+		},
+		{
+			name:    "custom con error de tipos",
+			rule:    entity.CountryRule{RuleType: entity.RuleTypeCustom, Config: map[string]interface{}{"expression": "country > 3"}},
+			wantErr: "operator > cannot be applied to string and number",
+		},
+		{
+			name:    "custom no booleana",
+			rule:    entity.CountryRule{RuleType: entity.RuleTypeCustom, Config: map[string]interface{}{"expression": "requested_amount * 2"}},
+			wantErr: "expression must be boolean",
+		},
+		{
+			name:    "custom sin expresión",
+			rule:    entity.CountryRule{RuleType: entity.RuleTypeCustom, Config: map[string]interface{}{}},
+			wantErr: "custom rule requires an expression",
+		},
+		{
+			name: "incorporada con on_fail",
+			rule: entity.CountryRule{RuleType: entity.RuleTypeIncomeCheck, Config: map[string]interface{}{"on_fail": "REQUIRE_REVIEW"}},
+		},
+		{
+			name:    "incorporada con on_fail PASS",
+			rule:    entity.CountryRule{RuleType: entity.RuleTypeCreditScore, Config: map[string]interface{}{"on_fail": "PASS"}},
+			wantErr: "on_fail must be REJECT or REQUIRE_REVIEW",
+		},
+		{
+			name:    "tipo desconocido",
+			rule:    entity.CountryRule{RuleType: "SCRIPT", Config: map[string]interface{}{}},
+			wantErr: `unknown rule type "SCRIPT"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CompileRule(&tt.rule)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("CompileRule: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CompileRule error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCustom(t *testing.T) {
+	activeLoans := func(n int) *entity.BankingInfo {
+		score := 700
+		return &entity.BankingInfo{CreditScore: &score, ActiveLoans: n}
+	}
+	app := func(info *entity.BankingInfo) *entity.CreditApplication {
+		return &entity.CreditApplication{
+			RequestedAmount: 10000, MonthlyIncome: 2000, DocumentType: "DNI",
+			Country: &entity.Country{Code: "ES"}, BankingInfo: info,
+		}
+	}
+	rule := func(config map[string]interface{}) entity.CountryRule {
+		return entity.CountryRule{Name: "custom", RuleType: entity.RuleTypeCustom, Config: config}
+	}
+	policy := map[string]interface{}{"expression": "monthly_income * 12 > requested_amount * 2 && banking.active_loans < 3"}
+
+	tests := []struct {
+		name    string
+		app     *entity.CreditApplication
+		rule    entity.CountryRule
+		passed  bool
+		review  bool
+		message string
+	}{
+		{"verdadera aplica PASS", app(activeLoans(1)), rule(policy), true, false, "Custom rule evaluated to true: PASS"},
+		{"falsa aplica REJECT", app(activeLoans(3)), rule(policy), false, false, "Custom rule evaluated to false: REJECT"},
+		{"variable no disponible aplica on_error", app(nil), rule(policy), true, true, "banking.active_loans is not available"},
+		{
+			name:   "guardas con banking.available",
+			app:    app(nil),
+			rule:   rule(map[string]interface{}{"expression": "!banking.available || banking.active_loans < 3"}),
+			passed: true, message: "Custom rule evaluated to true: PASS",
+		},
+		{
+			name:   "mensaje propio en el rechazo",
+			app:    app(activeLoans(5)),
+			rule:   rule(map[string]interface{}{"expression": "banking.active_loans < 3", "message": "Too many active loans"}),
+			passed: false, message: "Too many active loans",
+		},
+		{
+			name:   "acciones configuradas",
+			app:    app(activeLoans(5)),
+			rule:   rule(map[string]interface{}{"expression": "banking.active_loans < 3", "on_false": "REQUIRE_REVIEW"}),
+			passed: true, review: true, message: "Custom rule evaluated to false: REQUIRE_REVIEW",
+		},
+		{
+			name:   "variables de la solicitud",
+			app:    app(nil),
+			rule:   rule(map[string]interface{}{"expression": "country == 'ES' && document_type in ['DNI', 'NIE'] && annual_income == 24000"}),
+			passed: true, message: "Custom rule evaluated to true: PASS",
+		},
+		{
+			name:   "expresión inválida guardada a mano",
+			app:    app(nil),
+			rule:   rule(map[string]interface{}{"expression": "salary > 1", "on_error": "REJECT"}),
+			passed: false, message: "Invalid custom rule expression",
+		},
+		{
+			name:   "configuración inválida va a revisión",
+			app:    app(nil),
+			rule:   rule(map[string]interface{}{}),
+			passed: true, review: true, message: "Invalid custom rule: custom rule requires an expression",
+		},
+	}
+
+	validator := &RuleValidator{log: logger.NewLogger()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validator.validateCustom(context.Background(), tt.app, tt.rule)
+			if result.Passed != tt.passed || result.RequiresReview != tt.review {
+				t.Errorf("validateCustom = passed %v review %v, want %v %v (%s)", result.Passed, result.RequiresReview, tt.passed, tt.review, result.Message)
+			}
+			if !strings.Contains(result.Message, tt.message) {
+				t.Errorf("validateCustom message = %q, want %q", result.Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestValidateCustomTimeout(t *testing.T) {
+	validator := &RuleValidator{log: logger.NewLogger()}
+	rule := entity.CountryRule{Name: "custom", RuleType: entity.RuleTypeCustom, Config: map[string]interface{}{
+		"expression": "requested_amount < 1000",
+	}}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithTimeout(context.Background(), -time.Millisecond)
+	defer cancelExpired()
+
+	// Sin tiempo la regla no llega a evaluarse y aplica on_error
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		message string
+	}{
+		{"contexto cancelado", cancelled, "context canceled"},
+		{"plazo vencido", expired, "context deadline exceeded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validator.validateCustom(tt.ctx, &entity.CreditApplication{RequestedAmount: 10}, rule)
+			if !result.Passed || !result.RequiresReview || !strings.Contains(result.Message, tt.message) {
+				t.Errorf("validateCustom = %+v, want REQUIRE_REVIEW with %q", result, tt.message)
+			}
+		})
+	}
+}
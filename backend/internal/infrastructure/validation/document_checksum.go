@@ -0,0 +1,430 @@
+package validation
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// DocumentValidator verifica el dígito de control de un número de documento
+// Recibe el número normalizado: en mayúsculas y sin espacios
+type DocumentValidator func(number string) bool
+
+// DocumentRegistry registro de validadores de documentos por país y tipo
+// El mismo código puede ser documentos distintos según el país (CC en CO y en PT),
+// por eso la búsqueda es siempre por país + tipo
+type DocumentRegistry struct {
+	mu         sync.RWMutex
+	validators map[string]map[string]DocumentValidator
+}
+
+// NewDocumentRegistry crea un registro vacío
+func NewDocumentRegistry() *DocumentRegistry {
+	return &DocumentRegistry{validators: make(map[string]map[string]DocumentValidator)}
+}
+
+// NewDefaultDocumentRegistry crea un registro con los validadores de los documentos sembrados
+func NewDefaultDocumentRegistry() *DocumentRegistry {
+	r := NewDocumentRegistry()
+
+	r.Register("ES", "DNI", validateSpanishDNI)
+	r.Register("ES", "NIE", validateSpanishNIE)
+
+	r.Register("MX", "CURP", validateMexicanCURP)
+	r.Register("MX", "RFC", validateMexicanRFC)
+
+	r.Register("CO", "CC", validateColombianCC)
+	r.Register("CO", "CE", validateColombianCE)
+
+	r.Register("BR", "CPF", validateBrazilianCPF)
+	r.Register("BR", "RG", validateBrazilianRG)
+
+	r.Register("PT", "NIF", validatePortugueseNIF)
+	r.Register("PT", "CC", validatePortugueseCC)
+
+	r.Register("IT", "CF", validateItalianCF)
+	r.Register("IT", "CI", validateItalianCI)
+
+	return r
+}
+
+// Register registra (o reemplaza) el validador de un tipo de documento de un país
+func (r *DocumentRegistry) Register(countryCode, docType string, validator DocumentValidator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	countryCode = strings.ToUpper(countryCode)
+	if r.validators[countryCode] == nil {
+		r.validators[countryCode] = make(map[string]DocumentValidator)
+	}
+	r.validators[countryCode][strings.ToUpper(docType)] = validator
+}
+
+// Validate verifica el dígito de control del documento
+// Un tipo sin validador registrado solo exige una longitud mínima
+func (r *DocumentRegistry) Validate(countryCode, docType, docNumber string) bool {
+	number := normalizeDocumentNumber(docNumber)
+
+	r.mu.RLock()
+	validator := r.validators[strings.ToUpper(countryCode)][strings.ToUpper(docType)]
+	r.mu.RUnlock()
+
+	if validator == nil {
+		return len(number) >= 5 // Validación básica
+	}
+	return validator(number)
+}
+
+// normalizeDocumentNumber pasa a mayúsculas y quita espacios
+func normalizeDocumentNumber(number string) string {
+	return strings.ToUpper(strings.ReplaceAll(number, " ", ""))
+}
+
+// onlyDigits quita separadores habituales (puntos, guiones)
+func onlyDigits(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+func isAllDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// allSameDigit detecta números de relleno como 00000000000
+func allSameDigit(s string) bool {
+	for i := 1; i < len(s); i++ {
+		if s[i] != s[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// Validaciones específicas por país
+
+// España
+
+const spanishControlLetters = "TRWAGMYFPDXBNJZSQVHLCKE"
+
+// validateSpanishDNI 8 dígitos + letra de control (número mod 23)
+func validateSpanishDNI(dni string) bool {
+	if len(dni) != 9 || !isAllDigits(dni[:8]) {
+		return false
+	}
+	return dni[8] == spanishLetter(dni[:8])
+}
+
+// validateSpanishNIE X/Y/Z + 7 dígitos + letra; el prefijo equivale a 0/1/2 y se calcula como un DNI
+func validateSpanishNIE(nie string) bool {
+	if len(nie) != 9 || !isAllDigits(nie[1:8]) {
+		return false
+	}
+	prefix := strings.IndexByte("XYZ", nie[0])
+	if prefix < 0 {
+		return false
+	}
+	return nie[8] == spanishLetter(string(rune('0'+prefix))+nie[1:8])
+}
+
+func spanishLetter(digits string) byte {
+	n := 0
+	for i := 0; i < len(digits); i++ {
+		n = (n*10 + int(digits[i]-'0')) % 23
+	}
+	return spanishControlLetters[n]
+}
+
+// México
+
+// curpAlphabet valor de cada carácter en el dígito verificador de la CURP
+const curpAlphabet = "0123456789ABCDEFGHIJKLMNÑOPQRSTUVWXYZ"
+
+// rfcAlphabet valor de cada carácter en el dígito verificador del RFC
+const rfcAlphabet = "0123456789ABCDEFGHIJKLMN&OPQRSTUVWXYZ Ñ"
+
+// validateMexicanCURP 18 caracteres; el último es el dígito verificador:
+// suma de valor(c) * (18 - posición) sobre los 17 primeros, (10 - suma mod 10) mod 10
+func validateMexicanCURP(curp string) bool {
+	chars := []rune(curp)
+	if len(chars) != 18 || !validYYMMDD(string(chars[4:10])) {
+		return false
+	}
+	if chars[10] != 'H' && chars[10] != 'M' {
+		return false
+	}
+
+	sum := 0
+	for i, c := range chars[:17] {
+		value := alphabetIndex(curpAlphabet, c)
+		if value < 0 {
+			return false
+		}
+		sum += value * (18 - i)
+	}
+	expected := (10 - sum%10) % 10
+	return int(chars[17]-'0') == expected
+}
+
+// validateMexicanRFC 12 (persona moral) o 13 (persona física) caracteres; el último
+// es el dígito de la homoclave: módulo 11 con pesos 13..2 sobre el RFC completado
+// a 12 caracteres con un espacio inicial (11 - resto; 10 = A, 11 = 0)
+func validateMexicanRFC(rfc string) bool {
+	chars := []rune(rfc)
+	if len(chars) == 12 {
+		chars = append([]rune{' '}, chars...)
+	}
+	if len(chars) != 13 || !validYYMMDD(string(chars[4:10])) {
+		return false
+	}
+
+	sum := 0
+	for i, c := range chars[:12] {
+		value := alphabetIndex(rfcAlphabet, c)
+		if value < 0 {
+			return false
+		}
+		sum += value * (13 - i)
+	}
+
+	var expected rune
+	switch r := sum % 11; r {
+	case 0:
+		expected = '0'
+	case 1:
+		expected = 'A'
+	default:
+		expected = rune('0' + 11 - r)
+	}
+	return chars[12] == expected
+}
+
+// alphabetIndex posición de un carácter (rune) en el alfabeto; -1 si no pertenece
+func alphabetIndex(alphabet string, c rune) int {
+	i := 0
+	for _, a := range alphabet {
+		if a == c {
+			return i
+		}
+		i++
+	}
+	return -1
+}
+
+// validYYMMDD fecha AAMMDD con mes y día posibles
+func validYYMMDD(s string) bool {
+	if utf8.RuneCountInString(s) != 6 || !isAllDigits(s) {
+		return false
+	}
+	month := int(s[2]-'0')*10 + int(s[3]-'0')
+	day := int(s[4]-'0')*10 + int(s[5]-'0')
+	return month >= 1 && month <= 12 && day >= 1 && day <= 31
+}
+
+// Colombia
+
+// validateColombianCC la cédula de ciudadanía no lleva dígito de control:
+// 6 a 10 dígitos sin cero inicial
+func validateColombianCC(cc string) bool {
+	cc = onlyDigits(cc)
+	return len(cc) >= 6 && len(cc) <= 10 && cc[0] != '0'
+}
+
+// validateColombianCE la cédula de extranjería tampoco lleva dígito de control
+func validateColombianCE(ce string) bool {
+	ce = onlyDigits(ce)
+	return len(ce) >= 6 && len(ce) <= 10 && !allSameDigit(ce)
+}
+
+// Brasil
+
+// validateBrazilianCPF 11 dígitos con dos dígitos verificadores módulo 11
+func validateBrazilianCPF(cpf string) bool {
+	cpf = onlyDigits(cpf)
+	if len(cpf) != 11 || allSameDigit(cpf) {
+		return false
+	}
+	return cpfDigit(cpf[:9]) == cpf[9] && cpfDigit(cpf[:10]) == cpf[10]
+}
+
+// cpfDigit dígito verificador del CPF: pesos n+1..2, 11 - resto (0 si el resto es < 2)
+func cpfDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		sum += int(digits[i]-'0') * (len(digits) + 1 - i)
+	}
+	remainder := sum % 11
+	if remainder < 2 {
+		return '0'
+	}
+	return byte('0' + 11 - remainder)
+}
+
+// validateBrazilianRG el RG lo emite cada estado; el formato de São Paulo (8 dígitos +
+// dígito verificador) se verifica con pesos 2..9 módulo 11 (10 = X, 11 = 0).
+// Los formatos estatales de 7 u 8 dígitos no tienen dígito de control
+func validateBrazilianRG(rg string) bool {
+	rg = strings.NewReplacer(".", "", "-", "").Replace(rg)
+	switch len(rg) {
+	case 7, 8:
+		return isAllDigits(rg) && !allSameDigit(rg)
+	case 9:
+	default:
+		return false
+	}
+	if !isAllDigits(rg[:8]) || allSameDigit(rg[:8]) {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < 8; i++ {
+		sum += int(rg[i]-'0') * (i + 2)
+	}
+	var expected byte
+	switch dv := 11 - sum%11; dv {
+	case 10:
+		expected = 'X'
+	case 11:
+		expected = '0'
+	default:
+		expected = byte('0' + dv)
+	}
+	return rg[8] == expected
+}
+
+// Portugal
+
+// validatePortugueseNIF 9 dígitos; el primero (o los dos primeros) indican el tipo de
+// contribuyente y el último es el dígito de control módulo 11 con pesos 9..2
+func validatePortugueseNIF(nif string) bool {
+	if len(nif) != 9 || !isAllDigits(nif) {
+		return false
+	}
+	if !strings.ContainsRune("1235689", rune(nif[0])) {
+		switch nif[:2] {
+		case "45", "70", "71", "72", "74", "75", "77", "79":
+		default:
+			return false
+		}
+	}
+
+	sum := 0
+	for i := 0; i < 8; i++ {
+		sum += int(nif[i]-'0') * (9 - i)
+	}
+	expected := 11 - sum%11
+	if expected >= 10 {
+		expected = 0
+	}
+	return int(nif[8]-'0') == expected
+}
+
+// validatePortugueseCC Cartão de Cidadão: número civil (8 dígitos) + dígito de control +
+// versión (2 caracteres) + dígito de control final. El número completo se verifica con
+// Luhn extendido a letras (A = 10 ... Z = 35). El número civil solo no lleva control
+func validatePortugueseCC(cc string) bool {
+	cc = strings.ReplaceAll(cc, "-", "")
+	if len(cc) == 8 {
+		return isAllDigits(cc)
+	}
+	if len(cc) != 12 || !isAllDigits(cc[:9]) || !isAllDigits(cc[11:]) {
+		return false
+	}
+
+	sum := 0
+	for i := len(cc) - 1; i >= 0; i-- {
+		value := alphanumericValue(cc[i])
+		if value < 0 {
+			return false
+		}
+		// Se duplica uno de cada dos empezando por el penúltimo
+		if (len(cc)-1-i)%2 == 1 {
+			value *= 2
+			if value > 9 {
+				value -= 9
+			}
+		}
+		sum += value
+	}
+	return sum%10 == 0
+}
+
+// alphanumericValue 0-9 para dígitos y 10-35 para letras
+func alphanumericValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A') + 10
+	}
+	return -1
+}
+
+// Italia
+
+// cfOddValues valor de cada carácter en posición impar (1ª, 3ª, ...) del codice fiscale,
+// indexado por dígito o letra (0/A = 1, 1/B = 0, ...)
+var cfOddValues = [26]int{1, 0, 5, 7, 9, 13, 15, 17, 19, 21, 2, 4, 18, 20, 11, 3, 6, 8, 12, 14, 16, 10, 22, 25, 24, 23}
+
+// cfOmocodia letras que sustituyen a los dígitos en caso de omocodia (L = 0 ... V = 9)
+const cfOmocodia = "LMNPQRSTUV"
+
+// validateItalianCF 16 caracteres; el último es la letra de control: suma de los valores
+// impares (tabla) y pares (0-9 / A-Z = 0-25) de los 15 primeros, módulo 26
+func validateItalianCF(cf string) bool {
+	if len(cf) != 16 {
+		return false
+	}
+	// Posiciones numéricas (admiten las letras de omocodia)
+	for _, i := range []int{6, 7, 9, 10, 12, 13, 14} {
+		c := cf[i]
+		if !(c >= '0' && c <= '9') && strings.IndexByte(cfOmocodia, c) < 0 {
+			return false
+		}
+	}
+
+	sum := 0
+	for i := 0; i < 15; i++ {
+		c := cf[i]
+		var index int
+		switch {
+		case c >= '0' && c <= '9':
+			index = int(c - '0')
+		case c >= 'A' && c <= 'Z':
+			index = int(c - 'A')
+		default:
+			return false
+		}
+		if i%2 == 0 {
+			sum += cfOddValues[index]
+		} else {
+			sum += index
+		}
+	}
+	return cf[15] == byte('A'+sum%26)
+}
+
+// validateItalianCI Carta d'Identità Elettronica (2 letras, 5 dígitos, 2 letras):
+// el número de serie no tiene dígito de control público
+func validateItalianCI(ci string) bool {
+	if len(ci) != 9 {
+		return false
+	}
+	for i := 0; i < len(ci); i++ {
+		isDigit := ci[i] >= '0' && ci[i] <= '9'
+		isLetter := ci[i] >= 'A' && ci[i] <= 'Z'
+		if (i >= 2 && i < 7) != isDigit || (!isDigit && !isLetter) {
+			return false
+		}
+	}
+	return true
+}
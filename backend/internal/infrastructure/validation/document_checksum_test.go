@@ -0,0 +1,129 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+)
+
+func TestDocumentRegistryValidate(t *testing.T) {
+	registry := NewDefaultDocumentRegistry()
+
+	tests := []struct {
+		country string
+		docType string
+		number  string
+		valid   bool
+	}{
+		// España
+		{"ES", "DNI", "12345678Z", true},
+		{"ES", "DNI", "12345678z", true}, // Se normaliza a mayúsculas
+		{"ES", "DNI", "12345678A", false},
+		{"ES", "DNI", "1234567Z", false},
+		{"ES", "NIE", "X1234567L", true},
+		{"ES", "NIE", "X1234567A", false},
+		{"ES", "NIE", "A1234567L", false},
+
+		// México
+		{"MX", "CURP", "HEGG560427MVZRRL04", true},
+		{"MX", "CURP", "BADD110313HCMLNS06", true},
+		{"MX", "CURP", "HEGG560427MVZRRL05", false},
+		{"MX", "CURP", "HEGG561327MVZRRL04", false}, // Mes 13
+		{"MX", "CURP", "HEGG560427XVZRRL04", false}, // Sexo inválido
+		{"MX", "RFC", "GODE561231GR8", true},
+		{"MX", "RFC", "GODE561231GR9", false},
+		{"MX", "RFC", "GODE561331GR8", false},
+
+		// Colombia
+		{"CO", "CC", "1020304050", true},
+		{"CO", "CC", "1.020.304", true},
+		{"CO", "CC", "0123456", false},
+		{"CO", "CC", "12345", false},
+		{"CO", "CE", "123456", true},
+		{"CO", "CE", "111111", false},
+
+		// Brasil
+		{"BR", "CPF", "529.982.247-25", true},
+		{"BR", "CPF", "52998224725", true},
+		{"BR", "CPF", "529.982.247-26", false},
+		{"BR", "CPF", "111.111.111-11", false},
+		{"BR", "RG", "12.345.678-2", true},
+		{"BR", "RG", "12.345.678-3", false},
+		{"BR", "RG", "1234567", true}, // Formato estatal sin dígito de control
+		{"BR", "RG", "1111111", false},
+
+		// Portugal
+		{"PT", "NIF", "123456789", true},
+		{"PT", "NIF", "123456780", false},
+		{"PT", "NIF", "423456789", false}, // Prefijo de contribuyente inválido
+		{"PT", "CC", "000000000ZZ4", true},
+		{"PT", "CC", "000000000ZZ5", false},
+		{"PT", "CC", "12345678", true}, // Número civil sin control
+
+		// Italia
+		{"IT", "CF", "RSSMRA85T10A562S", true},
+		{"IT", "CF", "RSSMRA85T10A562T", false},
+		{"IT", "CF", "RSSMRA85T10A56", false},
+		{"IT", "CI", "CA00000AA", true},
+		{"IT", "CI", "CA0000AAA", false},
+
+		// Sin validador registrado: solo longitud mínima
+		{"AR", "DNI", "12345", true},
+		{"AR", "DNI", "1234", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.country+"/"+tt.docType+"/"+tt.number, func(t *testing.T) {
+			if got := registry.Validate(tt.country, tt.docType, tt.number); got != tt.valid {
+				t.Errorf("Validate(%q, %q, %q) = %v, want %v", tt.country, tt.docType, tt.number, got, tt.valid)
+			}
+		})
+	}
+}
+
+func TestValidateDocumentUsesCountryCode(t *testing.T) {
+	v := &RuleValidator{documents: NewDefaultDocumentRegistry()}
+	rule := entity.CountryRule{
+		Name:   "document",
+		Config: map[string]interface{}{"validate_checksum": true},
+	}
+
+	tests := []struct {
+		name    string
+		country *entity.Country
+		docType string
+		number  string
+		passed  bool
+	}{
+		{"ES DNI válido", &entity.Country{Code: "ES"}, "DNI", "12345678Z", true},
+		{"ES DNI inválido", &entity.Country{Code: "ES"}, "DNI", "12345678A", false},
+		// CC es un documento distinto en Colombia y en Portugal
+		{"CO CC", &entity.Country{Code: "CO"}, "CC", "1020304050", true},
+		{"PT CC con número colombiano", &entity.Country{Code: "PT"}, "CC", "1020304050", false},
+		{"PT CC", &entity.Country{Code: "PT"}, "CC", "000000000ZZ4", true},
+		{"CO CC con número portugués", &entity.Country{Code: "CO"}, "CC", "000000000ZZ4", false},
+		// Sin país no hay validador: solo longitud mínima
+		{"sin país", nil, "DNI", "12345678A", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &entity.CreditApplication{
+				Country:        tt.country,
+				DocumentType:   tt.docType,
+				DocumentNumber: tt.number,
+			}
+			if got := v.validateDocument(app, rule); got.Passed != tt.passed {
+				t.Errorf("validateDocument passed = %v, want %v (%s)", got.Passed, tt.passed, got.Message)
+			}
+		})
+	}
+
+	t.Run("tipo de documento requerido", func(t *testing.T) {
+		required := entity.CountryRule{Config: map[string]interface{}{"required_document": "DNI"}}
+		app := &entity.CreditApplication{Country: &entity.Country{Code: "ES"}, DocumentType: "NIE", DocumentNumber: "X1234567L"}
+		if got := v.validateDocument(app, required); got.Passed {
+			t.Errorf("validateDocument passed with document type %s, want DNI required", app.DocumentType)
+		}
+	})
+}
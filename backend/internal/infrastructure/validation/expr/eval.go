@@ -0,0 +1,256 @@
+package expr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DefaultMaxSteps presupuesto de pasos de evaluación por defecto
+const DefaultMaxSteps = 10000
+
+// ErrBudgetExceeded la evaluación superó el presupuesto de pasos
+var ErrBudgetExceeded = errors.New("evaluation step budget exceeded")
+
+// Vars valores de las variables: float64, string, bool o nil si no están disponibles
+type Vars map[string]interface{}
+
+// Eval evalúa el programa con las variables dadas
+// La evaluación se corta si el contexto vence o si se supera maxSteps (0 = por defecto)
+func (p *Program) Eval(ctx context.Context, vars Vars, maxSteps int) (bool, error) {
+	if maxSteps <= 0 {
+		maxSteps = DefaultMaxSteps
+	}
+	e := &evaluator{ctx: ctx, vars: vars, budget: maxSteps}
+	v, err := e.eval(p.root)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, errorAt(0, "expression did not evaluate to a boolean")
+	}
+	return b, nil
+}
+
+// evaluator intérprete del árbol sintáctico con presupuesto de pasos
+type evaluator struct {
+	ctx    context.Context
+	vars   Vars
+	budget int
+}
+
+func (e *evaluator) step() error {
+	e.budget--
+	if e.budget < 0 {
+		return ErrBudgetExceeded
+	}
+	return e.ctx.Err()
+}
+
+func (e *evaluator) eval(n node) (interface{}, error) {
+	if err := e.step(); err != nil {
+		return nil, err
+	}
+
+	switch n := n.(type) {
+	case *literalNode:
+		return n.value, nil
+
+	case *identNode:
+		return normalize(e.vars[n.name]), nil
+
+	case *unaryNode:
+		x, err := e.eval(n.x)
+		if err != nil {
+			return nil, err
+		}
+		if x == nil {
+			return nil, nullOperand(n.x)
+		}
+		if n.op == "!" {
+			return !x.(bool), nil
+		}
+		return -x.(float64), nil
+
+	case *binaryNode:
+		return e.evalBinary(n)
+
+	case *listNode:
+		items := make([]interface{}, 0, len(n.items))
+		for _, item := range n.items {
+			v, err := e.eval(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		return items, nil
+
+	case *callNode:
+		args := make([]interface{}, 0, len(n.args))
+		for _, arg := range n.args {
+			v, err := e.eval(arg)
+			if err != nil {
+				return nil, err
+			}
+			if v == nil && !n.fn.acceptsNull {
+				return nil, nullOperand(arg)
+			}
+			args = append(args, v)
+		}
+		v, err := n.fn.call(args)
+		if err != nil {
+			return nil, errorAt(n.pos, err.Error())
+		}
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("unknown node %T", n)
+}
+
+func (e *evaluator) evalBinary(n *binaryNode) (interface{}, error) {
+	l, err := e.eval(n.l)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cortocircuito: permite proteger accesos, ej. banking.available && banking.active_loans < 3
+	switch n.op {
+	case "&&", "||":
+		if l == nil {
+			return nil, nullOperand(n.l)
+		}
+		if l.(bool) == (n.op == "||") {
+			return l, nil
+		}
+		r, err := e.eval(n.r)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			return nil, nullOperand(n.r)
+		}
+		return r, nil
+	}
+
+	r, err := e.eval(n.r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return l == r, nil
+	case "!=":
+		return l != r, nil
+	}
+
+	if l == nil {
+		return nil, nullOperand(n.l)
+	}
+	if r == nil {
+		return nil, nullOperand(n.r)
+	}
+
+	switch n.op {
+	case "in":
+		for _, item := range r.([]interface{}) {
+			if item == l {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "<", "<=", ">", ">=":
+		return compare(n.op, l, r), nil
+	case "+":
+		if ls, ok := l.(string); ok {
+			return ls + r.(string), nil
+		}
+		return l.(float64) + r.(float64), nil
+	}
+
+	a, b := l.(float64), r.(float64)
+	switch n.op {
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return nil, errorAt(n.pos, "division by zero")
+		}
+		return a / b, nil
+	case "%":
+		if b == 0 {
+			return nil, errorAt(n.pos, "division by zero")
+		}
+		return math.Mod(a, b), nil
+	}
+
+	return nil, errorAt(n.pos, fmt.Sprintf("unknown operator %s", n.op))
+}
+
+func compare(op string, l, r interface{}) bool {
+	var c int
+	if ls, ok := l.(string); ok {
+		c = strings.Compare(ls, r.(string))
+	} else {
+		a, b := l.(float64), r.(float64)
+		switch {
+		case a < b:
+			c = -1
+		case a > b:
+			c = 1
+		}
+	}
+	switch op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	}
+	return c >= 0
+}
+
+// nullOperand error por usar un valor no disponible en una operación
+func nullOperand(n node) error {
+	if id, ok := n.(*identNode); ok {
+		return errorAt(n.position(), fmt.Sprintf("%s is not available", id.name))
+	}
+	return errorAt(n.position(), "operand is null")
+}
+
+// normalize convierte los valores numéricos de las variables a float64
+func normalize(v interface{}) interface{} {
+	switch x := v.(type) {
+	case int:
+		return float64(x)
+	case int32:
+		return float64(x)
+	case int64:
+		return float64(x)
+	case float32:
+		return float64(x)
+	case *int:
+		if x == nil {
+			return nil
+		}
+		return float64(*x)
+	case *float64:
+		if x == nil {
+			return nil
+		}
+		return *x
+	case *string:
+		if x == nil {
+			return nil
+		}
+		return *x
+	}
+	return v
+}
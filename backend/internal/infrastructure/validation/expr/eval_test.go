@@ -0,0 +1,216 @@
+package expr
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustCompile(t *testing.T, src string) *Program {
+	t.Helper()
+	program, err := Compile(src, testSchema)
+	if err != nil {
+		t.Fatalf("Compile(%q): %v", src, err)
+	}
+	return program
+}
+
+func TestEval(t *testing.T) {
+	score := 720
+	history := "GOOD"
+	vars := Vars{
+		"amount":          5000, // int: se normaliza a float64
+		"income":          2000.0,
+		"name":            "abc",
+		"ok":              true,
+		"banking.score":   &score,
+		"banking.history": &history,
+	}
+
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{"amount * 12 > income * 3", true},
+		{"amount - income * 2 == 1000", true},
+		{"amount / income == 2.5", true},
+		{"amount % 7 == 2", true},
+		{"-amount < 0", true},
+		{"2 + 3 * 4 == 14", true},
+		{"(2 + 3) * 4 == 20", true},
+		{"banking.score >= 720 && banking.score < 721", true},
+		{"banking.history in ['GOOD', 'REGULAR']", true},
+		{"banking.history in ['BAD']", false},
+		{"amount in [1000, 5000]", true},
+		{"name < 'abd' && name >= 'abc'", true},
+		{"name + 'd' == 'abcd'", true},
+		{"!ok", false},
+		{"ok != false", true},
+		{"has(banking.score)", true},
+		{"abs(-amount) == 5000 && round(2.5) == 3", true},
+		{"min(amount, income, 3000) == 2000 && max(1, amount) == 5000", true},
+		{"len(name) == 3 && upper(name) == 'ABC' && lower('ABC') == name", true},
+		{"starts_with(name, 'ab') && contains(name, 'bc') && !contains(name, 'x')", true},
+		{"amount == null", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			got, err := mustCompile(t, tt.src).Eval(context.Background(), vars, 0)
+			if err != nil {
+				t.Fatalf("Eval: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestEvalNull las variables ausentes o nil valen null: solo se pueden comparar
+// con ==/!= o preguntar con has(); cualquier otra operación es un error
+func TestEvalNull(t *testing.T) {
+	var noScore *int
+	vars := Vars{"amount": 5000.0, "ok": true, "banking.score": noScore} // banking.history ausente
+
+	tests := []struct {
+		src     string
+		want    bool
+		wantErr string
+	}{
+		{src: "banking.score == null", want: true},
+		{src: "banking.history == null", want: true},
+		{src: "banking.score != null", want: false},
+		{src: "null == null", want: true},
+		{src: "banking.score == banking.score", want: true},
+		{src: "has(banking.score) || has(banking.history)", want: false},
+		{src: "banking.score != null && banking.score > 600", want: false},
+		{src: "banking.score == null || banking.score > 600", want: true},
+		{src: "ok || banking.score > 600", want: true},
+		{src: "banking.score > 600", wantErr: "banking.score is not available"},
+		{src: "ok && banking.score > 600", wantErr: "banking.score is not available"},
+		{src: "banking.history in ['GOOD']", wantErr: "banking.history is not available"},
+		{src: "-banking.score < 0", wantErr: "banking.score is not available"},
+		{src: "abs(banking.score) > 0", wantErr: "banking.score is not available"},
+		{src: "amount + banking.score > 0", wantErr: "banking.score is not available"},
+		{src: "banking.history + 'x' == 'x'", wantErr: "banking.history is not available"},
+		{src: "name == 'a'", want: false}, // name ausente: null == 'a' es falso
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			got, err := mustCompile(t, tt.src).Eval(context.Background(), vars, 0)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Eval error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Eval: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	vars := Vars{"amount": 5000.0, "income": 0.0, "ok": true}
+
+	tests := []struct {
+		src     string
+		wantErr string
+		pos     int
+	}{
+		{"amount / income > 1", "division by zero", 7},
+		{"amount % income == 0", "division by zero", 7},
+		{"ok && amount / (income * 2) > 1", "division by zero", 13},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := mustCompile(t, tt.src).Eval(context.Background(), vars, 0)
+			var exprErr *Error
+			if !errors.As(err, &exprErr) || exprErr.Msg != tt.wantErr || exprErr.Pos != tt.pos {
+				t.Errorf("Eval error = %v, want %q at position %d", err, tt.wantErr, tt.pos)
+			}
+		})
+	}
+}
+
+func TestEvalStepBudget(t *testing.T) {
+	// 7 nodos: &&, dos comparaciones y cuatro operandos
+	program := mustCompile(t, "amount > 0 && amount > 1")
+	vars := Vars{"amount": 5.0}
+
+	tests := []struct {
+		maxSteps int
+		wantErr  error
+	}{
+		{0, nil}, // Presupuesto por defecto
+		{7, nil},
+		{6, ErrBudgetExceeded},
+		{1, ErrBudgetExceeded},
+	}
+
+	for _, tt := range tests {
+		_, err := program.Eval(context.Background(), vars, tt.maxSteps)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("Eval with %d steps error = %v, want %v", tt.maxSteps, err, tt.wantErr)
+		}
+	}
+
+	// El cortocircuito no consume los pasos de la rama que no se evalúa
+	if _, err := mustCompile(t, "ok || amount > 0 && amount > 1").Eval(context.Background(), Vars{"ok": true}, 3); err != nil {
+		t.Errorf("Eval short-circuit: %v", err)
+	}
+}
+
+func TestEvalContext(t *testing.T) {
+	program := mustCompile(t, "amount > 0")
+	vars := Vars{"amount": 5.0}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithTimeout(context.Background(), -time.Second)
+	defer cancelExpired()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr error
+	}{
+		{"activo", context.Background(), nil},
+		{"cancelado", cancelled, context.Canceled},
+		{"vencido", expired, context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := program.Eval(tt.ctx, vars, 0)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Eval error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestEvalLargestProgram el mayor programa que admite el compilador cabe en
+// MaxNodes*2 pasos y tarda muy por debajo del tiempo de una regla (50ms)
+func TestEvalLargestProgram(t *testing.T) {
+	src := "amount > 0" + strings.Repeat(" && amount > 0", MaxNodes/2-1)
+	program := mustCompile(t, src)
+
+	start := time.Now()
+	got, err := program.Eval(context.Background(), Vars{"amount": 1.0}, MaxNodes*2)
+	if err != nil || !got {
+		t.Fatalf("Eval = %v, %v", got, err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Eval took %s", elapsed)
+	}
+}
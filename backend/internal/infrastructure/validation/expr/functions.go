@@ -0,0 +1,120 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// function función permitida en las expresiones
+// Solo existen estas funciones: no hay acceso a nada fuera de las variables del esquema
+type function struct {
+	params      []Type // Tipos de los parámetros; con variadic todos son de params[0]
+	variadic    bool
+	acceptsNull bool
+	result      Type
+	impl        func(args []interface{}) (interface{}, error)
+}
+
+var functions = map[string]*function{
+	"has": {
+		params: []Type{""}, acceptsNull: true, result: TypeBool,
+		impl: func(args []interface{}) (interface{}, error) { return args[0] != nil, nil },
+	},
+	"abs": {
+		params: []Type{TypeNumber}, result: TypeNumber,
+		impl: func(args []interface{}) (interface{}, error) { return math.Abs(args[0].(float64)), nil },
+	},
+	"round": {
+		params: []Type{TypeNumber}, result: TypeNumber,
+		impl: func(args []interface{}) (interface{}, error) { return math.Round(args[0].(float64)), nil },
+	},
+	"min": {
+		params: []Type{TypeNumber}, variadic: true, result: TypeNumber,
+		impl: func(args []interface{}) (interface{}, error) {
+			v := args[0].(float64)
+			for _, a := range args[1:] {
+				v = math.Min(v, a.(float64))
+			}
+			return v, nil
+		},
+	},
+	"max": {
+		params: []Type{TypeNumber}, variadic: true, result: TypeNumber,
+		impl: func(args []interface{}) (interface{}, error) {
+			v := args[0].(float64)
+			for _, a := range args[1:] {
+				v = math.Max(v, a.(float64))
+			}
+			return v, nil
+		},
+	},
+	"len": {
+		params: []Type{TypeString}, result: TypeNumber,
+		impl: func(args []interface{}) (interface{}, error) { return float64(len(args[0].(string))), nil },
+	},
+	"lower": {
+		params: []Type{TypeString}, result: TypeString,
+		impl: func(args []interface{}) (interface{}, error) { return strings.ToLower(args[0].(string)), nil },
+	},
+	"upper": {
+		params: []Type{TypeString}, result: TypeString,
+		impl: func(args []interface{}) (interface{}, error) { return strings.ToUpper(args[0].(string)), nil },
+	},
+	"starts_with": {
+		params: []Type{TypeString, TypeString}, result: TypeBool,
+		impl: func(args []interface{}) (interface{}, error) {
+			return strings.HasPrefix(args[0].(string), args[1].(string)), nil
+		},
+	},
+	"contains": {
+		params: []Type{TypeString, TypeString}, result: TypeBool,
+		impl: func(args []interface{}) (interface{}, error) {
+			return strings.Contains(args[0].(string), args[1].(string)), nil
+		},
+	},
+}
+
+// Functions nombres de las funciones disponibles
+func Functions() []string {
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// check verifica el número y tipo de los argumentos en compilación
+func (f *function) check(args []node) error {
+	if f.variadic {
+		if len(args) == 0 {
+			return fmt.Errorf("expects at least 1 argument")
+		}
+	} else if len(args) != len(f.params) {
+		return fmt.Errorf("expects %d argument(s), got %d", len(f.params), len(args))
+	}
+
+	for i, arg := range args {
+		want := f.params[0]
+		if !f.variadic {
+			want = f.params[i]
+		}
+		if want == "" {
+			// has(): el argumento debe ser una variable
+			if _, ok := arg.(*identNode); !ok {
+				return fmt.Errorf("argument must be a variable")
+			}
+			continue
+		}
+		if arg.typ() != want {
+			return fmt.Errorf("argument %d must be %s, got %s", i+1, want, arg.typ())
+		}
+	}
+	return nil
+}
+
+func (f *function) call(args []interface{}) (interface{}, error) {
+	return f.impl(args)
+}
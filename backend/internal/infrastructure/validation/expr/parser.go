@@ -0,0 +1,432 @@
+package expr
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Límites de compilación: acotan el tamaño de lo que se llega a evaluar
+const (
+	MaxSourceLength = 4096
+	MaxNodes        = 256
+	MaxDepth        = 32
+)
+
+// Type tipo estático de una expresión
+type Type string
+
+const (
+	TypeNumber Type = "number"
+	TypeString Type = "string"
+	TypeBool   Type = "bool"
+	TypeNull   Type = "null"
+	TypeList   Type = "list"
+)
+
+// Schema variables disponibles y su tipo; cualquier variable puede valer null en tiempo de ejecución
+type Schema map[string]Type
+
+// Error error de compilación o evaluación con la posición en el código fuente
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("at position %d: %s", e.Pos, e.Msg)
+}
+
+func errorAt(pos int, msg string) *Error {
+	return &Error{Pos: pos, Msg: msg}
+}
+
+// Nodos del árbol sintáctico
+type (
+	node interface {
+		typ() Type
+		position() int
+	}
+
+	literalNode struct {
+		value interface{}
+		t     Type
+		pos   int
+	}
+
+	identNode struct {
+		name string
+		t    Type
+		pos  int
+	}
+
+	unaryNode struct {
+		op  string
+		x   node
+		t   Type
+		pos int
+	}
+
+	binaryNode struct {
+		op   string
+		l, r node
+		t    Type
+		pos  int
+	}
+
+	listNode struct {
+		items []node
+		elem  Type
+		pos   int
+	}
+
+	callNode struct {
+		fn   *function
+		args []node
+		pos  int
+	}
+)
+
+func (n *literalNode) typ() Type     { return n.t }
+func (n *literalNode) position() int { return n.pos }
+func (n *identNode) typ() Type       { return n.t }
+func (n *identNode) position() int   { return n.pos }
+func (n *unaryNode) typ() Type       { return n.t }
+func (n *unaryNode) position() int   { return n.pos }
+func (n *binaryNode) typ() Type      { return n.t }
+func (n *binaryNode) position() int  { return n.pos }
+func (n *listNode) typ() Type        { return TypeList }
+func (n *listNode) position() int    { return n.pos }
+func (n *callNode) typ() Type        { return n.fn.result }
+func (n *callNode) position() int    { return n.pos }
+
+// Program expresión compilada y verificada, lista para evaluar
+type Program struct {
+	source    string
+	root      node
+	variables []string
+}
+
+// Source código fuente de la expresión
+func (p *Program) Source() string {
+	return p.source
+}
+
+// Variables variables referenciadas por la expresión, ordenadas
+func (p *Program) Variables() []string {
+	return p.variables
+}
+
+// Compile analiza la expresión, verifica tipos y variables contra el esquema
+// y exige que el resultado sea booleano
+func Compile(src string, schema Schema) (*Program, error) {
+	if len(src) > MaxSourceLength {
+		return nil, errorAt(0, fmt.Sprintf("expression longer than %d characters", MaxSourceLength))
+	}
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return nil, errorAt(0, "empty expression")
+	}
+
+	p := &parser{tokens: tokens, schema: schema, vars: make(map[string]bool)}
+	root, err := p.parseExpr(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, errorAt(tok.pos, fmt.Sprintf("unexpected %q", tok.text))
+	}
+	if root.typ() != TypeBool {
+		return nil, errorAt(0, fmt.Sprintf("expression must be boolean, got %s", root.typ()))
+	}
+
+	variables := make([]string, 0, len(p.vars))
+	for v := range p.vars {
+		variables = append(variables, v)
+	}
+	sort.Strings(variables)
+
+	return &Program{source: src, root: root, variables: variables}, nil
+}
+
+// parser analizador descendente con precedencia de operadores (Pratt)
+type parser struct {
+	tokens []token
+	pos    int
+	nodes  int
+	schema Schema
+	vars   map[string]bool
+}
+
+// precedence precedencia de los operadores binarios (mayor = más fuerte)
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4, "in": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	tok := p.next()
+	if tok.kind != kind {
+		return errorAt(tok.pos, fmt.Sprintf("expected %q", text))
+	}
+	return nil
+}
+
+func (p *parser) count(pos int) error {
+	p.nodes++
+	if p.nodes > MaxNodes {
+		return errorAt(pos, fmt.Sprintf("expression exceeds %d nodes", MaxNodes))
+	}
+	return nil
+}
+
+// binaryOp devuelve el operador binario del token (si lo es)
+func binaryOp(tok token) (string, bool) {
+	if tok.kind == tokOperator && tok.text != "!" {
+		return tok.text, true
+	}
+	if tok.kind == tokIdent && tok.text == "in" {
+		return "in", true
+	}
+	return "", false
+}
+
+func (p *parser) parseExpr(minPrec, depth int) (node, error) {
+	if depth > MaxDepth {
+		return nil, errorAt(p.peek().pos, fmt.Sprintf("expression nested deeper than %d levels", MaxDepth))
+	}
+
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := binaryOp(p.peek())
+		if !ok || precedence[op] <= minPrec {
+			return left, nil
+		}
+		tok := p.next()
+		right, err := p.parseExpr(precedence[op], depth+1)
+		if err != nil {
+			return nil, err
+		}
+		left, err = p.binary(op, left, right, tok.pos)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseUnary(depth int) (node, error) {
+	tok := p.peek()
+	if tok.kind == tokOperator && (tok.text == "!" || tok.text == "-") {
+		p.next()
+		if err := p.count(tok.pos); err != nil {
+			return nil, err
+		}
+		if depth > MaxDepth {
+			return nil, errorAt(tok.pos, fmt.Sprintf("expression nested deeper than %d levels", MaxDepth))
+		}
+		x, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		want := TypeNumber
+		if tok.text == "!" {
+			want = TypeBool
+		}
+		if x.typ() != want {
+			return nil, errorAt(tok.pos, fmt.Sprintf("operator %s requires %s, got %s", tok.text, want, x.typ()))
+		}
+		return &unaryNode{op: tok.text, x: x, t: want, pos: tok.pos}, nil
+	}
+	return p.parsePrimary(depth)
+}
+
+func (p *parser) parsePrimary(depth int) (node, error) {
+	tok := p.next()
+	if err := p.count(tok.pos); err != nil {
+		return nil, err
+	}
+
+	switch tok.kind {
+	case tokNumber:
+		v, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, errorAt(tok.pos, fmt.Sprintf("invalid number %q", tok.text))
+		}
+		return &literalNode{value: v, t: TypeNumber, pos: tok.pos}, nil
+
+	case tokString:
+		return &literalNode{value: tok.value, t: TypeString, pos: tok.pos}, nil
+
+	case tokLParen:
+		x, err := p.parseExpr(0, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokRParen, ")"); err != nil {
+			return nil, err
+		}
+		return x, nil
+
+	case tokLBracket:
+		return p.parseList(tok.pos, depth)
+
+	case tokIdent:
+		switch tok.text {
+		case "true", "false":
+			return &literalNode{value: tok.text == "true", t: TypeBool, pos: tok.pos}, nil
+		case "null":
+			return &literalNode{value: nil, t: TypeNull, pos: tok.pos}, nil
+		case "in":
+			return nil, errorAt(tok.pos, "unexpected \"in\"")
+		}
+		if p.peek().kind == tokLParen {
+			return p.parseCall(tok, depth)
+		}
+		t, ok := p.schema[tok.text]
+		if !ok {
+			return nil, errorAt(tok.pos, fmt.Sprintf("unknown variable %q", tok.text))
+		}
+		p.vars[tok.text] = true
+		return &identNode{name: tok.text, t: t, pos: tok.pos}, nil
+
+	case tokEOF:
+		return nil, errorAt(tok.pos, "unexpected end of expression")
+	}
+
+	return nil, errorAt(tok.pos, fmt.Sprintf("unexpected %q", tok.text))
+}
+
+func (p *parser) parseList(pos, depth int) (node, error) {
+	list := &listNode{pos: pos}
+	if p.peek().kind == tokRBracket {
+		p.next()
+		return nil, errorAt(pos, "empty list")
+	}
+	for {
+		item, err := p.parseExpr(0, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if item.typ() != TypeNumber && item.typ() != TypeString {
+			return nil, errorAt(item.position(), fmt.Sprintf("list items must be number or string, got %s", item.typ()))
+		}
+		if list.elem == "" {
+			list.elem = item.typ()
+		} else if item.typ() != list.elem {
+			return nil, errorAt(item.position(), fmt.Sprintf("list mixes %s and %s", list.elem, item.typ()))
+		}
+		list.items = append(list.items, item)
+
+		tok := p.next()
+		if tok.kind == tokRBracket {
+			return list, nil
+		}
+		if tok.kind != tokComma {
+			return nil, errorAt(tok.pos, "expected \",\" or \"]\"")
+		}
+	}
+}
+
+func (p *parser) parseCall(name token, depth int) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, errorAt(name.pos, fmt.Sprintf("unknown function %q", name.text))
+	}
+	p.next() // (
+
+	var args []node
+	if p.peek().kind != tokRParen {
+		for {
+			arg, err := p.parseExpr(0, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if err := p.expect(tokRParen, ")"); err != nil {
+		return nil, err
+	}
+
+	if err := fn.check(args); err != nil {
+		return nil, errorAt(name.pos, fmt.Sprintf("%s: %s", name.text, err.Error()))
+	}
+	return &callNode{fn: fn, args: args, pos: name.pos}, nil
+}
+
+// binary verifica los tipos de un operador binario y construye el nodo
+func (p *parser) binary(op string, l, r node, pos int) (node, error) {
+	lt, rt := l.typ(), r.typ()
+	mismatch := func() error {
+		return errorAt(pos, fmt.Sprintf("operator %s cannot be applied to %s and %s", op, lt, rt))
+	}
+
+	var t Type
+	switch op {
+	case "&&", "||":
+		if lt != TypeBool || rt != TypeBool {
+			return nil, mismatch()
+		}
+		t = TypeBool
+	case "==", "!=":
+		if lt != rt && lt != TypeNull && rt != TypeNull {
+			return nil, mismatch()
+		}
+		if lt == TypeList || rt == TypeList {
+			return nil, mismatch()
+		}
+		t = TypeBool
+	case "<", "<=", ">", ">=":
+		if lt != rt || (lt != TypeNumber && lt != TypeString) {
+			return nil, mismatch()
+		}
+		t = TypeBool
+	case "in":
+		list, ok := r.(*listNode)
+		if !ok || list.elem != lt {
+			return nil, mismatch()
+		}
+		t = TypeBool
+	case "+":
+		if lt != rt || (lt != TypeNumber && lt != TypeString) {
+			return nil, mismatch()
+		}
+		t = lt
+	case "-", "*", "/", "%":
+		if lt != TypeNumber || rt != TypeNumber {
+			return nil, mismatch()
+		}
+		t = TypeNumber
+	default:
+		return nil, errorAt(pos, fmt.Sprintf("unknown operator %s", op))
+	}
+
+	return &binaryNode{op: op, l: l, r: r, t: t, pos: pos}, nil
+}
@@ -0,0 +1,163 @@
+package expr
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testSchema = Schema{
+	"amount":          TypeNumber,
+	"income":          TypeNumber,
+	"name":            TypeString,
+	"ok":              TypeBool,
+	"banking.score":   TypeNumber,
+	"banking.history": TypeString,
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		src  string
+		vars []string
+	}{
+		{"amount > 100", []string{"amount"}},
+		{"amount * 12 > income * 3 && banking.score >= 600", []string{"amount", "banking.score", "income"}},
+		{"amount + income * 2 == amount + (income * 2)", []string{"amount", "income"}},
+		{"banking.history in ['GOOD', \"REGULAR\"]", []string{"banking.history"}},
+		{"has(banking.score) && banking.score > 600", []string{"banking.score"}},
+		{"!(amount < 0) || !ok", []string{"amount", "ok"}},
+		{"-amount < -1e3", []string{"amount"}},
+		{"name + 'x' == 'a\\'x'", []string{"name"}},
+		{"banking.score == null", []string{"banking.score"}},
+		{"null != banking.history", []string{"banking.history"}},
+		{"min(amount, income, 5) > .5", []string{"amount", "income"}},
+		{"lower(name) == upper(name) && len(name) % 2 == 0", []string{"name"}},
+		{"starts_with(name, 'a') || contains(name, 'b')", []string{"name"}},
+		{"true", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			program, err := Compile(tt.src, testSchema)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			if program.Source() != tt.src {
+				t.Errorf("Source = %q", program.Source())
+			}
+			if !reflect.DeepEqual(program.Variables(), tt.vars) {
+				t.Errorf("Variables = %v, want %v", program.Variables(), tt.vars)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		pos     int
+		wantErr string
+	}{
+		// Sintaxis
+		{"vacía", "", 0, "empty expression"},
+		{"solo espacios", "  \n\t", 0, "empty expression"},
+		{"sin operando", "amount > ", 9, "unexpected end of expression"},
+		{"tokens de más", "amount > 1 1", 11, `unexpected "1"`},
+		{"paréntesis sin cerrar", "(amount > 1", 11, `expected ")"`},
+		{"paréntesis de más", "amount > 1)", 10, `unexpected ")"`},
+		{"texto sin cerrar", "name == 'abc", 8, "unterminated string"},
+		{"número inválido", "1.2.3 > 1", 0, `invalid number "1.2.3"`},
+		{"exponente incompleto", "1e > 1", 0, `invalid number "1e"`},
+		{"carácter desconocido", "amount > 1; ok", 10, "unexpected character ';'"},
+		{"asignación", "amount = 1", 7, "unexpected character '='"},
+		{"in sin operando", "in [1]", 0, `unexpected "in"`},
+		{"lista vacía", "amount in []", 10, "empty list"},
+		{"lista sin separador", "amount in [1 2]", 13, `expected "," or "]"`},
+
+		// Variables y funciones
+		{"variable desconocida", "salary > 1", 0, `unknown variable "salary"`},
+		{"ruta desconocida", "banking.password == 'x'", 0, `unknown variable "banking.password"`},
+		{"función desconocida", "exec('rm -rf /')", 0, `unknown function "exec"`},
+		{"has de una expresión", "has(1)", 0, "has: argument must be a variable"},
+		{"argumento de otro tipo", "abs('a') > 1", 0, "abs: argument 1 must be number, got string"},
+		{"argumentos de más", "abs(1, 2) > 1", 0, "abs: expects 1 argument(s), got 2"},
+		{"variádica sin argumentos", "min() > 1", 0, "min: expects at least 1 argument"},
+		{"variádica con otro tipo", "max(1, name) > 1", 0, "max: argument 2 must be number, got string"},
+
+		// Tipos
+		{"resultado numérico", "amount * 2", 0, "expression must be boolean, got number"},
+		{"resultado de texto", "name", 0, "expression must be boolean, got string"},
+		{"comparar número y texto", "amount > 'a'", 7, "operator > cannot be applied to number and string"},
+		{"igualdad número y texto", "amount == name", 7, "operator == cannot be applied to number and string"},
+		{"sumar booleanos", "ok + ok", 3, "operator + cannot be applied to bool and bool"},
+		{"restar textos", "name - name == 0", 5, "operator - cannot be applied to string and string"},
+		{"y con número", "ok && amount", 3, "operator && cannot be applied to bool and number"},
+		{"comparar listas", "[1] == [1]", 4, "operator == cannot be applied to list and list"},
+		{"in con otro tipo", "name in [1, 2]", 5, "operator in cannot be applied to string and list"},
+		{"in sin lista", "name in name", 5, "operator in cannot be applied to string and string"},
+		{"lista mixta", "amount in [1, 'a']", 14, "list mixes number and string"},
+		{"lista de booleanos", "ok in [ok]", 7, "list items must be number or string, got bool"},
+		{"negar un texto", "-name < 0", 0, "operator - requires number, got string"},
+		{"no de un número", "!amount", 0, "operator ! requires bool, got number"},
+		{"null ordenado", "amount < null", 7, "operator < cannot be applied to number and null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.src, testSchema)
+			var exprErr *Error
+			if !errors.As(err, &exprErr) {
+				t.Fatalf("Compile(%q) error = %v, want *Error", tt.src, err)
+			}
+			if !strings.Contains(exprErr.Msg, tt.wantErr) || exprErr.Pos != tt.pos {
+				t.Errorf("Compile(%q) error = %v, want %q at position %d", tt.src, err, tt.wantErr, tt.pos)
+			}
+		})
+	}
+}
+
+// TestCompileHostile expresiones que buscan agotar el analizador o el evaluador
+func TestCompileHostile(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{"demasiado larga", "ok || " + strings.Repeat(" ", MaxSourceLength), "longer than 4096 characters"},
+		{"paréntesis anidados", strings.Repeat("(", MaxDepth+5) + "ok" + strings.Repeat(")", MaxDepth+5), "nested deeper than 32 levels"},
+		{"negaciones anidadas", strings.Repeat("!", MaxDepth+5) + "ok", "nested deeper than 32 levels"},
+		{"llamadas anidadas", strings.Repeat("abs(", MaxDepth+5) + "1" + strings.Repeat(")", MaxDepth+5) + " > 0", "nested deeper than 32 levels"},
+		{"listas anidadas", "amount in " + strings.Repeat("[", MaxDepth+5), "nested deeper than 32 levels"},
+		{"cadena de disyunciones", "ok" + strings.Repeat(" || ok", MaxNodes), "exceeds 256 nodes"},
+		{"suma larga", "1" + strings.Repeat("+1", MaxNodes) + " > 0", "exceeds 256 nodes"},
+		{"lista enorme", "amount in [1" + strings.Repeat(",1", MaxNodes) + "]", "exceeds 256 nodes"},
+		{"argumentos de más", "max(1" + strings.Repeat(",1", MaxNodes) + ") > 0", "exceeds 256 nodes"},
+		{"unicode", "amount > 1 ∧ ok", "unexpected character"},
+		{"byte nulo", "ok\x00", "unexpected character"},
+		{"plantilla", "${amount} > 1", "unexpected character '$'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.src, testSchema)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Compile error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Justo en los límites sí compila
+	atLimit := strings.Repeat("(", MaxDepth) + "ok" + strings.Repeat(")", MaxDepth)
+	if _, err := Compile(atLimit, testSchema); err != nil {
+		t.Errorf("Compile at the depth limit: %v", err)
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	want := []string{"abs", "contains", "has", "len", "lower", "max", "min", "round", "starts_with", "upper"}
+	if got := Functions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Functions = %v, want %v", got, want)
+	}
+}
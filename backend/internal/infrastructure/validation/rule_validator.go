@@ -2,6 +2,7 @@ package validation
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
@@ -14,19 +15,23 @@ import (
 
 // RuleValidator servicio para validación de reglas por país
 type RuleValidator struct {
-	db  *database.PostgresDB
-	log *logger.Logger
+	db        *database.PostgresDB
+	log       *logger.Logger
+	programs  programCache
+	documents *DocumentRegistry
 }
 
 // NewRuleValidator crea una nueva instancia del validador
 func NewRuleValidator(db *database.PostgresDB, log *logger.Logger) *RuleValidator {
 	return &RuleValidator{
-		db:  db,
-		log: log,
+		db:        db,
+		log:       log,
+		documents: NewDefaultDocumentRegistry(),
 	}
 }
 
 // ValidateApplication valida una solicitud según las reglas del país
+// Las reglas se evalúan en el orden recibido (prioridad descendente)
 func (v *RuleValidator) ValidateApplication(ctx context.Context, app *entity.CreditApplication, rules []entity.CountryRule) ([]entity.ValidationResult, error) {
 	var results []entity.ValidationResult
 
@@ -35,7 +40,7 @@ func (v *RuleValidator) ValidateApplication(ctx context.Context, app *entity.Cre
 			continue
 		}
 
-		result := v.evaluateRule(app, rule)
+		result := v.evaluateRule(ctx, app, rule)
 		results = append(results, result)
 
 		v.log.Debug().
@@ -49,7 +54,7 @@ func (v *RuleValidator) ValidateApplication(ctx context.Context, app *entity.Cre
 }
 
 // evaluateRule evalúa una regla específica
-func (v *RuleValidator) evaluateRule(app *entity.CreditApplication, rule entity.CountryRule) entity.ValidationResult {
+func (v *RuleValidator) evaluateRule(ctx context.Context, app *entity.CreditApplication, rule entity.CountryRule) entity.ValidationResult {
 	result := entity.ValidationResult{
 		RuleID:   rule.ID,
 		RuleName: rule.Name,
@@ -67,16 +72,41 @@ func (v *RuleValidator) evaluateRule(app *entity.CreditApplication, rule entity.
 		result = v.validateCreditScore(app, rule)
 	case entity.RuleTypeAmountThreshold:
 		result = v.validateAmountThreshold(app, rule)
+	case entity.RuleTypeCustom:
+		result = v.validateCustom(ctx, app, rule)
 	default:
 		result.Message = "Rule type not implemented"
 	}
 
+	// on_fail: REQUIRE_REVIEW convierte el fallo de una regla predefinida en revisión manual
+	if !result.Passed && rule.RuleType != entity.RuleTypeCustom {
+		if onFail, _ := rule.Config["on_fail"].(string); entity.RuleAction(onFail) == entity.RuleActionRequireReview {
+			result.RequiresReview = true
+		}
+	}
+
 	result.RuleID = rule.ID
 	result.RuleName = rule.Name
 
 	return result
 }
 
+// ClassifyResults devuelve la primera regla que rechaza (por prioridad)
+// y los nombres de las reglas que obligan a revisión manual
+func ClassifyResults(results []entity.ValidationResult) (*entity.ValidationResult, []string) {
+	var review []string
+	for i := range results {
+		r := &results[i]
+		if !r.Passed && !r.RequiresReview {
+			return r, nil
+		}
+		if r.RequiresReview {
+			review = append(review, r.RuleName)
+		}
+	}
+	return nil, review
+}
+
 // validateDocument valida el documento según la configuración
 func (v *RuleValidator) validateDocument(app *entity.CreditApplication, rule entity.CountryRule) entity.ValidationResult {
 	result := entity.ValidationResult{Passed: true}
@@ -91,7 +121,11 @@ func (v *RuleValidator) validateDocument(app *entity.CreditApplication, rule ent
 
 	// Validar formato si hay regex
 	if validateChecksum, ok := rule.Config["validate_checksum"].(bool); ok && validateChecksum {
-		if !v.validateDocumentChecksum(app.DocumentType, app.DocumentNumber) {
+		countryCode := ""
+		if app.Country != nil {
+			countryCode = app.Country.Code
+		}
+		if !v.documents.Validate(countryCode, app.DocumentType, app.DocumentNumber) {
 			result.Passed = false
 			result.Message = "Document validation failed"
 			return result
@@ -102,140 +136,6 @@ func (v *RuleValidator) validateDocument(app *entity.CreditApplication, rule ent
 	return result
 }
 
-// validateDocumentChecksum valida el checksum de documentos por tipo
-func (v *RuleValidator) validateDocumentChecksum(docType, docNumber string) bool {
-	docNumber = strings.ToUpper(strings.ReplaceAll(docNumber, " ", ""))
-
-	switch docType {
-	case "DNI":
-		return v.validateSpanishDNI(docNumber)
-	case "NIE":
-		return v.validateSpanishNIE(docNumber)
-	case "NIF":
-		return v.validatePortugueseNIF(docNumber)
-	case "CURP":
-		return v.validateMexicanCURP(docNumber)
-	case "CPF":
-		return v.validateBrazilianCPF(docNumber)
-	case "CC":
-		return v.validateColombianCC(docNumber)
-	case "CF":
-		return v.validateItalianCF(docNumber)
-	default:
-		return len(docNumber) >= 5 // Validación básica
-	}
-}
-
-// Validaciones específicas por país
-
-func (v *RuleValidator) validateSpanishDNI(dni string) bool {
-	// Formato: 8 dígitos + 1 letra
-	if len(dni) != 9 {
-		return false
-	}
-	match, _ := regexp.MatchString(`^[0-9]{8}[A-Z]$`, dni)
-	if !match {
-		return false
-	}
-
-	// Validar letra de control
-	letters := "TRWAGMYFPDXBNJZSQVHLCKE"
-	num := dni[:8]
-	var numInt int
-	fmt.Sscanf(num, "%d", &numInt)
-	expectedLetter := letters[numInt%23]
-	return dni[8] == byte(expectedLetter)
-}
-
-func (v *RuleValidator) validateSpanishNIE(nie string) bool {
-	if len(nie) != 9 {
-		return false
-	}
-	match, _ := regexp.MatchString(`^[XYZ][0-9]{7}[A-Z]$`, nie)
-	return match
-}
-
-func (v *RuleValidator) validatePortugueseNIF(nif string) bool {
-	if len(nif) != 9 {
-		return false
-	}
-	match, _ := regexp.MatchString(`^[0-9]{9}$`, nif)
-	return match
-}
-
-func (v *RuleValidator) validateMexicanCURP(curp string) bool {
-	if len(curp) != 18 {
-		return false
-	}
-	match, _ := regexp.MatchString(`^[A-Z]{4}[0-9]{6}[HM][A-Z]{5}[0-9A-Z][0-9]$`, curp)
-	return match
-}
-
-func (v *RuleValidator) validateBrazilianCPF(cpf string) bool {
-	// Remover caracteres no numéricos
-	cpf = regexp.MustCompile(`[^0-9]`).ReplaceAllString(cpf, "")
-	if len(cpf) != 11 {
-		return false
-	}
-
-	// Verificar si todos los dígitos son iguales
-	allEqual := true
-	for i := 1; i < len(cpf); i++ {
-		if cpf[i] != cpf[0] {
-			allEqual = false
-			break
-		}
-	}
-	if allEqual {
-		return false
-	}
-
-	// Validar dígitos verificadores
-	return v.validateCPFDigits(cpf)
-}
-
-func (v *RuleValidator) validateCPFDigits(cpf string) bool {
-	// Primer dígito verificador
-	sum := 0
-	for i := 0; i < 9; i++ {
-		sum += int(cpf[i]-'0') * (10 - i)
-	}
-	remainder := sum % 11
-	digit1 := 0
-	if remainder >= 2 {
-		digit1 = 11 - remainder
-	}
-	if int(cpf[9]-'0') != digit1 {
-		return false
-	}
-
-	// Segundo dígito verificador
-	sum = 0
-	for i := 0; i < 10; i++ {
-		sum += int(cpf[i]-'0') * (11 - i)
-	}
-	remainder = sum % 11
-	digit2 := 0
-	if remainder >= 2 {
-		digit2 = 11 - remainder
-	}
-	return int(cpf[10]-'0') == digit2
-}
-
-func (v *RuleValidator) validateColombianCC(cc string) bool {
-	// Cédula colombiana: 6-10 dígitos
-	cc = regexp.MustCompile(`[^0-9]`).ReplaceAllString(cc, "")
-	return len(cc) >= 6 && len(cc) <= 10
-}
-
-func (v *RuleValidator) validateItalianCF(cf string) bool {
-	if len(cf) != 16 {
-		return false
-	}
-	match, _ := regexp.MatchString(`^[A-Z]{6}[0-9]{2}[A-Z][0-9]{2}[A-Z][0-9]{3}[A-Z]$`, cf)
-	return match
-}
-
 // validateIncome valida la relación ingreso/monto solicitado
 func (v *RuleValidator) validateIncome(app *entity.CreditApplication, rule entity.CountryRule) entity.ValidationResult {
 	result := entity.ValidationResult{Passed: true}
@@ -362,15 +262,18 @@ func (v *RuleValidator) ValidateDocument(ctx context.Context, docType, docNumber
 	// Si hay regex, validar
 	if validationRegex != "" {
 		docNumber = strings.ToUpper(strings.ReplaceAll(docNumber, " ", ""))
-		match, _ := regexp.MatchString(validationRegex, docNumber)
+		match, err := regexp.MatchString(validationRegex, docNumber)
+		if err != nil {
+			return false, "Document validation regex is invalid", fmt.Errorf("invalid validation regex for %s/%s: %w", countryCode, docType, err)
+		}
 		if !match {
 			return false, "Document format is invalid", nil
 		}
 	}
 
 	// Validar checksum si aplica
-	if !v.validateDocumentChecksum(docType, docNumber) {
-		return false, "Document validation failed", nil
+	if !v.documents.Validate(countryCode, docType, docNumber) {
+		return false, "Document check digit is invalid", nil
 	}
 
 	return true, "Document is valid", nil
@@ -402,6 +305,9 @@ func (v *RuleValidator) GetRulesForCountry(ctx context.Context, countryID uuid.U
 		); err != nil {
 			return nil, err
 		}
+		if err := json.Unmarshal(configJSON, &rule.Config); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal rule config: %w", err)
+		}
 		rules = append(rules, rule)
 	}
 
@@ -0,0 +1,195 @@
+package watchlist
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+)
+
+// ErrInvalidListFile el archivo no tiene el formato esperado
+var ErrInvalidListFile = errors.New("invalid watchlist file")
+
+// csvColumns nombres aceptados para cada columna del CSV (en minúsculas)
+var csvColumns = map[string][]string{
+	"external_id": {"external_id", "id", "reference", "reference_number", "uid"},
+	"name":        {"full_name", "name", "nombre"},
+	"aliases":     {"aliases", "alias", "aka"},
+	"documents":   {"document_numbers", "document_number", "documents", "document", "documento"},
+	"country":     {"country", "nationality", "pais"},
+	"program":     {"program", "programme", "list", "un_list_type"},
+}
+
+// ParseCSV lee una lista en CSV con cabecera. La columna del nombre es obligatoria;
+// alias y documentos admiten varios valores separados por ";" o "|". El separador
+// de campos puede ser "," o ";" (se detecta en la cabecera)
+func ParseCSV(r io.Reader) ([]entity.WatchlistEntry, error) {
+	br := bufio.NewReader(r)
+	header, _ := br.Peek(4096)
+	firstLine := string(header)
+	if i := strings.IndexAny(firstLine, "\r\n"); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	if !strings.Contains(firstLine, ",") && strings.Contains(firstLine, ";") {
+		reader.Comma = ';'
+	}
+
+	columns, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing header: %s", ErrInvalidListFile, err.Error())
+	}
+	index := make(map[string]int)
+	for i, column := range columns {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		for field, names := range csvColumns {
+			for _, name := range names {
+				if _, seen := index[field]; !seen && column == name {
+					index[field] = i
+				}
+			}
+		}
+	}
+	if _, ok := index["name"]; !ok {
+		return nil, fmt.Errorf("%w: header must include a name or full_name column", ErrInvalidListFile)
+	}
+
+	get := func(record []string, field string) string {
+		i, ok := index[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var entries []entity.WatchlistEntry
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %s", ErrInvalidListFile, line, err.Error())
+		}
+		name := get(record, "name")
+		if name == "" {
+			continue
+		}
+		entries = append(entries, entity.WatchlistEntry{
+			ExternalID:      get(record, "external_id"),
+			FullName:        name,
+			Aliases:         splitValues(get(record, "aliases")),
+			DocumentNumbers: splitValues(get(record, "documents")),
+			Country:         get(record, "country"),
+			Program:         get(record, "program"),
+		})
+	}
+	return entries, nil
+}
+
+// consolidatedAlias alias de una persona o entidad de la lista consolidada
+type consolidatedAlias struct {
+	Quality string `xml:"QUALITY"`
+	Name    string `xml:"ALIAS_NAME"`
+}
+
+// consolidatedRecord persona (INDIVIDUAL) o entidad (ENTITY) de la lista consolidada
+type consolidatedRecord struct {
+	DataID        string              `xml:"DATAID"`
+	FirstName     string              `xml:"FIRST_NAME"`
+	SecondName    string              `xml:"SECOND_NAME"`
+	ThirdName     string              `xml:"THIRD_NAME"`
+	FourthName    string              `xml:"FOURTH_NAME"`
+	ListType      string              `xml:"UN_LIST_TYPE"`
+	Reference     string              `xml:"REFERENCE_NUMBER"`
+	Nationalities []string            `xml:"NATIONALITY>VALUE"`
+	Aliases       []consolidatedAlias `xml:"INDIVIDUAL_ALIAS"`
+	EntityAliases []consolidatedAlias `xml:"ENTITY_ALIAS"`
+	Documents     []struct {
+		Type   string `xml:"TYPE_OF_DOCUMENT"`
+		Number string `xml:"NUMBER"`
+	} `xml:"INDIVIDUAL_DOCUMENT"`
+}
+
+// ParseConsolidatedXML lee la lista consolidada en XML (CONSOLIDATED_LIST con
+// INDIVIDUALS/INDIVIDUAL y ENTITIES/ENTITY) procesando un registro a la vez
+func ParseConsolidatedXML(r io.Reader) ([]entity.WatchlistEntry, error) {
+	decoder := xml.NewDecoder(r)
+	var entries []entity.WatchlistEntry
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidListFile, err.Error())
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || (start.Name.Local != "INDIVIDUAL" && start.Name.Local != "ENTITY") {
+			continue
+		}
+
+		var record consolidatedRecord
+		if err := decoder.DecodeElement(&record, &start); err != nil {
+			return nil, fmt.Errorf("%w: %s: %s", ErrInvalidListFile, start.Name.Local, err.Error())
+		}
+		if entry, ok := record.toEntry(); ok {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: no INDIVIDUAL or ENTITY records found", ErrInvalidListFile)
+	}
+	return entries, nil
+}
+
+// toEntry convierte el registro de la lista consolidada
+func (c consolidatedRecord) toEntry() (entity.WatchlistEntry, bool) {
+	name := strings.Join(strings.Fields(strings.Join([]string{c.FirstName, c.SecondName, c.ThirdName, c.FourthName}, " ")), " ")
+	if name == "" {
+		return entity.WatchlistEntry{}, false
+	}
+
+	entry := entity.WatchlistEntry{
+		ExternalID: c.DataID,
+		FullName:   name,
+		Program:    c.ListType,
+	}
+	if entry.ExternalID == "" {
+		entry.ExternalID = c.Reference
+	}
+	if len(c.Nationalities) > 0 {
+		entry.Country = strings.TrimSpace(c.Nationalities[0])
+	}
+	for _, alias := range append(c.Aliases, c.EntityAliases...) {
+		if n := strings.TrimSpace(alias.Name); n != "" {
+			entry.Aliases = append(entry.Aliases, n)
+		}
+	}
+	for _, doc := range c.Documents {
+		if n := strings.TrimSpace(doc.Number); n != "" {
+			entry.DocumentNumbers = append(entry.DocumentNumbers, n)
+		}
+	}
+	return entry, true
+}
+
+// splitValues separa una celda con varios valores
+func splitValues(value string) []string {
+	var values []string
+	for _, v := range strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == '|' }) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
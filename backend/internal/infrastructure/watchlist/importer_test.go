@@ -0,0 +1,156 @@
+package watchlist
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+)
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []entity.WatchlistEntry
+		wantErr string
+	}{
+		{
+			name: "todas las columnas",
+			input: "external_id,full_name,aliases,document_numbers,country,program\n" +
+				"QDi.1,Juan Pérez,\"Juancho; J. Perez\",A123|B456,MX,SANCTIONS\n",
+			want: []entity.WatchlistEntry{{
+				ExternalID: "QDi.1", FullName: "Juan Pérez", Aliases: []string{"Juancho", "J. Perez"},
+				DocumentNumbers: []string{"A123", "B456"}, Country: "MX", Program: "SANCTIONS",
+			}},
+		},
+		{
+			name:  "nombres alternativos, BOM y separador punto y coma",
+			input: "\ufeffID;Nombre;Documento\n7;Ana Gómez;X1\n8; Luis Díaz ;\n",
+			want: []entity.WatchlistEntry{
+				{ExternalID: "7", FullName: "Ana Gómez", DocumentNumbers: []string{"X1"}},
+				{ExternalID: "8", FullName: "Luis Díaz"},
+			},
+		},
+		{
+			name:  "filas sin nombre o cortas",
+			input: "name,aliases\n,Sin nombre\nEva Ruiz\n\n",
+			want:  []entity.WatchlistEntry{{FullName: "Eva Ruiz"}},
+		},
+		{
+			name:  "la primera columna que coincide gana",
+			input: "full_name,name\nPrimera,Segunda\n",
+			want:  []entity.WatchlistEntry{{FullName: "Primera"}},
+		},
+		{"sin cabecera", "", nil, "missing header"},
+		{"sin columna de nombre", "id,country\n1,MX\n", nil, "header must include a name or full_name column"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := ParseCSV(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrInvalidListFile) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseCSV error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCSV: %v", err)
+			}
+			if !reflect.DeepEqual(entries, tt.want) {
+				t.Errorf("ParseCSV = %+v, want %+v", entries, tt.want)
+			}
+		})
+	}
+}
+
+const consolidatedList = `<?xml version="1.0" encoding="UTF-8"?>
+<CONSOLIDATED_LIST>
+  <INDIVIDUALS>
+    <INDIVIDUAL>
+      <DATAID>6908555</DATAID>
+      <FIRST_NAME>ABDUL</FIRST_NAME>
+      <SECOND_NAME>  RAHMAN </SECOND_NAME>
+      <THIRD_NAME></THIRD_NAME>
+      <UN_LIST_TYPE>Al-Qaida</UN_LIST_TYPE>
+      <REFERENCE_NUMBER>QDi.001</REFERENCE_NUMBER>
+      <NATIONALITY><VALUE>Afghanistan</VALUE><VALUE>Pakistan</VALUE></NATIONALITY>
+      <INDIVIDUAL_ALIAS><QUALITY>Good</QUALITY><ALIAS_NAME>Abu Rahman</ALIAS_NAME></INDIVIDUAL_ALIAS>
+      <INDIVIDUAL_ALIAS><QUALITY>Low</QUALITY><ALIAS_NAME> </ALIAS_NAME></INDIVIDUAL_ALIAS>
+      <INDIVIDUAL_DOCUMENT><TYPE_OF_DOCUMENT>Passport</TYPE_OF_DOCUMENT><NUMBER>OR801168</NUMBER></INDIVIDUAL_DOCUMENT>
+    </INDIVIDUAL>
+    <INDIVIDUAL>
+      <REFERENCE_NUMBER>QDi.002</REFERENCE_NUMBER>
+    </INDIVIDUAL>
+  </INDIVIDUALS>
+  <ENTITIES>
+    <ENTITY>
+      <FIRST_NAME>AL RASHID TRUST</FIRST_NAME>
+      <REFERENCE_NUMBER>QDe.005</REFERENCE_NUMBER>
+      <ENTITY_ALIAS><QUALITY>a.k.a.</QUALITY><ALIAS_NAME>Al-Rasheed Trust</ALIAS_NAME></ENTITY_ALIAS>
+    </ENTITY>
+  </ENTITIES>
+</CONSOLIDATED_LIST>`
+
+func TestParseConsolidatedXML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []entity.WatchlistEntry
+		wantErr string
+	}{
+		{
+			name:  "personas y entidades",
+			input: consolidatedList,
+			want: []entity.WatchlistEntry{
+				{
+					ExternalID: "6908555", FullName: "ABDUL RAHMAN", Program: "Al-Qaida", Country: "Afghanistan",
+					Aliases: []string{"Abu Rahman"}, DocumentNumbers: []string{"OR801168"},
+				},
+				{ExternalID: "QDe.005", FullName: "AL RASHID TRUST", Aliases: []string{"Al-Rasheed Trust"}},
+			},
+		},
+		{"sin registros", "<CONSOLIDATED_LIST><INDIVIDUALS/></CONSOLIDATED_LIST>", nil, "no INDIVIDUAL or ENTITY records found"},
+		{"registros sin nombre", "<CONSOLIDATED_LIST><INDIVIDUAL><DATAID>1</DATAID></INDIVIDUAL></CONSOLIDATED_LIST>", nil, "no INDIVIDUAL or ENTITY records found"},
+		{"XML mal formado", "<CONSOLIDATED_LIST><INDIVIDUAL><FIRST_NAME>A</INDIVIDUAL>", nil, "INDIVIDUAL"},
+		{"no es XML", "name,aliases\nAna,", nil, "no INDIVIDUAL or ENTITY records found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := ParseConsolidatedXML(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrInvalidListFile) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseConsolidatedXML error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseConsolidatedXML: %v", err)
+			}
+			if !reflect.DeepEqual(entries, tt.want) {
+				t.Errorf("ParseConsolidatedXML = %+v, want %+v", entries, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitValues(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"a; b |c", []string{"a", "b", "c"}},
+		{" ;| ", nil},
+		{"", nil},
+		{"solo", []string{"solo"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitValues(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitValues(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package watchlist
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// foldings letras que no se descomponen en letra base + acento
+var foldings = map[rune]string{
+	'ß': "SS", 'Æ': "AE", 'æ': "AE", 'Œ': "OE", 'œ': "OE",
+	'Ø': "O", 'ø': "O", 'Ł': "L", 'ł': "L", 'Đ': "D", 'đ': "D", 'Ð': "D", 'ð': "D",
+	'Þ': "TH", 'þ': "TH", 'ı': "I",
+}
+
+// NormalizeName normaliza un nombre para compararlo: sin acentos, en mayúsculas,
+// solo letras, dígitos y un espacio entre palabras ("José  Pérez-Núñez" -> "JOSE PEREZ NUNEZ")
+func NormalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Acento separado de su letra por NFD
+		case foldings[r] != "":
+			b.WriteString(foldings[r])
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToUpper(r))
+		default:
+			b.WriteByte(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// NormalizeDocument normaliza un número de documento: mayúsculas, solo letras y dígitos
+func NormalizeDocument(number string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(number) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package watchlist
+
+import "testing"
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"José  Pérez-Núñez", "JOSE PEREZ NUNEZ"},
+		{"  maría\tdel   carmen ", "MARIA DEL CARMEN"},
+		{"Müller, Jürgen", "MULLER JURGEN"},
+		{"Strauß", "STRAUSS"},
+		{"Søren Kierkegaard", "SOREN KIERKEGAARD"},
+		{"Łukasz Żółć", "LUKASZ ZOLC"},
+		{"Œdipe Æthelred", "OEDIPE AETHELRED"},
+		{"O'Brien Jr.", "O BRIEN JR"},
+		{"Ali Hassan 2", "ALI HASSAN 2"},
+		{"", ""},
+		{"-- .. --", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeName(tt.name); got != tt.want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeDocument(t *testing.T) {
+	tests := []struct {
+		number string
+		want   string
+	}{
+		{"12.345.678-k", "12345678K"},
+		{" x1234567-l ", "X1234567L"},
+		{"AB 123 456", "AB123456"},
+		{"", ""},
+		{"--", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeDocument(tt.number); got != tt.want {
+			t.Errorf("NormalizeDocument(%q) = %q, want %q", tt.number, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package watchlist
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/domain/repository"
+	"github.com/fintech-multipass/backend/internal/domain/service"
+	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+	"github.com/google/uuid"
+)
+
+var _ service.WatchlistScreener = (*Screener)(nil)
+
+// DefaultMatchThreshold similitud de nombre (0-1) desde la que se considera un posible hit
+// No puede ser menor que pg_trgm.similarity_threshold (0.3), que prefiltra con el índice
+const DefaultMatchThreshold = 0.6
+
+// maxMatches hits que se guardan por solicitud
+const maxMatches = 20
+
+// Screener busca a los solicitantes en las listas cargadas
+type Screener struct {
+	repo      repository.WatchlistRepository
+	threshold float64
+	log       *logger.Logger
+}
+
+// NewScreener crea el screener con el umbral de similitud indicado (0 = por defecto)
+func NewScreener(repo repository.WatchlistRepository, threshold float64, log *logger.Logger) *Screener {
+	if threshold <= 0 {
+		threshold = DefaultMatchThreshold
+	}
+	return &Screener{repo: repo, threshold: threshold, log: log}
+}
+
+// Screen busca el nombre (sin acentos, por similitud) y el documento (exacto) del
+// solicitante, guarda los posibles hits y devuelve el estado de screening
+func (s *Screener) Screen(ctx context.Context, app *entity.CreditApplication) (entity.ScreeningStatus, error) {
+	matches, err := s.repo.FindMatches(ctx, NormalizeName(app.FullName), NormalizeDocument(app.DocumentNumber), s.threshold, maxMatches)
+	if err != nil {
+		return "", err
+	}
+
+	hits := make([]entity.WatchlistHit, 0, len(matches))
+	for _, m := range matches {
+		entryID := m.Entry.ID
+		hits = append(hits, entity.WatchlistHit{
+			ID:          uuid.New(),
+			EntryID:     &entryID,
+			Source:      m.Entry.Source,
+			ListType:    m.Entry.ListType,
+			ExternalID:  m.Entry.ExternalID,
+			EntryName:   m.Entry.FullName,
+			MatchedName: m.MatchedName,
+			MatchType:   m.MatchType,
+			Score:       m.Score,
+		})
+	}
+
+	status, err := s.repo.SaveScreening(ctx, app.ID, hits)
+	if err != nil {
+		return "", fmt.Errorf("failed to save screening: %w", err)
+	}
+
+	s.log.Info().
+		Str("application_id", app.ID.String()).
+		Int("matches", len(matches)).
+		Str("screening_status", string(status)).
+		Msg("Watchlist screening completed")
+
+	return status, nil
+}
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/fintech-multipass/backend/internal/application/usecase"
@@ -35,6 +37,7 @@ func NewApplicationHandler(uc *usecase.ApplicationUseCase, log *logger.Logger) *
 // @Param input body usecase.CreateApplicationInput true "Datos de la solicitud"
 // @Success 201 {object} entity.CreditApplication
 // @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Security BearerAuth
 // @Router /applications [post]
@@ -53,6 +56,13 @@ func (h *ApplicationHandler) Create(c *gin.Context) {
 	input.UserAgent = c.GetHeader("User-Agent")
 
 	app, err := h.usecase.CreateApplication(c.Request.Context(), input)
+	if errors.Is(err, usecase.ErrVelocityLimitExceeded) {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "velocity_limit_exceeded",
+			Message: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		h.log.Error().Err(err).Msg("Failed to create application")
 		c.JSON(http.StatusBadRequest, ErrorResponse{
@@ -213,6 +223,7 @@ func (h *ApplicationHandler) List(c *gin.Context) {
 // @Success 200 {object} entity.CreditApplication
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
 // @Security BearerAuth
 // @Router /applications/{id}/status [patch]
 func (h *ApplicationHandler) UpdateStatus(c *gin.Context) {
@@ -251,6 +262,13 @@ func (h *ApplicationHandler) UpdateStatus(c *gin.Context) {
 	}
 
 	app, err := h.usecase.UpdateStatus(c.Request.Context(), input)
+	if errors.Is(err, usecase.ErrWatchlistHitPending) {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "watchlist_hit_pending",
+			Message: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		status := http.StatusBadRequest
 		if err.Error() == "application not found" {
@@ -266,6 +284,52 @@ func (h *ApplicationHandler) UpdateStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, app)
 }
 
+// RefreshBankingInfo fuerza una nueva consulta bancaria para una solicitud
+// @Summary Refrescar información bancaria
+// @Description Encola una nueva consulta al proveedor ignorando reportes reutilizables del mismo documento
+// @Tags applications
+// @Produce json
+// @Param id path string true "ID de la solicitud"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /applications/{id}/banking-info/refresh [post]
+func (h *ApplicationHandler) RefreshBankingInfo(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid application ID format",
+		})
+		return
+	}
+
+	job, err := h.usecase.RefreshBankingInfo(c.Request.Context(), id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, usecase.ErrBankingRefreshNotAllowed):
+			status = http.StatusConflict
+		case strings.HasPrefix(err.Error(), "application not found"):
+			status = http.StatusNotFound
+		}
+		c.JSON(status, ErrorResponse{
+			Error:   "refresh_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"application_id": id,
+		"job_id":         job.ID,
+		"message":        "Banking info refresh enqueued",
+	})
+}
+
 // GetHistory obtiene el historial de transiciones de una solicitud
 // @Summary Obtener historial
 // @Description Obtiene el historial de cambios de estado de una solicitud
@@ -299,6 +363,65 @@ func (h *ApplicationHandler) GetHistory(c *gin.Context) {
 	c.JSON(http.StatusOK, history)
 }
 
+// GetDecision obtiene el desglose de la última evaluación de riesgo
+// @Summary Obtener decisión de riesgo
+// @Description Devuelve el score, la decisión, el aporte de cada factor y los códigos de motivo adversos. Con history=true incluye las evaluaciones anteriores
+// @Tags applications
+// @Produce json
+// @Param id path string true "ID de la solicitud"
+// @Param history query bool false "Incluir evaluaciones anteriores"
+// @Success 200 {object} entity.RiskDecision
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /applications/{id}/decision [get]
+func (h *ApplicationHandler) GetDecision(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid application ID format",
+		})
+		return
+	}
+
+	decision, err := h.usecase.GetDecision(c.Request.Context(), id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, usecase.ErrDecisionNotFound):
+			status = http.StatusNotFound
+		case strings.HasPrefix(err.Error(), "application not found"):
+			status = http.StatusNotFound
+		}
+		c.JSON(status, ErrorResponse{
+			Error:   "not_found",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if c.Query("history") != "true" {
+		c.JSON(http.StatusOK, decision)
+		return
+	}
+
+	history, err := h.usecase.GetDecisionHistory(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "fetch_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"decision": decision,
+		"history":  history,
+	})
+}
+
 // UpdateStatusRequest request para actualizar estado
 type UpdateStatusRequest struct {
 	Status string `json:"status" binding:"required"`
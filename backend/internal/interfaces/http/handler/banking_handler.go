@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/repository"
+	"github.com/fintech-multipass/backend/internal/infrastructure/database"
+	"github.com/fintech-multipass/backend/internal/infrastructure/encryption"
+	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// BankingHandler handler para consultar las llamadas a proveedores bancarios
+type BankingHandler struct {
+	providerRepo repository.BankingProviderRepository
+	cipher       *encryption.Envelope
+	db           *database.PostgresDB
+	log          *logger.Logger
+}
+
+// NewBankingHandler crea una nueva instancia del handler
+func NewBankingHandler(providerRepo repository.BankingProviderRepository, cipher *encryption.Envelope, db *database.PostgresDB, log *logger.Logger) *BankingHandler {
+	return &BankingHandler{
+		providerRepo: providerRepo,
+		cipher:       cipher,
+		db:           db,
+		log:          log,
+	}
+}
+
+// BankingRequestDetail llamada a un proveedor con los datos descifrados
+type BankingRequestDetail struct {
+	ID              uuid.UUID       `json:"id"`
+	ProviderID      uuid.UUID       `json:"provider_id"`
+	ProviderCode    string          `json:"provider_code"`
+	RequestType     string          `json:"request_type"`
+	Status          string          `json:"status"`
+	ErrorMessage    string          `json:"error_message,omitempty"`
+	DurationMs      int             `json:"duration_ms"`
+	EncryptionKeyID string          `json:"encryption_key_id,omitempty"`
+	RequestData     json.RawMessage `json:"request_data,omitempty"`
+	ResponseData    json.RawMessage `json:"response_data,omitempty"`
+	DecryptError    string          `json:"decrypt_error,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	CompletedAt     *time.Time      `json:"completed_at,omitempty"`
+}
+
+// GetApplicationRequests lista y descifra las llamadas a proveedores de una solicitud
+// @Summary Llamadas a proveedores de una solicitud
+// @Description Devuelve cada llamada a burós de crédito con petición y respuesta descifradas. Cada acceso queda auditado
+// @Tags admin
+// @Produce json
+// @Param id path string true "ID de la solicitud"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/applications/{id}/banking-requests [get]
+func (h *BankingHandler) GetApplicationRequests(c *gin.Context) {
+	appID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid application ID format",
+		})
+		return
+	}
+
+	requests, err := h.providerRepo.GetRequestsByApplication(c.Request.Context(), appID)
+	if err != nil {
+		h.log.Error().Err(err).Str("application_id", appID.String()).Msg("Failed to get banking requests")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: "Failed to get banking requests",
+		})
+		return
+	}
+
+	details := make([]BankingRequestDetail, 0, len(requests))
+	for _, req := range requests {
+		detail := BankingRequestDetail{
+			ID:              req.ID,
+			ProviderID:      req.ProviderID,
+			ProviderCode:    req.ProviderCode,
+			RequestType:     req.RequestType,
+			Status:          req.Status,
+			ErrorMessage:    req.ErrorMessage,
+			DurationMs:      req.Duration,
+			EncryptionKeyID: req.EncryptionKeyID,
+			CreatedAt:       req.CreatedAt,
+			CompletedAt:     req.CompletedAt,
+		}
+
+		if len(req.RequestData) > 0 {
+			if plain, err := h.cipher.Decrypt(req.RequestData); err != nil {
+				detail.DecryptError = err.Error()
+			} else {
+				detail.RequestData = plain
+			}
+		}
+		if len(req.ResponseData) > 0 {
+			if plain, err := h.cipher.Decrypt(req.ResponseData); err != nil {
+				detail.DecryptError = err.Error()
+			} else {
+				detail.ResponseData = plain
+			}
+		}
+
+		details = append(details, detail)
+	}
+
+	h.auditAccess(c, appID, len(details))
+
+	c.JSON(http.StatusOK, gin.H{
+		"application_id": appID,
+		"requests":       details,
+		"count":          len(details),
+	})
+}
+
+// auditAccess registra en audit_logs quién descifró los datos de la solicitud
+func (h *BankingHandler) auditAccess(c *gin.Context, appID uuid.UUID, count int) {
+	var actorID *uuid.UUID
+	if userID, ok := c.Get("user_id"); ok {
+		if uid, ok := userID.(uuid.UUID); ok {
+			actorID = &uid
+		}
+	}
+
+	newValues, _ := json.Marshal(map[string]interface{}{"banking_requests": count})
+
+	query := `
+		INSERT INTO audit_logs (entity_type, entity_id, action, actor_type, actor_id, new_values, ip_address, user_agent)
+		VALUES ('banking_requests', $1, 'DECRYPT', 'USER', $2, $3::jsonb, $4::inet, $5)
+	`
+	if err := h.db.Exec(c.Request.Context(), query, appID, actorID, string(newValues), c.ClientIP(), c.GetHeader("User-Agent")); err != nil {
+		h.log.Error().Err(err).Str("application_id", appID.String()).Msg("Failed to audit banking request access")
+	}
+}
@@ -0,0 +1,478 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fintech-multipass/backend/internal/application/usecase"
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// DocumentTypeRequest alta o modificación de un tipo de documento
+type DocumentTypeRequest struct {
+	Code            string `json:"code" binding:"required"`
+	Name            string `json:"name" binding:"required"`
+	ValidationRegex string `json:"validation_regex"`
+	IsRequired      *bool  `json:"is_required"` // Por defecto true
+}
+
+// ListAllRules lista todas las reglas de un país, incluidas las inactivas
+// @Summary Listar reglas de país (admin)
+// @Description Lista todas las reglas del país, activas e inactivas, por prioridad
+// @Tags admin
+// @Produce json
+// @Param code path string true "Código del país"
+// @Success 200 {array} entity.CountryRule
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/rules [get]
+func (h *CountryHandler) ListAllRules(c *gin.Context) {
+	country, ok := h.adminCountry(c)
+	if !ok {
+		return
+	}
+
+	rules, err := h.usecase.GetAllRules(c.Request.Context(), country.ID)
+	if err != nil {
+		h.configError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// DeleteRule elimina una regla de un país
+// @Summary Eliminar regla de país
+// @Description Elimina la regla y guarda una nueva versión de la configuración; se puede recuperar con un rollback
+// @Tags admin
+// @Produce json
+// @Param code path string true "Código del país"
+// @Param id path string true "ID de la regla"
+// @Success 200 {object} entity.CountryConfigVersion
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/rules/{id} [delete]
+func (h *CountryHandler) DeleteRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid rule ID format",
+		})
+		return
+	}
+	country, ok := h.adminCountry(c)
+	if !ok {
+		return
+	}
+
+	version, err := h.usecase.DeleteRule(c.Request.Context(), country.ID, id, currentUserID(c))
+	if err != nil {
+		h.configError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, version)
+}
+
+// CreateDocumentType crea un tipo de documento de un país
+// @Summary Crear tipo de documento
+// @Description Crea un tipo de documento y guarda una nueva versión de la configuración. La regex debe compilar y el código ser único en el país
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param code path string true "Código del país"
+// @Param request body DocumentTypeRequest true "Tipo de documento"
+// @Success 201 {object} entity.DocumentType
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/document-types [post]
+func (h *CountryHandler) CreateDocumentType(c *gin.Context) {
+	country, ok := h.adminCountry(c)
+	if !ok {
+		return
+	}
+
+	var req DocumentTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	dt := &entity.DocumentType{CountryID: country.ID, IsRequired: true}
+	req.apply(dt)
+
+	if err := h.usecase.CreateDocumentType(c.Request.Context(), dt, currentUserID(c)); err != nil {
+		h.configError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dt)
+}
+
+// UpdateDocumentType actualiza un tipo de documento de un país
+// @Summary Actualizar tipo de documento
+// @Description Reemplaza el tipo de documento y guarda una nueva versión de la configuración
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param code path string true "Código del país"
+// @Param id path string true "ID del tipo de documento"
+// @Param request body DocumentTypeRequest true "Tipo de documento"
+// @Success 200 {object} entity.DocumentType
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/document-types/{id} [put]
+func (h *CountryHandler) UpdateDocumentType(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid document type ID format",
+		})
+		return
+	}
+	country, ok := h.adminCountry(c)
+	if !ok {
+		return
+	}
+
+	var req DocumentTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	dt := &entity.DocumentType{ID: id, CountryID: country.ID, IsRequired: true}
+	req.apply(dt)
+
+	if err := h.usecase.UpdateDocumentType(c.Request.Context(), dt, currentUserID(c)); err != nil {
+		h.configError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dt)
+}
+
+// DeleteDocumentType elimina un tipo de documento de un país
+// @Summary Eliminar tipo de documento
+// @Description Elimina el tipo de documento y guarda una nueva versión de la configuración; las solicitudes existentes no cambian
+// @Tags admin
+// @Produce json
+// @Param code path string true "Código del país"
+// @Param id path string true "ID del tipo de documento"
+// @Success 200 {object} entity.CountryConfigVersion
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/document-types/{id} [delete]
+func (h *CountryHandler) DeleteDocumentType(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid document type ID format",
+		})
+		return
+	}
+	country, ok := h.adminCountry(c)
+	if !ok {
+		return
+	}
+
+	version, err := h.usecase.DeleteDocumentType(c.Request.Context(), country.ID, id, currentUserID(c))
+	if err != nil {
+		h.configError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, version)
+}
+
+// ConfigUpdateRequest nueva configuración del país, opcionalmente programada
+type ConfigUpdateRequest struct {
+	entity.CountryConfig
+	EffectiveFrom string `json:"effective_from"` // Fecha futura en la zona horaria del país (2027-01-01) o RFC3339
+}
+
+// UpdateConfig reemplaza la configuración de un país
+// @Summary Actualizar configuración de país
+// @Description Reemplaza la configuración (montos, umbrales, acción ante documento inválido) y guarda una nueva versión. Si no hay cambios no se crea versión. Con effective_from el cambio se programa: responde 202 con la versión y rige para las solicitudes desde esa fecha
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param code path string true "Código del país"
+// @Param request body ConfigUpdateRequest true "Configuración"
+// @Success 200 {object} entity.Country
+// @Success 202 {object} entity.CountryConfigVersion
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/config [put]
+func (h *CountryHandler) UpdateConfig(c *gin.Context) {
+	country, ok := h.adminCountry(c)
+	if !ok {
+		return
+	}
+
+	var req ConfigUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+	config := req.CountryConfig
+	config.InvalidDocumentAction = entity.RuleAction(strings.ToUpper(string(config.InvalidDocumentAction)))
+	if config.Velocity != nil {
+		velocity := *config.Velocity
+		velocity.Action = entity.RuleAction(strings.ToUpper(string(velocity.Action)))
+		config.Velocity = &velocity
+	}
+
+	version, err := h.usecase.UpdateConfig(c.Request.Context(), country, config, req.EffectiveFrom, currentUserID(c))
+	if err != nil {
+		h.configError(c, err)
+		return
+	}
+	if req.EffectiveFrom != "" {
+		c.JSON(http.StatusAccepted, version)
+		return
+	}
+
+	updated, err := h.usecase.GetCountryByID(c.Request.Context(), country.ID)
+	if err != nil {
+		h.configError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// ListVersions lista el historial de versiones de la configuración de un país
+// @Summary Historial de configuración de país
+// @Description Lista las versiones (quién, cuándo y diff), de la más reciente a la más antigua, sin la foto completa
+// @Tags admin
+// @Produce json
+// @Param code path string true "Código del país"
+// @Param limit query int false "Máximo de versiones (por defecto 50, máximo 500)"
+// @Success 200 {array} entity.CountryConfigVersion
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/versions [get]
+func (h *CountryHandler) ListVersions(c *gin.Context) {
+	country, ok := h.adminCountry(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	versions, err := h.usecase.ListConfigVersions(c.Request.Context(), country.ID, limit)
+	if err != nil {
+		h.configError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// GetVersion obtiene una versión de la configuración de un país
+// @Summary Obtener versión de configuración
+// @Description Devuelve la versión con su foto completa (config, reglas y tipos de documento) y el diff respecto a la anterior
+// @Tags admin
+// @Produce json
+// @Param code path string true "Código del país"
+// @Param version path int true "Versión"
+// @Success 200 {object} entity.CountryConfigVersion
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/versions/{version} [get]
+func (h *CountryHandler) GetVersion(c *gin.Context) {
+	version, ok := parseVersionParam(c)
+	if !ok {
+		return
+	}
+	country, ok := h.adminCountry(c)
+	if !ok {
+		return
+	}
+
+	v, err := h.usecase.GetConfigVersion(c.Request.Context(), country.ID, version)
+	if err != nil {
+		h.configError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v)
+}
+
+// RollbackVersion vuelve a la configuración de una versión anterior
+// @Summary Volver a una versión de configuración
+// @Description Restaura la configuración, reglas y tipos de documento de la versión indicada como una versión nueva (ROLLBACK)
+// @Tags admin
+// @Produce json
+// @Param code path string true "Código del país"
+// @Param version path int true "Versión a restaurar"
+// @Success 200 {object} entity.CountryConfigVersion
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/versions/{version}/rollback [post]
+func (h *CountryHandler) RollbackVersion(c *gin.Context) {
+	version, ok := parseVersionParam(c)
+	if !ok {
+		return
+	}
+	country, ok := h.adminCountry(c)
+	if !ok {
+		return
+	}
+
+	v, err := h.usecase.RollbackConfig(c.Request.Context(), country.ID, version, currentUserID(c))
+	if err != nil {
+		h.configError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v)
+}
+
+// CancelScheduledVersion cancela un cambio de configuración programado
+// @Summary Cancelar cambio programado
+// @Description Cancela una versión CONFIG_SCHEDULED que aún no entró en vigor; queda en el historial como cancelada
+// @Tags admin
+// @Produce json
+// @Param code path string true "Código del país"
+// @Param version path int true "Versión programada"
+// @Success 200 {object} entity.CountryConfigVersion
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/versions/{version}/cancel [post]
+func (h *CountryHandler) CancelScheduledVersion(c *gin.Context) {
+	version, ok := parseVersionParam(c)
+	if !ok {
+		return
+	}
+	country, ok := h.adminCountry(c)
+	if !ok {
+		return
+	}
+
+	v, err := h.usecase.CancelScheduledConfig(c.Request.Context(), country.ID, version, currentUserID(c))
+	if err != nil {
+		h.configError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v)
+}
+
+// apply copia los campos de la petición al tipo de documento
+func (r *DocumentTypeRequest) apply(dt *entity.DocumentType) {
+	dt.Code = strings.ToUpper(strings.TrimSpace(r.Code))
+	dt.Name = r.Name
+	dt.ValidationRegex = r.ValidationRegex
+	if r.IsRequired != nil {
+		dt.IsRequired = *r.IsRequired
+	}
+}
+
+// adminCountry obtiene el país de la ruta o responde 404
+func (h *CountryHandler) adminCountry(c *gin.Context) (*entity.Country, bool) {
+	country, err := h.usecase.GetCountryByCode(c.Request.Context(), strings.ToUpper(c.Param("code")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Country not found",
+		})
+		return nil, false
+	}
+	return country, true
+}
+
+// parseVersionParam lee el número de versión de la ruta o responde 400
+func parseVersionParam(c *gin.Context) (int, bool) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_version",
+			Message: "Version must be a positive integer",
+		})
+		return 0, false
+	}
+	return version, true
+}
+
+// currentUserID usuario autenticado, si lo hay
+func currentUserID(c *gin.Context) *uuid.UUID {
+	if userID, ok := c.Get("user_id"); ok {
+		if uid, ok := userID.(uuid.UUID); ok {
+			return &uid
+		}
+	}
+	return nil
+}
+
+// configError responde al error de un cambio de configuración del país
+func (h *CountryHandler) configError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidRule):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_rule",
+			Message: err.Error(),
+		})
+	case errors.Is(err, usecase.ErrInvalidDocumentType):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_document_type",
+			Message: err.Error(),
+		})
+	case errors.Is(err, usecase.ErrInvalidCountryConfig):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_config",
+			Message: err.Error(),
+		})
+	case errors.Is(err, usecase.ErrCountryNotFound),
+		errors.Is(err, usecase.ErrRuleNotFound),
+		errors.Is(err, usecase.ErrDocumentTypeNotFound),
+		errors.Is(err, usecase.ErrConfigVersionNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: err.Error(),
+		})
+	case errors.Is(err, usecase.ErrConfigConflict):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "config_conflict",
+			Message: "The country configuration was modified by another change, reload and retry",
+		})
+	case errors.Is(err, usecase.ErrNoConfigChange):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "no_changes",
+			Message: "The change matches the configuration already in effect",
+		})
+	default:
+		h.log.Error().Err(err).Msg("Failed to save country configuration")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "save_failed",
+			Message: "Failed to save country configuration",
+		})
+	}
+}
@@ -2,8 +2,10 @@ package handler
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/fintech-multipass/backend/internal/application/usecase"
+	"github.com/fintech-multipass/backend/internal/domain/entity"
 	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -139,6 +141,133 @@ func (h *CountryHandler) GetDocumentTypes(c *gin.Context) {
 	c.JSON(http.StatusOK, docTypes)
 }
 
-// Placeholder para evitar error de importación no usada
-var _ = uuid.New
+// CountryRuleRequest alta o modificación de una regla de país
+type CountryRuleRequest struct {
+	RuleType    string                 `json:"rule_type" binding:"required"`
+	Name        string                 `json:"name" binding:"required"`
+	Description string                 `json:"description"`
+	IsActive    *bool                  `json:"is_active"`
+	Priority    int                    `json:"priority"`
+	Config      map[string]interface{} `json:"config"`
+}
+
+// CreateRule crea una regla de un país
+// @Summary Crear regla de país
+// @Description Crea una regla y guarda una nueva versión de la configuración; las reglas CUSTOM se compilan y se rechazan si la expresión no es válida
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param code path string true "Código del país"
+// @Param request body CountryRuleRequest true "Regla"
+// @Success 201 {object} entity.CountryRule
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/rules [post]
+func (h *CountryHandler) CreateRule(c *gin.Context) {
+	country, err := h.usecase.GetCountryByCode(c.Request.Context(), strings.ToUpper(c.Param("code")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Country not found",
+		})
+		return
+	}
+
+	var req CountryRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	rule := &entity.CountryRule{CountryID: country.ID, IsActive: true}
+	req.apply(rule)
+
+	if err := h.usecase.CreateRule(c.Request.Context(), rule, currentUserID(c)); err != nil {
+		h.configError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateRule actualiza una regla de un país
+// @Summary Actualizar regla de país
+// @Description Reemplaza la regla y guarda una nueva versión de la configuración; las reglas CUSTOM se compilan y se rechazan si la expresión no es válida
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param code path string true "Código del país"
+// @Param id path string true "ID de la regla"
+// @Param request body CountryRuleRequest true "Regla"
+// @Success 200 {object} entity.CountryRule
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/rules/{id} [put]
+func (h *CountryHandler) UpdateRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid rule ID format",
+		})
+		return
+	}
+
+	country, err := h.usecase.GetCountryByCode(c.Request.Context(), strings.ToUpper(c.Param("code")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Country not found",
+		})
+		return
+	}
+
+	rule, err := h.usecase.GetRule(c.Request.Context(), id)
+	if err != nil || rule.CountryID != country.ID {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Rule not found",
+		})
+		return
+	}
+
+	var req CountryRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+	req.apply(rule)
+
+	if err := h.usecase.UpdateRule(c.Request.Context(), rule, currentUserID(c)); err != nil {
+		h.configError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// apply copia los campos de la petición a la regla
+func (r *CountryRuleRequest) apply(rule *entity.CountryRule) {
+	rule.RuleType = entity.RuleType(strings.ToUpper(r.RuleType))
+	rule.Name = r.Name
+	rule.Description = r.Description
+	rule.Priority = r.Priority
+	rule.Config = r.Config
+	if rule.Config == nil {
+		rule.Config = map[string]interface{}{}
+	}
+	if r.IsActive != nil {
+		rule.IsActive = *r.IsActive
+	}
+}
 
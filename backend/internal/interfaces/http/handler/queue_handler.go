@@ -0,0 +1,314 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+	"github.com/fintech-multipass/backend/internal/infrastructure/queue"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// QueueHandler handler para inspeccionar y operar trabajos de la cola (dead-letter)
+type QueueHandler struct {
+	queue *queue.PostgresQueue
+	log   *logger.Logger
+}
+
+// NewQueueHandler crea una nueva instancia del handler
+func NewQueueHandler(jobQueue *queue.PostgresQueue, log *logger.Logger) *QueueHandler {
+	return &QueueHandler{
+		queue: jobQueue,
+		log:   log,
+	}
+}
+
+// BulkRequeueRequest filtro de los trabajos a reencolar en bloque
+type BulkRequeueRequest struct {
+	Status string     `json:"status"` // FAILED (por defecto) o CANCELLED
+	Type   string     `json:"type"`
+	Error  string     `json:"error"`
+	From   *time.Time `json:"from"`
+	To     *time.Time `json:"to"`
+	Limit  int        `json:"limit"` // Por defecto 50, máximo 1000
+}
+
+// EditJobPayloadRequest payload corregido de un trabajo
+type EditJobPayloadRequest struct {
+	Payload json.RawMessage `json:"payload" binding:"required"`
+	Requeue bool            `json:"requeue"` // Reencolar tras editar
+}
+
+// CancelJobRequest motivo de la cancelación
+type CancelJobRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// ListDeadLetters lista los trabajos fallidos
+// @Summary Dead-letter de la cola
+// @Description Lista los trabajos que agotaron sus intentos (o los cancelados), los más recientes primero
+// @Tags admin
+// @Produce json
+// @Param status query string false "FAILED (por defecto) o CANCELLED"
+// @Param type query string false "Tipo de trabajo"
+// @Param error query string false "Texto contenido en el último error"
+// @Param from query string false "Fallidos desde (RFC3339)"
+// @Param to query string false "Fallidos antes de (RFC3339)"
+// @Param limit query int false "Máximo de trabajos (por defecto 50, máximo 500)"
+// @Param offset query int false "Desplazamiento"
+// @Success 200 {object} queue.DeadLetterPage
+// @Failure 400 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/queue/dead-letter [get]
+func (h *QueueHandler) ListDeadLetters(c *gin.Context) {
+	filter := queue.DeadLetterFilter{
+		Status: entity.JobStatus(strings.ToUpper(c.Query("status"))),
+		Type:   entity.JobType(strings.ToUpper(c.Query("type"))),
+		Error:  c.Query("error"),
+	}
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if v := c.Query(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{
+					Error:   "invalid_request",
+					Message: param + " must be an RFC3339 date",
+				})
+				return
+			}
+			*target = &t
+		}
+	}
+	filter.Limit, _ = strconv.Atoi(c.Query("limit"))
+	filter.Offset, _ = strconv.Atoi(c.Query("offset"))
+
+	page, err := h.queue.ListDeadLetters(c.Request.Context(), filter)
+	if err != nil {
+		h.queueError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetJob obtiene un trabajo con su payload e historial
+// @Summary Detalle de trabajo
+// @Description Devuelve el trabajo con su payload y el historial de eventos (errores de cada intento, reencolados, ediciones y cancelación)
+// @Tags admin
+// @Produce json
+// @Param id path string true "ID del trabajo"
+// @Success 200 {object} queue.JobDetail
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/queue/jobs/{id} [get]
+func (h *QueueHandler) GetJob(c *gin.Context) {
+	id, ok := parseJobID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.queue.GetJobDetail(c.Request.Context(), id)
+	if err != nil {
+		h.queueError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// RequeueJob reencola un trabajo
+// @Summary Reencolar trabajo
+// @Description Reencola un trabajo fallido, cancelado o en espera de reintento para ejecutarlo ya, reiniciando sus intentos
+// @Tags admin
+// @Produce json
+// @Param id path string true "ID del trabajo"
+// @Success 200 {object} queue.JobDetail
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/queue/jobs/{id}/requeue [post]
+func (h *QueueHandler) RequeueJob(c *gin.Context) {
+	id, ok := parseJobID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.queue.Requeue(c.Request.Context(), id, currentUserID(c)); err != nil {
+		h.queueError(c, err)
+		return
+	}
+
+	h.respondJob(c, id)
+}
+
+// BulkRequeue reencola en bloque los trabajos del dead-letter que cumplen el filtro
+// @Summary Reencolar dead-letter en bloque
+// @Description Reencola los trabajos fallidos (o cancelados) que cumplen el filtro, los más antiguos primero
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body BulkRequeueRequest true "Filtro"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/queue/dead-letter/requeue [post]
+func (h *QueueHandler) BulkRequeue(c *gin.Context) {
+	var req BulkRequeueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ids, err := h.queue.RequeueDeadLetters(c.Request.Context(), queue.DeadLetterFilter{
+		Status: entity.JobStatus(strings.ToUpper(req.Status)),
+		Type:   entity.JobType(strings.ToUpper(req.Type)),
+		Error:  req.Error,
+		From:   req.From,
+		To:     req.To,
+		Limit:  req.Limit,
+	}, currentUserID(c))
+	if err != nil {
+		h.queueError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"requeued": len(ids),
+		"job_ids":  ids,
+	})
+}
+
+// EditPayload corrige el payload de un trabajo antes de reejecutarlo
+// @Summary Editar payload de trabajo
+// @Description Reemplaza el payload de un trabajo fallido o cancelado (el anterior queda en el historial) y opcionalmente lo reencola
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del trabajo"
+// @Param request body EditJobPayloadRequest true "Nuevo payload"
+// @Success 200 {object} queue.JobDetail
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/queue/jobs/{id}/payload [put]
+func (h *QueueHandler) EditPayload(c *gin.Context) {
+	id, ok := parseJobID(c)
+	if !ok {
+		return
+	}
+
+	var req EditJobPayloadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.queue.EditPayload(c.Request.Context(), id, req.Payload, req.Requeue, currentUserID(c)); err != nil {
+		h.queueError(c, err)
+		return
+	}
+
+	h.respondJob(c, id)
+}
+
+// CancelJob cancela un trabajo
+// @Summary Cancelar trabajo
+// @Description Cancela un trabajo pendiente, en espera de reintento o fallido. Los trabajos en ejecución no se pueden cancelar
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del trabajo"
+// @Param request body CancelJobRequest false "Motivo"
+// @Success 200 {object} queue.JobDetail
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/queue/jobs/{id}/cancel [post]
+func (h *QueueHandler) CancelJob(c *gin.Context) {
+	id, ok := parseJobID(c)
+	if !ok {
+		return
+	}
+
+	var req CancelJobRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	if err := h.queue.Cancel(c.Request.Context(), id, strings.TrimSpace(req.Reason), currentUserID(c)); err != nil {
+		h.queueError(c, err)
+		return
+	}
+
+	h.respondJob(c, id)
+}
+
+// respondJob responde con el detalle actualizado del trabajo
+func (h *QueueHandler) respondJob(c *gin.Context, id uuid.UUID) {
+	job, err := h.queue.GetJobDetail(c.Request.Context(), id)
+	if err != nil {
+		h.queueError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// queueError traduce los errores de la cola a respuestas HTTP
+func (h *QueueHandler) queueError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, queue.ErrInvalidJobRequest):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+	case errors.Is(err, queue.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Job not found",
+		})
+	case errors.Is(err, queue.ErrJobStateConflict):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "invalid_job_state",
+			Message: err.Error(),
+		})
+	default:
+		h.log.Error().Err(err).Msg("Queue operation failed")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Queue operation failed",
+		})
+	}
+}
+
+// parseJobID lee el ID del trabajo de la ruta
+func parseJobID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid job ID format",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
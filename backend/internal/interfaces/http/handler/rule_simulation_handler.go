@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/application/usecase"
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// RuleSimulationHandler handler para simular cambios de reglas sobre solicitudes históricas
+type RuleSimulationHandler struct {
+	usecase *usecase.RuleSimulationUseCase
+	log     *logger.Logger
+}
+
+// NewRuleSimulationHandler crea una nueva instancia del handler
+func NewRuleSimulationHandler(uc *usecase.RuleSimulationUseCase, log *logger.Logger) *RuleSimulationHandler {
+	return &RuleSimulationHandler{
+		usecase: uc,
+		log:     log,
+	}
+}
+
+// RuleSimulationRequest configuración propuesta y rango de solicitudes a reproducir
+type RuleSimulationRequest struct {
+	From                time.Time                     `json:"from" binding:"required"`
+	To                  time.Time                     `json:"to" binding:"required"`
+	Limit               int                           `json:"limit"`                          // Por defecto 1000, máximo 5000
+	Rules               []CountryRuleRequest          `json:"rules" binding:"omitempty,dive"` // Omitido = reglas actuales
+	ScoringModelVersion *int                          `json:"scoring_model_version"`          // Versión guardada del modelo
+	ScoringDefinition   *entity.ScoringDefinition     `json:"scoring_definition"`             // Definición sin guardar
+	Config              *usecase.RuleSimulationConfig `json:"config"`                         // Cambios a la configuración del país
+}
+
+// Simulate reproduce reglas y scoring propuestos sobre solicitudes históricas
+// @Summary Simular reglas de país
+// @Description Evalúa las solicitudes decididas del rango con las reglas (y opcionalmente el scoring) propuestos y devuelve cuántas decisiones cambiarían. No modifica nada
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param code path string true "Código del país"
+// @Param request body RuleSimulationRequest true "Configuración propuesta"
+// @Success 200 {object} entity.RuleSimulationResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/rules/simulate [post]
+func (h *RuleSimulationHandler) Simulate(c *gin.Context) {
+	var req RuleSimulationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+	if req.ScoringModelVersion != nil && req.ScoringDefinition != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Use either scoring_model_version or scoring_definition, not both",
+		})
+		return
+	}
+
+	input := usecase.RuleSimulationInput{
+		CountryCode:         strings.ToUpper(c.Param("code")),
+		From:                req.From,
+		To:                  req.To,
+		Limit:               req.Limit,
+		ScoringModelVersion: req.ScoringModelVersion,
+		ScoringDefinition:   req.ScoringDefinition,
+		Config:              req.Config,
+	}
+	if req.Rules != nil {
+		input.Rules = make([]entity.CountryRule, 0, len(req.Rules))
+		for _, r := range req.Rules {
+			rule := entity.CountryRule{IsActive: true}
+			r.apply(&rule)
+			input.Rules = append(input.Rules, rule)
+		}
+	}
+
+	result, err := h.usecase.Simulate(c.Request.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrCountryNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Country not found",
+			})
+		case errors.Is(err, usecase.ErrInvalidRule):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_rule",
+				Message: err.Error(),
+			})
+		case errors.Is(err, usecase.ErrInvalidSimulation):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_simulation",
+				Message: err.Error(),
+			})
+		default:
+			h.log.Error().Err(err).Str("country", input.CountryCode).Msg("Failed to simulate country rules")
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "simulation_failed",
+				Message: "Failed to simulate country rules",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,429 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/domain/repository"
+	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+	"github.com/fintech-multipass/backend/internal/infrastructure/scoring"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ScoringHandler handler para administrar los modelos de scoring por país
+type ScoringHandler struct {
+	models    repository.ScoringModelRepository
+	countries repository.CountryRepository
+	log       *logger.Logger
+}
+
+// NewScoringHandler crea una nueva instancia del handler
+func NewScoringHandler(models repository.ScoringModelRepository, countries repository.CountryRepository, log *logger.Logger) *ScoringHandler {
+	return &ScoringHandler{
+		models:    models,
+		countries: countries,
+		log:       log,
+	}
+}
+
+// CreateScoringModelRequest nueva versión de un modelo de scoring
+type CreateScoringModelRequest struct {
+	Name       string                   `json:"name" binding:"required"`
+	Notes      string                   `json:"notes"`
+	Definition entity.ScoringDefinition `json:"definition" binding:"required"`
+	Activate   bool                     `json:"activate"`
+}
+
+// SetChallengerRequest configuración del challenger
+type SetChallengerRequest struct {
+	TrafficPercent int `json:"traffic_percent"` // 0 = solo sombra
+}
+
+// List lista las versiones del modelo de un país
+// @Summary Modelos de scoring de un país
+// @Description Devuelve todas las versiones del modelo de scoring, la más reciente primero
+// @Tags admin
+// @Produce json
+// @Param code path string true "Código del país"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/scoring-models [get]
+func (h *ScoringHandler) List(c *gin.Context) {
+	country, ok := h.country(c)
+	if !ok {
+		return
+	}
+
+	models, err := h.models.ListByCountry(c.Request.Context(), country.ID)
+	if err != nil {
+		h.log.Error().Err(err).Str("country", country.Code).Msg("Failed to list scoring models")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: "Failed to list scoring models",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"country": country.Code,
+		"models":  models,
+		"count":   len(models),
+	})
+}
+
+// Create crea una nueva versión del modelo de un país
+// @Summary Crear versión del modelo de scoring
+// @Description Valida la definición y la guarda como nueva versión; opcionalmente la activa
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param code path string true "Código del país"
+// @Param request body CreateScoringModelRequest true "Definición del modelo"
+// @Success 201 {object} entity.ScoringModel
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/scoring-models [post]
+func (h *ScoringHandler) Create(c *gin.Context) {
+	country, ok := h.country(c)
+	if !ok {
+		return
+	}
+
+	var req CreateScoringModelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if _, err := scoring.Compile("", 0, req.Name, req.Definition); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_model",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	model := &entity.ScoringModel{
+		CountryID:  country.ID,
+		Name:       req.Name,
+		Definition: req.Definition,
+		Notes:      req.Notes,
+	}
+	if userID, ok := c.Get("user_id"); ok {
+		if uid, ok := userID.(uuid.UUID); ok {
+			model.CreatedBy = &uid
+		}
+	}
+
+	if err := h.models.Create(c.Request.Context(), model); err != nil {
+		h.log.Error().Err(err).Str("country", country.Code).Msg("Failed to create scoring model")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "create_failed",
+			Message: "Failed to create scoring model",
+		})
+		return
+	}
+
+	if req.Activate {
+		if err := h.models.Activate(c.Request.Context(), country.ID, model.Version); err != nil {
+			h.log.Error().Err(err).Str("country", country.Code).Int("version", model.Version).Msg("Failed to activate scoring model")
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "activate_failed",
+				Message: "Model created but could not be activated",
+			})
+			return
+		}
+		model.IsActive = true
+	}
+
+	h.log.Info().
+		Str("country", country.Code).
+		Int("version", model.Version).
+		Bool("active", model.IsActive).
+		Msg("Scoring model version created")
+
+	c.JSON(http.StatusCreated, model)
+}
+
+// Activate activa una versión del modelo de un país
+// @Summary Activar versión del modelo de scoring
+// @Description Activa la versión indicada; las nuevas evaluaciones del país la usarán
+// @Tags admin
+// @Produce json
+// @Param code path string true "Código del país"
+// @Param version path int true "Versión del modelo"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/scoring-models/{version}/activate [post]
+func (h *ScoringHandler) Activate(c *gin.Context) {
+	country, ok := h.country(c)
+	if !ok {
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_version",
+			Message: "Version must be a positive integer",
+		})
+		return
+	}
+
+	model, err := h.models.GetByVersion(c.Request.Context(), country.ID, version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Scoring model version not found",
+		})
+		return
+	}
+
+	// Una definición guardada antes de un cambio de esquema podría ya no ser válida
+	if _, err := scoring.CompileModel(model); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_model",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.models.Activate(c.Request.Context(), country.ID, version); err != nil {
+		h.log.Error().Err(err).Str("country", country.Code).Int("version", version).Msg("Failed to activate scoring model")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "activate_failed",
+			Message: "Failed to activate scoring model",
+		})
+		return
+	}
+
+	h.log.Info().Str("country", country.Code).Int("version", version).Msg("Scoring model activated")
+
+	c.JSON(http.StatusOK, gin.H{
+		"country": country.Code,
+		"version": version,
+		"message": "Scoring model activated",
+	})
+}
+
+// SetChallenger marca una versión como challenger del país
+// @Summary Definir challenger
+// @Description Evalúa la versión en sombra en cada RISK_EVALUATION; con traffic_percent > 0 decide sobre ese porcentaje de solicitudes
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param code path string true "Código del país"
+// @Param version path int true "Versión del modelo"
+// @Param request body SetChallengerRequest false "Porcentaje de tráfico"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/scoring-models/{version}/challenger [post]
+func (h *ScoringHandler) SetChallenger(c *gin.Context) {
+	country, ok := h.country(c)
+	if !ok {
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_version",
+			Message: "Version must be a positive integer",
+		})
+		return
+	}
+
+	var req SetChallengerRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+	if req.TrafficPercent < 0 || req.TrafficPercent > 100 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_traffic_percent",
+			Message: "traffic_percent must be between 0 and 100",
+		})
+		return
+	}
+
+	model, err := h.models.GetByVersion(c.Request.Context(), country.ID, version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Scoring model version not found",
+		})
+		return
+	}
+	if model.IsActive {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "model_active",
+			Message: "The active model cannot be its own challenger",
+		})
+		return
+	}
+	if _, err := scoring.CompileModel(model); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_model",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.models.SetChallenger(c.Request.Context(), country.ID, version, req.TrafficPercent); err != nil {
+		h.log.Error().Err(err).Str("country", country.Code).Int("version", version).Msg("Failed to set challenger scoring model")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "update_failed",
+			Message: "Failed to set challenger scoring model",
+		})
+		return
+	}
+
+	h.log.Info().
+		Str("country", country.Code).
+		Int("version", version).
+		Int("traffic_percent", req.TrafficPercent).
+		Msg("Challenger scoring model set")
+
+	c.JSON(http.StatusOK, gin.H{
+		"country":         country.Code,
+		"version":         version,
+		"traffic_percent": req.TrafficPercent,
+		"message":         "Challenger scoring model set",
+	})
+}
+
+// ClearChallenger retira el challenger del país
+// @Summary Retirar challenger
+// @Description Deja de evaluar el challenger; los resultados en sombra ya guardados se conservan
+// @Tags admin
+// @Produce json
+// @Param code path string true "Código del país"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/scoring-models/challenger [delete]
+func (h *ScoringHandler) ClearChallenger(c *gin.Context) {
+	country, ok := h.country(c)
+	if !ok {
+		return
+	}
+
+	if err := h.models.ClearChallenger(c.Request.Context(), country.ID); err != nil {
+		h.log.Error().Err(err).Str("country", country.Code).Msg("Failed to clear challenger scoring model")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "update_failed",
+			Message: "Failed to clear challenger scoring model",
+		})
+		return
+	}
+
+	h.log.Info().Str("country", country.Code).Msg("Challenger scoring model cleared")
+
+	c.JSON(http.StatusOK, gin.H{
+		"country": country.Code,
+		"message": "Challenger scoring model cleared",
+	})
+}
+
+// ChallengerStats compara champion y challenger del país
+// @Summary Estadísticas champion/challenger
+// @Description Tasa de aprobación, revisión y rechazo, distribución de scores y concordancia de decisiones
+// @Tags admin
+// @Produce json
+// @Param code path string true "Código del país"
+// @Param version query int false "Versión challenger (por defecto la actual)"
+// @Param since query string false "Desde (RFC3339)"
+// @Success 200 {object} entity.ChallengerStats
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/countries/{code}/scoring-models/challenger/stats [get]
+func (h *ScoringHandler) ChallengerStats(c *gin.Context) {
+	country, ok := h.country(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	var challenger *entity.ScoringModel
+	var err error
+	if v := c.Query("version"); v != "" {
+		version, convErr := strconv.Atoi(v)
+		if convErr != nil || version <= 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_version",
+				Message: "Version must be a positive integer",
+			})
+			return
+		}
+		challenger, err = h.models.GetByVersion(ctx, country.ID, version)
+	} else {
+		challenger, err = h.models.GetChallengerByCountry(ctx, country.ID)
+	}
+	if err != nil || challenger == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Challenger scoring model not found",
+		})
+		return
+	}
+
+	var since *time.Time
+	if v := c.Query("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_since",
+				Message: "since must be an RFC3339 timestamp",
+			})
+			return
+		}
+		since = &t
+	}
+
+	stats, err := h.models.GetChallengerStats(ctx, country.ID, challenger.ID, since)
+	if err != nil {
+		h.log.Error().Err(err).Str("country", country.Code).Msg("Failed to get challenger stats")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: "Failed to get challenger stats",
+		})
+		return
+	}
+	stats.ChallengerVersion = challenger.Version
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// country resuelve el país del path y responde 404 si no existe
+func (h *ScoringHandler) country(c *gin.Context) (*entity.Country, bool) {
+	code := strings.ToUpper(c.Param("code"))
+	country, err := h.countries.GetByCode(c.Request.Context(), code)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Country not found",
+		})
+		return nil, false
+	}
+	return country, true
+}
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fintech-multipass/backend/internal/application/usecase"
+	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxWatchlistFileSize tamaño máximo del archivo de lista (las listas consolidadas rondan los 2-10 MB)
+const maxWatchlistFileSize = 64 << 20
+
+// WatchlistHandler handler para listas de sanciones/PEP y resolución de hits
+type WatchlistHandler struct {
+	usecase *usecase.WatchlistUseCase
+	log     *logger.Logger
+}
+
+// NewWatchlistHandler crea una nueva instancia del handler
+func NewWatchlistHandler(uc *usecase.WatchlistUseCase, log *logger.Logger) *WatchlistHandler {
+	return &WatchlistHandler{
+		usecase: uc,
+		log:     log,
+	}
+}
+
+// ResolveWatchlistHitRequest resolución de un hit por un analista
+type ResolveWatchlistHitRequest struct {
+	Status string `json:"status" binding:"required,oneof=CONFIRMED DISMISSED"`
+	Note   string `json:"note" binding:"max=1000"`
+}
+
+// Import importa un archivo de lista
+// @Summary Importar lista de sanciones/PEP
+// @Description Carga un archivo CSV (cabecera con name/full_name y opcionalmente external_id, aliases, document_numbers, country, program) o XML de lista consolidada. Reemplaza la carga anterior de la misma fuente; los hits existentes se conservan
+// @Tags admin
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Archivo de la lista"
+// @Param source formData string true "Fuente (UN, OFAC_SDN, PEP_MX...)"
+// @Param list_type formData string true "SANCTIONS o PEP"
+// @Param format formData string false "CSV o XML (por defecto según la extensión)"
+// @Success 201 {object} entity.WatchlistImport
+// @Failure 400 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/watchlists/import [post]
+func (h *WatchlistHandler) Import(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxWatchlistFileSize)
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "A list file is required in the 'file' field",
+		})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	imp, err := h.usecase.Import(c.Request.Context(), usecase.WatchlistImportInput{
+		Source:     c.PostForm("source"),
+		ListType:   c.PostForm("list_type"),
+		Format:     c.PostForm("format"),
+		FileName:   header.Filename,
+		File:       file,
+		ImportedBy: currentUserID(c),
+	})
+	if err != nil {
+		h.watchlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, imp)
+}
+
+// ListImports lista las importaciones de listas
+// @Summary Listar importaciones de listas
+// @Description Lista las cargas de listas, de la más reciente a la más antigua
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Máximo de importaciones (por defecto 50, máximo 500)"
+// @Success 200 {array} entity.WatchlistImport
+// @Security BearerAuth
+// @Router /admin/watchlists/imports [get]
+func (h *WatchlistHandler) ListImports(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	imports, err := h.usecase.ListImports(c.Request.Context(), limit)
+	if err != nil {
+		h.watchlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, imports)
+}
+
+// ListHits bandeja de hits de screening
+// @Summary Listar hits de listas
+// @Description Lista los hits de screening con los datos del solicitante. Por defecto solo los pendientes; status=ALL devuelve todos
+// @Tags admin
+// @Produce json
+// @Param status query string false "PENDING (por defecto), CONFIRMED, DISMISSED o ALL"
+// @Param application_id query string false "Filtrar por solicitud"
+// @Param limit query int false "Máximo de hits (por defecto 50, máximo 500)"
+// @Success 200 {array} entity.WatchlistHit
+// @Failure 400 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/watchlist-hits [get]
+func (h *WatchlistHandler) ListHits(c *gin.Context) {
+	filter := entity.WatchlistHitFilter{Status: entity.WatchlistHitPending}
+	switch status := strings.ToUpper(c.Query("status")); status {
+	case "":
+	case "ALL":
+		filter.Status = ""
+	case string(entity.WatchlistHitPending), string(entity.WatchlistHitConfirmed), string(entity.WatchlistHitDismissed):
+		filter.Status = entity.WatchlistHitStatus(status)
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "status must be PENDING, CONFIRMED, DISMISSED or ALL",
+		})
+		return
+	}
+	if appID := c.Query("application_id"); appID != "" {
+		id, err := uuid.Parse(appID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_id",
+				Message: "Invalid application ID format",
+			})
+			return
+		}
+		filter.ApplicationID = &id
+	}
+	filter.Limit, _ = strconv.Atoi(c.Query("limit"))
+
+	hits, err := h.usecase.ListHits(c.Request.Context(), filter)
+	if err != nil {
+		h.watchlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hits)
+}
+
+// ResolveHit confirma o descarta un hit
+// @Summary Resolver hit de lista
+// @Description CONFIRMED marca la coincidencia como verdadera y rechaza la solicitud; DISMISSED la descarta como falso positivo. Con todos los hits descartados la solicitud puede aprobarse
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del hit"
+// @Param request body ResolveWatchlistHitRequest true "Resolución"
+// @Success 200 {object} entity.WatchlistHit
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/watchlist-hits/{id}/resolve [post]
+func (h *WatchlistHandler) ResolveHit(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid hit ID format",
+		})
+		return
+	}
+
+	var req ResolveWatchlistHitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	hit, err := h.usecase.ResolveHit(c.Request.Context(), id, entity.WatchlistHitStatus(req.Status), req.Note, currentUserID(c))
+	if err != nil {
+		h.watchlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hit)
+}
+
+// watchlistError traduce los errores del caso de uso a respuestas HTTP
+func (h *WatchlistHandler) watchlistError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidWatchlistImport), errors.Is(err, usecase.ErrInvalidHitResolution):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+	case errors.Is(err, usecase.ErrWatchlistHitNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Watchlist hit not found",
+		})
+	case errors.Is(err, usecase.ErrWatchlistHitResolved):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "already_resolved",
+			Message: err.Error(),
+		})
+	default:
+		h.log.Error().Err(err).Msg("Watchlist operation failed")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Watchlist operation failed",
+		})
+	}
+}
@@ -6,31 +6,39 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"time"
 
 	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/domain/service"
 	"github.com/fintech-multipass/backend/internal/infrastructure/config"
 	"github.com/fintech-multipass/backend/internal/infrastructure/database"
 	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 // WebhookHandler handler para webhooks entrantes y salientes
 type WebhookHandler struct {
-	db     *database.PostgresDB
-	log    *logger.Logger
-	config config.WebhookConfig
+	db       *database.PostgresDB
+	banking  service.BankingService
+	jobQueue service.JobQueue
+	log      *logger.Logger
+	config   config.WebhookConfig
 }
 
 // NewWebhookHandler crea una nueva instancia del handler
-func NewWebhookHandler(db *database.PostgresDB, log *logger.Logger, cfg config.WebhookConfig) *WebhookHandler {
+func NewWebhookHandler(db *database.PostgresDB, banking service.BankingService, jobQueue service.JobQueue, log *logger.Logger, cfg config.WebhookConfig) *WebhookHandler {
 	return &WebhookHandler{
-		db:     db,
-		log:    log,
-		config: cfg,
+		db:       db,
+		banking:  banking,
+		jobQueue: jobQueue,
+		log:      log,
+		config:   cfg,
 	}
 }
 
@@ -230,17 +238,69 @@ func (h *WebhookHandler) processPaymentGatewayEvent(ctx context.Context, event *
 }
 
 // handleCreditReportReady maneja el evento de reporte crediticio listo
+// El payload trae el reporte del proveedor asíncrono en "report" (objeto JSON o
+// XML en texto) y opcionalmente su "reference_id". El reporte se normaliza con
+// el mapeo del proveedor, se guarda y se encola la evaluación de riesgo
 func (h *WebhookHandler) handleCreditReportReady(ctx context.Context, applicationID uuid.UUID, payload map[string]interface{}) error {
 	h.log.Info().
 		Str("application_id", applicationID.String()).
 		Msg("Handling credit report ready event")
 
+	reference, _ := payload["reference_id"].(string)
+	report, ok := payload["report"]
+	if !ok {
+		return fmt.Errorf("credit_report_ready event without report")
+	}
+
+	// CompleteAsyncReport reclama la consulta y guarda banking_info en una transacción.
+	// Una entrega repetida (concurrente, o reintento tras fallar algún paso de aquí
+	// abajo) también llega hasta aquí: el cambio de estado es condicional y el
+	// trabajo de fraude tiene un ID derivado de la consulta, así que se encola una vez
+	response, provider, err := h.banking.CompleteAsyncReport(ctx, applicationID, reference, report)
+	if err != nil {
+		return fmt.Errorf("failed to process credit report: %w", err)
+	}
+
+	// El webhook puede llegar antes de que el worker deje la solicitud en PENDING_BANK_INFO
+	var countryID uuid.UUID
 	query := `
 		UPDATE credit_applications 
 		SET status = 'VALIDATING', updated_at = NOW()
-		WHERE id = $1 AND status = 'PENDING_BANK_INFO'
+		WHERE id = $1 AND status IN ('PENDING', 'VALIDATING', 'PENDING_BANK_INFO')
+		RETURNING country_id
 	`
-	return h.db.Exec(ctx, query, applicationID)
+	if err := h.db.QueryRow(ctx, query, applicationID).Scan(&countryID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			h.log.Warn().
+				Str("application_id", applicationID.String()).
+				Msg("Credit report saved but application is no longer waiting for it")
+			return nil
+		}
+		return fmt.Errorf("failed to update application status: %w", err)
+	}
+
+	riskPayload, _ := json.Marshal(entity.RiskEvaluationPayload{
+		ApplicationID: applicationID,
+		CountryID:     countryID,
+	})
+	// La detección de fraude encola después la evaluación de riesgo
+	fraudJob := &entity.Job{
+		ID:       uuid.NewSHA1(applicationID, []byte(string(entity.JobTypeFraudCheck)+":"+response.ReferenceID)),
+		Type:     entity.JobTypeFraudCheck,
+		Priority: 10,
+		Payload:  riskPayload,
+	}
+	if err := h.jobQueue.Enqueue(ctx, fraudJob); err != nil {
+		return fmt.Errorf("failed to enqueue fraud check: %w", err)
+	}
+
+	h.log.Info().
+		Str("application_id", applicationID.String()).
+		Str("provider", provider.Code).
+		Str("fraud_job_id", fraudJob.ID.String()).
+		Msg("Async credit report processed, fraud check enqueued")
+
+	return nil
 }
 
 // handleVerificationComplete maneja el evento de verificación completa
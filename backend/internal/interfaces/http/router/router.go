@@ -5,12 +5,15 @@ import (
 
 	"github.com/fintech-multipass/backend/internal/application/usecase"
 	"github.com/fintech-multipass/backend/internal/domain/entity"
+	"github.com/fintech-multipass/backend/internal/domain/service"
 	"github.com/fintech-multipass/backend/internal/infrastructure/cache"
 	"github.com/fintech-multipass/backend/internal/infrastructure/config"
 	"github.com/fintech-multipass/backend/internal/infrastructure/database"
+	"github.com/fintech-multipass/backend/internal/infrastructure/encryption"
 	"github.com/fintech-multipass/backend/internal/infrastructure/logger"
 	"github.com/fintech-multipass/backend/internal/infrastructure/persistence"
 	"github.com/fintech-multipass/backend/internal/infrastructure/queue"
+	"github.com/fintech-multipass/backend/internal/infrastructure/validation"
 	"github.com/fintech-multipass/backend/internal/interfaces/http/handler"
 	"github.com/fintech-multipass/backend/internal/interfaces/http/middleware"
 	"github.com/fintech-multipass/backend/internal/interfaces/websocket"
@@ -22,6 +25,8 @@ func NewRouter(
 	db *database.PostgresDB,
 	cacheService cache.CacheService,
 	jobQueue *queue.PostgresQueue,
+	bankingService service.BankingService,
+	cipher *encryption.Envelope,
 	cfg *config.Config,
 	log *logger.Logger,
 ) *gin.Engine {
@@ -43,6 +48,11 @@ func NewRouter(
 	countryRepo := persistence.NewCountryRepository(db)
 	appRepo := persistence.NewApplicationRepository(db)
 	userRepo := persistence.NewUserRepository(db)
+	providerRepo := persistence.NewBankingProviderRepository(db)
+	scoringModelRepo := persistence.NewScoringModelRepository(db)
+	watchlistRepo := persistence.NewWatchlistRepository(db)
+
+	ruleValidator := validation.NewRuleValidator(db, log)
 
 	// Inicializar casos de uso
 	authUseCase := usecase.NewAuthUseCase(userRepo, cfg.JWT, log)
@@ -50,19 +60,34 @@ func NewRouter(
 	appUseCase := usecase.NewApplicationUseCase(
 		appRepo,
 		countryRepo,
-		nil, // providerRepo - se puede agregar después
-		nil, // validator - se puede agregar después
+		providerRepo,
+		ruleValidator,
 		cacheService,
 		nil, // eventPub - se puede agregar después
-		nil, // jobQueue - se puede agregar después
+		jobQueue,
+		log,
+	)
+
+	ruleSimulationUseCase := usecase.NewRuleSimulationUseCase(
+		countryRepo,
+		appRepo,
+		scoringModelRepo,
+		ruleValidator,
 		log,
 	)
 
+	watchlistUseCase := usecase.NewWatchlistUseCase(watchlistRepo, appRepo, cacheService, log)
+
 	// Inicializar handlers
 	authHandler := handler.NewAuthHandler(authUseCase, log)
 	countryHandler := handler.NewCountryHandler(countryUseCase, log)
 	appHandler := handler.NewApplicationHandler(appUseCase, log)
-	webhookHandler := handler.NewWebhookHandler(db, log, cfg.Webhook)
+	webhookHandler := handler.NewWebhookHandler(db, bankingService, jobQueue, log, cfg.Webhook)
+	bankingHandler := handler.NewBankingHandler(providerRepo, cipher, db, log)
+	scoringHandler := handler.NewScoringHandler(scoringModelRepo, countryRepo, log)
+	ruleSimulationHandler := handler.NewRuleSimulationHandler(ruleSimulationUseCase, log)
+	watchlistHandler := handler.NewWatchlistHandler(watchlistUseCase, log)
+	queueHandler := handler.NewQueueHandler(jobQueue, log)
 
 	// Inicializar middleware de autenticación
 	authMiddleware := middleware.NewAuthMiddleware(authUseCase)
@@ -145,9 +170,13 @@ func NewRouter(
 
 		// Obtener historial de una solicitud
 		applications.GET("/:id/history", authMiddleware.RequirePermission("read"), appHandler.GetHistory)
+		applications.GET("/:id/decision", authMiddleware.RequirePermission("read"), appHandler.GetDecision)
 
 		// Actualizar estado (requiere permiso 'update')
 		applications.PATCH("/:id/status", authMiddleware.RequirePermission("update"), appHandler.UpdateStatus)
+
+		// Forzar nueva consulta bancaria (requiere permiso 'update')
+		applications.POST("/:id/banking-info/refresh", authMiddleware.RequirePermission("update"), appHandler.RefreshBankingInfo)
 	}
 
 	// Admin routes (solo admins y analysts)
@@ -163,6 +192,43 @@ func NewRouter(
 		// Country specific stats
 		admin.GET("/stats/country/:code", statsHandler.GetCountryStats)
 
+		// Llamadas a proveedores bancarios con datos descifrados (solo admin)
+		admin.GET("/applications/:id/banking-requests", authMiddleware.RequirePermission("admin"), bankingHandler.GetApplicationRequests)
+
+		// Reglas de validación por país (cambios solo admin); las CUSTOM se compilan al guardar
+		// Cada cambio de reglas, tipos de documento o configuración guarda una versión
+		admin.GET("/countries/:code/rules", countryHandler.ListAllRules)
+		admin.POST("/countries/:code/rules", authMiddleware.RequirePermission("admin"), countryHandler.CreateRule)
+		admin.PUT("/countries/:code/rules/:id", authMiddleware.RequirePermission("admin"), countryHandler.UpdateRule)
+		admin.DELETE("/countries/:code/rules/:id", authMiddleware.RequirePermission("admin"), countryHandler.DeleteRule)
+		admin.POST("/countries/:code/rules/simulate", authMiddleware.RequirePermission("admin"), ruleSimulationHandler.Simulate)
+
+		// Tipos de documento y configuración del país (solo admin)
+		admin.POST("/countries/:code/document-types", authMiddleware.RequirePermission("admin"), countryHandler.CreateDocumentType)
+		admin.PUT("/countries/:code/document-types/:id", authMiddleware.RequirePermission("admin"), countryHandler.UpdateDocumentType)
+		admin.DELETE("/countries/:code/document-types/:id", authMiddleware.RequirePermission("admin"), countryHandler.DeleteDocumentType)
+		admin.PUT("/countries/:code/config", authMiddleware.RequirePermission("admin"), countryHandler.UpdateConfig)
+
+		// Historial de versiones de configuración, rollback y cancelación de cambios programados (solo admin)
+		admin.GET("/countries/:code/versions", countryHandler.ListVersions)
+		admin.GET("/countries/:code/versions/:version", countryHandler.GetVersion)
+		admin.POST("/countries/:code/versions/:version/rollback", authMiddleware.RequirePermission("admin"), countryHandler.RollbackVersion)
+		admin.POST("/countries/:code/versions/:version/cancel", authMiddleware.RequirePermission("admin"), countryHandler.CancelScheduledVersion)
+
+		// Modelos de scoring versionados por país (crear, activar y challenger solo admin)
+		admin.GET("/countries/:code/scoring-models", scoringHandler.List)
+		admin.POST("/countries/:code/scoring-models", authMiddleware.RequirePermission("admin"), scoringHandler.Create)
+		admin.POST("/countries/:code/scoring-models/:version/activate", authMiddleware.RequirePermission("admin"), scoringHandler.Activate)
+		admin.POST("/countries/:code/scoring-models/:version/challenger", authMiddleware.RequirePermission("admin"), scoringHandler.SetChallenger)
+		admin.DELETE("/countries/:code/scoring-models/challenger", authMiddleware.RequirePermission("admin"), scoringHandler.ClearChallenger)
+		admin.GET("/countries/:code/scoring-models/challenger/stats", scoringHandler.ChallengerStats)
+
+		// Listas de sanciones/PEP (importación solo admin) y bandeja de hits para analistas
+		admin.POST("/watchlists/import", authMiddleware.RequirePermission("admin"), watchlistHandler.Import)
+		admin.GET("/watchlists/imports", watchlistHandler.ListImports)
+		admin.GET("/watchlist-hits", watchlistHandler.ListHits)
+		admin.POST("/watchlist-hits/:id/resolve", authMiddleware.RequirePermission("reject"), watchlistHandler.ResolveHit)
+
 		// Queue stats
 		admin.GET("/queue/stats", func(c *gin.Context) {
 			stats, err := jobQueue.Stats(c.Request.Context())
@@ -198,6 +264,14 @@ func NewRouter(
 				"count": len(jobs),
 			})
 		})
+
+		// Dead-letter: inspección, reencolado, edición de payload y cancelación (cambios solo admin)
+		admin.GET("/queue/dead-letter", queueHandler.ListDeadLetters)
+		admin.POST("/queue/dead-letter/requeue", authMiddleware.RequirePermission("admin"), queueHandler.BulkRequeue)
+		admin.GET("/queue/jobs/:id", queueHandler.GetJob)
+		admin.POST("/queue/jobs/:id/requeue", authMiddleware.RequirePermission("admin"), queueHandler.RequeueJob)
+		admin.PUT("/queue/jobs/:id/payload", authMiddleware.RequirePermission("admin"), queueHandler.EditPayload)
+		admin.POST("/queue/jobs/:id/cancel", authMiddleware.RequirePermission("admin"), queueHandler.CancelJob)
 	}
 
 	// ==========================================
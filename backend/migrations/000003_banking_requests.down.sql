@@ -0,0 +1,3 @@
+-- Migración 003 DOWN: Eliminar registro de llamadas a proveedores
+
+DROP TABLE IF EXISTS banking_requests CASCADE;
@@ -0,0 +1,23 @@
+-- Migración 003: Registro de llamadas a proveedores bancarios
+-- Permite el failover entre proveedores y el circuit breaker por proveedor
+
+-- =====================================================
+-- TABLA: banking_requests
+-- Cada llamada realizada a un proveedor bancario
+-- =====================================================
+CREATE TABLE IF NOT EXISTS banking_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    application_id UUID REFERENCES credit_applications(id) ON DELETE CASCADE,
+    provider_id UUID NOT NULL REFERENCES banking_providers(id) ON DELETE CASCADE,
+    request_type VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING', -- PENDING, SUCCESS, FAILED
+    request_data BYTEA,
+    response_data BYTEA,
+    error_message TEXT,
+    duration_ms INT DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_banking_requests_provider_created ON banking_requests(provider_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_banking_requests_application ON banking_requests(application_id);
@@ -0,0 +1,3 @@
+-- Migración 004 DOWN: Eliminar rate limiting de proveedores
+
+DROP TABLE IF EXISTS provider_rate_limits CASCADE;
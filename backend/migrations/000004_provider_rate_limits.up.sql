@@ -0,0 +1,12 @@
+-- Migración 004: Rate limiting de proveedores bancarios
+-- Token bucket compartido entre procesos API y workers
+
+-- =====================================================
+-- TABLA: provider_rate_limits
+-- Estado del token bucket por proveedor
+-- =====================================================
+CREATE TABLE IF NOT EXISTS provider_rate_limits (
+    provider_id UUID PRIMARY KEY REFERENCES banking_providers(id) ON DELETE CASCADE,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
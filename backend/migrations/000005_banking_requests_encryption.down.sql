@@ -0,0 +1,4 @@
+-- Migración 005 DOWN: Eliminar metadatos de cifrado
+
+DROP INDEX IF EXISTS idx_banking_requests_key;
+ALTER TABLE banking_requests DROP COLUMN IF EXISTS encryption_key_id;
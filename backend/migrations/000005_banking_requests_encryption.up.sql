@@ -0,0 +1,6 @@
+-- Migración 005: Cifrado de datos en banking_requests
+-- request_data y response_data se guardan cifrados por sobre (envelope encryption)
+
+ALTER TABLE banking_requests ADD COLUMN IF NOT EXISTS encryption_key_id VARCHAR(50);
+
+CREATE INDEX IF NOT EXISTS idx_banking_requests_key ON banking_requests(encryption_key_id);
@@ -0,0 +1,6 @@
+-- Migración 006 DOWN: Eliminar procedencia de información bancaria
+
+DROP INDEX IF EXISTS idx_banking_info_expires;
+ALTER TABLE banking_info DROP COLUMN IF EXISTS reused_from_application_id;
+ALTER TABLE banking_info DROP COLUMN IF EXISTS reused_from_id;
+ALTER TABLE banking_info DROP COLUMN IF EXISTS source;
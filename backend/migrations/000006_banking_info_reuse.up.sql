@@ -0,0 +1,8 @@
+-- Migración 006: Reutilización de información bancaria entre solicitudes
+-- Un mismo documento en el mismo país reutiliza un reporte aún vigente
+
+ALTER TABLE banking_info ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'PROVIDER'; -- PROVIDER, CACHE, WEBHOOK
+ALTER TABLE banking_info ADD COLUMN IF NOT EXISTS reused_from_id UUID REFERENCES banking_info(id) ON DELETE SET NULL;
+ALTER TABLE banking_info ADD COLUMN IF NOT EXISTS reused_from_application_id UUID REFERENCES credit_applications(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_banking_info_expires ON banking_info(expires_at);
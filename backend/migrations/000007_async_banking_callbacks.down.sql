@@ -0,0 +1,4 @@
+-- Migración 007 DOWN: Eliminar soporte de reportes asíncronos
+
+DROP INDEX IF EXISTS idx_banking_requests_pending_async;
+ALTER TABLE banking_requests DROP COLUMN IF EXISTS external_reference;
@@ -0,0 +1,8 @@
+-- Migración 007: Reportes bancarios asíncronos
+-- Los proveedores con config.async aceptan la consulta y envían el reporte después
+-- por el webhook credit_report_ready. La llamada queda PENDING hasta recibirlo
+
+ALTER TABLE banking_requests ADD COLUMN IF NOT EXISTS external_reference VARCHAR(255); -- Referencia asignada por el proveedor
+
+CREATE INDEX IF NOT EXISTS idx_banking_requests_pending_async ON banking_requests(application_id, created_at DESC)
+    WHERE request_type = 'BANKING_INFO_ASYNC' AND status = 'PENDING';
@@ -0,0 +1,5 @@
+-- Migración 008 DOWN: Eliminar modelos de scoring versionados
+
+ALTER TABLE credit_applications DROP COLUMN IF EXISTS scoring_model_version;
+ALTER TABLE credit_applications DROP COLUMN IF EXISTS scoring_model_id;
+DROP TABLE IF EXISTS scoring_models;
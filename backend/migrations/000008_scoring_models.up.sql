@@ -0,0 +1,64 @@
+-- Migración 008: Modelos de scoring versionados por país
+-- Sustituye los factores, tramos y umbrales (70/40) fijos del worker por una
+-- definición declarativa. Las versiones son inmutables; solo una activa por país
+
+CREATE TABLE IF NOT EXISTS scoring_models (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    country_id UUID NOT NULL REFERENCES countries(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    definition JSONB NOT NULL,          -- base_score, min/max_score, factors[], cutoffs
+    is_active BOOLEAN NOT NULL DEFAULT false,
+    notes TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    activated_at TIMESTAMPTZ,
+    UNIQUE (country_id, version)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_scoring_models_active ON scoring_models(country_id) WHERE is_active;
+
+-- Modelo con el que se evaluó cada solicitud
+ALTER TABLE credit_applications ADD COLUMN IF NOT EXISTS scoring_model_id UUID REFERENCES scoring_models(id) ON DELETE SET NULL;
+ALTER TABLE credit_applications ADD COLUMN IF NOT EXISTS scoring_model_version INTEGER;
+
+-- Versión 1 para cada país: equivalente al cálculo histórico de cinco factores
+-- (la penalización por no alcanzar min_credit_score queda incluida en el tramo < 0)
+INSERT INTO scoring_models (country_id, version, name, definition, is_active, notes, activated_at)
+SELECT c.id, 1, 'baseline', '{
+    "base_score": 50,
+    "min_score": 0,
+    "max_score": 100,
+    "cutoffs": {"approve": 70, "review": 40},
+    "factors": [
+        {"name": "requested_amount_ratio", "input": "requested_ratio_vs_max", "weight": 1, "buckets": [
+            {"max": 0.5, "points": 25},
+            {"min": 0.5, "max": 0.75, "points": 15},
+            {"min": 0.75, "max": 1, "points": 5},
+            {"min": 1.25, "points": -15}
+        ]},
+        {"name": "credit_score", "input": "credit_score_margin", "weight": 1, "buckets": [
+            {"min": 150, "points": 35},
+            {"min": 50, "max": 150, "points": 20},
+            {"min": 0, "max": 50, "points": 5},
+            {"max": 0, "points": -40}
+        ]},
+        {"name": "payment_history", "input": "payment_history", "weight": 1, "buckets": [
+            {"value": "GOOD", "points": 20},
+            {"value": "REGULAR", "points": 5},
+            {"value": "BAD", "points": -25}
+        ]},
+        {"name": "existing_debt", "input": "debt_ratio_vs_max", "weight": 1, "buckets": [
+            {"max": 0.25, "points": 10},
+            {"min": 0.25, "max": 0.5, "points": 5},
+            {"min": 1, "points": -10}
+        ]},
+        {"name": "employment_stability", "input": "months_employed", "weight": 1, "buckets": [
+            {"min": 24, "points": 10},
+            {"min": 12, "max": 24, "points": 5},
+            {"max": 6, "points": -5}
+        ]}
+    ]
+}'::jsonb, true, 'Migrated from the hard-coded worker scoring', NOW()
+FROM countries c
+ON CONFLICT (country_id, version) DO NOTHING;
@@ -0,0 +1,3 @@
+-- Migración 009 DOWN: Eliminar decisiones de riesgo
+
+DROP TABLE IF EXISTS risk_decisions;
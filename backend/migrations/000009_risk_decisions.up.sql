@@ -0,0 +1,19 @@
+-- Migración 009: Decisiones de riesgo explicables
+-- Cada evaluación guarda el desglose por factor (valor, tramo, puntos) y los
+-- códigos de motivo adversos para poder comunicarlos y auditarlos
+
+CREATE TABLE IF NOT EXISTS risk_decisions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    application_id UUID NOT NULL REFERENCES credit_applications(id) ON DELETE CASCADE,
+    scoring_model_id UUID REFERENCES scoring_models(id) ON DELETE SET NULL,
+    scoring_model_version INTEGER NOT NULL,
+    score DECIMAL(5, 2) NOT NULL,
+    decision VARCHAR(20) NOT NULL,      -- APPROVE, REVIEW, REJECT (según el modelo)
+    outcome VARCHAR(30) NOT NULL,       -- Estado resultante tras las políticas del país
+    requires_review BOOLEAN NOT NULL DEFAULT false,
+    factors JSONB NOT NULL DEFAULT '[]',
+    reason_codes JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_risk_decisions_application ON risk_decisions(application_id, created_at DESC);
@@ -0,0 +1,6 @@
+-- Migración 010 DOWN: Eliminar champion/challenger de modelos de scoring
+
+DROP TABLE IF EXISTS scoring_shadow_results;
+DROP INDEX IF EXISTS idx_scoring_models_challenger;
+ALTER TABLE scoring_models DROP COLUMN IF EXISTS traffic_percent;
+ALTER TABLE scoring_models DROP COLUMN IF EXISTS is_challenger;
@@ -0,0 +1,29 @@
+-- Migración 010: Champion/challenger de modelos de scoring
+-- Un país puede tener una versión challenger que se evalúa en sombra en cada
+-- RISK_EVALUATION. Con traffic_percent > 0 decide sobre ese porcentaje de solicitudes
+
+ALTER TABLE scoring_models ADD COLUMN IF NOT EXISTS is_challenger BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE scoring_models ADD COLUMN IF NOT EXISTS traffic_percent INTEGER NOT NULL DEFAULT 0
+    CHECK (traffic_percent BETWEEN 0 AND 100);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_scoring_models_challenger ON scoring_models(country_id) WHERE is_challenger;
+
+-- Resultado de ambos modelos por evaluación
+CREATE TABLE IF NOT EXISTS scoring_shadow_results (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    application_id UUID NOT NULL REFERENCES credit_applications(id) ON DELETE CASCADE,
+    country_id UUID NOT NULL REFERENCES countries(id) ON DELETE CASCADE,
+    champion_model_id UUID REFERENCES scoring_models(id) ON DELETE SET NULL,
+    champion_version INTEGER NOT NULL,
+    champion_score DECIMAL(5, 2) NOT NULL,
+    champion_decision VARCHAR(20) NOT NULL,
+    challenger_model_id UUID NOT NULL REFERENCES scoring_models(id) ON DELETE CASCADE,
+    challenger_version INTEGER NOT NULL,
+    challenger_score DECIMAL(5, 2) NOT NULL,
+    challenger_decision VARCHAR(20) NOT NULL,
+    decided_by VARCHAR(20) NOT NULL,    -- CHAMPION, CHALLENGER
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scoring_shadow_challenger ON scoring_shadow_results(challenger_model_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_scoring_shadow_application ON scoring_shadow_results(application_id);
@@ -0,0 +1,5 @@
+-- Migración 011 DOWN: Eliminar el resultado de la validación del documento
+
+ALTER TABLE credit_applications DROP COLUMN IF EXISTS document_validated_at;
+ALTER TABLE credit_applications DROP COLUMN IF EXISTS document_validation_message;
+ALTER TABLE credit_applications DROP COLUMN IF EXISTS document_valid;
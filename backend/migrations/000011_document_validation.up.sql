@@ -0,0 +1,7 @@
+-- Migración 011: Resultado de la validación del documento de identidad
+-- El trabajo DOCUMENT_VALIDATION aplica el regex del tipo de documento y el
+-- dígito de control del país y deja aquí el resultado (NULL = pendiente)
+
+ALTER TABLE credit_applications ADD COLUMN IF NOT EXISTS document_valid BOOLEAN;
+ALTER TABLE credit_applications ADD COLUMN IF NOT EXISTS document_validation_message TEXT;
+ALTER TABLE credit_applications ADD COLUMN IF NOT EXISTS document_validated_at TIMESTAMPTZ;
@@ -0,0 +1,10 @@
+-- Migración 012 DOWN: Restaurar los formatos de documento originales
+
+UPDATE document_types SET validation_regex = '^[0-9]{7,9}$'
+WHERE code = 'RG' AND country_id = (SELECT id FROM countries WHERE code = 'BR');
+
+UPDATE document_types SET validation_regex = '^[0-9]{8}$'
+WHERE code = 'CC' AND country_id = (SELECT id FROM countries WHERE code = 'PT');
+
+UPDATE document_types SET validation_regex = '^[A-Z]{6}[0-9]{2}[A-Z][0-9]{2}[A-Z][0-9]{3}[A-Z]$'
+WHERE code = 'CF' AND country_id = (SELECT id FROM countries WHERE code = 'IT');
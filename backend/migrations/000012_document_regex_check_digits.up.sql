@@ -0,0 +1,12 @@
+-- Migración 012: Formatos de documento compatibles con los dígitos de control
+-- RG de São Paulo con dígito verificador X, Cartão de Cidadão completo
+-- (número civil + control + versión + control) y codice fiscale con omocodia
+
+UPDATE document_types SET validation_regex = '^([0-9]{7,8}|[0-9]{8}[0-9X])$'
+WHERE code = 'RG' AND country_id = (SELECT id FROM countries WHERE code = 'BR');
+
+UPDATE document_types SET validation_regex = '^[0-9]{8}([0-9][A-Z0-9]{2}[0-9])?$'
+WHERE code = 'CC' AND country_id = (SELECT id FROM countries WHERE code = 'PT');
+
+UPDATE document_types SET validation_regex = '^[A-Z]{6}[0-9LMNPQRSTUV]{2}[A-Z][0-9LMNPQRSTUV]{2}[A-Z][0-9LMNPQRSTUV]{3}[A-Z]$'
+WHERE code = 'CF' AND country_id = (SELECT id FROM countries WHERE code = 'IT');
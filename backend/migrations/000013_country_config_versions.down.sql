@@ -0,0 +1,3 @@
+-- Migración 013 DOWN: Eliminar las versiones de configuración de países
+
+DROP TABLE IF EXISTS country_config_versions;
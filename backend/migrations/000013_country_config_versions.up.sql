@@ -0,0 +1,19 @@
+-- Migración 013: Versiones inmutables de la configuración de cada país
+-- Cada cambio de reglas, tipos de documento o configuración hecho desde la API
+-- guarda una versión con la foto completa resultante, quién y cuándo lo hizo y
+-- el diff respecto a la anterior. Volver a una versión crea una versión nueva
+
+CREATE TABLE IF NOT EXISTS country_config_versions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    country_id UUID NOT NULL REFERENCES countries(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    change_type VARCHAR(50) NOT NULL,  -- INITIAL, RULE_CREATED, CONFIG_UPDATED, ROLLBACK, etc.
+    summary TEXT,
+    snapshot JSONB NOT NULL,           -- {config, rules, document_types}
+    diff JSONB NOT NULL DEFAULT '[]',
+    rolled_back_to INTEGER,            -- Versión restaurada (solo ROLLBACK)
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE(country_id, version)
+);
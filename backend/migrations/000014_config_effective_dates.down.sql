@@ -0,0 +1,11 @@
+-- Migración 014 DOWN: Quitar la fecha de entrada en vigor de las versiones de configuración
+
+DROP FUNCTION IF EXISTS country_config_at(UUID, TIMESTAMPTZ);
+DROP INDEX IF EXISTS idx_country_config_versions_effective;
+
+-- Las versiones programadas que aún no entraron en vigor dejan de tener sentido
+DELETE FROM country_config_versions WHERE effective_from > NOW() AND change_type = 'CONFIG_SCHEDULED';
+
+ALTER TABLE country_config_versions DROP COLUMN IF EXISTS cancelled_by;
+ALTER TABLE country_config_versions DROP COLUMN IF EXISTS cancelled_at;
+ALTER TABLE country_config_versions DROP COLUMN IF EXISTS effective_from;
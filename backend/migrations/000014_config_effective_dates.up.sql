@@ -0,0 +1,34 @@
+-- Migración 014: Fecha de entrada en vigor de las versiones de configuración
+-- Un cambio de CountryConfig se puede programar para una fecha futura. Las
+-- solicitudes se evalúan con la configuración vigente en su application_date,
+-- de modo que las decisiones pasadas siguen siendo reproducibles
+
+ALTER TABLE country_config_versions ADD COLUMN IF NOT EXISTS effective_from TIMESTAMPTZ;
+ALTER TABLE country_config_versions ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMPTZ;
+ALTER TABLE country_config_versions ADD COLUMN IF NOT EXISTS cancelled_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+-- Los cambios existentes entraron en vigor al guardarse; la versión INITIAL rige desde siempre
+UPDATE country_config_versions SET effective_from = created_at WHERE effective_from IS NULL;
+UPDATE country_config_versions SET effective_from = 'epoch' WHERE change_type = 'INITIAL';
+
+ALTER TABLE country_config_versions ALTER COLUMN effective_from SET DEFAULT NOW();
+ALTER TABLE country_config_versions ALTER COLUMN effective_from SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_country_config_versions_effective
+    ON country_config_versions(country_id, effective_from DESC, version DESC);
+
+-- Configuración vigente de un país en un instante: la última versión no cancelada
+-- en vigor o, si el país nunca se versionó, countries.config
+CREATE OR REPLACE FUNCTION country_config_at(p_country_id UUID, p_at TIMESTAMPTZ)
+RETURNS JSONB AS $$
+    SELECT COALESCE(
+        (SELECT v.snapshot->'config'
+         FROM country_config_versions v
+         WHERE v.country_id = p_country_id
+           AND v.effective_from <= p_at
+           AND v.cancelled_at IS NULL
+         ORDER BY v.effective_from DESC, v.version DESC
+         LIMIT 1),
+        (SELECT c.config FROM countries c WHERE c.id = p_country_id)
+    );
+$$ LANGUAGE sql STABLE;
@@ -0,0 +1,9 @@
+-- Migración 015 DOWN: Quitar los controles de velocidad
+
+UPDATE countries SET config = config - 'velocity' WHERE config->'velocity' IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_applications_ip;
+DROP INDEX IF EXISTS idx_applications_phone;
+DROP INDEX IF EXISTS idx_applications_email;
+
+ALTER TABLE credit_applications DROP COLUMN IF EXISTS velocity_check;
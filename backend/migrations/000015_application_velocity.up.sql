@@ -0,0 +1,21 @@
+-- Migración 015: Controles de duplicados y velocidad al crear solicitudes
+-- Límites de solicitudes abiertas por documento, email, teléfono e IP en una
+-- ventana configurable por país (CountryConfig.velocity). El resultado de cada
+-- control (conteos, límites y acción) queda en la solicitud para auditoría
+
+ALTER TABLE credit_applications ADD COLUMN IF NOT EXISTS velocity_check JSONB;
+
+-- Conteos por email, teléfono (solo dígitos) e IP dentro del país
+CREATE INDEX IF NOT EXISTS idx_applications_email
+    ON credit_applications(country_id, lower(email)) WHERE email IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_applications_phone
+    ON credit_applications(country_id, regexp_replace(phone, '[^0-9]', '', 'g')) WHERE phone IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_applications_ip
+    ON credit_applications(country_id, created_by_ip) WHERE created_by_ip IS NOT NULL;
+
+-- Límites por defecto para los países cuya configuración aún no se versionó por API
+-- (los versionados se configuran con PUT /admin/countries/:code/config)
+UPDATE countries c
+SET config = c.config || '{"velocity": {"window_hours": 720, "max_per_document": 1, "max_per_email": 3, "max_per_phone": 3, "max_per_ip": 0, "action": "REJECT"}}'::jsonb
+WHERE c.config->'velocity' IS NULL
+  AND NOT EXISTS (SELECT 1 FROM country_config_versions v WHERE v.country_id = c.id);
@@ -0,0 +1,10 @@
+-- Migración 016 DOWN: Detección de fraude
+
+UPDATE countries SET config = config - 'fraud' WHERE config->'fraud' IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_applications_fraud_score;
+DROP INDEX IF EXISTS idx_applications_ip_inet;
+
+ALTER TABLE credit_applications DROP COLUMN IF EXISTS fraud_checked_at;
+ALTER TABLE credit_applications DROP COLUMN IF EXISTS fraud_signals;
+ALTER TABLE credit_applications DROP COLUMN IF EXISTS fraud_score;
@@ -0,0 +1,23 @@
+-- Migración 016: Detección de fraude (trabajo FRAUD_CHECK)
+-- Tras obtener la información bancaria se calculan señales de fraude (documento con
+-- otro nombre, email/teléfono compartidos, email desechable, ingreso incoherente con
+-- el buró, ráfaga desde una subred) y se guarda el score. RISK_EVALUATION fuerza la
+-- revisión si el score alcanza CountryConfig.fraud.review_threshold
+
+ALTER TABLE credit_applications ADD COLUMN IF NOT EXISTS fraud_score DECIMAL(5,2);
+ALTER TABLE credit_applications ADD COLUMN IF NOT EXISTS fraud_signals JSONB;
+ALTER TABLE credit_applications ADD COLUMN IF NOT EXISTS fraud_checked_at TIMESTAMPTZ;
+
+-- Ráfagas por subred (el mismo documento usa idx_applications_document)
+CREATE INDEX IF NOT EXISTS idx_applications_ip_inet
+    ON credit_applications USING gist (created_by_ip inet_ops) WHERE created_by_ip IS NOT NULL;
+
+-- Solicitudes sospechosas para los analistas
+CREATE INDEX IF NOT EXISTS idx_applications_fraud_score
+    ON credit_applications(fraud_score DESC) WHERE fraud_score > 0;
+
+-- Umbral por defecto para los países cuya configuración aún no se versionó por API
+UPDATE countries c
+SET config = c.config || '{"fraud": {"review_threshold": 50}}'::jsonb
+WHERE c.config->'fraud' IS NULL
+  AND NOT EXISTS (SELECT 1 FROM country_config_versions v WHERE v.country_id = c.id);
@@ -0,0 +1,9 @@
+-- Migración 017 DOWN: Listas de sanciones y PEP
+
+ALTER TABLE credit_applications DROP COLUMN IF EXISTS screened_at;
+ALTER TABLE credit_applications DROP COLUMN IF EXISTS screening_status;
+
+DROP TABLE IF EXISTS watchlist_hits;
+DROP TABLE IF EXISTS watchlist_names;
+DROP TABLE IF EXISTS watchlist_entries;
+DROP TABLE IF EXISTS watchlist_imports;
@@ -0,0 +1,75 @@
+-- Migración 017: Listas de sanciones y PEP y screening de solicitantes
+-- Las listas se importan por fuente (UN, OFAC, PEP_MX...) reemplazando la carga
+-- anterior. Los nombres se guardan normalizados (sin acentos, mayúsculas) y se
+-- comparan con pg_trgm; los documentos, por coincidencia exacta. Cada posible
+-- coincidencia queda como hit pendiente hasta que un analista la resuelve
+
+-- Cargas de listas (una fila por importación)
+CREATE TABLE IF NOT EXISTS watchlist_imports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source VARCHAR(50) NOT NULL,           -- UN, OFAC, EU, PEP_MX...
+    list_type VARCHAR(20) NOT NULL,        -- SANCTIONS, PEP
+    format VARCHAR(10) NOT NULL,           -- CSV, XML
+    file_name VARCHAR(255),
+    entries INT NOT NULL DEFAULT 0,
+    names INT NOT NULL DEFAULT 0,
+    imported_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_watchlist_imports_source ON watchlist_imports(source, created_at DESC);
+
+-- Personas y entidades listadas (la carga vigente de cada fuente)
+CREATE TABLE IF NOT EXISTS watchlist_entries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    import_id UUID NOT NULL REFERENCES watchlist_imports(id) ON DELETE CASCADE,
+    source VARCHAR(50) NOT NULL,
+    list_type VARCHAR(20) NOT NULL,
+    external_id VARCHAR(100) NOT NULL,     -- Identificador en la lista (DATAID, referencia...)
+    full_name VARCHAR(500) NOT NULL,
+    aliases TEXT[] NOT NULL DEFAULT '{}',
+    document_numbers TEXT[] NOT NULL DEFAULT '{}', -- Normalizados: mayúsculas, solo letras y dígitos
+    country VARCHAR(100),
+    program VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(source, external_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_watchlist_entries_documents ON watchlist_entries USING gin (document_numbers);
+
+-- Nombres y alias normalizados para la búsqueda difusa
+CREATE TABLE IF NOT EXISTS watchlist_names (
+    entry_id UUID NOT NULL REFERENCES watchlist_entries(id) ON DELETE CASCADE,
+    name VARCHAR(500) NOT NULL,
+    normalized_name VARCHAR(500) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_watchlist_names_entry ON watchlist_names(entry_id);
+CREATE INDEX IF NOT EXISTS idx_watchlist_names_trgm ON watchlist_names USING gin (normalized_name gin_trgm_ops);
+
+-- Posibles coincidencias de una solicitud. Guardan los datos de la entrada para
+-- conservar la evidencia aunque la lista se vuelva a importar
+CREATE TABLE IF NOT EXISTS watchlist_hits (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    application_id UUID NOT NULL REFERENCES credit_applications(id) ON DELETE CASCADE,
+    entry_id UUID REFERENCES watchlist_entries(id) ON DELETE SET NULL,
+    source VARCHAR(50) NOT NULL,
+    list_type VARCHAR(20) NOT NULL,
+    external_id VARCHAR(100) NOT NULL,
+    entry_name VARCHAR(500) NOT NULL,
+    matched_name VARCHAR(500) NOT NULL,    -- Nombre o alias de la lista que coincidió
+    match_type VARCHAR(20) NOT NULL,       -- NAME, DOCUMENT
+    score DECIMAL(5,4) NOT NULL,           -- Similitud 0-1 (1 en coincidencia de documento)
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING', -- PENDING, CONFIRMED, DISMISSED
+    resolution_note TEXT,
+    resolved_by UUID REFERENCES users(id),
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(application_id, source, external_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_watchlist_hits_pending ON watchlist_hits(created_at) WHERE status = 'PENDING';
+
+-- Resultado del screening en la solicitud (NULL = aún no se hizo)
+ALTER TABLE credit_applications ADD COLUMN IF NOT EXISTS screening_status VARCHAR(20); -- CLEAR, POTENTIAL_HIT, CONFIRMED_HIT
+ALTER TABLE credit_applications ADD COLUMN IF NOT EXISTS screened_at TIMESTAMPTZ;
@@ -0,0 +1,5 @@
+-- Migración 018 DOWN: Aviso de trabajos nuevos con LISTEN/NOTIFY
+
+DROP TRIGGER IF EXISTS jobs_queue_notify_update ON jobs_queue;
+DROP TRIGGER IF EXISTS jobs_queue_notify_insert ON jobs_queue;
+DROP FUNCTION IF EXISTS notify_job_ready();
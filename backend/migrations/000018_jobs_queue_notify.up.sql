@@ -0,0 +1,27 @@
+-- Migración 018: Aviso de trabajos nuevos con LISTEN/NOTIFY
+-- Cada trabajo que queda PENDING o RETRYING (insert, reintento o reprogramación)
+-- publica en el canal jobs_queue su id, tipo y scheduled_at. Los workers despiertan
+-- al instante si ya puede ejecutarse o programan el despertar para scheduled_at;
+-- el polling queda solo como respaldo
+
+CREATE OR REPLACE FUNCTION notify_job_ready()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status IN ('PENDING', 'RETRYING') THEN
+        PERFORM pg_notify('jobs_queue', json_build_object(
+            'id', NEW.id,
+            'type', NEW.type,
+            'scheduled_at', NEW.scheduled_at
+        )::text);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER jobs_queue_notify_insert AFTER INSERT ON jobs_queue
+    FOR EACH ROW EXECUTE FUNCTION notify_job_ready();
+
+CREATE TRIGGER jobs_queue_notify_update AFTER UPDATE OF status, scheduled_at ON jobs_queue
+    FOR EACH ROW
+    WHEN (OLD.status IS DISTINCT FROM NEW.status OR OLD.scheduled_at IS DISTINCT FROM NEW.scheduled_at)
+    EXECUTE FUNCTION notify_job_ready();
@@ -0,0 +1,4 @@
+-- Migración 019 DOWN: Dead-letter de la cola de trabajos
+
+DROP INDEX IF EXISTS idx_jobs_dead_letter;
+DROP TABLE IF EXISTS job_events;
@@ -0,0 +1,21 @@
+-- Migración 019: Dead-letter de la cola de trabajos
+-- job_events guarda el historial de cada trabajo: los errores de cada intento
+-- (RETRYING/FAILED), las reprogramaciones por rate limit y las acciones de los
+-- admins sobre los trabajos muertos (reencolar, editar payload, cancelar)
+
+CREATE TABLE IF NOT EXISTS job_events (
+    id BIGSERIAL PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES jobs_queue(id) ON DELETE CASCADE,
+    event VARCHAR(30) NOT NULL,      -- RETRYING, FAILED, THROTTLED, REQUEUED, PAYLOAD_EDITED, CANCELLED
+    attempt INT,                     -- Intento al que corresponde (errores)
+    message TEXT,                    -- Error o motivo
+    actor_id UUID REFERENCES users(id),
+    details JSONB,                   -- Ej. payload anterior al editar
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_events_job ON job_events(job_id, created_at);
+
+-- Vista de dead-letter: trabajos fallidos o cancelados por tipo y fecha
+CREATE INDEX IF NOT EXISTS idx_jobs_dead_letter ON jobs_queue(status, type, completed_at DESC)
+    WHERE status IN ('FAILED', 'CANCELLED');
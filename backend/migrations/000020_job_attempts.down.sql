@@ -0,0 +1,3 @@
+-- Migración 020 DOWN: Historial de ejecuciones de trabajos
+
+DROP TABLE IF EXISTS job_attempts;
@@ -0,0 +1,19 @@
+-- Migración 020: Historial de ejecuciones de trabajos
+-- Una fila por dequeue: qué worker lo ejecutó, cuándo, cuánto tardó, cómo terminó
+-- y el error (con el stack truncado si el handler entró en pánico). Si el worker
+-- muere a mitad de ejecución, la recuperación de huérfanos cierra el intento como ABANDONED
+
+CREATE TABLE IF NOT EXISTS job_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES jobs_queue(id) ON DELETE CASCADE,
+    attempt INT NOT NULL,               -- jobs_queue.attempts al hacer dequeue
+    worker_id VARCHAR(100) NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ,
+    duration_ms BIGINT,
+    outcome VARCHAR(20) NOT NULL DEFAULT 'RUNNING', -- RUNNING, SUCCEEDED, FAILED, THROTTLED, PANICKED, ABANDONED
+    error TEXT,
+    stack TEXT                          -- Solo en PANICKED, truncado
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_attempts_job ON job_attempts(job_id, started_at);
@@ -0,0 +1,5 @@
+-- Migración 021 DOWN: Lease de los trabajos en ejecución
+
+DROP INDEX IF EXISTS idx_jobs_queue_lease;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS lease_token;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS lease_expires_at;
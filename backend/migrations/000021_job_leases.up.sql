@@ -0,0 +1,14 @@
+-- Migración 021: Lease de los trabajos en ejecución
+-- El worker renueva lease_expires_at con un heartbeat mientras el handler se ejecuta.
+-- La recuperación de huérfanos solo reclama trabajos PROCESSING con el lease vencido,
+-- así un trabajo lento pero vivo no se ejecuta dos veces. Un handler que supera el
+-- timeout de su tipo cierra su intento en job_attempts como TIMED_OUT
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMPTZ;
+
+-- Token de cada dequeue: el heartbeat solo renueva si el trabajo sigue con el suyo.
+-- worker_id no sirve porque todos los procesos nombran igual a sus workers
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS lease_token UUID;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_queue_lease ON jobs_queue(lease_expires_at)
+    WHERE status = 'PROCESSING';
@@ -0,0 +1,4 @@
+-- Migración 022 DOWN: Fallos que cuentan para el circuit breaker y llamada de prueba
+
+DROP TABLE IF EXISTS banking_circuit_probes;
+ALTER TABLE banking_requests DROP COLUMN IF EXISTS failure_kind;
@@ -0,0 +1,14 @@
+-- Migración 022: Fallos que cuentan para el circuit breaker y llamada de prueba
+-- failure_kind clasifica cada llamada FAILED. Solo TRANSPORT, SERVER (5xx) y TIMEOUT
+-- indican que el proveedor está caído; un 4xx de negocio (documento no encontrado),
+-- una respuesta que no se pudo mapear o una consulta asíncrona vencida sin reporte
+-- no abren el circuito. Los registros anteriores (NULL) cuentan como caída
+
+ALTER TABLE banking_requests ADD COLUMN IF NOT EXISTS failure_kind VARCHAR(20);
+
+-- Reserva de la llamada de prueba en HALF_OPEN: solo la consigue quien inserta la
+-- fila o renueva una reserva vencida, así la API y los workers no prueban a la vez
+CREATE TABLE IF NOT EXISTS banking_circuit_probes (
+    provider_id UUID PRIMARY KEY REFERENCES banking_providers(id) ON DELETE CASCADE,
+    claimed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);